@@ -9,6 +9,9 @@ type decimalMapper struct {
 	base *big.Int
 }
 
+// NewDecimalMapperFromFloat truncates the scaled amount.
+//
+// Deprecated: float amounts may end up off by one base unit, pass base units to NewDecimalMapperFromBig.
 func NewDecimalMapperFromFloat(amount float64, decimals uint) *decimalMapper {
 	qtr := math.Pow(10, float64(decimals))
 	base := big.NewInt(int64(amount * qtr))
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+
+	"github.com/Gravity-Tech/solanoid/commands"
+	_ "github.com/Gravity-Tech/solanoid/commands/gateway"
+)
+
+func main() {
+	if err := commands.SolanoidCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/types"
@@ -39,7 +38,7 @@ func init() {
 	viper.BindPFlag("program", callMemoCmd.Flags().Lookup("program"))
 	callMemoCmd.MarkFlagRequired("program")
 
-	callMemoCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding")
+	callMemoCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", callMemoCmd.Flags().Lookup("private-key"))
 	callMemoCmd.MarkFlagRequired("private-key")
 
@@ -66,7 +65,7 @@ func NewCallMemoInstruction(fromAccount, targetProgramID common.PublicKey, msg s
 }
 
 func callMemo(ccmd *cobra.Command, args []string) {
-	pk, err := base58.Decode(UpdateConsulsPrivateKey)
+	pk, err := DecodePrivateKey(UpdateConsulsPrivateKey)
 	if err != nil {
 		zap.L().Fatal(err.Error())
 	}
@@ -1,15 +1,14 @@
 package commands
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"log"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/gorilla/websocket"
+	"github.com/Gravity-Tech/solanoid/commands/ws"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -77,59 +76,23 @@ func waitTx(tx string, host string) {
 	u := url.URL{Scheme: "ws", Host: host, Path: "/"}
 	log.Printf("connecting to %s", u.String())
 
-	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	ctx := context.Background()
+
+	client, err := ws.Dial(ctx, u.String())
 	if err != nil {
 		log.Fatal("dial:", err)
 	}
-	defer c.Close()
-	req := `
-	{
-		"jsonrpc": "2.0",
-		"id": 1,
-		"method": "signatureSubscribe",
-		"params": [
-		  "%s",
-		  {
-			"commitment": "finalized"
-		  }
-		]
-	  }
-	`
-	unsubscribeRequest := "{\"jsonrpc\":\"2.0\", \"id\":1, \"method\":\"signatureUnsubscribe\", \"params\":[%d]}"
-	done := make(chan struct{})
-	defer close(done)
-	go func() {
-
-		subscription := 0
-		for {
-			_, message, err := c.ReadMessage()
-			if err != nil {
-				log.Println("read:", err)
-				return
-			}
-			log.Printf("recv: %s", message)
-			a := struct {
-				Method       string `json:"method"`
-				Result       int    `json:"result"`
-				Subscription int    `json:"subscription"`
-			}{}
-			json.Unmarshal(message, &a)
-			switch a.Method {
-			case "":
-				subscription = a.Subscription
-			case "signatureNotification":
-				c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(unsubscribeRequest, subscription)))
-				//time.Sleep(time.Millisecond * 100)
-				c.Close()
-				done <- struct{}{}
-				return
-			}
-
-		}
+	defer client.Close()
 
-	}()
+	sub, err := client.SignatureSubscribe(ctx, tx, ws.CommitmentFinalized)
+	if err != nil {
+		log.Fatal("subscribe:", err)
+	}
 
-	err = c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(req, tx)))
-	<-done
-	//time.Sleep(time.Millisecond * 100)
+	result, err := sub.Wait(ctx)
+	if err != nil {
+		log.Println("read:", err)
+		return
+	}
+	log.Printf("recv: slot %d, err %v", result.Context.Slot, result.Value.Err)
 }
@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
 
 	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/client"
@@ -22,12 +23,16 @@ var (
 	BPFLoader2ProgramID common.PublicKey = common.PublicKeyFromString("BPFLoader2111111111111111111111111111111111")
 	filename            string
 	privateKey          string
+	programKeypairPath  string
+	maxDataLen          uint64
+	legacyDeploy        bool
+	rpcURL              string
 	// alias for show
 	deployCmd = &cobra.Command{
 		Hidden: false,
 
 		Use:   "deploy",
-		Short: "Display a file from the hoarder storage",
+		Short: "Deploy program via BPF Upgradeable Loader (or BPF Loader 2 with --legacy)",
 		Long:  ``,
 		Run:   deploy,
 	}
@@ -55,21 +60,35 @@ func init() {
 	viper.BindPFlag("program-file", SolanoidCmd.Flags().Lookup("program-file"))
 	deployCmd.MarkFlagRequired("program-file")
 
-	deployCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "private key in base58 encoding")
+	deployCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", SolanoidCmd.Flags().Lookup("private-key"))
 	deployCmd.MarkFlagRequired("private-key")
 
+	deployCmd.Flags().StringVar(&programKeypairPath, "program-keypair", "", "Path to program keypair JSON, new program address is generated if omitted")
+	viper.BindPFlag("program-keypair", deployCmd.Flags().Lookup("program-keypair"))
+
+	deployCmd.Flags().Uint64Var(&maxDataLen, "max-len", 0, "Maximum program size reserved for upgrades, twice the binary size by default")
+	viper.BindPFlag("max-len", deployCmd.Flags().Lookup("max-len"))
+
+	deployCmd.Flags().BoolVar(&legacyDeploy, "legacy", false, "Deploy non upgradeable program with BPF Loader 2")
+	viper.BindPFlag("legacy", deployCmd.Flags().Lookup("legacy"))
+
+	deployCmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	viper.BindPFlag("url", deployCmd.Flags().Lookup("url"))
+
+	deployCmd.Flags().StringVar(&bufferAddress, "buffer", "", "Buffer of the interrupted deploy to resume")
+	viper.BindPFlag("buffer", deployCmd.Flags().Lookup("buffer"))
+
 	SolanoidCmd.AddCommand(deployCmd)
 }
-func createNewAccountForProgram(c *client.Client, account types.Account, space uint64) types.Account {
 
-	res, err := c.GetRecentBlockhash(context.Background())
+// createNewAccountForProgram returns once the account creation is confirmed, the upload writes to it right after
+func createNewAccountForProgram(c *client.Client, endpoint string, account types.Account, program types.Account, space uint64) types.Account {
+	res, err := executor.GetLatestBlockhash(context.Background(), endpoint)
 	if err != nil {
 		log.Fatalf("get recent block hash error, err: %v\n", err)
 	}
 
-	program := types.NewAccount()
-
 	rentBalance, err := c.GetMinimumBalanceForRentExemption(context.Background(), space)
 	if err != nil {
 		zap.L().Fatal(err.Error())
@@ -113,77 +132,71 @@ func createNewAccountForProgram(c *client.Client, account types.Account, space u
 	}
 
 	log.Println("txHash:", txSig)
+
+	_, err = executor.AwaitConfirmation(context.Background(), endpoint, txSig, client.CommitmentConfirmed, res.LastValidBlockHeight)
+	if err != nil {
+		log.Fatalf("await program account creation error, err: %v\n", err)
+	}
+
 	return program
 }
-func uploadDataToProgram(c *client.Client, program types.Account, account types.Account, data []byte, chunkSize int) {
-	chunks := splitArray(data, chunkSize)
-	for i, chunk := range chunks {
-		chunkData, err := common.SerializeData(struct {
-			Instruction uint32
-			Offset      uint32
-			Length      uint32
-			Padding     uint32
-			Data        []byte
-		}{
-			Instruction: 0,
-			Offset:      uint32(i) * uint32(chunkSize),
-			Length:      uint32(len(chunk)),
-			Data:        chunk,
-		})
-		if err != nil {
-			log.Fatalf("Serialize error, err: %v\n", err)
-		}
-		//zap.L().Sugar().Debug("data: ", chunkData)
-		res, err := c.GetRecentBlockhash(context.Background())
-		if err != nil {
-			log.Fatalf("get recent block hash error, err: %v\n", err)
-		}
+func legacyWriteInstruction(program common.PublicKey, offset uint32, chunk []byte) types.Instruction {
+	chunkData, err := common.SerializeData(struct {
+		Instruction uint32
+		Offset      uint32
+		Length      uint32
+		Padding     uint32
+		Data        []byte
+	}{
+		Instruction: 0,
+		Offset:      offset,
+		Length:      uint32(len(chunk)),
+		Data:        chunk,
+	})
+	if err != nil {
+		panic(err)
+	}
 
-		message2 := types.NewMessage(
-			account.PublicKey,
-			[]types.Instruction{
-				{
-					ProgramID: BPFLoader2ProgramID,
-					Accounts: []types.AccountMeta{
-						{
-							PubKey:     program.PublicKey,
-							IsSigner:   true,
-							IsWritable: true,
-						},
-					},
-					Data: chunkData,
-				},
+	return types.Instruction{
+		ProgramID: BPFLoader2ProgramID,
+		Accounts: []types.AccountMeta{
+			{
+				PubKey:     program,
+				IsSigner:   true,
+				IsWritable: true,
 			},
-			res.Blockhash,
-		)
-
-		serializedMessage2, err := message2.Serialize()
-		if err != nil {
-			log.Fatalf("serialize message error, err: %v\n", err)
-		}
-
-		tx2, err := types.CreateTransaction(message2, map[common.PublicKey]types.Signature{
-			program.PublicKey: ed25519.Sign(program.PrivateKey, serializedMessage2),
-			account.PublicKey: ed25519.Sign(account.PrivateKey, serializedMessage2),
-		})
-		if err != nil {
-			log.Fatalf("generate tx error, err: %v\n", err)
-		}
+		},
+		Data: chunkData,
+	}
+}
 
-		rawTx2, err := tx2.Serialize()
-		if err != nil {
-			log.Fatalf("serialize tx error, err: %v\n", err)
-		}
+// uploadDataToProgram reads the program account back and sends only the chunks which differ from data,
+// so rerunning it against the same program account resumes the upload.
+func uploadDataToProgram(ctx context.Context, endpoint string, program types.Account, account types.Account, data []byte, chunkSize int) (*UploadReport, error) {
+	remote, owner, err := ReadAccountData(ctx, endpoint, program.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if owner != BPFLoader2ProgramID.ToBase58() {
+		return nil, fmt.Errorf("program account %v is not owned by BPF Loader 2", program.PublicKey.ToBase58())
+	}
+	if len(remote) != len(data) {
+		return nil, fmt.Errorf("program account %v size %v does not match binary size %v", program.PublicKey.ToBase58(), len(remote), len(data))
+	}
 
-		tx2Sig, err := c.SendRawTransaction(context.Background(), rawTx2)
-		if err != nil {
-			log.Fatalf("send tx error, err: %v\n", err)
-		}
+	chunks := DiffChunks(data, remote, chunkSize)
+	total := len(splitArray(data, chunkSize))
 
-		log.Printf("upload program txHash [chunk %d]: %s", i, tx2Sig)
-		time.Sleep(time.Second * 1)
+	log.Printf("program %v: %d of %d chunks to upload", program.PublicKey.ToBase58(), len(chunks), total)
 
-	}
+	return NewChunkUploader().Upload(ctx, total, chunks, func(ctx context.Context, chunk UploadChunk) (string, error) {
+		return sendSigned(
+			ctx, endpoint, account,
+			[]types.Instruction{legacyWriteInstruction(program.PublicKey, uint32(chunk.Offset), chunk.Data)},
+			client.CommitmentConfirmed,
+			program,
+		)
+	})
 }
 
 func finalizeProgramDeployment(c *client.Client, program types.Account, account types.Account) {
@@ -290,38 +303,96 @@ func createAttachedAccountToProgram(c *client.Client, program types.Account, acc
 	return newAcc
 }
 
-// show utilizes the api to show data associated to key
+func resolveRPCEndpoint(url string) string {
+	if url != "" {
+		return url
+	}
+
+	endpoint, err := InferSystemDefinedRPC()
+	if err != nil {
+		zap.L().Fatal(err.Error())
+	}
+	return endpoint
+}
+
 func deploy(ccmd *cobra.Command, args []string) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		zap.L().Fatal(err.Error())
 	}
 
-	pk, err := base58.Decode(privateKey)
+	pk, err := DecodePrivateKey(privateKey)
 	if err != nil {
 		zap.L().Fatal(err.Error())
 	}
 	account := types.AccountFromPrivateKeyBytes(pk)
 
-	c := client.NewClient(client.TestnetRPCEndpoint)
+	endpoint := resolveRPCEndpoint(rpcURL)
+
+	program := types.NewAccount()
+	if programKeypairPath != "" {
+		program, err = ReadAccountFromPath(programKeypairPath)
+		if err != nil {
+			zap.L().Fatal(err.Error())
+		}
+	}
 
-	program := createNewAccountForProgram(c, account, uint64(len(data)))
+	if legacyDeploy {
+		deployLegacy(endpoint, account, program, data)
+		return
+	}
 
-	fmt.Print("Waiting  ")
-	for i := 0; i < 25; i++ {
-		time.Sleep(time.Second * 1)
-		fmt.Print(".")
+	deployer, err := NewUpgradeableDeployer(account, endpoint)
+	if err != nil {
+		zap.L().Fatal(err.Error())
 	}
-	fmt.Print("\n")
-	//deploy start
-	uploadDataToProgram(c, program, account, data, 940)
 
-	fmt.Print("Waiting  ")
-	for i := 0; i < 25; i++ {
-		time.Sleep(time.Second * 1)
-		fmt.Print(".")
+	result, err := deployer.ResumeDeployProgram(context.Background(), program, account, data, maxDataLen, resumeBuffer())
+	if err != nil {
+		if result != nil {
+			fmt.Printf("Resume with: --buffer %s --program-keypair <path to %s keypair>\n", result.Buffer.ToBase58(), result.ProgramID.ToBase58())
+			fmt.Printf("Program PrivateKey: %s\n", base58.Encode(program.PrivateKey))
+		}
+		log.Fatalf("Error on 'DeployProgram': %v\n", err)
+	}
+
+	fmt.Printf("Program PubKey(Id): %s\n", result.ProgramID.ToBase58())
+	if programKeypairPath == "" {
+		fmt.Printf("Program PrivateKey: %s\n", base58.Encode(program.PrivateKey))
+	}
+	fmt.Printf("Program Data: %s\n", result.ProgramData.ToBase58())
+	fmt.Printf("Signature: %s\n", result.Signature)
+}
+
+func resumeBuffer() *common.PublicKey {
+	if bufferAddress == "" {
+		return nil
+	}
+
+	buffer := common.PublicKeyFromString(bufferAddress)
+	return &buffer
+}
+
+func deployLegacy(endpoint string, account types.Account, program types.Account, data []byte) {
+	c := client.NewClient(endpoint)
+
+	_, owner, err := ReadAccountData(context.Background(), endpoint, program.PublicKey)
+	if err != nil {
+		zap.L().Fatal(err.Error())
+	}
+
+	// existing program account means the previous upload was interrupted, so it is resumed
+	if owner == "" {
+		program = createNewAccountForProgram(c, endpoint, account, program, uint64(len(data)))
+	}
+
+	//deploy start
+	_, err = uploadDataToProgram(context.Background(), endpoint, program, account, data, 940)
+	if err != nil {
+		fmt.Printf("Resume with: --legacy --program-keypair <path to %s keypair>\n", program.PublicKey.ToBase58())
+		fmt.Printf("Program PrivateKey: %s\n", base58.Encode(program.PrivateKey))
+		log.Fatalf("Error on 'uploadDataToProgram': %v\n", err)
 	}
-	fmt.Print("\n")
 
 	//finalize
 	finalizeProgramDeployment(c, program, account)
@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// AmountFormat selects how token amounts are laid out in port instructions and byte vectors
+type AmountFormat uint8
+
+const (
+	// AmountFormatUnset is the zero value, the codec rejects it: the deployed programs read f64
+	// while the newer ones read u64, so the format has to be picked explicitly
+	AmountFormatUnset AmountFormat = iota
+	// AmountFormatU64 is little endian u64 of token base units
+	AmountFormatU64
+	// AmountFormatFloat64 is little endian f64 of UI amount, the programs convert it as `(amount * 10^decimals) as u64`.
+	// Kept for programs deployed before base units.
+	AmountFormatFloat64
+)
+
+func (format AmountFormat) String() string {
+	switch format {
+	case AmountFormatUnset:
+		return "unset"
+	case AmountFormatU64:
+		return "u64"
+	case AmountFormatFloat64:
+		return "f64"
+	}
+	return fmt.Sprintf("AmountFormat(%d)", uint8(format))
+}
+
+func ParseAmountFormat(format string) (AmountFormat, error) {
+	for _, candidate := range []AmountFormat{AmountFormatU64, AmountFormatFloat64} {
+		if candidate.String() == format {
+			return candidate, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown amount format: %v", format)
+}
+
+var errAmountFormatUnset = fmt.Errorf("amount format is not set, pick %v or %v to match the deployed port", AmountFormatU64, AmountFormatFloat64)
+
+// EVMAmountSize is the uint256 width of amounts in EVM byte vectors
+const EVMAmountSize = 32
+
+// EVMAmountLayout selects how amounts are laid out in EVM byte vectors, it does not depend on the Solana format
+type EVMAmountLayout uint8
+
+const (
+	// EVMAmountUint256 is the big endian uint256 padded to EVMAmountSize
+	EVMAmountUint256 EVMAmountLayout = iota
+	// EVMAmountUnpadded keeps the minimal big endian bytes the legacy builders produced
+	EVMAmountUnpadded
+)
+
+// AmountCodec encodes base units of the token with Decimals into the 8 byte amount field
+type AmountCodec struct {
+	Format   AmountFormat
+	Decimals uint8
+}
+
+// LegacyAmountCodec matches the float encoding of the deployed port programs
+var LegacyAmountCodec = AmountCodec{Format: AmountFormatFloat64, Decimals: DefaultDecimals}
+
+func NewAmountCodec(format AmountFormat, decimals uint8) AmountCodec {
+	return AmountCodec{Format: format, Decimals: decimals}
+}
+
+// floatToBaseUnits mirrors the program side conversion, including the truncation
+func floatToBaseUnits(amount float64, decimals uint8) (uint64, bool) {
+	scaled := amount * math.Pow10(int(decimals))
+	if math.IsNaN(scaled) || scaled < 0 || scaled >= math.MaxUint64 {
+		return 0, false
+	}
+	return uint64(scaled), true
+}
+
+// Encode rejects amounts the float format cannot carry exactly, these are the ones ending up off by one unit
+func (codec AmountCodec) Encode(amount uint64) ([]byte, error) {
+	encoded := make([]byte, 8)
+
+	switch codec.Format {
+	case AmountFormatU64:
+		binary.LittleEndian.PutUint64(encoded, amount)
+	case AmountFormatFloat64:
+		uiAmount := float64(amount) / math.Pow10(int(codec.Decimals))
+
+		// nearest float may be just below the amount, the next one up survives the truncation
+		decoded, ok := floatToBaseUnits(uiAmount, codec.Decimals)
+		if ok && decoded != amount {
+			uiAmount = math.Nextafter(uiAmount, math.Inf(1))
+			decoded, ok = floatToBaseUnits(uiAmount, codec.Decimals)
+		}
+		if !ok || decoded != amount {
+			return nil, fmt.Errorf("amount %d with %d decimals is not representable as f64, program would read %d", amount, codec.Decimals, decoded)
+		}
+
+		binary.LittleEndian.PutUint64(encoded, math.Float64bits(uiAmount))
+	case AmountFormatUnset:
+		return nil, errAmountFormatUnset
+	default:
+		return nil, fmt.Errorf("unknown amount format: %v", codec.Format)
+	}
+
+	return encoded, nil
+}
+
+func (codec AmountCodec) Decode(encoded []byte) (uint64, error) {
+	if len(encoded) != 8 {
+		return 0, fmt.Errorf("invalid amount length: %d", len(encoded))
+	}
+
+	raw := binary.LittleEndian.Uint64(encoded)
+
+	switch codec.Format {
+	case AmountFormatU64:
+		return raw, nil
+	case AmountFormatFloat64:
+		uiAmount := math.Float64frombits(raw)
+
+		amount, ok := floatToBaseUnits(uiAmount, codec.Decimals)
+		if !ok {
+			return 0, fmt.Errorf("invalid f64 amount: %v", uiAmount)
+		}
+		return amount, nil
+	case AmountFormatUnset:
+		return 0, errAmountFormatUnset
+	}
+
+	return 0, fmt.Errorf("unknown amount format: %v", codec.Format)
+}
+
+// EncodeEVMAmount lays the amount out for EVM ports
+func EncodeEVMAmount(amount *big.Int, layout EVMAmountLayout) ([]byte, error) {
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("negative amount: %v", amount)
+	}
+	if amount.BitLen() > EVMAmountSize*8 {
+		return nil, fmt.Errorf("amount %v overflows uint256", amount)
+	}
+
+	switch layout {
+	case EVMAmountUint256:
+		encoded := make([]byte, EVMAmountSize)
+		return amount.FillBytes(encoded), nil
+	case EVMAmountUnpadded:
+		return amount.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("unknown evm amount layout: %d", layout)
+}
+
+// ConvertDecimals rescales base units between tokens, scaling down must not drop non zero digits
+func ConvertDecimals(amount *big.Int, fromDecimals, toDecimals uint8) (*big.Int, error) {
+	if fromDecimals == toDecimals {
+		return new(big.Int).Set(amount), nil
+	}
+
+	if fromDecimals < toDecimals {
+		qtr := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil)
+		return new(big.Int).Mul(amount, qtr), nil
+	}
+
+	qtr := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil)
+	converted, remainder := new(big.Int).QuoRem(amount, qtr, new(big.Int))
+	if remainder.Sign() != 0 {
+		return nil, fmt.Errorf("amount %v loses precision converting from %d to %d decimals", amount, fromDecimals, toDecimals)
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+func TestAmountCodecU64(t *testing.T) {
+	codec := NewAmountCodec(AmountFormatU64, 8)
+
+	encoded, err := codec.Encode(math.MaxUint64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := codec.Decode(encoded)
+	if err != nil || decoded != math.MaxUint64 {
+		t.Fatalf("round trip failed: %v, %v", decoded, err)
+	}
+}
+
+func TestAmountCodecFloat64(t *testing.T) {
+	codec := LegacyAmountCodec
+
+	// 2.227 * 1e8 truncates to 222699999 on the program side
+	for _, amount := range []uint64{222700000, 1, 100000000, 123456789} {
+		encoded, err := codec.Encode(amount)
+		if err != nil {
+			t.Fatalf("%d: %v", amount, err)
+		}
+
+		decoded, err := codec.Decode(encoded)
+		if err != nil || decoded != amount {
+			t.Fatalf("%d: round trip gave %d, %v", amount, decoded, err)
+		}
+	}
+
+	// beyond 2^53 base units f64 has no exact representation
+	if _, err := codec.Encode(1<<53 + 1); err == nil {
+		t.Fatal("lossy amount must be rejected")
+	}
+}
+
+func TestConvertDecimals(t *testing.T) {
+	amount, err := ConvertDecimals(big.NewInt(123), 8, 18)
+	if err != nil || amount.String() != "1230000000000" {
+		t.Fatalf("unexpected scale up: %v, %v", amount, err)
+	}
+
+	amount, err = ConvertDecimals(amount, 18, 8)
+	if err != nil || amount.Int64() != 123 {
+		t.Fatalf("unexpected scale down: %v, %v", amount, err)
+	}
+
+	if _, err := ConvertDecimals(big.NewInt(1230000000001), 18, 8); err == nil {
+		t.Fatal("dropping non zero digits must be rejected")
+	}
+}
+
+func TestByteArrayBuildersUseBaseUnits(t *testing.T) {
+	receiver := common.PublicKeyFromString("EnwGpvfZdCpkjs8jMShjo8evce2LbNfrYvREzdwGh5oc")
+
+	evmToSolana := &EVMToSolanaBABuilder{
+		Amount:   new(big.Int).Mul(big.NewInt(2227), big.NewInt(1e15)),
+		Receiver: receiver,
+	}
+	evmToSolana.SetCfg(BACfg{OriginDecimals: 18, DestDecimals: 8})
+	if _, err := evmToSolana.BuildForDirect(); err == nil {
+		t.Fatal("solana amount of unset format must be rejected")
+	}
+
+	evmToSolana.SetCfg(BACfg{OriginDecimals: 18, DestDecimals: 8, AmountFormat: AmountFormatU64})
+	direct, err := evmToSolana.BuildForDirect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation, err := UnpackByteArray(direct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, err := operation.BaseUnits(NewAmountCodec(AmountFormatU64, 8))
+	if err != nil || amount != 222700000 {
+		t.Fatalf("unexpected amount: %v, %v", amount, err)
+	}
+	if operation.Receiver != receiver {
+		t.Fatalf("unexpected receiver: %v", operation.Receiver)
+	}
+
+	// the evm amount does not depend on the solana format
+	solanaToEVM := &SolanaToEVMBABuilder{Amount: 222700000}
+	solanaToEVM.SetCfg(BACfg{OriginDecimals: 8, DestDecimals: 18, AmountFormat: AmountFormatFloat64})
+
+	direct, err = solanaToEVM.BuildForDirect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// action, swap id, uint256 amount, receiver
+	if len(direct) != 1+32+EVMAmountSize+20 {
+		t.Fatalf("unexpected evm byte vector length: %d", len(direct))
+	}
+	if new(big.Int).SetBytes(direct[33:65]).Cmp(evmToSolana.Amount) != 0 {
+		t.Fatalf("unexpected evm amount: %v", new(big.Int).SetBytes(direct[33:65]))
+	}
+
+	evmToSolana.Amount = big.NewInt(1)
+	if _, err := evmToSolana.BuildForDirect(); err == nil {
+		t.Fatal("dust below destination decimals must be rejected")
+	}
+}
@@ -2,21 +2,28 @@ package executor
 
 import (
 	"crypto/rand"
-	"math"
+	"fmt"
 	"math/big"
 
-	"github.com/Gravity-Tech/solanoid/abstract"
 	"github.com/portto/solana-go-sdk/common"
 )
 
 type BACfg struct {
 	OriginDecimals, DestDecimals int
+	// AmountFormat of the Solana port, it has to be set for the byte vectors carrying Solana amounts
+	AmountFormat AmountFormat
+	// EVMAmountLayout of the EVM port, zero value is uint256
+	EVMAmountLayout EVMAmountLayout
+}
+
+func (cfg BACfg) codec(decimals int) AmountCodec {
+	return NewAmountCodec(cfg.AmountFormat, uint8(decimals))
 }
 
 type CrossChainBridgeBABuilder interface {
 	SetCfg(BACfg)
-	BuildForDirect() []byte
-	BuildForReverse() []byte
+	BuildForDirect() ([]byte, error)
+	BuildForReverse() ([]byte, error)
 }
 
 type EVMSOLByteArrayData struct {
@@ -30,7 +37,7 @@ type EVMToSolanaBABuilder struct {
 	lastSwapID [32]byte
 	cfg        BACfg
 
-	// Amount *big.Int
+	// Amount in base units of the EVM token
 	Amount   *big.Int
 	Origin   [20]byte
 	Receiver common.PublicKey
@@ -40,36 +47,46 @@ func (ets *EVMToSolanaBABuilder) SetCfg(cfg BACfg) {
 	ets.cfg = cfg
 }
 
-func (ets *EVMToSolanaBABuilder) BuildForDirect() []byte {
+func (ets *EVMToSolanaBABuilder) BuildForDirect() ([]byte, error) {
 	ets.lastSwapID = rndSwapID()
 	var swapID [16]byte
 	copy(swapID[:], ets.lastSwapID[:])
 
-	// mapper := abstract.NewDecimalMapperFromFloat(ets.Amount, uint(ets.cfg.OriginDecimals))
-	mapper := abstract.NewDecimalMapperFromBig(ets.Amount)
-	amount := mapper.MapThrough(uint(ets.cfg.OriginDecimals), uint(ets.cfg.DestDecimals))
+	amount, err := ConvertDecimals(ets.Amount, uint8(ets.cfg.OriginDecimals), uint8(ets.cfg.DestDecimals))
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsUint64() {
+		return nil, fmt.Errorf("amount %v overflows u64", amount)
+	}
 
-	floatAmount := float64(amount.Int64()) / math.Pow(10, float64(ets.cfg.DestDecimals))
+	amountBytes, err := ets.cfg.codec(ets.cfg.DestDecimals).Encode(amount.Uint64())
+	if err != nil {
+		return nil, err
+	}
 
-	return buildMintSolana(swapID[:], ets.Receiver, floatAmount)
+	return buildMintSolana(swapID[:], ets.Receiver, amountBytes), nil
 }
 
-func (ets *EVMToSolanaBABuilder) BuildForReverse() []byte {
+func (ets *EVMToSolanaBABuilder) BuildForReverse() ([]byte, error) {
 	ets.lastSwapID = rndSwapID()
 	var swapID [32]byte
 	copy(swapID[:], ets.lastSwapID[:])
-	amount := ets.Amount
 
-	return buildUnlockEVM(swapID[:], ets.Origin, amount)
+	amountBytes, err := EncodeEVMAmount(ets.Amount, ets.cfg.EVMAmountLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildUnlockEVM(swapID[:], ets.Origin, amountBytes), nil
 }
 
 type SolanaToEVMBABuilder struct {
 	lastSwapID [32]byte
 	cfg        BACfg
 
-	// Amount *big.Int
-	// Amount *big.Int
-	Amount float64
+	// Amount in base units of the Solana token
+	Amount uint64
 	// Origin [32]byte
 	Origin   common.PublicKey
 	Receiver [20]byte
@@ -79,23 +96,35 @@ func (ets *SolanaToEVMBABuilder) SetCfg(cfg BACfg) {
 	ets.cfg = cfg
 }
 
-func (ets *SolanaToEVMBABuilder) BuildForDirect() []byte {
+func (ets *SolanaToEVMBABuilder) BuildForDirect() ([]byte, error) {
 	ets.lastSwapID = rndSwapID()
 	var swapID [32]byte
 	copy(swapID[:], ets.lastSwapID[:])
 
-	mapper := abstract.NewDecimalMapperFromFloat(ets.Amount, uint(ets.cfg.OriginDecimals))
-	amount := mapper.MapThrough(uint(ets.cfg.OriginDecimals), uint(ets.cfg.DestDecimals))
+	amount, err := ConvertDecimals(new(big.Int).SetUint64(ets.Amount), uint8(ets.cfg.OriginDecimals), uint8(ets.cfg.DestDecimals))
+	if err != nil {
+		return nil, err
+	}
 
-	return buildMintEVM(swapID[:], ets.Receiver, amount)
+	amountBytes, err := EncodeEVMAmount(amount, ets.cfg.EVMAmountLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMintEVM(swapID[:], ets.Receiver, amountBytes), nil
 }
 
-func (ets *SolanaToEVMBABuilder) BuildForReverse() []byte {
+func (ets *SolanaToEVMBABuilder) BuildForReverse() ([]byte, error) {
 	ets.lastSwapID = rndSwapID()
 	var swapID [16]byte
 	copy(swapID[:], ets.lastSwapID[:])
 
-	return buildUnlockSolana(swapID[:], ets.Origin, ets.Amount)
+	amountBytes, err := ets.cfg.codec(ets.cfg.OriginDecimals).Encode(ets.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildUnlockSolana(swapID[:], ets.Origin, amountBytes), nil
 }
 
 func rndSwapID() [32]byte {
@@ -104,7 +133,7 @@ func rndSwapID() [32]byte {
 	return subID
 }
 
-func buildMintEVM(swapId []byte, receiver [20]byte, amount *big.Int) []byte {
+func buildMintEVM(swapId []byte, receiver [20]byte, amount []byte) []byte {
 	var res []byte
 
 	// action
@@ -112,14 +141,14 @@ func buildMintEVM(swapId []byte, receiver [20]byte, amount *big.Int) []byte {
 	// swap id
 	res = append(res, swapId[0:32]...)
 	// amount
-	res = append(res, amount.Bytes()...)
+	res = append(res, amount...)
 	// receiver
 	res = append(res, receiver[:]...)
 
 	return res
 }
 
-func buildUnlockEVM(swapId []byte, receiver [20]byte, amount *big.Int) []byte {
+func buildUnlockEVM(swapId []byte, receiver [20]byte, amount []byte) []byte {
 	var res []byte
 
 	// action
@@ -127,14 +156,14 @@ func buildUnlockEVM(swapId []byte, receiver [20]byte, amount *big.Int) []byte {
 	// swap id
 	res = append(res, swapId[0:32]...)
 	// amount
-	res = append(res, amount.Bytes()...)
+	res = append(res, amount...)
 	// receiver
 	res = append(res, receiver[:]...)
 
 	return res
 }
 
-func buildMintSolana(swapId []byte, receiver common.PublicKey, amount float64) []byte {
+func buildMintSolana(swapId []byte, receiver common.PublicKey, amount []byte) []byte {
 	var res []byte
 
 	// action
@@ -142,14 +171,14 @@ func buildMintSolana(swapId []byte, receiver common.PublicKey, amount float64) [
 	// swap id
 	res = append(res, swapId[0:16]...)
 	// amount
-	res = append(res, Float64ToBytes(amount)...)
+	res = append(res, amount...)
 	// receiver
 	res = append(res, receiver[:]...)
 
 	return res
 }
 
-func buildUnlockSolana(swapId []byte, receiver common.PublicKey, amount float64) []byte {
+func buildUnlockSolana(swapId []byte, receiver common.PublicKey, amount []byte) []byte {
 	var res []byte
 
 	// action
@@ -157,7 +186,7 @@ func buildUnlockSolana(swapId []byte, receiver common.PublicKey, amount float64)
 	// swap id
 	res = append(res, swapId[0:16]...)
 	// amount
-	res = append(res, Float64ToBytes(amount)...)
+	res = append(res, amount...)
 	// receiver
 	res = append(res, receiver[:]...)
 
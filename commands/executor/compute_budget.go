@@ -0,0 +1,236 @@
+package executor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+var ComputeBudgetProgramID = common.PublicKeyFromString("ComputeBudget111111111111111111111111111111")
+
+const (
+	// MaxComputeUnitLimit is the most compute units a transaction may request
+	MaxComputeUnitLimit = 1_400_000
+	// DefaultComputeUnitMargin is added on top of the simulated units by the auto limit
+	DefaultComputeUnitMargin = 0.2
+	// DefaultPriorityFeePercentile is the percentile of the recent fees the auto price takes
+	DefaultPriorityFeePercentile = 75
+)
+
+// compute_budget::ComputeBudgetInstruction tags
+const (
+	computeBudgetSetComputeUnitLimit = 2
+	computeBudgetSetComputeUnitPrice = 3
+)
+
+func SetComputeUnitLimit(units uint32) types.Instruction {
+	data := make([]byte, 5)
+	data[0] = computeBudgetSetComputeUnitLimit
+	binary.LittleEndian.PutUint32(data[1:], units)
+
+	return types.Instruction{ProgramID: ComputeBudgetProgramID, Accounts: []types.AccountMeta{}, Data: data}
+}
+
+// SetComputeUnitPrice sets the priority fee in micro-lamports per requested compute unit
+func SetComputeUnitPrice(microLamports uint64) types.Instruction {
+	data := make([]byte, 9)
+	data[0] = computeBudgetSetComputeUnitPrice
+	binary.LittleEndian.PutUint64(data[1:], microLamports)
+
+	return types.Instruction{ProgramID: ComputeBudgetProgramID, Accounts: []types.AccountMeta{}, Data: data}
+}
+
+// WithComputeBudget prepends the ComputeBudget instructions, zero limit and price are left out.
+// The kinds the list already has are left out as well, the runtime rejects the duplicates.
+// Offline transactions take the budget this way.
+func WithComputeBudget(instructionsList []types.Instruction, unitLimit uint32, unitPrice uint64) []types.Instruction {
+	hasLimit, hasPrice := computeBudgetPresent(instructionsList)
+
+	var budget []types.Instruction
+	if unitLimit > 0 && !hasLimit {
+		budget = append(budget, SetComputeUnitLimit(unitLimit))
+	}
+	if unitPrice > 0 && !hasPrice {
+		budget = append(budget, SetComputeUnitPrice(unitPrice))
+	}
+
+	return append(budget, instructionsList...)
+}
+
+// computeBudgetPresent tells which of the ComputeBudget instructions the list carries on its own
+func computeBudgetPresent(instructionsList []types.Instruction) (hasLimit, hasPrice bool) {
+	for _, instruction := range instructionsList {
+		if instruction.ProgramID != ComputeBudgetProgramID || len(instruction.Data) == 0 {
+			continue
+		}
+
+		switch instruction.Data[0] {
+		case computeBudgetSetComputeUnitLimit:
+			hasLimit = true
+		case computeBudgetSetComputeUnitPrice:
+			hasPrice = true
+		}
+	}
+	return hasLimit, hasPrice
+}
+
+// ComputeBudget is what the executor requests for every transaction it sends
+type ComputeBudget struct {
+	// UnitLimit is the compute unit limit, zero leaves the runtime default of 200k per instruction
+	UnitLimit uint32
+	// UnitPrice is the priority fee in micro-lamports per compute unit
+	UnitPrice uint64
+
+	// AutoUnitLimit simulates the transaction first and takes the consumed units plus UnitLimitMargin as the limit
+	AutoUnitLimit   bool
+	UnitLimitMargin float64
+
+	// AutoUnitPrice takes the UnitPricePercentile of the recent fees paid for the writable accounts of the transaction,
+	// capped by MaxUnitPrice if set
+	AutoUnitPrice       bool
+	UnitPricePercentile int
+	MaxUnitPrice        uint64
+}
+
+// SetComputeBudget makes the executor prepend SetComputeUnitLimit and SetComputeUnitPrice to the transactions
+func (ge *GenericExecutor) SetComputeBudget(budget ComputeBudget) {
+	ge.computeBudget = &budget
+}
+
+func (ge *GenericExecutor) EraseComputeBudget() {
+	ge.computeBudget = nil
+}
+
+// withComputeBudget goes before recentBlockhash, AdvanceNonceAccount has to stay the first instruction.
+// The limit and the price the list sets on its own are kept and not estimated.
+func (ge *GenericExecutor) withComputeBudget(ctx context.Context, instructionsList []types.Instruction) ([]types.Instruction, error) {
+	if ge.computeBudget == nil {
+		return instructionsList, nil
+	}
+	budget := *ge.computeBudget
+	hasLimit, hasPrice := computeBudgetPresent(instructionsList)
+
+	unitPrice := budget.UnitPrice
+	if budget.AutoUnitPrice && !hasPrice {
+		estimated, err := EstimatePriorityFee(ctx, ge.clientEndpoint, writableAccounts(instructionsList), budget.UnitPricePercentile)
+		if err != nil {
+			fmt.Printf("estimate priority fee error, err: %v\n", err)
+			return nil, err
+		}
+
+		unitPrice = estimated
+		if budget.MaxUnitPrice > 0 && unitPrice > budget.MaxUnitPrice {
+			unitPrice = budget.MaxUnitPrice
+		}
+	}
+
+	unitLimit := budget.UnitLimit
+	if budget.AutoUnitLimit && !hasLimit {
+		estimated, err := ge.estimateComputeUnitLimit(ctx, instructionsList, unitPrice, budget.UnitLimitMargin)
+		if err != nil {
+			fmt.Printf("estimate compute units error, err: %v\n", err)
+			return nil, err
+		}
+		// the node reporting no units leaves the configured limit
+		if estimated > 0 {
+			unitLimit = estimated
+		}
+	}
+
+	return WithComputeBudget(instructionsList, unitLimit, unitPrice), nil
+}
+
+// estimateComputeUnitLimit simulates the transaction with the max limit, so the limit itself does not fail it.
+// Failed simulation is returned as is, the transaction would fail the same way. Zero is returned if the node
+// does not report the consumed units.
+func (ge *GenericExecutor) estimateComputeUnitLimit(ctx context.Context, instructionsList []types.Instruction, unitPrice uint64, margin float64) (uint32, error) {
+	response, err := ge.simulateInstruction(ctx, WithComputeBudget(instructionsList, MaxComputeUnitLimit, unitPrice))
+	if err != nil {
+		return 0, err
+	}
+	if response.Simulation == nil || response.Simulation.UnitsConsumed == 0 {
+		return 0, nil
+	}
+
+	if margin <= 0 {
+		margin = DefaultComputeUnitMargin
+	}
+
+	units := math.Ceil(float64(response.Simulation.UnitsConsumed) * (1 + margin))
+	if units > MaxComputeUnitLimit {
+		return MaxComputeUnitLimit, nil
+	}
+	return uint32(units), nil
+}
+
+// writableAccounts are the accounts the transaction locks for writing, the fees paid for them make its price
+func writableAccounts(instructionsList []types.Instruction) []common.PublicKey {
+	var accounts []common.PublicKey
+	seen := make(map[common.PublicKey]bool)
+
+	for _, instruction := range instructionsList {
+		for _, meta := range instruction.Accounts {
+			if meta.IsWritable && !seen[meta.PubKey] {
+				seen[meta.PubKey] = true
+				accounts = append(accounts, meta.PubKey)
+			}
+		}
+	}
+	return accounts
+}
+
+type PrioritizationFee struct {
+	Slot              uint64 `json:"slot"`
+	PrioritizationFee uint64 `json:"prioritizationFee"`
+}
+
+// GetRecentPrioritizationFees reports the fees of the recent slots in micro-lamports per compute unit,
+// with accounts set only the transactions locking them count
+func GetRecentPrioritizationFees(ctx context.Context, endpoint string, accounts []common.PublicKey) ([]PrioritizationFee, error) {
+	addresses := make([]string, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = account.ToBase58()
+	}
+
+	var fees []PrioritizationFee
+	err := newRPCClient(endpoint).call(ctx, "getRecentPrioritizationFees", []interface{}{addresses}, &fees)
+	return fees, err
+}
+
+// EstimatePriorityFee is the percentile of the recent fees paid for the accounts, zero percentile stands for
+// DefaultPriorityFeePercentile. Slots with no fee paid count, so the estimate stays low off congestion.
+func EstimatePriorityFee(ctx context.Context, endpoint string, accounts []common.PublicKey, percentile int) (uint64, error) {
+	fees, err := GetRecentPrioritizationFees(ctx, endpoint, accounts)
+	if err != nil {
+		return 0, err
+	}
+
+	return priorityFeePercentile(fees, percentile), nil
+}
+
+func priorityFeePercentile(fees []PrioritizationFee, percentile int) uint64 {
+	if len(fees) == 0 {
+		return 0
+	}
+	if percentile <= 0 {
+		percentile = DefaultPriorityFeePercentile
+	}
+	if percentile > 100 {
+		percentile = 100
+	}
+
+	values := make([]uint64, len(fees))
+	for i, fee := range fees {
+		values[i] = fee.PrioritizationFee
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	// nearest rank
+	rank := (percentile*len(values) + 99) / 100
+	return values[rank-1]
+}
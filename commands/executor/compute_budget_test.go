@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/solanatest"
+	"github.com/Gravity-Tech/solanoid/models"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// heavyUnits is above the default limit of the single instruction transaction
+const heavyUnits = 300_000
+
+func TestComputeBudget(t *testing.T) {
+	ctx := context.Background()
+	payer, dataAccount := types.NewAccount(), types.NewAccount()
+	programID := types.NewAccount().PublicKey
+
+	ledger := solanatest.NewLedger()
+	ledger.RegisterProgram(programID, func(ctx *solanatest.InvokeContext) error {
+		return ctx.ConsumeUnits(heavyUnits)
+	})
+	ledger.SetAccount(dataAccount.PublicKey, solanatest.Account{Lamports: 1_000_000, Owner: programID, Data: make([]byte, 1)})
+	ledger.Airdrop(payer.PublicKey, 1_000_000_000)
+
+	server := solanatest.NewLedgerServer(ledger)
+	defer server.Close()
+
+	attach := types.Instruction{
+		ProgramID: programID,
+		Accounts:  []types.AccountMeta{{PubKey: dataAccount.PublicKey, IsWritable: true}},
+		Data:      []byte{1},
+	}
+
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(payer), server.URL)
+
+	var failure *models.TransactionFailure
+	_, err := ge.SendAndConfirm(ctx, []types.Instruction{attach}, solclient.CommitmentFinalized)
+	if !errors.As(err, &failure) || failure.TxError.InstructionError != "ComputationalBudgetExceeded" {
+		t.Fatalf("expected compute budget error, got %v", err)
+	}
+
+	// the limit is sized by the simulation of the budget instructions and the heavy one
+	ge.SetComputeBudget(ComputeBudget{AutoUnitLimit: true, UnitLimitMargin: 0.5, UnitPrice: 10_000})
+
+	before := ledger.Balance(payer.PublicKey)
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{attach}, solclient.CommitmentFinalized); err != nil {
+		t.Fatal(err)
+	}
+
+	unitLimit := (3*solanatest.InstructionComputeUnits + heavyUnits) * 3 / 2
+	if fee := before - ledger.Balance(payer.PublicKey); fee != solanatest.LamportsPerSignature+(uint64(unitLimit)*10_000+999_999)/1_000_000 {
+		t.Fatalf("unexpected fee: %v", fee)
+	}
+
+	if price, err := EstimatePriorityFee(ctx, server.URL, []common.PublicKey{dataAccount.PublicKey}, 100); err != nil || price != 10_000 {
+		t.Fatalf("unexpected priority fee of the data account: %v, err: %v", price, err)
+	}
+	if price, err := EstimatePriorityFee(ctx, server.URL, []common.PublicKey{programID}, 100); err != nil || price != 0 {
+		t.Fatalf("unexpected priority fee of the readonly account: %v, err: %v", price, err)
+	}
+
+	// the rejected transaction has not landed, so the only recent fee is 10k and the cap takes over
+	ge.SetComputeBudget(ComputeBudget{UnitLimit: 400_000, AutoUnitPrice: true, MaxUnitPrice: 5_000})
+	if _, err := ge.InvokeIXList([]types.Instruction{attach}); err != nil {
+		t.Fatal(err)
+	}
+
+	fees, err := GetRecentPrioritizationFees(ctx, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fees) != 2 || fees[0].PrioritizationFee != 10_000 || fees[1].PrioritizationFee != 5_000 {
+		t.Fatalf("unexpected recent fees: %+v", fees)
+	}
+
+	ge.SetComputeBudget(ComputeBudget{UnitLimit: heavyUnits})
+	if _, err := ge.InvokeIXList([]types.Instruction{attach}); !errors.As(err, &failure) || *failure.TxError.InstructionIndex != 1 {
+		t.Fatalf("expected compute budget error of the instruction following the budget, got %v", err)
+	}
+
+	// the dry run takes the budget of the send
+	ge.SetComputeBudget(ComputeBudget{UnitLimit: 400_000})
+	if _, err := ge.Simulate(ctx, []types.Instruction{attach}); err != nil {
+		t.Fatalf("simulation is not given the budget: %v", err)
+	}
+
+	// the limit of the list is kept, the executor one would fail the transaction
+	ge.SetComputeBudget(ComputeBudget{UnitLimit: heavyUnits, UnitPrice: 1, AutoUnitLimit: true})
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{SetComputeUnitLimit(400_000), attach}, solclient.CommitmentFinalized); err != nil {
+		t.Fatalf("budget instructions of the list are duplicated: %v", err)
+	}
+}
+
+func TestComputeBudgetUnreportedUnits(t *testing.T) {
+	feePayer := types.NewAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Method string `json:"method"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		value := map[string]interface{}{"blockhash": common.PublicKey{}.ToBase58(), "lastValidBlockHeight": 150}
+		if request.Method == "simulateTransaction" {
+			// the node of no unitsConsumed support
+			value = map[string]interface{}{"err": nil, "logs": []string{}}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": value},
+		})
+	}))
+	defer server.Close()
+
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(feePayer), server.URL)
+	ge.SetComputeBudget(ComputeBudget{UnitLimit: 400_000, AutoUnitLimit: true})
+
+	transfer := sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1)
+	instructionsList, err := ge.withComputeBudget(context.Background(), []types.Instruction{transfer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instructionsList) != 2 || !reflect.DeepEqual(instructionsList[0], SetComputeUnitLimit(400_000)) {
+		t.Fatalf("configured limit is not kept: %+v", instructionsList)
+	}
+}
+
+func TestPriorityFeePercentile(t *testing.T) {
+	fees := []PrioritizationFee{{PrioritizationFee: 40}, {PrioritizationFee: 0}, {PrioritizationFee: 10}, {PrioritizationFee: 30}}
+
+	for percentile, expected := range map[int]uint64{0: 30, 25: 0, 50: 10, 75: 30, 100: 40, 150: 40} {
+		if actual := priorityFeePercentile(fees, percentile); actual != expected {
+			t.Fatalf("unexpected %v percentile: %v", percentile, actual)
+		}
+	}
+	if priorityFeePercentile(nil, 75) != 0 {
+		t.Fatal("unexpected fee of no slots")
+	}
+}
@@ -0,0 +1,219 @@
+package executor
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// BlockhashValidityWindow is the amount of blocks a recent blockhash stays valid for.
+const BlockhashValidityWindow = 150
+
+var ConfirmationPollInterval = 500 * time.Millisecond
+
+var ErrBlockhashExpired = errors.New("blockhash expired before transaction reached requested commitment")
+
+type LatestBlockhash struct {
+	Blockhash            string `json:"blockhash"`
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
+}
+
+type TxConfirmation struct {
+	Signature          string
+	Slot               uint64
+	ConfirmationStatus solclient.Commitment
+	Err                interface{}
+}
+
+var commitmentRank = map[solclient.Commitment]int{
+	solclient.CommitmentProcessed: 0,
+	solclient.CommitmentConfirmed: 1,
+	solclient.CommitmentFinalized: 2,
+}
+
+func commitmentReached(actual, requested solclient.Commitment) bool {
+	return commitmentRank[actual] >= commitmentRank[requested]
+}
+
+func GetBlockHeight(ctx context.Context, endpoint string) (uint64, error) {
+	var height uint64
+	err := newRPCClient(endpoint).call(ctx, "getBlockHeight", nil, &height)
+	return height, err
+}
+
+// GetLatestBlockhash falls back to getRecentBlockhash on nodes which do not serve getLatestBlockhash yet.
+func GetLatestBlockhash(ctx context.Context, endpoint string) (*LatestBlockhash, error) {
+	response := struct {
+		Value LatestBlockhash `json:"value"`
+	}{}
+
+	err := newRPCClient(endpoint).call(ctx, "getLatestBlockhash", nil, &response)
+	if err == nil {
+		return &response.Value, nil
+	}
+	if !isMethodNotFound(err) {
+		return nil, err
+	}
+
+	recent, err := solclient.NewClient(endpoint).GetRecentBlockhash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LatestBlockhash{Blockhash: recent.Blockhash}
+
+	height, err := GetBlockHeight(ctx, endpoint)
+	if err == nil {
+		result.LastValidBlockHeight = height + BlockhashValidityWindow
+	}
+
+	return result, nil
+}
+
+// GetTransaction fetches the transaction at the commitment, getConfirmedTransaction
+// serves finalized transactions only, so it is a fallback for older nodes.
+// Nil response means the transaction is not found at the commitment yet.
+func GetTransaction(ctx context.Context, endpoint, signature string, commitment solclient.Commitment) (*solclient.GetConfirmedTransactionResponse, error) {
+	var response *solclient.GetConfirmedTransactionResponse
+
+	rc := newRPCClient(endpoint)
+	config := map[string]interface{}{
+		"encoding":   "json",
+		"commitment": commitment,
+	}
+
+	err := rc.call(ctx, "getTransaction", []interface{}{signature, config}, &response)
+	if err == nil || !isMethodNotFound(err) {
+		return response, err
+	}
+
+	err = rc.call(ctx, "getConfirmedTransaction", []interface{}{signature, "json"}, &response)
+	return response, err
+}
+
+func signatureStatusCommitment(status solclient.GetSignatureStatusesResponse) (solclient.Commitment, bool) {
+	if status.ConfirmationStatus != nil {
+		return *status.ConfirmationStatus, true
+	}
+	if status.Slot == 0 {
+		return "", false
+	}
+	// nodes prior to 1.5 report confirmations only, nil stands for rooted
+	if status.Confirmations == nil {
+		return solclient.CommitmentFinalized, true
+	}
+	if *status.Confirmations > 0 {
+		return solclient.CommitmentConfirmed, true
+	}
+	return solclient.CommitmentProcessed, true
+}
+
+// AwaitConfirmation polls the signature status until the commitment is reached.
+// Zero lastValidBlockHeight disables the blockhash expiration check, so ctx is the only limit then.
+// The check applies to the transactions not seen by the node only, the landed one does not expire.
+func AwaitConfirmation(ctx context.Context, endpoint, signature string, commitment solclient.Commitment, lastValidBlockHeight uint64) (*TxConfirmation, error) {
+	c := solclient.NewClient(endpoint)
+
+	for {
+		landed := false
+
+		statuses, err := c.GetSignatureStatuses(ctx, []string{signature})
+		if err != nil {
+			fmt.Printf("get signature status error, err: %v\n", err)
+		}
+
+		if err == nil && len(statuses) > 0 {
+			status := statuses[0]
+
+			actual, ok := signatureStatusCommitment(status)
+			landed = ok
+			if ok && commitmentReached(actual, commitment) {
+				confirmation := &TxConfirmation{
+					Signature:          signature,
+					Slot:               status.Slot,
+					ConfirmationStatus: actual,
+					Err:                status.Err,
+				}
+				if status.Err != nil {
+					return confirmation, fmt.Errorf("transaction %v failed: %v", signature, status.Err)
+				}
+				return confirmation, nil
+			}
+		}
+
+		if lastValidBlockHeight > 0 && !landed {
+			height, err := GetBlockHeight(ctx, endpoint)
+			if err == nil && height > lastValidBlockHeight {
+				return nil, ErrBlockhashExpired
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(ConfirmationPollInterval):
+		}
+	}
+}
+
+// SendAndConfirm signs and sends the instructions, then blocks until the requested commitment is reached.
+// The response is returned along with the error when the transaction has failed on-chain.
+// Failures are *models.TransactionFailure, so errors.Is matches the registered program errors.
+func (ge *GenericExecutor) SendAndConfirm(ctx context.Context, instructionsList []types.Instruction, commitment solclient.Commitment) (*models.CommandResponse, error) {
+	instructionsList, err := ge.withComputeBudget(ctx, instructionsList)
+	if err != nil {
+		return nil, err
+	}
+
+	if ge.simulate {
+		return ge.simulateInstruction(ctx, instructionsList)
+	}
+
+	instructionsList, blockhash, err := ge.recentBlockhash(ctx, instructionsList)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx, serializedMessage, err := ge.signTransaction(instructionsList, blockhash.Blockhash)
+	if err != nil {
+		return nil, err
+	}
+
+	programIDs := instructionProgramIDs(instructionsList)
+
+	txSig, err := sendRawTransaction(ctx, ge.clientEndpoint, rawTx, programIDs)
+	if err != nil {
+		fmt.Printf("send tx error, err: %v\n", err)
+		return nil, err
+	}
+
+	confirmation, err := AwaitConfirmation(ctx, ge.clientEndpoint, txSig, commitment, blockhash.LastValidBlockHeight)
+	if confirmation == nil {
+		return nil, err
+	}
+	err = confirmationFailure(ctx, ge.clientEndpoint, confirmation, programIDs, err)
+
+	return &models.CommandResponse{
+		SerializedMessage:  hex.EncodeToString(serializedMessage),
+		TxSignature:        txSig,
+		Slot:               confirmation.Slot,
+		ConfirmationStatus: string(confirmation.ConfirmationStatus),
+		TxError:            confirmation.Err,
+	}, err
+}
+
+func (ge *GenericExecutor) BuildAndConfirm(ctx context.Context, instruction interface{}, commitment solclient.Commitment) (*models.CommandResponse, error) {
+	builtIx, err := ge.buildIx(instruction)
+	if err != nil {
+		return nil, err
+	}
+
+	return ge.SendAndConfirm(ctx, []types.Instruction{*builtIx}, commitment)
+}
@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+const confirmSignature = "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW"
+
+// confirmNode answers the methods by the script, every getSignatureStatuses call takes the next status
+// and the last one sticks. Methods off the script are answered with null.
+type confirmNode struct {
+	mu       sync.Mutex
+	statuses []interface{}
+	polls    int
+	results  map[string]interface{}
+}
+
+func (node *confirmNode) setStatuses(statuses ...interface{}) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	node.statuses, node.polls = statuses, 0
+}
+
+func newConfirmNode(t *testing.T, node *confirmNode) string {
+	ConfirmationPollInterval = time.Millisecond
+	t.Cleanup(func() { ConfirmationPollInterval = 500 * time.Millisecond })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Method string `json:"method"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		node.mu.Lock()
+		result := node.results[request.Method]
+		if request.Method == "getSignatureStatuses" {
+			status := node.statuses[len(node.statuses)-1]
+			if node.polls < len(node.statuses) {
+				status = node.statuses[node.polls]
+			}
+			node.polls++
+			result = map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": []interface{}{status}}
+		}
+		node.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func signatureStatus(slot uint64, commitment solclient.Commitment, err interface{}) map[string]interface{} {
+	return map[string]interface{}{"slot": slot, "confirmations": nil, "err": err, "confirmationStatus": commitment}
+}
+
+func TestAwaitConfirmationCommitment(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		commitment solclient.Commitment
+		polls      int
+	}{
+		{solclient.CommitmentProcessed, 2},
+		{solclient.CommitmentConfirmed, 3},
+		{solclient.CommitmentFinalized, 4},
+	} {
+		node := &confirmNode{statuses: []interface{}{
+			nil,
+			signatureStatus(10, solclient.CommitmentProcessed, nil),
+			signatureStatus(10, solclient.CommitmentConfirmed, nil),
+			signatureStatus(10, solclient.CommitmentFinalized, nil),
+		}}
+		endpoint := newConfirmNode(t, node)
+
+		confirmation, err := AwaitConfirmation(ctx, endpoint, confirmSignature, tc.commitment, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if confirmation.ConfirmationStatus != tc.commitment || confirmation.Slot != 10 || node.polls != tc.polls {
+			t.Fatalf("unexpected %v confirmation: %+v after %v polls", tc.commitment, confirmation, node.polls)
+		}
+	}
+}
+
+func TestSignatureStatusCommitment(t *testing.T) {
+	confirmations := func(n uint64) *uint64 { return &n }
+
+	for _, tc := range []struct {
+		status   solclient.GetSignatureStatusesResponse
+		expected solclient.Commitment
+		ok       bool
+	}{
+		{solclient.GetSignatureStatusesResponse{}, "", false},
+		{solclient.GetSignatureStatusesResponse{Slot: 1}, solclient.CommitmentFinalized, true},
+		{solclient.GetSignatureStatusesResponse{Slot: 1, Confirmations: confirmations(0)}, solclient.CommitmentProcessed, true},
+		{solclient.GetSignatureStatusesResponse{Slot: 1, Confirmations: confirmations(3)}, solclient.CommitmentConfirmed, true},
+	} {
+		if actual, ok := signatureStatusCommitment(tc.status); actual != tc.expected || ok != tc.ok {
+			t.Fatalf("unexpected commitment of %+v: %v, %v", tc.status, actual, ok)
+		}
+	}
+}
+
+func TestAwaitConfirmationBlockhashExpired(t *testing.T) {
+	ctx := context.Background()
+
+	node := &confirmNode{statuses: []interface{}{nil}, results: map[string]interface{}{"getBlockHeight": 151}}
+	endpoint := newConfirmNode(t, node)
+
+	if _, err := AwaitConfirmation(ctx, endpoint, confirmSignature, solclient.CommitmentConfirmed, 150); err != ErrBlockhashExpired {
+		t.Fatalf("expected expired blockhash, got %v", err)
+	}
+
+	// the landed transaction is confirmed even if the blockhash has expired since
+	node.setStatuses(signatureStatus(10, solclient.CommitmentFinalized, nil))
+	if _, err := AwaitConfirmation(ctx, endpoint, confirmSignature, solclient.CommitmentConfirmed, 150); err != nil {
+		t.Fatal(err)
+	}
+
+	// the processed transaction does not expire while it is awaited to be finalized
+	node.setStatuses(
+		signatureStatus(10, solclient.CommitmentProcessed, nil),
+		signatureStatus(10, solclient.CommitmentProcessed, nil),
+		signatureStatus(10, solclient.CommitmentFinalized, nil),
+	)
+	if confirmation, err := AwaitConfirmation(ctx, endpoint, confirmSignature, solclient.CommitmentFinalized, 150); err != nil || confirmation.ConfirmationStatus != solclient.CommitmentFinalized {
+		t.Fatalf("landed transaction has expired: %+v, %v", confirmation, err)
+	}
+
+	// zero height leaves the context the only limit
+	node.setStatuses(nil)
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := AwaitConfirmation(timeout, endpoint, confirmSignature, solclient.CommitmentConfirmed, 0); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline, got %v", err)
+	}
+}
+
+func TestSendAndConfirm(t *testing.T) {
+	ctx := context.Background()
+	feePayer := types.NewAccount()
+	transfer := sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1)
+
+	results := map[string]interface{}{
+		"getLatestBlockhash": map[string]interface{}{
+			"context": map[string]interface{}{"slot": 1},
+			"value":   map[string]interface{}{"blockhash": common.PublicKey{}.ToBase58(), "lastValidBlockHeight": 150},
+		},
+		"sendTransaction": confirmSignature,
+		"getBlockHeight":  151,
+	}
+
+	// the transaction dropped past its blockhash
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(feePayer), newConfirmNode(t, &confirmNode{statuses: []interface{}{nil}, results: results}))
+	if response, err := ge.SendAndConfirm(ctx, []types.Instruction{transfer}, solclient.CommitmentConfirmed); err != ErrBlockhashExpired || response != nil {
+		t.Fatalf("expected expired blockhash, got %+v, %v", response, err)
+	}
+
+	// the transaction failed on-chain after the preflight, the logs are not served
+	results["getBlockHeight"] = 100
+	ge = NewSignerExecutor(NewGravityBftSignerFromAccount(feePayer), newConfirmNode(t, &confirmNode{statuses: []interface{}{
+		signatureStatus(10, solclient.CommitmentProcessed, nil),
+		signatureStatus(10, solclient.CommitmentFinalized, map[string]interface{}{"InstructionError": []interface{}{0, map[string]interface{}{"Custom": 1}}}),
+	}, results: results}))
+
+	response, err := ge.SendAndConfirm(ctx, []types.Instruction{transfer}, solclient.CommitmentFinalized)
+
+	var failure *models.TransactionFailure
+	if !errors.As(err, &failure) || failure.TxError.Custom == nil || *failure.TxError.Custom != 1 || failure.ProgramID != common.SystemProgramID {
+		t.Fatalf("expected failure of the transfer, got %v", err)
+	}
+	if response == nil || response.TxSignature != confirmSignature || response.Slot != 10 || response.ConfirmationStatus != string(solclient.CommitmentFinalized) || response.TxError == nil {
+		t.Fatalf("unexpected response of the failed transaction: %+v", response)
+	}
+}
@@ -17,10 +17,30 @@ func NewIBPortInstructionBuilder() *IBPortInstructionBuilder {
 
 var IBPortIXBuilder = &IBPortInstructionBuilder{}
 
+var (
+	IBPortCreateTransferUnwrapRequestAccounts = AccountLayout{
+		{Name: RoleTokenProgram},
+		{Name: RoleMint, IsWritable: true},
+		{Name: RoleTokenAccount, IsWritable: true},
+		{Name: RolePortPDA},
+	}
+	IBPortAttachValueAccounts = AccountLayout{
+		{Name: RoleTokenProgram},
+		{Name: RoleMint, IsWritable: true},
+		{Name: RoleTokenAccount, IsWritable: true},
+		{Name: RolePortPDA},
+	}
+	IBPortTransferTokenOwnershipAccounts = AccountLayout{
+		{Name: RoleMint, IsWritable: true},
+		{Name: RolePortPDA},
+		{Name: RoleTokenProgram},
+	}
+)
+
 type IBPortInstructionBuilder struct{}
 
-func (port *IBPortInstructionBuilder) Init(nebula, token common.PublicKey) interface{} {
-	return struct {
+func (port *IBPortInstructionBuilder) Init(nebula, token common.PublicKey) *LayoutInstruction {
+	return newLayoutInstruction(struct {
 		Instruction       uint8
 		NebulaDataAccount common.PublicKey
 		TokenDataAccount  common.PublicKey
@@ -28,11 +48,11 @@ func (port *IBPortInstructionBuilder) Init(nebula, token common.PublicKey) inter
 		Instruction:       0,
 		NebulaDataAccount: nebula,
 		TokenDataAccount:  token,
-	}
+	}, nil)
 }
 
-func (port *IBPortInstructionBuilder) InitWithOracles(nebula, token, tokenMint common.PublicKey, bft uint8, oracles []byte) interface{} {
-	return struct {
+func (port *IBPortInstructionBuilder) InitWithOracles(nebula, token, tokenMint common.PublicKey, bft uint8, oracles []byte) *LayoutInstruction {
+	return newLayoutInstruction(struct {
 		Instruction       uint8
 		NebulaDataAccount common.PublicKey
 		TokenDataAccount  common.PublicKey
@@ -46,7 +66,7 @@ func (port *IBPortInstructionBuilder) InitWithOracles(nebula, token, tokenMint c
 		TokenMint:         tokenMint,
 		Bft:               bft,
 		Oracles:           oracles,
-	}
+	}, nil)
 }
 
 type CreateTransferUnwrapRequestInstruction struct {
@@ -67,46 +87,70 @@ func (ix *CreateTransferUnwrapRequestInstruction) Pack() []byte {
 	return res
 }
 
-func (port *IBPortInstructionBuilder) CreateTransferUnwrapRequest(receiver [32]byte, amount float64) interface{} {
+// CreateTransferUnwrapRequest encodes UI amount as f64.
+//
+// Deprecated: f64 amounts may end up off by one base unit, use CreateTransferUnwrapRequestWithCodec.
+func (port *IBPortInstructionBuilder) CreateTransferUnwrapRequest(receiver [32]byte, amount float64) *LayoutInstruction {
 	var requestID [16]byte
 	rand.Read(requestID[:])
 
 	fmt.Printf("CreateTransferUnwrapRequest - rq_id: %v amount: %v \n", requestID, amount)
 	amountBytes := float64ToByte(amount)
 
-	return CreateTransferUnwrapRequestInstruction{
+	return newLayoutInstruction(CreateTransferUnwrapRequestInstruction{
 		Instruction: 1,
 		TokenAmount: amountBytes,
 		Receiver:    receiver,
 		RequestID:   requestID,
+	}, IBPortCreateTransferUnwrapRequestAccounts)
+}
+
+// CreateTransferUnwrapRequestWithCodec takes amount in base units
+func (port *IBPortInstructionBuilder) CreateTransferUnwrapRequestWithCodec(receiver [32]byte, amount uint64, codec AmountCodec) (*LayoutInstruction, error) {
+	var requestID [16]byte
+	rand.Read(requestID[:])
+
+	amountBytes, err := codec.Encode(amount)
+	if err != nil {
+		return nil, err
 	}
+
+	fmt.Printf("CreateTransferUnwrapRequest - rq_id: %v amount: %v (%v) \n", requestID, amount, codec.Format)
+
+	return newLayoutInstruction(CreateTransferUnwrapRequestInstruction{
+		Instruction: 1,
+		TokenAmount: amountBytes,
+		Receiver:    receiver,
+		RequestID:   requestID,
+	}, IBPortCreateTransferUnwrapRequestAccounts), nil
 }
-func (port *IBPortInstructionBuilder) ConfirmProcessedRequest(requestID []byte) interface{} {
-	return struct {
+
+func (port *IBPortInstructionBuilder) ConfirmProcessedRequest(requestID []byte) *LayoutInstruction {
+	return newLayoutInstruction(struct {
 		Instruction uint8
 		RequestID   []byte
 	}{
 		Instruction: 3,
 		RequestID:   requestID,
-	}
+	}, nil)
 }
 
-func (port *IBPortInstructionBuilder) AttachValue(byte_vector []byte) interface{} {
+func (port *IBPortInstructionBuilder) AttachValue(byte_vector []byte) *LayoutInstruction {
 	fmt.Printf("AttachValue - byte_vector: %v", byte_vector)
 
-	return struct {
+	return newLayoutInstruction(struct {
 		Instruction uint8
 		ByteVector  []byte
 	}{
 		Instruction: 2,
 		ByteVector:  byte_vector,
-	}
+	}, IBPortAttachValueAccounts)
 }
 
-func (port *IBPortInstructionBuilder) TransferTokenOwnership(newOwner, newToken common.PublicKey) interface{} {
+func (port *IBPortInstructionBuilder) TransferTokenOwnership(newOwner, newToken common.PublicKey) *LayoutInstruction {
 	fmt.Printf("TransferOwnership - newOwner: %v, newToken: %v \n", newOwner, newToken)
 
-	return struct {
+	return newLayoutInstruction(struct {
 		Instruction  uint8
 		NewAuthority common.PublicKey
 		NewToken     common.PublicKey
@@ -114,5 +158,5 @@ func (port *IBPortInstructionBuilder) TransferTokenOwnership(newOwner, newToken
 		Instruction:  4,
 		NewAuthority: newOwner,
 		NewToken:     newToken,
-	}
+	}, IBPortTransferTokenOwnershipAccounts)
 }
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// Account roles shared by the port and nebula handlers
+const (
+	RoleTokenProgram = "token_program"
+	RoleMint         = "mint"
+	// RoleTokenAccount is the user token account the port burns from, mints to or locks from
+	RoleTokenAccount = "token_account"
+	// RolePortPDA is the port program address which holds the token authority
+	RolePortPDA = "port_pda"
+	// RolePortTokenAccount is the LU Port custody of the locked tokens
+	RolePortTokenAccount = "port_token_account"
+	// RolePortInitializer is the initializer_pubkey of the port data account
+	RolePortInitializer = "port_initializer"
+
+	RoleSubscriberProgram     = "subscriber_program"
+	RoleSubscriberDataAccount = "subscriber_data_account"
+)
+
+type AccountRole struct {
+	Name       string
+	IsSigner   bool
+	IsWritable bool
+}
+
+// AccountLayout is the order of the accounts the handler reads after the ones every instruction
+// of the executor starts with: the fee payer, the data account, the multisig account and the signers
+type AccountLayout []AccountRole
+
+// Accounts binds the layout roles to the keys
+type Accounts map[string]common.PublicKey
+
+// Metas orders the accounts by the layout, every role has to be bound and every account has to have its role
+func (layout AccountLayout) Metas(accounts Accounts) ([]types.AccountMeta, error) {
+	metas := make([]types.AccountMeta, 0, len(layout))
+	declared := make(map[string]bool, len(layout))
+
+	var missing []string
+	for _, role := range layout {
+		declared[role.Name] = true
+
+		pubkey, ok := accounts[role.Name]
+		if !ok {
+			missing = append(missing, role.Name)
+			continue
+		}
+		metas = append(metas, types.AccountMeta{PubKey: pubkey, IsSigner: role.IsSigner, IsWritable: role.IsWritable})
+	}
+
+	var extra []string
+	for name := range accounts {
+		if !declared[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing accounts: %v", strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		return nil, fmt.Errorf("accounts not in the layout: %v", strings.Join(extra, ", "))
+	}
+
+	return metas, nil
+}
+
+// check tells whether the positional metas take the places of the layout roles
+func (layout AccountLayout) check(metas []types.AccountMeta) error {
+	if len(metas) != len(layout) {
+		return fmt.Errorf("additional meta does not match the layout: %v accounts, %v expected", len(metas), len(layout))
+	}
+
+	for i, role := range layout {
+		if metas[i].IsWritable != role.IsWritable || metas[i].IsSigner != role.IsSigner {
+			return fmt.Errorf("additional meta does not match the layout: account #%v takes the %v role", i, role.Name)
+		}
+	}
+	return nil
+}
+
+// without returns the layout with the role left out, nebula passes the token program to the subscriber on its own
+func (layout AccountLayout) without(name string) AccountLayout {
+	result := make(AccountLayout, 0, len(layout))
+	for _, role := range layout {
+		if role.Name != name {
+			result = append(result, role)
+		}
+	}
+	return result
+}
+
+// LayoutInstruction is the instruction data of the builders along with the accounts its handler expects
+type LayoutInstruction struct {
+	Data     interface{}
+	Layout   AccountLayout
+	Accounts Accounts
+}
+
+func newLayoutInstruction(data interface{}, layout AccountLayout) *LayoutInstruction {
+	return &LayoutInstruction{Data: data, Layout: layout}
+}
+
+// WithAccounts binds the accounts, the executor builds the metas by the layout then
+func (ix *LayoutInstruction) WithAccounts(accounts Accounts) *LayoutInstruction {
+	bound := *ix
+	bound.Accounts = accounts
+	return &bound
+}
+
+// metas resolves the accounts following the executor ones. Instructions with no accounts bound
+// take the positional additionalMeta, so SetAdditionalMeta callers keep working, yet it has to
+// match the layout in length and access unless the instruction declares none.
+func (ix *LayoutInstruction) metas(additionalMeta []types.AccountMeta) ([]types.AccountMeta, error) {
+	if ix.Accounts == nil && len(additionalMeta) > 0 {
+		if ix.Layout != nil {
+			if err := ix.Layout.check(additionalMeta); err != nil {
+				return nil, err
+			}
+		}
+		return additionalMeta, nil
+	}
+	if len(additionalMeta) > 0 {
+		return nil, fmt.Errorf("additional meta is set along with the layout accounts")
+	}
+
+	return ix.Layout.Metas(ix.Accounts)
+}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestBuildLayoutInstruction(t *testing.T) {
+	feePayer, dataAccount := types.NewAccount(), types.NewAccount()
+	portProgram, mint, tokenAccount, portPDA := types.NewAccount(), types.NewAccount(), types.NewAccount(), types.NewAccount()
+
+	ge := NewNebulaSignerExecutor(NewGravityBftSignerFromAccount(feePayer), portProgram.PublicKey.ToBase58(), dataAccount.PublicKey.ToBase58(), "", "")
+
+	accounts := Accounts{
+		RolePortPDA:      portPDA.PublicKey,
+		RoleTokenAccount: tokenAccount.PublicKey,
+		RoleMint:         mint.PublicKey,
+		RoleTokenProgram: common.TokenProgramID,
+	}
+	expected := []types.AccountMeta{
+		{PubKey: feePayer.PublicKey, IsSigner: true},
+		{PubKey: dataAccount.PublicKey, IsWritable: true},
+		{PubKey: common.TokenProgramID},
+		{PubKey: mint.PublicKey, IsWritable: true},
+		{PubKey: tokenAccount.PublicKey, IsWritable: true},
+		{PubKey: portPDA.PublicKey},
+	}
+
+	unwrap := IBPortIXBuilder.CreateTransferUnwrapRequest([32]byte{1}, 1)
+	ix, err := ge.BuildInstruction(unwrap.WithAccounts(accounts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ix.Accounts, expected) {
+		t.Fatalf("unexpected accounts: %+v", ix.Accounts)
+	}
+
+	data, err := common.SerializeData(unwrap.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ix.Data, data) {
+		t.Fatal("unexpected instruction data")
+	}
+
+	missing := Accounts{RoleTokenProgram: common.TokenProgramID, RoleMint: mint.PublicKey}
+	if _, err := ge.BuildInstruction(unwrap.WithAccounts(missing)); err == nil || err.Error() != "missing accounts: token_account, port_pda" {
+		t.Fatalf("unexpected error of missing accounts: %v", err)
+	}
+
+	extra := Accounts{RolePortInitializer: feePayer.PublicKey}
+	for role, pubkey := range accounts {
+		extra[role] = pubkey
+	}
+	if _, err := ge.BuildInstruction(unwrap.WithAccounts(extra)); err == nil || err.Error() != "accounts not in the layout: port_initializer" {
+		t.Fatalf("unexpected error of extra accounts: %v", err)
+	}
+
+	// positional meta is taken by the unbound instructions only, as long as it matches the layout
+	ge.SetAdditionalMeta(expected[2:])
+	if ix, err := ge.BuildInstruction(unwrap); err != nil || !reflect.DeepEqual(ix.Accounts, expected) {
+		t.Fatalf("unexpected legacy accounts: %+v, err: %v", ix, err)
+	}
+
+	ge.SetAdditionalMeta(expected[2:5])
+	if _, err := ge.BuildInstruction(unwrap); err == nil || err.Error() != "additional meta does not match the layout: 3 accounts, 4 expected" {
+		t.Fatalf("unexpected error of missing legacy accounts: %v", err)
+	}
+
+	readonlyMint := append([]types.AccountMeta{}, expected[2:]...)
+	readonlyMint[1].IsWritable = false
+	ge.SetAdditionalMeta(readonlyMint)
+	if _, err := ge.BuildInstruction(unwrap); err == nil || err.Error() != "additional meta does not match the layout: account #1 takes the mint role" {
+		t.Fatalf("unexpected error of readonly legacy mint: %v", err)
+	}
+
+	ge.SetAdditionalMeta(expected[2:])
+	if _, err := ge.BuildInstruction(unwrap.WithAccounts(accounts)); err == nil {
+		t.Fatal("additional meta is mixed with the layout accounts")
+	}
+}
+
+func TestNebulaSendValueToSubsAccounts(t *testing.T) {
+	var roles []string
+	for _, role := range NebulaSendValueToSubsAccounts(LUPortAttachValueAccounts) {
+		roles = append(roles, role.Name)
+	}
+
+	expected := []string{
+		RoleTokenProgram, RoleSubscriberProgram, RoleSubscriberDataAccount,
+		RoleMint, RoleTokenAccount, RolePortPDA, RolePortInitializer, RolePortTokenAccount,
+	}
+	if !reflect.DeepEqual(roles, expected) {
+		t.Fatalf("unexpected layout: %v", roles)
+	}
+}
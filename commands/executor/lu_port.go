@@ -10,10 +10,27 @@ import (
 
 var LUPortIXBuilder = &LUPortInstructionBuilder{}
 
+var (
+	LUPortCreateTransferWrapRequestAccounts = AccountLayout{
+		{Name: RoleTokenProgram},
+		{Name: RoleMint, IsWritable: true},
+		{Name: RoleTokenAccount, IsWritable: true},
+		{Name: RolePortTokenAccount, IsWritable: true},
+	}
+	LUPortAttachValueAccounts = AccountLayout{
+		{Name: RoleTokenProgram},
+		{Name: RoleMint, IsWritable: true},
+		{Name: RoleTokenAccount, IsWritable: true},
+		{Name: RolePortPDA},
+		{Name: RolePortInitializer},
+		{Name: RolePortTokenAccount, IsWritable: true},
+	}
+)
+
 type LUPortInstructionBuilder struct{}
 
-func (port *LUPortInstructionBuilder) InitWithOracles(nebula, token, tokenMint common.PublicKey, bft uint8, oracles []byte) interface{} {
-	return struct {
+func (port *LUPortInstructionBuilder) InitWithOracles(nebula, token, tokenMint common.PublicKey, bft uint8, oracles []byte) *LayoutInstruction {
+	return newLayoutInstruction(struct {
 		Instruction       uint8
 		NebulaDataAccount common.PublicKey
 		TokenDataAccount  common.PublicKey
@@ -27,7 +44,7 @@ func (port *LUPortInstructionBuilder) InitWithOracles(nebula, token, tokenMint c
 		TokenMint:         tokenMint,
 		Bft:               bft,
 		Oracles:           oracles,
-	}
+	}, nil)
 }
 
 type CreateTransferWrapRequestInstruction struct {
@@ -56,7 +73,10 @@ func RandomEVMAddress() [20]byte {
 	return ethReceiverAddress
 }
 
-func (port *LUPortInstructionBuilder) CreateTransferWrapRequest(receiver [32]byte, amount float64) interface{} {
+// CreateTransferWrapRequest encodes UI amount as f64.
+//
+// Deprecated: f64 amounts may end up off by one base unit, use CreateTransferWrapRequestWithCodec.
+func (port *LUPortInstructionBuilder) CreateTransferWrapRequest(receiver [32]byte, amount float64) *LayoutInstruction {
 	var requestID [16]byte
 
 	// uint id = uint(keccak256(abi.encodePacked(msg.sender, receiver, block.number, amount)));
@@ -66,39 +86,60 @@ func (port *LUPortInstructionBuilder) CreateTransferWrapRequest(receiver [32]byt
 	fmt.Printf("CreateTransferUnwrapRequest - rq_id: %v amount: %v \n", requestID, amount)
 	amountBytes := float64ToByte(amount)
 
-	return CreateTransferWrapRequestInstruction{
+	return newLayoutInstruction(CreateTransferWrapRequestInstruction{
 		Instruction: 1,
 		TokenAmount: amountBytes,
 		Receiver:    receiver,
 		RequestID:   requestID,
+	}, LUPortCreateTransferWrapRequestAccounts)
+}
+
+// CreateTransferWrapRequestWithCodec takes amount in base units
+func (port *LUPortInstructionBuilder) CreateTransferWrapRequestWithCodec(receiver [32]byte, amount uint64, codec AmountCodec) (*LayoutInstruction, error) {
+	var requestID [16]byte
+	rand.Read(requestID[:])
+
+	amountBytes, err := codec.Encode(amount)
+	if err != nil {
+		return nil, err
 	}
+
+	fmt.Printf("CreateTransferWrapRequest - rq_id: %v amount: %v (%v) \n", requestID, amount, codec.Format)
+
+	return newLayoutInstruction(CreateTransferWrapRequestInstruction{
+		Instruction: 1,
+		TokenAmount: amountBytes,
+		Receiver:    receiver,
+		RequestID:   requestID,
+	}, LUPortCreateTransferWrapRequestAccounts), nil
 }
-func (port *LUPortInstructionBuilder) ConfirmProcessedRequest(requestID []byte) interface{} {
-	return struct {
+
+func (port *LUPortInstructionBuilder) ConfirmProcessedRequest(requestID []byte) *LayoutInstruction {
+	return newLayoutInstruction(struct {
 		Instruction uint8
 		RequestID   []byte
 	}{
 		Instruction: 3,
 		RequestID:   requestID,
-	}
+	}, nil)
 }
 
-func (port *LUPortInstructionBuilder) AttachValue(byte_vector []byte) interface{} {
+func (port *LUPortInstructionBuilder) AttachValue(byte_vector []byte) *LayoutInstruction {
 	fmt.Printf("AttachValue - byte_vector: %v", byte_vector)
 
-	return struct {
+	return newLayoutInstruction(struct {
 		Instruction uint8
 		ByteVector  []byte
 	}{
 		Instruction: 2,
 		ByteVector:  byte_vector,
-	}
+	}, LUPortAttachValueAccounts)
 }
 
-func (port *LUPortInstructionBuilder) TransferTokenOwnership(newOwner, newToken common.PublicKey) interface{} {
+func (port *LUPortInstructionBuilder) TransferTokenOwnership(newOwner, newToken common.PublicKey) *LayoutInstruction {
 	fmt.Printf("TransferOwnership - newOwner: %v, newToken: %v \n", newOwner, newToken)
 
-	return struct {
+	return newLayoutInstruction(struct {
 		Instruction  uint8
 		NewAuthority common.PublicKey
 		NewToken     common.PublicKey
@@ -106,5 +147,5 @@ func (port *LUPortInstructionBuilder) TransferTokenOwnership(newOwner, newToken
 		Instruction:  4,
 		NewAuthority: newOwner,
 		NewToken:     newToken,
-	}
+	}, nil)
 }
@@ -73,49 +73,63 @@ type NebulaInstructionBuilder struct{}
 
 var NebulaIXBuilder = &NebulaInstructionBuilder{}
 
-func (port *NebulaInstructionBuilder) Init(bft, dataType uint8, gravityProgramID common.PublicKey, oracles []byte) interface{} {
-	return InitNebulaContractInstruction{
+func (port *NebulaInstructionBuilder) Init(bft, dataType uint8, gravityProgramID common.PublicKey, oracles []byte) *LayoutInstruction {
+	return newLayoutInstruction(InitNebulaContractInstruction{
 		Instruction:              0,
 		Bft:                      bft,
 		NebulaDataType:           dataType,
 		GravityContractProgramID: gravityProgramID,
 		InitialOracles:           oracles,
-	}
+	}, nil)
 }
 
-func (port *NebulaInstructionBuilder) Subscribe(subscriber common.PublicKey, minConfirmations uint8, reward uint64, subscriptionID [16]byte) interface{} {
-	return SubscribeNebulaContractInstruction{
+func (port *NebulaInstructionBuilder) Subscribe(subscriber common.PublicKey, minConfirmations uint8, reward uint64, subscriptionID [16]byte) *LayoutInstruction {
+	return newLayoutInstruction(SubscribeNebulaContractInstruction{
 		Instruction:      4,
 		Subscriber:       subscriber,
 		MinConfirmations: minConfirmations,
 		Reward:           reward,
 		SubscriptionID:   subscriptionID,
+	}, nil)
+}
+
+// NebulaSendValueToSubsAccounts is the SendValueToSubs layout for the subscriber AttachValue one,
+// nebula passes the token program and then the accounts of the subscriber
+func NebulaSendValueToSubsAccounts(subscriber AccountLayout) AccountLayout {
+	layout := AccountLayout{
+		{Name: RoleTokenProgram},
+		{Name: RoleSubscriberProgram},
+		{Name: RoleSubscriberDataAccount, IsWritable: true},
 	}
+	return append(layout, subscriber.without(RoleTokenProgram)...)
 }
 
-func (port *NebulaInstructionBuilder) SendValueToSubs(data [64]byte, dataType uint8, pulseID uint64, subscriptionID [16]byte) interface{} {
-	return SendValueToSubsNebulaContractInstruction{
+// SendValueToSubs takes the AttachValue layout of the subscriber, e.g. IBPortAttachValueAccounts
+func (port *NebulaInstructionBuilder) SendValueToSubs(subscriber AccountLayout, data [64]byte, dataType uint8, pulseID uint64, subscriptionID [16]byte) *LayoutInstruction {
+	return newLayoutInstruction(SendValueToSubsNebulaContractInstruction{
 		Instruction:    3,
 		DataValue:      data,
 		DataType:       dataType,
 		PulseID:        pulseID,
 		SubscriptionID: subscriptionID,
-	}
+	}, NebulaSendValueToSubsAccounts(subscriber))
 }
 
-func (port *NebulaInstructionBuilder) SendHashValue(data [32]byte) interface{} {
-	return SendHashValueNebulaContractInstruction{
+func (port *NebulaInstructionBuilder) SendHashValue(data [32]byte) *LayoutInstruction {
+	return newLayoutInstruction(SendHashValueNebulaContractInstruction{
 		Instruction: 2,
 		DataValue:   data,
-	}
+	}, nil)
 }
 
 type ExecutionVisitor interface {
 	InvokePureInstruction(interface{}) (*models.CommandResponse, error)
 }
 
+// SignerDelegate signs serialized transaction messages, the key may live out of process,
+// e.g. behind the remote signer, so signing can fail
 type SignerDelegate interface {
-	Sign([]byte) []byte
+	Sign([]byte) ([]byte, error)
 	Pubkey() string
 	Meta() types.AccountMeta
 }
@@ -138,8 +152,8 @@ func NewGravityBftSignerFromAccount(account types.Account) *GravityBftSigner {
 	}
 }
 
-func (signer *GravityBftSigner) Sign(message []byte) []byte {
-	return ed25519.Sign(signer.account.PrivateKey, message)
+func (signer *GravityBftSigner) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(signer.account.PrivateKey, message), nil
 }
 
 func (signer *GravityBftSigner) Pubkey() string {
@@ -151,7 +165,8 @@ func (signer *GravityBftSigner) Meta() types.AccountMeta {
 }
 
 type GenericExecutor struct {
-	deployerPrivKey types.Account
+	// feePayer signs every transaction and is the first account of built instructions
+	feePayer        SignerDelegate
 	nebulaProgramID string
 
 	dataAccount         string
@@ -159,25 +174,44 @@ type GenericExecutor struct {
 
 	clientEndpoint string
 
-	signers        []GravityBftSigner
+	signers        []SignerDelegate
 	additionalMeta []types.AccountMeta
 
 	client *solclient.Client
+
+	simulate bool
+	// nonceAccount replaces the recent blockhash with the durable nonce if set
+	nonceAccount *common.PublicKey
+	// computeBudget is prepended to every transaction if set
+	computeBudget *ComputeBudget
 }
 
 func (ge *GenericExecutor) Deployer() common.PublicKey {
-	return ge.deployerPrivKey.PublicKey
+	return ge.feePayer.Meta().PubKey
 }
 
 func (ge *GenericExecutor) SetAdditionalSigners(signers []GravityBftSigner) {
+	delegates := make([]SignerDelegate, len(signers))
+	for i := range signers {
+		delegates[i] = &signers[i]
+	}
+	ge.signers = delegates
+}
+
+// SetAdditionalSignerDelegates is SetAdditionalSigners for keys held elsewhere, e.g. by the remote signer
+func (ge *GenericExecutor) SetAdditionalSignerDelegates(signers []SignerDelegate) {
 	ge.signers = signers
 }
 func (ge *GenericExecutor) EraseAdditionalSigners() {
-	ge.signers = make([]GravityBftSigner, 0)
+	ge.signers = make([]SignerDelegate, 0)
 }
 
 func (ge *GenericExecutor) SetDeployerPK(pk types.Account) {
-	ge.deployerPrivKey = pk
+	ge.feePayer = NewGravityBftSignerFromAccount(pk)
+}
+
+func (ge *GenericExecutor) SetFeePayer(feePayer SignerDelegate) {
+	ge.feePayer = feePayer
 }
 
 func (ge *GenericExecutor) SetAdditionalMeta(meta []types.AccountMeta) {
@@ -187,45 +221,42 @@ func (ge *GenericExecutor) EraseAdditionalMeta() {
 	ge.additionalMeta = make([]types.AccountMeta, 0)
 }
 
-func (ge *GenericExecutor) invokeInstruction(instructionsList []types.Instruction) (*models.CommandResponse, error) {
-	account := ge.deployerPrivKey
-
+func (ge *GenericExecutor) rpc() *solclient.Client {
 	if ge.client == nil {
 		ge.client = solclient.NewClient(ge.clientEndpoint)
 	}
 
-	c := ge.client
-
-	res, err := c.GetRecentBlockhash(context.Background())
-	if err != nil {
-		fmt.Printf("get recent block hash error, err: %v\n", err)
-		return nil, err
-	}
+	return ge.client
+}
 
+func (ge *GenericExecutor) signTransaction(instructionsList []types.Instruction, blockhash string) ([]byte, []byte, error) {
 	message := types.NewMessage(
-		account.PublicKey,
+		ge.Deployer(),
 		instructionsList,
-		res.Blockhash,
+		blockhash,
 	)
 
 	serializedMessage, err := message.Serialize()
 	if err != nil {
 		fmt.Printf("serialize message error, err: %v\n", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	signatures := map[common.PublicKey]types.Signature{
-		account.PublicKey: ed25519.Sign(account.PrivateKey, serializedMessage),
-	}
-	for _, signer := range ge.signers {
-		signatures[signer.Meta().PubKey] = signer.Sign(serializedMessage)
+	signatures := make(map[common.PublicKey]types.Signature, len(ge.signers)+1)
+	for _, signer := range append([]SignerDelegate{ge.feePayer}, ge.signers...) {
+		signature, err := signer.Sign(serializedMessage)
+		if err != nil {
+			fmt.Printf("sign message error, signer: %v, err: %v\n", signer.Pubkey(), err)
+			return nil, nil, err
+		}
+		signatures[signer.Meta().PubKey] = signature
 	}
 
 	tx, err := types.CreateTransaction(message, signatures)
 
 	if err != nil {
 		fmt.Printf("generate tx error, err: %v\n", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	rawTx, err := tx.Serialize()
@@ -247,10 +278,33 @@ func (ge *GenericExecutor) invokeInstruction(instructionsList []types.Instructio
 	if err != nil {
 		fmt.Printf("serialize tx error, err: %v\n", err)
 		// logTx()
+		return nil, nil, err
+	}
+
+	return rawTx, serializedMessage, nil
+}
+
+func (ge *GenericExecutor) invokeInstruction(instructionsList []types.Instruction) (*models.CommandResponse, error) {
+	instructionsList, err := ge.withComputeBudget(context.Background(), instructionsList)
+	if err != nil {
+		return nil, err
+	}
+
+	if ge.simulate {
+		return ge.simulateInstruction(context.Background(), instructionsList)
+	}
+
+	instructionsList, blockhash, err := ge.recentBlockhash(context.Background(), instructionsList)
+	if err != nil {
 		return nil, err
 	}
 
-	txSig, err := c.SendRawTransaction(context.Background(), rawTx)
+	rawTx, serializedMessage, err := ge.signTransaction(instructionsList, blockhash.Blockhash)
+	if err != nil {
+		return nil, err
+	}
+
+	txSig, err := sendRawTransaction(context.Background(), ge.clientEndpoint, rawTx, instructionProgramIDs(instructionsList))
 	if err != nil {
 		fmt.Printf("send tx error, err: %v\n", err)
 		// logTx()
@@ -309,7 +363,20 @@ func (ge *GenericExecutor) BuildAndInvoke(instruction interface{}) (*models.Comm
 	return ge.InvokePureInstruction(instruction)
 }
 
+// BuildInstruction prepends the executor accounts to the ones of the instruction,
+// *LayoutInstruction accounts are checked against its layout
 func (ge *GenericExecutor) BuildInstruction(instruction interface{}) (*types.Instruction, error) {
+	instructionMeta := ge.additionalMeta
+	if ix, ok := instruction.(*LayoutInstruction); ok {
+		var err error
+		instructionMeta, err = ix.metas(ge.additionalMeta)
+		if err != nil {
+			fmt.Printf("build instruction accounts error, err: %v\n", err)
+			return nil, err
+		}
+		instruction = ix.Data
+	}
+
 	data, err := common.SerializeData(instruction)
 
 	if err != nil {
@@ -321,7 +388,7 @@ func (ge *GenericExecutor) BuildInstruction(instruction interface{}) (*types.Ins
 	// fmt.Println("------- END RAW INSTRUCTION DATA ---------")
 
 	accountMeta := []types.AccountMeta{
-		{PubKey: ge.deployerPrivKey.PublicKey, IsSigner: true, IsWritable: false},
+		{PubKey: ge.Deployer(), IsSigner: true, IsWritable: false},
 		{PubKey: common.PublicKeyFromString(ge.dataAccount), IsSigner: false, IsWritable: true},
 	}
 
@@ -333,7 +400,7 @@ func (ge *GenericExecutor) BuildInstruction(instruction interface{}) (*types.Ins
 		accountMeta = append(accountMeta, signer.Meta())
 	}
 
-	accountMeta = append(accountMeta, ge.additionalMeta...)
+	accountMeta = append(accountMeta, instructionMeta...)
 
 	return &types.Instruction{
 		Accounts:  accountMeta,
@@ -351,11 +418,19 @@ func NewEmptyExecutor(privateKey, clientEndpoint string) (*GenericExecutor, erro
 	account := types.AccountFromPrivateKeyBytes(pk)
 
 	return &GenericExecutor{
-		deployerPrivKey: account,
-		clientEndpoint:  clientEndpoint,
+		feePayer:       NewGravityBftSignerFromAccount(account),
+		clientEndpoint: clientEndpoint,
 	}, nil
 }
 
+// NewSignerExecutor is NewEmptyExecutor with the fee payer key held by the delegate
+func NewSignerExecutor(feePayer SignerDelegate, clientEndpoint string) *GenericExecutor {
+	return &GenericExecutor{
+		feePayer:       feePayer,
+		clientEndpoint: clientEndpoint,
+	}
+}
+
 func NewNebulaExecutor(privateKey, nebulaProgramID, dataAccount, multisigDataAccount, clientEndpoint string, gravityProgramID common.PublicKey) (*GenericExecutor, error) {
 	pk, err := base58.Decode(privateKey)
 	if err != nil {
@@ -364,13 +439,18 @@ func NewNebulaExecutor(privateKey, nebulaProgramID, dataAccount, multisigDataAcc
 	}
 	account := types.AccountFromPrivateKeyBytes(pk)
 
+	return NewNebulaSignerExecutor(NewGravityBftSignerFromAccount(account), nebulaProgramID, dataAccount, multisigDataAccount, clientEndpoint), nil
+}
+
+// NewNebulaSignerExecutor is NewNebulaExecutor with the fee payer key held by the delegate
+func NewNebulaSignerExecutor(feePayer SignerDelegate, nebulaProgramID, dataAccount, multisigDataAccount, clientEndpoint string) *GenericExecutor {
 	return &GenericExecutor{
-		deployerPrivKey: account,
+		feePayer:        feePayer,
 		nebulaProgramID: nebulaProgramID,
 
 		dataAccount:         dataAccount,
 		multisigDataAccount: multisigDataAccount,
 
 		clientEndpoint: clientEndpoint,
-	}, nil
+	}
 }
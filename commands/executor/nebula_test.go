@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+type failingSigner struct {
+	*GravityBftSigner
+}
+
+func (signer failingSigner) Sign(message []byte) ([]byte, error) {
+	return nil, errors.New("signer is offline")
+}
+
+func TestSignTransactionWithDelegates(t *testing.T) {
+	feePayer, consul := types.NewAccount(), types.NewAccount()
+
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(feePayer), "")
+	ge.SetAdditionalSignerDelegates([]SignerDelegate{NewGravityBftSignerFromAccount(consul)})
+
+	if ge.Deployer() != feePayer.PublicKey {
+		t.Fatalf("unexpected deployer: %v", ge.Deployer().ToBase58())
+	}
+
+	ix := sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1)
+	ix.Accounts = append(ix.Accounts, NewGravityBftSignerFromAccount(consul).Meta())
+
+	rawTx, serializedMessage, err := ge.signTransaction([]types.Instruction{ix}, common.PublicKey{}.ToBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := types.TransactionDeserialize(rawTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Signatures) != 2 {
+		t.Fatalf("unexpected signatures: %v", len(tx.Signatures))
+	}
+	for i, account := range []types.Account{feePayer, consul} {
+		if !ed25519.Verify(account.PublicKey.Bytes(), serializedMessage, tx.Signatures[i]) {
+			t.Fatalf("signature #%d is invalid", i)
+		}
+	}
+
+	ge.SetFeePayer(failingSigner{NewGravityBftSignerFromAccount(feePayer)})
+	if _, _, err := ge.signTransaction([]types.Instruction{ix}, common.PublicKey{}.ToBase58()); err == nil {
+		t.Fatal("failed signature is ignored")
+	}
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// nonceStateInitialized is the state of the nonce account after InitializeNonceAccount
+const nonceStateInitialized = 1
+
+var ErrNonceUninitialized = errors.New("nonce account is not initialized")
+
+// DurableNonce is the stored nonce of the account. It replaces the recent blockhash of the message,
+// so the transaction stays valid until the nonce is advanced, however long the signatures are collected.
+type DurableNonce struct {
+	Account              common.PublicKey
+	Authority            common.PublicKey
+	Nonce                string
+	LamportsPerSignature uint64
+}
+
+func DecodeDurableNonce(account common.PublicKey, data []byte) (*DurableNonce, error) {
+	decoded, err := sysprog.NonceAccountDeserialize(data)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.State != nonceStateInitialized {
+		return nil, ErrNonceUninitialized
+	}
+
+	return &DurableNonce{
+		Account:              account,
+		Authority:            decoded.AuthorizedPubkey,
+		Nonce:                decoded.Nonce.ToBase58(),
+		LamportsPerSignature: decoded.FeeCalculator.LamportsPerSignature,
+	}, nil
+}
+
+func GetDurableNonce(ctx context.Context, endpoint string, account common.PublicKey) (*DurableNonce, error) {
+	info, err := solclient.NewClient(endpoint).GetAccountInfo(ctx, account.ToBase58(), solclient.GetAccountInfoConfig{
+		Encoding: solclient.GetAccountInfoConfigEncodingBase64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if info.Owner == "" {
+		return nil, fmt.Errorf("nonce account %v does not exist", account.ToBase58())
+	}
+	if info.Owner != common.SystemProgramID.ToBase58() {
+		return nil, fmt.Errorf("account %v is owned by %v, not a nonce account", account.ToBase58(), info.Owner)
+	}
+
+	encoded, ok := info.Data.([]interface{})
+	if !ok || len(encoded) == 0 {
+		return nil, fmt.Errorf("unexpected account data encoding: %v", info.Data)
+	}
+	content, _ := encoded[0].(string)
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeDurableNonce(account, data)
+}
+
+// AdvanceInstructions puts AdvanceNonceAccount first, the runtime accepts nonce transactions in this form only
+func (nonce *DurableNonce) AdvanceInstructions(instructionsList []types.Instruction) []types.Instruction {
+	return append(
+		[]types.Instruction{sysprog.AdvanceNonceAccount(nonce.Account, nonce.Authority)},
+		instructionsList...,
+	)
+}
+
+// CreateNonceAccountInstructions funds the new nonce account and initializes it, both payer and account sign
+func CreateNonceAccountInstructions(payer, account, authority common.PublicKey, lamports uint64) []types.Instruction {
+	return []types.Instruction{
+		sysprog.CreateAccount(payer, account, common.SystemProgramID, lamports, sysprog.NonceAccountSize),
+		sysprog.InitializeNonceAccount(account, authority),
+	}
+}
+
+func GetNonceAccountRent(ctx context.Context, endpoint string) (uint64, error) {
+	return solclient.NewClient(endpoint).GetMinimumBalanceForRentExemption(ctx, sysprog.NonceAccountSize)
+}
+
+// SetDurableNonceAccount makes the executor sign with the stored nonce instead of the recent blockhash,
+// the nonce authority must be the fee payer or one of the additional signers
+func (ge *GenericExecutor) SetDurableNonceAccount(account common.PublicKey) {
+	ge.nonceAccount = &account
+}
+
+func (ge *GenericExecutor) EraseDurableNonceAccount() {
+	ge.nonceAccount = nil
+}
+
+// recentBlockhash returns the instructions and the blockhash to sign them with. The nonce is read
+// on every call, each transaction advances it. Zero LastValidBlockHeight stands for no expiration.
+func (ge *GenericExecutor) recentBlockhash(ctx context.Context, instructionsList []types.Instruction) ([]types.Instruction, *LatestBlockhash, error) {
+	if ge.nonceAccount == nil {
+		blockhash, err := GetLatestBlockhash(ctx, ge.clientEndpoint)
+		if err != nil {
+			fmt.Printf("get latest block hash error, err: %v\n", err)
+			return nil, nil, err
+		}
+		return instructionsList, blockhash, nil
+	}
+
+	nonce, err := GetDurableNonce(ctx, ge.clientEndpoint, *ge.nonceAccount)
+	if err != nil {
+		fmt.Printf("get durable nonce error, err: %v\n", err)
+		return nil, nil, err
+	}
+
+	return nonce.AdvanceInstructions(instructionsList), &LatestBlockhash{Blockhash: nonce.Nonce}, nil
+}
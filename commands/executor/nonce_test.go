@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func nonceAccountData(authority, nonce common.PublicKey, state uint32) []byte {
+	data := make([]byte, sysprog.NonceAccountSize)
+	binary.LittleEndian.PutUint32(data[4:8], state)
+	copy(data[8:40], authority.Bytes())
+	copy(data[40:72], nonce.Bytes())
+	binary.LittleEndian.PutUint64(data[72:80], 5000)
+	return data
+}
+
+func TestDurableNonce(t *testing.T) {
+	feePayer, authority := types.NewAccount(), types.NewAccount()
+	nonceAccount, storedNonce := types.NewAccount().PublicKey, types.NewAccount().PublicKey
+
+	if _, err := DecodeDurableNonce(nonceAccount, nonceAccountData(authority.PublicKey, storedNonce, 0)); err != ErrNonceUninitialized {
+		t.Fatalf("expected uninitialized nonce, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result": map[string]interface{}{
+				"context": map[string]interface{}{"slot": 1},
+				"value": map[string]interface{}{
+					"data":     []string{base64.StdEncoding.EncodeToString(nonceAccountData(authority.PublicKey, storedNonce, 1)), "base64"},
+					"owner":    common.SystemProgramID.ToBase58(),
+					"lamports": 1447680,
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	nonce, err := GetDurableNonce(context.Background(), server.URL, nonceAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Nonce != storedNonce.ToBase58() || nonce.Authority != authority.PublicKey || nonce.LamportsPerSignature != 5000 {
+		t.Fatalf("unexpected nonce: %+v", nonce)
+	}
+
+	transfer := sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1)
+
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(feePayer), server.URL)
+	ge.SetDurableNonceAccount(nonceAccount)
+
+	instructions, blockhash, err := ge.recentBlockhash(context.Background(), []types.Instruction{transfer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockhash.Blockhash != nonce.Nonce || blockhash.LastValidBlockHeight != 0 {
+		t.Fatalf("unexpected blockhash: %+v", blockhash)
+	}
+	if len(instructions) != 2 || instructions[0].ProgramID != common.SystemProgramID || instructions[0].Accounts[0].PubKey != nonceAccount {
+		t.Fatalf("advance nonce instruction is not first: %+v", instructions)
+	}
+
+	tx, err := NewOfflineNonceTransaction(feePayer.PublicKey, nonce, []types.Instruction{transfer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.NonceAccount != nonceAccount.ToBase58() || len(tx.Signers) != 2 || tx.Signers[1] != authority.PublicKey.ToBase58() {
+		t.Fatalf("unexpected nonce transaction: %+v", tx)
+	}
+	message, err := tx.decodeMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.RecentBlockHash != nonce.Nonce {
+		t.Fatalf("message is not bound to the nonce: %v", message.RecentBlockHash)
+	}
+}
@@ -0,0 +1,361 @@
+package executor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+var (
+	ErrMessageMismatch = errors.New("offline transactions carry different messages")
+	ErrNotRequired     = errors.New("key is not a required signer of the message")
+)
+
+// OfflineTransaction is the serialized message passed between the signers as a file.
+// Every signer adds its signature offline, the merged file is broadcast once all of them are collected.
+type OfflineTransaction struct {
+	Message []byte `json:"message"`
+	// Signers are the required signers in the message order, the fee payer goes first
+	Signers    []string          `json:"signers"`
+	Signatures map[string][]byte `json:"signatures"`
+	// LastValidBlockHeight is zero if unknown, e.g. the blockhash was supplied by the user,
+	// or if the message is bound to the durable nonce and does not expire
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight,omitempty"`
+	NonceAccount         string `json:"nonceAccount,omitempty"`
+}
+
+type OfflineAccountMeta struct {
+	PubKey     string `json:"pubkey"`
+	IsSigner   bool   `json:"isSigner"`
+	IsWritable bool   `json:"isWritable"`
+}
+
+// OfflineInstruction is the JSON form of types.Instruction, data is base64 encoded
+type OfflineInstruction struct {
+	ProgramID string               `json:"programId"`
+	Accounts  []OfflineAccountMeta `json:"accounts"`
+	Data      []byte               `json:"data"`
+}
+
+func (ix OfflineInstruction) Instruction() types.Instruction {
+	accounts := make([]types.AccountMeta, len(ix.Accounts))
+	for i, meta := range ix.Accounts {
+		accounts[i] = types.AccountMeta{
+			PubKey:     common.PublicKeyFromString(meta.PubKey),
+			IsSigner:   meta.IsSigner,
+			IsWritable: meta.IsWritable,
+		}
+	}
+
+	return types.Instruction{
+		ProgramID: common.PublicKeyFromString(ix.ProgramID),
+		Accounts:  accounts,
+		Data:      ix.Data,
+	}
+}
+
+func ReadOfflineInstructions(path string) ([]types.Instruction, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var offline []OfflineInstruction
+	if err := json.Unmarshal(content, &offline); err != nil {
+		return nil, fmt.Errorf("decode instructions %v: %v", path, err)
+	}
+	if len(offline) == 0 {
+		return nil, fmt.Errorf("no instructions in %v", path)
+	}
+
+	instructions := make([]types.Instruction, len(offline))
+	for i, ix := range offline {
+		instructions[i] = ix.Instruction()
+	}
+
+	return instructions, nil
+}
+
+// NewOfflineTransaction serializes the unsigned message, no keys are needed to build it
+func NewOfflineTransaction(feePayer common.PublicKey, instructionsList []types.Instruction, blockhash string) (*OfflineTransaction, error) {
+	message := types.NewMessage(feePayer, instructionsList, blockhash)
+
+	serializedMessage, err := message.Serialize()
+	if err != nil {
+		fmt.Printf("serialize message error, err: %v\n", err)
+		return nil, err
+	}
+
+	signers := make([]string, message.Header.NumRequireSignatures)
+	for i := range signers {
+		signers[i] = message.Accounts[i].ToBase58()
+	}
+
+	return &OfflineTransaction{
+		Message:    serializedMessage,
+		Signers:    signers,
+		Signatures: make(map[string][]byte),
+	}, nil
+}
+
+// NewOfflineNonceTransaction binds the message to the durable nonce, the nonce authority becomes a required signer
+func NewOfflineNonceTransaction(feePayer common.PublicKey, nonce *DurableNonce, instructionsList []types.Instruction) (*OfflineTransaction, error) {
+	tx, err := NewOfflineTransaction(feePayer, nonce.AdvanceInstructions(instructionsList), nonce.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	tx.NonceAccount = nonce.Account.ToBase58()
+
+	return tx, nil
+}
+
+// BuildOfflineTransaction is NewOfflineTransaction paid by the executor deployer
+func (ge *GenericExecutor) BuildOfflineTransaction(instructionsList []types.Instruction, blockhash string) (*OfflineTransaction, error) {
+	return NewOfflineTransaction(ge.Deployer(), instructionsList, blockhash)
+}
+
+// decodeMessage recovers from the deserializer, the file may come from anywhere
+func (tx *OfflineTransaction) decodeMessage() (message types.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed message: %v", r)
+		}
+	}()
+
+	return types.MessageDeserialize(tx.Message)
+}
+
+// Validate checks the signers list against the message and every signature present
+func (tx *OfflineTransaction) Validate() error {
+	message, err := tx.decodeMessage()
+	if err != nil {
+		return err
+	}
+
+	required := int(message.Header.NumRequireSignatures)
+	if required != len(tx.Signers) || required > len(message.Accounts) {
+		return fmt.Errorf("message requires %v signers, %v listed", required, len(tx.Signers))
+	}
+	for i, signer := range tx.Signers {
+		if message.Accounts[i].ToBase58() != signer {
+			return fmt.Errorf("signer #%v is %v, message expects %v", i, signer, message.Accounts[i].ToBase58())
+		}
+	}
+
+	for pubkey, signature := range tx.Signatures {
+		if !tx.isRequired(pubkey) {
+			return fmt.Errorf("%v: %v", ErrNotRequired, pubkey)
+		}
+		if !ed25519.Verify(common.PublicKeyFromString(pubkey).Bytes(), tx.Message, signature) {
+			return fmt.Errorf("invalid signature of %v", pubkey)
+		}
+	}
+
+	return nil
+}
+
+func (tx *OfflineTransaction) isRequired(pubkey string) bool {
+	for _, signer := range tx.Signers {
+		if signer == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign adds the signatures of the delegates, each of them must be a required signer
+func (tx *OfflineTransaction) Sign(signers ...SignerDelegate) error {
+	if tx.Signatures == nil {
+		tx.Signatures = make(map[string][]byte)
+	}
+
+	for _, signer := range signers {
+		pubkey := signer.Pubkey()
+		if !tx.isRequired(pubkey) {
+			return fmt.Errorf("%v: %v", ErrNotRequired, pubkey)
+		}
+
+		signature, err := signer.Sign(tx.Message)
+		if err != nil {
+			fmt.Printf("sign message error, signer: %v, err: %v\n", pubkey, err)
+			return err
+		}
+		if !ed25519.Verify(signer.Meta().PubKey.Bytes(), tx.Message, signature) {
+			return fmt.Errorf("invalid signature of %v", pubkey)
+		}
+
+		tx.Signatures[pubkey] = signature
+	}
+
+	return nil
+}
+
+// Merge collects the signatures of the other copy of the same message
+func (tx *OfflineTransaction) Merge(other *OfflineTransaction) error {
+	if string(tx.Message) != string(other.Message) {
+		return ErrMessageMismatch
+	}
+	if err := other.Validate(); err != nil {
+		return err
+	}
+
+	if tx.Signatures == nil {
+		tx.Signatures = make(map[string][]byte)
+	}
+	for pubkey, signature := range other.Signatures {
+		tx.Signatures[pubkey] = signature
+	}
+	if tx.LastValidBlockHeight == 0 {
+		tx.LastValidBlockHeight = other.LastValidBlockHeight
+	}
+
+	return nil
+}
+
+// Missing lists the required signers which have not signed yet
+func (tx *OfflineTransaction) Missing() []string {
+	var missing []string
+	for _, signer := range tx.Signers {
+		if _, ok := tx.Signatures[signer]; !ok {
+			missing = append(missing, signer)
+		}
+	}
+	return missing
+}
+
+// Serialize assembles the signed transaction, all the required signatures must be present
+func (tx *OfflineTransaction) Serialize() ([]byte, error) {
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+	if missing := tx.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("missing signatures of %v", missing)
+	}
+
+	message, err := tx.decodeMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make(map[common.PublicKey]types.Signature, len(tx.Signatures))
+	for pubkey, signature := range tx.Signatures {
+		signatures[common.PublicKeyFromString(pubkey)] = signature
+	}
+
+	assembled, err := types.CreateTransaction(message, signatures)
+	if err != nil {
+		fmt.Printf("generate tx error, err: %v\n", err)
+		return nil, err
+	}
+
+	return assembled.Serialize()
+}
+
+// Send broadcasts the assembled transaction and awaits the commitment
+func (tx *OfflineTransaction) Send(ctx context.Context, endpoint string, commitment solclient.Commitment) (*models.CommandResponse, error) {
+	rawTx, err := tx.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := tx.decodeMessage()
+	if err != nil {
+		return nil, err
+	}
+	programIDs := messageProgramIDs(message)
+
+	txSig, err := sendRawTransaction(ctx, endpoint, rawTx, programIDs)
+	if err != nil {
+		fmt.Printf("send tx error, err: %v\n", err)
+		return nil, err
+	}
+
+	confirmation, err := AwaitConfirmation(ctx, endpoint, txSig, commitment, tx.LastValidBlockHeight)
+	if confirmation == nil {
+		return &models.CommandResponse{TxSignature: txSig}, err
+	}
+	err = confirmationFailure(ctx, endpoint, confirmation, programIDs, err)
+
+	return &models.CommandResponse{
+		TxSignature:        txSig,
+		Slot:               confirmation.Slot,
+		ConfirmationStatus: string(confirmation.ConfirmationStatus),
+		TxError:            confirmation.Err,
+	}, err
+}
+
+func ReadOfflineTransaction(path string) (*OfflineTransaction, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &OfflineTransaction{}
+	if err := json.Unmarshal(content, tx); err != nil {
+		return nil, fmt.Errorf("decode offline transaction %v: %v", path, err)
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, fmt.Errorf("offline transaction %v: %v", path, err)
+	}
+
+	return tx, nil
+}
+
+func (tx *OfflineTransaction) Save(path string) error {
+	content, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// MergeOfflineTransactions merges the copies signed by different parties into the first one
+func MergeOfflineTransactions(transactions ...*OfflineTransaction) (*OfflineTransaction, error) {
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("nothing to merge")
+	}
+
+	merged := &OfflineTransaction{
+		Message:              transactions[0].Message,
+		Signers:              transactions[0].Signers,
+		Signatures:           make(map[string][]byte),
+		LastValidBlockHeight: transactions[0].LastValidBlockHeight,
+		NonceAccount:         transactions[0].NonceAccount,
+	}
+	for _, tx := range transactions {
+		if err := merged.Merge(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// SignedBy lists the signers present, sorted for stable output
+func (tx *OfflineTransaction) SignedBy() []string {
+	signed := make([]string, 0, len(tx.Signatures))
+	for pubkey := range tx.Signatures {
+		signed = append(signed, pubkey)
+	}
+	sort.Strings(signed)
+	return signed
+}
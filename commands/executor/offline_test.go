@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestOfflineTransactionMerge(t *testing.T) {
+	feePayer, consul := types.NewAccount(), types.NewAccount()
+
+	ix := sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1)
+	ix.Accounts = append(ix.Accounts, NewGravityBftSignerFromAccount(consul).Meta())
+
+	unsigned, err := NewOfflineTransaction(feePayer.PublicKey, []types.Instruction{ix}, common.PublicKey{}.ToBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unsigned.Signers) != 2 || unsigned.Signers[0] != feePayer.PublicKey.ToBase58() {
+		t.Fatalf("unexpected signers: %v", unsigned.Signers)
+	}
+
+	dir := t.TempDir()
+	unsignedPath := filepath.Join(dir, "unsigned.json")
+	if err := unsigned.Save(unsignedPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// every party signs its own copy of the file
+	sign := func(account types.Account) *OfflineTransaction {
+		tx, err := ReadOfflineTransaction(unsignedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Sign(NewGravityBftSignerFromAccount(account)); err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join(dir, account.PublicKey.ToBase58()+".json")
+		if err := tx.Save(path); err != nil {
+			t.Fatal(err)
+		}
+		signed, err := ReadOfflineTransaction(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	payerCopy, consulCopy := sign(feePayer), sign(consul)
+
+	if _, err := payerCopy.Serialize(); err == nil {
+		t.Fatal("transaction with missing signature is serialized")
+	}
+
+	merged, err := MergeOfflineTransactions(payerCopy, consulCopy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing := merged.Missing(); len(missing) != 0 {
+		t.Fatalf("unexpected missing signers: %v", missing)
+	}
+
+	rawTx, err := merged.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.TransactionDeserialize(rawTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Signatures) != 2 {
+		t.Fatalf("unexpected signatures: %v", len(tx.Signatures))
+	}
+
+	if err := unsigned.Sign(NewGravityBftSignerFromAccount(types.NewAccount())); err == nil {
+		t.Fatal("signature of not required key is accepted")
+	}
+
+	other, err := NewOfflineTransaction(feePayer.PublicKey, []types.Instruction{ix}, types.NewAccount().PublicKey.ToBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Merge(consulCopy); err != ErrMessageMismatch {
+		t.Fatalf("expected message mismatch, got %v", err)
+	}
+
+	consulCopy.Signatures[feePayer.PublicKey.ToBase58()] = consulCopy.Signatures[consul.PublicKey.ToBase58()]
+	if err := payerCopy.Merge(consulCopy); err == nil {
+		t.Fatal("forged signature is merged")
+	}
+}
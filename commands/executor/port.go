@@ -26,6 +26,9 @@ func float64ToByte(f float64) []byte {
 	return buf.Bytes()
 }
 
+// BuildCrossChainMintByteVector encodes UI amount as f64.
+//
+// Deprecated: f64 amounts may end up off by one base unit, use BuildCrossChainMintByteVectorWithCodec.
 func BuildCrossChainMintByteVector(swapId []byte, receiver common.PublicKey, amount float64) []byte {
 	var res []byte
 
@@ -44,6 +47,16 @@ func BuildCrossChainMintByteVector(swapId []byte, receiver common.PublicKey, amo
 	return res
 }
 
+// BuildCrossChainMintByteVectorWithCodec takes amount in base units
+func BuildCrossChainMintByteVectorWithCodec(swapId []byte, receiver common.PublicKey, amount uint64, codec AmountCodec) ([]byte, error) {
+	amountBytes, err := codec.Encode(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMintSolana(swapId, receiver, amountBytes), nil
+}
+
 type PortOperation struct {
 	Action   uint8
 	SwapID   [16]byte
@@ -64,6 +77,11 @@ func (po *PortOperation) Pack() []byte {
 	return res
 }
 
+// BaseUnits decodes the amount with the codec of the program the operation is meant for
+func (po *PortOperation) BaseUnits(codec AmountCodec) (uint64, error) {
+	return codec.Decode(po.Amount[:])
+}
+
 func UnpackByteArray(encoded []byte) (*PortOperation, error) {
 	if len(encoded) < 57 {
 		return nil, fmt.Errorf("invalid byte array length")
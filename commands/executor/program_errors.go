@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+
+	"github.com/Gravity-Tech/solanoid/commands/contract"
+	"github.com/Gravity-Tech/solanoid/models"
+	"github.com/Gravity-Tech/solanoid/models/gravity"
+	"github.com/Gravity-Tech/solanoid/models/nebula"
+	"github.com/Gravity-Tech/solanoid/models/port/ibport"
+	"github.com/Gravity-Tech/solanoid/models/port/luport"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+var (
+	programErrorsMu sync.RWMutex
+	programErrors   = map[common.PublicKey]*models.ProgramErrors{
+		common.PublicKeyFromString(contract.GravityBinary): gravity.Errors,
+		common.PublicKeyFromString(contract.NebulaBinary):  nebula.Errors,
+		common.PublicKeyFromString(contract.IBPortBinary):  ibport.Errors,
+		common.PublicKeyFromString(contract.LUPortBinary):  luport.Errors,
+	}
+)
+
+// RegisterProgramErrors makes failures of the program decode to its errors,
+// programs deployed at the contract addresses are registered already
+func RegisterProgramErrors(programID common.PublicKey, errs *models.ProgramErrors) {
+	programErrorsMu.Lock()
+	defer programErrorsMu.Unlock()
+
+	programErrors[programID] = errs
+}
+
+func lookupProgramErrors(programID common.PublicKey) (*models.ProgramErrors, bool) {
+	programErrorsMu.RLock()
+	defer programErrorsMu.RUnlock()
+
+	errs, ok := programErrors[programID]
+	return errs, ok
+}
+
+// DecodeTransactionFailure resolves the program which raised the error, programIDs are indexed as the instructions.
+// The logs tell the program apart from the ones the instruction invoked, e.g. SPL Token failing under IB Port,
+// so the custom code is looked up in the errors of the raising program only. Nil is returned for nil txErr.
+func DecodeTransactionFailure(txErr *models.TransactionError, programIDs []common.PublicKey, logs []string) error {
+	if txErr == nil {
+		return nil
+	}
+
+	failure := &models.TransactionFailure{TxError: txErr}
+	if txErr.InstructionIndex == nil {
+		return failure
+	}
+
+	index := *txErr.InstructionIndex
+	failure.Logs = models.InstructionLogs(logs, index)
+	if index < 0 || index >= len(programIDs) {
+		return failure
+	}
+
+	failure.ProgramID = programIDs[index]
+	if failed, ok := models.FailedProgram(logs, index); ok {
+		failure.ProgramID = failed
+	}
+	if errs, ok := lookupProgramErrors(failure.ProgramID); ok && txErr.Custom != nil {
+		failure.ProgramError = errs.Lookup(*txErr.Custom)
+	}
+
+	return failure
+}
+
+func instructionProgramIDs(instructionsList []types.Instruction) []common.PublicKey {
+	programIDs := make([]common.PublicKey, len(instructionsList))
+	for i, instruction := range instructionsList {
+		programIDs[i] = instruction.ProgramID
+	}
+	return programIDs
+}
+
+func messageProgramIDs(message types.Message) []common.PublicKey {
+	programIDs := make([]common.PublicKey, len(message.Instructions))
+	for i, instruction := range message.Instructions {
+		if instruction.ProgramIDIndex < len(message.Accounts) {
+			programIDs[i] = message.Accounts[instruction.ProgramIDIndex]
+		}
+	}
+	return programIDs
+}
+
+// sendRawTransaction is SendRawTransaction of the portto client which keeps the failed preflight details,
+// the error is decoded by DecodeTransactionFailure then
+func sendRawTransaction(ctx context.Context, endpoint string, rawTx []byte, programIDs []common.PublicKey) (string, error) {
+	var txSig string
+
+	err := newRPCClient(endpoint).call(ctx, "sendTransaction", []interface{}{
+		base64.StdEncoding.EncodeToString(rawTx),
+		solclient.SendTransactionConfig{
+			PreflightCommitment: solclient.CommitmentFinalized,
+			Encoding:            "base64",
+		},
+	}, &txSig)
+
+	rpcErr, ok := err.(*rpcError)
+	if !ok || len(rpcErr.Data) == 0 {
+		return txSig, err
+	}
+
+	preflight := struct {
+		Err  json.RawMessage `json:"err"`
+		Logs []string        `json:"logs"`
+	}{}
+	if json.Unmarshal(rpcErr.Data, &preflight) != nil {
+		return txSig, err
+	}
+
+	txErr, decodeErr := models.DecodeTransactionErrorJSON(preflight.Err)
+	if decodeErr != nil || txErr == nil {
+		return txSig, err
+	}
+
+	return txSig, DecodeTransactionFailure(txErr, programIDs, preflight.Logs)
+}
+
+// transactionLogs fetches the log messages of the landed transaction, nil if they are not available
+func transactionLogs(ctx context.Context, endpoint, signature string, commitment solclient.Commitment) []string {
+	var response *struct {
+		Meta struct {
+			LogMessages []string `json:"logMessages"`
+		} `json:"meta"`
+	}
+
+	config := map[string]interface{}{
+		"encoding":   "json",
+		"commitment": commitment,
+	}
+	if err := newRPCClient(endpoint).call(ctx, "getTransaction", []interface{}{signature, config}, &response); err != nil || response == nil {
+		return nil
+	}
+
+	return response.Meta.LogMessages
+}
+
+// confirmationFailure decodes the error of the transaction failed on-chain
+func confirmationFailure(ctx context.Context, endpoint string, confirmation *TxConfirmation, programIDs []common.PublicKey, err error) error {
+	if confirmation == nil || confirmation.Err == nil {
+		return err
+	}
+
+	txErr, decodeErr := models.DecodeTransactionError(confirmation.Err)
+	if decodeErr != nil {
+		return err
+	}
+
+	return DecodeTransactionFailure(txErr, programIDs, transactionLogs(ctx, endpoint, confirmation.Signature, confirmation.ConfirmationStatus))
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/contract"
+	"github.com/Gravity-Tech/solanoid/commands/solanatest"
+	"github.com/Gravity-Tech/solanoid/models"
+	"github.com/Gravity-Tech/solanoid/models/nebula"
+	"github.com/Gravity-Tech/solanoid/models/port/ibport"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// processAttachValue marks the swap of the data account processed, the second attach is a double spend
+func processAttachValue(ctx *solanatest.InvokeContext) error {
+	account, err := ctx.Account(0)
+	if err != nil {
+		return err
+	}
+	if account.Data[0] == 1 {
+		ctx.Log("swap %x is already processed", ctx.Data)
+		return solanatest.CustomError(ibport.ErrRequestAlreadyProcessed.Code)
+	}
+
+	account.Data[0] = 1
+	return nil
+}
+
+func TestDecodeProgramErrors(t *testing.T) {
+	ctx := context.Background()
+	payer, dataAccount := types.NewAccount(), types.NewAccount()
+	portProgramID := common.PublicKeyFromString(contract.IBPortBinary)
+
+	ledger := solanatest.NewLedger()
+	ledger.RegisterProgram(portProgramID, processAttachValue)
+	ledger.SetAccount(dataAccount.PublicKey, solanatest.Account{Lamports: 1_000_000, Owner: portProgramID, Data: make([]byte, 1)})
+	ledger.Airdrop(payer.PublicKey, 1_000_000_000)
+
+	server := solanatest.NewLedgerServer(ledger)
+	defer server.Close()
+
+	attach := func(swapID byte) types.Instruction {
+		return types.Instruction{
+			ProgramID: portProgramID,
+			Accounts:  []types.AccountMeta{{PubKey: dataAccount.PublicKey, IsWritable: true}},
+			Data:      []byte{swapID},
+		}
+	}
+
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(payer), server.URL)
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{attach(1)}, solclient.CommitmentFinalized); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ge.SendAndConfirm(ctx, []types.Instruction{attach(2)}, solclient.CommitmentFinalized)
+	if !errors.Is(err, ibport.ErrRequestAlreadyProcessed) {
+		t.Fatalf("expected double spend error, got %v", err)
+	}
+
+	var failure *models.TransactionFailure
+	if !errors.As(err, &failure) {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+	if failure.ProgramID != portProgramID || len(failure.Logs) != 1 || failure.Logs[0] != "swap 02 is already processed" {
+		t.Fatalf("unexpected failure: %+v", failure)
+	}
+
+	ge.SetSimulate(true)
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{attach(3)}, solclient.CommitmentFinalized); !errors.Is(err, ibport.ErrRequestAlreadyProcessed) {
+		t.Fatalf("expected simulated double spend error, got %v", err)
+	}
+
+	// programs deployed elsewhere are unknown until registered
+	customProgramID := types.NewAccount().PublicKey
+	ledger.RegisterProgram(customProgramID, func(ctx *solanatest.InvokeContext) error {
+		return solanatest.CustomError(nebula.ErrSubscriptionExists.Code)
+	})
+	subscribe := types.Instruction{ProgramID: customProgramID, Data: []byte{4}}
+
+	_, err = ge.SendAndConfirm(ctx, []types.Instruction{subscribe}, solclient.CommitmentFinalized)
+	if !errors.As(err, &failure) || failure.ProgramError != nil || *failure.TxError.Custom != nebula.ErrSubscriptionExists.Code {
+		t.Fatalf("unexpected error of unknown program: %v", err)
+	}
+
+	RegisterProgramErrors(customProgramID, nebula.Errors)
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{subscribe}, solclient.CommitmentFinalized); !errors.Is(err, nebula.ErrSubscriptionExists) {
+		t.Fatalf("expected subscription error, got %v", err)
+	}
+}
+
+func TestDecodeProgramErrorsOfInvokedProgram(t *testing.T) {
+	portProgramID := common.PublicKeyFromString(contract.IBPortBinary)
+	index, code := 1, ibport.ErrRequestAlreadyProcessed.Code
+
+	txErr := &models.TransactionError{Kind: "InstructionError", InstructionIndex: &index, InstructionError: "Custom", Custom: &code}
+	logs := []string{
+		"Program ComputeBudget111111111111111111111111111111 invoke [1]",
+		"Program ComputeBudget111111111111111111111111111111 success",
+		"Program " + portProgramID.ToBase58() + " invoke [1]",
+		"Program log: Instruction: CreateTransferUnwrapRequest",
+		"Program " + common.TokenProgramID.ToBase58() + " invoke [2]",
+		"Program log: Error: insufficient funds",
+		"Program " + common.TokenProgramID.ToBase58() + " failed: custom program error: 0x1",
+		"Program " + portProgramID.ToBase58() + " failed: custom program error: 0x1",
+	}
+
+	// SPL Token InsufficientFunds shares the code with the double spend of the port
+	err := DecodeTransactionFailure(txErr, []common.PublicKey{ComputeBudgetProgramID, portProgramID}, logs)
+	if errors.Is(err, ibport.ErrRequestAlreadyProcessed) {
+		t.Fatalf("token failure is credited to the port: %v", err)
+	}
+
+	var failure *models.TransactionFailure
+	if !errors.As(err, &failure) || failure.ProgramID != common.TokenProgramID || failure.ProgramError != nil {
+		t.Fatalf("unexpected failure: %+v", failure)
+	}
+
+	// the port failing on its own is still decoded
+	err = DecodeTransactionFailure(txErr, []common.PublicKey{ComputeBudgetProgramID, portProgramID}, append(logs[:4:4],
+		"Program "+portProgramID.ToBase58()+" failed: custom program error: 0x1",
+	))
+	if !errors.Is(err, ibport.ErrRequestAlreadyProcessed) {
+		t.Fatalf("expected double spend error, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+type simulateTransactionConfig struct {
+	SigVerify  bool   `json:"sigVerify"`
+	Commitment string `json:"commitment,omitempty"`
+	Encoding   string `json:"encoding"`
+}
+
+type simulateTransactionResponse struct {
+	Value struct {
+		Err           json.RawMessage `json:"err"`
+		Logs          []string        `json:"logs"`
+		UnitsConsumed uint64          `json:"unitsConsumed"`
+	} `json:"value"`
+}
+
+// SetSimulate switches the executor into dry-run mode: every invocation is signed as usual,
+// but passed to simulateTransaction instead of being broadcasted.
+func (ge *GenericExecutor) SetSimulate(simulate bool) {
+	ge.simulate = simulate
+}
+
+func (ge *GenericExecutor) Simulating() bool {
+	return ge.simulate
+}
+
+func (ge *GenericExecutor) simulateInstruction(ctx context.Context, instructionsList []types.Instruction) (*models.CommandResponse, error) {
+	instructionsList, blockhash, err := ge.recentBlockhash(ctx, instructionsList)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx, serializedMessage, err := ge.signTransaction(instructionsList, blockhash.Blockhash)
+	if err != nil {
+		return nil, err
+	}
+
+	response := simulateTransactionResponse{}
+	err = newRPCClient(ge.clientEndpoint).call(
+		ctx,
+		"simulateTransaction",
+		[]interface{}{
+			base64.StdEncoding.EncodeToString(rawTx),
+			simulateTransactionConfig{SigVerify: true, Commitment: "processed", Encoding: "base64"},
+		},
+		&response,
+	)
+	if err != nil {
+		fmt.Printf("simulate tx error, err: %v\n", err)
+		return nil, err
+	}
+
+	txErr, decodeErr := models.DecodeTransactionErrorJSON(response.Value.Err)
+
+	result := &models.CommandResponse{
+		SerializedMessage: hex.EncodeToString(serializedMessage),
+		Simulation: &models.SimulationResult{
+			Logs:          response.Value.Logs,
+			UnitsConsumed: response.Value.UnitsConsumed,
+			Err:           txErr,
+		},
+	}
+	// the error of unknown form still fails the simulation, it is kept raw
+	if decodeErr != nil {
+		fmt.Printf("decode simulation error, err: %v\n", decodeErr)
+		result.TxError = string(response.Value.Err)
+		return result, fmt.Errorf("simulation failed: %s", response.Value.Err)
+	}
+	if txErr != nil {
+		result.TxError = txErr.Raw
+		return result, DecodeTransactionFailure(txErr, instructionProgramIDs(instructionsList), response.Value.Logs)
+	}
+
+	return result, nil
+}
+
+// Simulate runs the instructions through simulateTransaction regardless of the executor mode,
+// with the compute budget the send would take.
+// The response is returned along with the error when the simulation has failed.
+func (ge *GenericExecutor) Simulate(ctx context.Context, instructionsList []types.Instruction) (*models.CommandResponse, error) {
+	instructionsList, err := ge.withComputeBudget(ctx, instructionsList)
+	if err != nil {
+		return nil, err
+	}
+
+	return ge.simulateInstruction(ctx, instructionsList)
+}
+
+func (ge *GenericExecutor) BuildAndSimulate(ctx context.Context, instruction interface{}) (*models.CommandResponse, error) {
+	builtIx, err := ge.buildIx(instruction)
+	if err != nil {
+		return nil, err
+	}
+
+	return ge.Simulate(ctx, []types.Instruction{*builtIx})
+}
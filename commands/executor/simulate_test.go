@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestSimulateUndecodableError(t *testing.T) {
+	feePayer := types.NewAccount()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Method string `json:"method"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		value := map[string]interface{}{"blockhash": common.PublicKey{}.ToBase58(), "lastValidBlockHeight": 150}
+		if request.Method == "simulateTransaction" {
+			// the error of the newer node the decoder does not know
+			value = map[string]interface{}{
+				"err":           map[string]interface{}{"InstructionError": []interface{}{0, 42}},
+				"logs":          []string{},
+				"unitsConsumed": 150,
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": value},
+		})
+	}))
+	defer server.Close()
+
+	ge := NewSignerExecutor(NewGravityBftSignerFromAccount(feePayer), server.URL)
+
+	response, err := ge.Simulate(context.Background(), []types.Instruction{
+		sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1),
+	})
+	if err == nil {
+		t.Fatal("failed simulation is reported as success")
+	}
+	if response == nil || response.TxError != `{"InstructionError":[0,42]}` {
+		t.Fatalf("raw simulation error is not kept: %+v", response)
+	}
+}
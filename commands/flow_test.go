@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"log"
 	"math/rand"
@@ -15,7 +14,6 @@ import (
 	"github.com/Gravity-Tech/solanoid/commands/ws"
 	"github.com/Gravity-Tech/solanoid/models"
 	"github.com/Gravity-Tech/solanoid/models/nebula"
-	"github.com/gorilla/websocket"
 
 	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
@@ -75,8 +73,7 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 		WrappedFaucet(t, deployer.PKPath, consul.PublicKey.ToBase58(), 10)
 	}
 
-	waitTransactionConfirmations()
-
+	ctx := context.Background()
 	RPCEndpoint, _ := InferSystemDefinedRPC()
 
 	tokenDeployResult, err := CreateToken(deployer.PKPath)
@@ -89,8 +86,6 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 	deployerTokenAccount, err := CreateTokenAccount(deployer.PKPath, tokenProgramAddress)
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
 	// mint some tokens for deployer
 	err = MintToken(deployer.PKPath, tokenProgramAddress, 1_000_000, deployerTokenAccount)
 	ValidateError(t, err)
@@ -111,6 +106,14 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 	ibportDataAccount, err := GenerateNewAccount(deployer.PrivateKey, IBPortAllocation, ibportProgram.PublicKey.ToBase58(), RPCEndpoint)
 	ValidateError(t, err)
 
+	awaitTransactions(t, RPCEndpoint,
+		gravityDataAccount.TxSignature,
+		gravityMultisigAccount.TxSignature,
+		nebulaDataAccount.TxSignature,
+		nebulaMultisigAccount.TxSignature,
+		ibportDataAccount.TxSignature,
+	)
+
 	ParallelExecution(
 		[]func(){
 			func() {
@@ -128,14 +131,10 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 		},
 	)
 
-	waitTransactionConfirmations()
-
 	err = AuthorizeToken(t, deployer.PKPath, tokenProgramAddress, "mint", ibportProgram.PDA.ToBase58())
 	ValidateError(t, err)
 	t.Log("Authorizing ib port to allow minting")
 
-	waitTransactionConfirmations()
-
 	gravityBuilder := executor.GravityInstructionBuilder{}
 	gravityExecutor, err := InitGenericExecutor(
 		deployer.PrivateKey,
@@ -169,28 +168,26 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 
 	oracles := consulsList.ConcatConsuls()
 
-	waitTransactionConfirmations()
-
 	ParallelExecution(
 		[]func(){
 			func() {
-				gravityInitResponse, err := gravityExecutor.BuildAndInvoke(
-					gravityBuilder.Init(BFT, 1, oracles),
+				gravityInitResponse, err := gravityExecutor.BuildAndConfirm(
+					ctx, gravityBuilder.Init(BFT, 1, oracles), solclient.CommitmentConfirmed,
 				)
 				fmt.Printf("Gravity Init: %v \n", gravityInitResponse.TxSignature)
 				ValidateError(t, err)
 			},
 			func() {
 				// (2)
-				nebulaInitResponse, err := nebulaExecutor.BuildAndInvoke(
-					nebulaBuilder.Init(BFT, nebula.Bytes, gravityDataAccount.Account.PublicKey, oracles),
+				nebulaInitResponse, err := nebulaExecutor.BuildAndConfirm(
+					ctx, nebulaBuilder.Init(BFT, nebula.Bytes, gravityDataAccount.Account.PublicKey, oracles), solclient.CommitmentConfirmed,
 				)
 				ValidateError(t, err)
 				fmt.Printf("Nebula Init: %v \n", nebulaInitResponse.TxSignature)
 			},
 			func() {
-				ibportInitResult, err := ibportExecutor.BuildAndInvoke(
-					executor.IBPortIXBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsList.ConcatConsuls()),
+				ibportInitResult, err := ibportExecutor.BuildAndConfirm(
+					ctx, executor.IBPortIXBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsList.ConcatConsuls()), solclient.CommitmentConfirmed,
 				)
 
 				fmt.Printf("IB Port Init: %v \n", ibportInitResult.TxSignature)
@@ -199,8 +196,6 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 		},
 	)
 
-	waitTransactionConfirmations()
-
 	fmt.Println("IB Port Program is being subscribed to Nebula")
 
 	var subID [16]byte
@@ -209,26 +204,21 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 	fmt.Printf("subID: %v \n", subID)
 
 	// (4)
-	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID),
+	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndConfirm(
+		ctx, nebulaBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID), solclient.CommitmentConfirmed,
 	)
 	ValidateError(t, err)
 
 	fmt.Printf("Nebula Subscribe: %v \n", nebulaSubscribePortResponse.TxSignature)
 	fmt.Println("Now checking for valid double spend prevent")
 
-	waitTransactionConfirmations()
-	// waitTransactionConfirmations()
-
-	_, err = nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID),
+	_, err = nebulaExecutor.BuildAndConfirm(
+		ctx, nebulaBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID), solclient.CommitmentConfirmed,
 	)
 	ValidateErrorExistence(t, err)
 
 	fmt.Printf("Nebula Subscribe with the same subID must have failed: %v \n", err.Error())
 
-	waitTransactionConfirmations()
-
 	i, requestsCount := 0, 1
 	pulseID := 0
 
@@ -264,10 +254,8 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 			nebulaExecutor.SetAdditionalSigners(consulsList.ToBftSigners())
 			nebulaExecutor.SetDeployerPK(operatingConsul.Account)
 
-			waitTransactionConfirmations()
-
-			nebulaSendHashValueResponse, err := nebulaExecutor.BuildAndInvoke(
-				nebulaBuilder.SendHashValue(dataHashForAttach),
+			nebulaSendHashValueResponse, err := nebulaExecutor.BuildAndConfirm(
+				ctx, nebulaBuilder.SendHashValue(dataHashForAttach), solclient.CommitmentConfirmed,
 			)
 			ValidateError(t, err)
 
@@ -285,10 +273,8 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 				{PubKey: ibportProgram.PDA, IsWritable: false, IsSigner: false},
 			})
 
-			waitTransactionConfirmations()
-
-			nebulaAttachResponse, err := nebulaExecutor.BuildAndInvoke(
-				nebulaBuilder.SendValueToSubs(rawDataValue, nebula.Bytes, uint64(pulseID), subID),
+			nebulaAttachResponse, err := nebulaExecutor.BuildAndConfirm(
+				ctx, nebulaBuilder.SendValueToSubs(executor.IBPortAttachValueAccounts, rawDataValue, nebula.Bytes, uint64(pulseID), subID), solclient.CommitmentConfirmed,
 			)
 			ValidateError(t, err)
 			if err != nil {
@@ -297,13 +283,9 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 
 			fmt.Printf("#%v Nebula SendValueToSubs Call:  %v \n", i, nebulaAttachResponse.TxSignature)
 
-			waitTransactionConfirmations()
-
 			i++
 			pulseID++
 		}
-
-		waitTransactionConfirmations()
 	}
 
 	const MaxIBPortRequestsLimit = 15
@@ -337,8 +319,6 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 		t.Log("Delegated some tokens to ibport from  deployer")
 		t.Log("Creating cross chain transfer tx")
 
-		waitTransactionConfirmations()
-
 		i = 0
 		for i < n {
 			ethReceiverPK, err := ethcrypto.GenerateKey()
@@ -357,7 +337,7 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 
 			instructionBatches = append(instructionBatches, ix)
 
-			castedIx := ix.(executor.CreateTransferUnwrapRequestInstruction)
+			castedIx := ix.Data.(executor.CreateTransferUnwrapRequestInstruction)
 			portOperation, err := executor.UnpackByteArray(castedIx.Pack()[:])
 
 			fmt.Printf("castedIx %+v \n", castedIx)
@@ -372,32 +352,28 @@ func TestNebulaSendValueToIBPortSubscriber(t *testing.T) {
 			i++
 		}
 
-		multipleBurnsResult, err := ibportExecutor.InvokeInstructionBatches(
-			instructionBatches,
-		)
-		if err != nil {
-			return nil, err
+		instructionsList := make([]types.Instruction, len(instructionBatches))
+		for j, instruction := range instructionBatches {
+			builtIx, err := ibportExecutor.BuildInstruction(instruction)
+			if err != nil {
+				return nil, err
+			}
+			instructionsList[j] = *builtIx
 		}
 
-		return multipleBurnsResult, nil
+		return ibportExecutor.SendAndConfirm(ctx, instructionsList, solclient.CommitmentConfirmed)
 	}
 
-	waitTransactionConfirmations()
-
 	// check for the limit
 
 	approvedLimitBurnsResult, err := sendNumerousBurnRequests(5)
 	ValidateError(t, err)
 	t.Logf("Sent %v times: CreateTransferUnwrapRequest - Tx: %v \n", i, approvedLimitBurnsResult.TxSignature)
 
-	waitTransactionConfirmations()
-
 	approvedLimitBurnsResult, err = sendNumerousBurnRequests(2)
 	ValidateError(t, err)
 	t.Logf("Sent %v times: CreateTransferUnwrapRequest - Tx: %v \n", i, approvedLimitBurnsResult.TxSignature)
 
-	waitTransactionConfirmations()
-
 	// approvedLimitBurnsResult, err = sendNumerousBurnRequests(1)
 	// ValidateErrorExistence(t, err)
 
@@ -494,40 +470,6 @@ func TestDataRealloc(t *testing.T) {
 	fmt.Printf("#1 == #2: %v \n", bytes.Equal(testDataAccount.Account.PublicKey[:], testDataAccount2.Account.PublicKey[:]))
 }
 
-func buildAccountSubscribeRequest(watched string) ws.RequestBody {
-	watchRequestParams := []interface{}{
-		watched,
-		ws.Encoding{
-			Encoding:   "base64",
-			Commitment: "finalized",
-		},
-	}
-
-	return ws.RequestBody{
-		Jsonrpc: "2.0",
-		ID:      1,
-		Method:  "accountSubscribe",
-		Params:  watchRequestParams,
-	}
-}
-
-func buildLogsSubscribeRequest(watched string) ws.LogsSubscribeBody {
-	return ws.LogsSubscribeBody{
-		Jsonrpc: "2.0",
-		ID:      1,
-		Method:  "logsSubscribe",
-		Params: []ws.LogsSubscribeParam{
-			{
-				Mentions: []string{
-					watched,
-				},
-			},
-			{
-				Commitment: "finalized",
-			},
-		},
-	}
-}
 func TestMintWatcher(t *testing.T) {
 	var err error
 
@@ -548,8 +490,6 @@ func TestMintWatcher(t *testing.T) {
 
 	WrappedFaucet(t, deployer.PKPath, "", 10)
 
-	waitTransactionConfirmations()
-
 	tokenDeployResult, err := CreateToken(deployer.PKPath)
 	ValidateError(t, err)
 
@@ -560,8 +500,6 @@ func TestMintWatcher(t *testing.T) {
 	deployerTokenAccount, err := CreateTokenAccount(deployer.PKPath, tokenProgramAddress)
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
 	// ibportExecutor, err := InitGenericExecutor(
 	// 	deployer.PrivateKey,
 	// 	ibportProgram.PublicKey.ToBase58(),
@@ -579,29 +517,20 @@ func TestMintWatcher(t *testing.T) {
 	// waitTransactionConfirmations()
 
 	fmt.Printf("WS Endpoint: %v \n", WSEndpoint)
-	// u := url.URL{Scheme: "ws", Host: WSEndpoint, Path: "/" }
 
 	log.Printf("connecting to %s", WSEndpoint)
 
-	c, _, err := websocket.DefaultDialer.Dial(WSEndpoint, nil)
+	ctx := context.Background()
+
+	wsClient, err := ws.Dial(ctx, WSEndpoint)
 	ValidateError(t, err)
 
-	defer c.Close()
+	defer wsClient.Close()
 
-	done := make(chan struct{})
+	logs, err := wsClient.LogsSubscribe(ctx, deployerTokenAccount, ws.CommitmentFinalized)
+	ValidateError(t, err)
 
-	// {
-	// 	"jsonrpc": "2.0",
-	// 	"id": 1,
-	// 	"method": "accountSubscribe",
-	// 	"params": [
-	// 	  "CM78CPUeXjn8o3yroDHxUtKsZZgoy4GPkPPXfouKNH12",
-	// 	  {
-	// 		"encoding": "base64",
-	// 		"commitment": "finalized"
-	// 	  }
-	// 	]
-	//   }
+	defer logs.Unsubscribe(ctx)
 
 	// mint some tokens for deployer
 	go func() {
@@ -612,110 +541,21 @@ func TestMintWatcher(t *testing.T) {
 		t.Log("Minted some tokens")
 	}()
 
-	// buildAccountUnsubscribeRequest := func(subID int64) WSRequestBody {
-	// 	return WSRequestBody {
-	// 		Jsonrpc: "2.0",
-	// 		ID: 1,
-	// 		Method: "accountSubscribe",
-	// 		Params: []interface{} {
-	// 			subID,
-	// 		},
-	// 	}
-	// }
-
-	// watchRequest := buildAccountSubscribeRequest(deployerTokenAccount)
-	watchRequest := buildLogsSubscribeRequest(deployerTokenAccount)
-
-	watchRequestBytes, err := json.Marshal(&watchRequest)
-	ValidateError(t, err)
+	solanaClient := solclient.NewClient(RPCEndpoint)
 
-	err = c.WriteMessage(websocket.TextMessage, watchRequestBytes)
-	ValidateError(t, err)
-
-	// go func() {
-	// 	defer close(done)
-
-	// 	for {
-	// 		_, message, err := c.ReadMessage()
-	// 		if err != nil {
-	// 			log.Println("read:", err)
-	// 			return
-	// 		}
-	// 		log.Printf("recv: %s", message)
-	// 	}
-	// }()
-	defer close(done)
-
-	for {
-		_, message, err := c.ReadMessage()
-		if err != nil {
-			log.Println("read:", err)
-			return
-		}
-		log.Printf("recv: %s", message)
-
-		var responseUnpacked ws.LogsSubscribeNotification
-		err = json.Unmarshal(message, &responseUnpacked)
-		if err != nil {
-			fmt.Printf("Error on WSLogsSubscribeNotification unpack: %v \n", err)
-			continue
-		}
-
-		txID := responseUnpacked.Params.Result.Value.Signature
+	for notification := range logs.C {
+		txID := notification.Value.Signature
 		if txID == "" {
 			continue
 		}
 
-		solanaClient := solclient.NewClient(RPCEndpoint)
-
-		ctx := context.Background()
-
 		response, err := solanaClient.GetConfirmedTransaction(ctx, txID)
 		ValidateError(t, err)
 
 		fmt.Printf("RESPONSE: %+v \n", response)
-		// var responseUnpacked WSAccountNotification
-		// err = json.Unmarshal(message, &responseUnpacked)
-		// if err != nil {
-		// 	fmt.Printf("Error on WSAccountNotification unpack: %v \n", err)
-		// 	continue
-		// }
-
-		// if len(responseUnpacked.Params.Result.Value.Data) == 0 {
-		// 	continue
-		// }
-
-		// accountValue := responseUnpacked.Params.Result.Value.Data[0]
-		// decodedAccountValue, err := base64.StdEncoding.DecodeString(accountValue)
-		// ValidateError(t, err)
-
-		// tokenAccount, err := tokenprog.TokenAccountFromData(decodedAccountValue)
-		// ValidateError(t, err)
-
-		// if err != nil {
-		// 	// log.Println("read:", err)
-		// 	fmt.Printf("Error on TokenAccount unpack: %v \n", err)
-		// 	continue
-		// }
-
-		// log.Printf("recv: %s", message)
-
-		// fmt.Printf("%+v \n", tokenAccount)
 	}
 
-	// ticker := time.NewTicker(time.Second)
-	// defer ticker.Stop()
-
-	// for {
-	// 	select {
-	// 	case <-done:
-	// 		return
-	// 	case t := <-ticker.C:
-	// 		err := c.WriteMessage(websocket.TextMessage, []byte(t.String()))
-	// 		if err != nil {
-	// 			log.Println("write:", err)
-	// 			return
-	// 		}
-	// 	}
-	// }
+	if err := logs.Err(); err != nil {
+		log.Println("read:", err)
+	}
 }
@@ -6,14 +6,24 @@ type AbstractValidatable interface {
 	Validate() error
 }
 
+const (
+	ChainTypeSolana = "solana"
+)
+
 type CrossChainTokenConfig struct {
-	AssetID              string
-	NodeURL              string
-	GravityAddress       string
-	ChainType            string
-	ConsulsList          []string
-	NebulaScriptPath     string
-	SubscriberScriptPath string
+	AssetID              string   `mapstructure:"asset_id"`
+	NodeURL              string   `mapstructure:"node_url"`
+	GravityAddress       string   `mapstructure:"gravity_address"`
+	ChainType            string   `mapstructure:"chain_type"`
+	ConsulsList          []string `mapstructure:"consuls_list"`
+	NebulaScriptPath     string   `mapstructure:"nebula_script_path"`
+	SubscriberScriptPath string   `mapstructure:"subscriber_script_path"`
+
+	// Solana only: deployer keypair, solana CLI keypair is used if omitted
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	// Solana only: deployed Nebula and Port binaries, contract defaults are used if omitted
+	NebulaProgramID string `mapstructure:"nebula_program_id"`
+	PortProgramID   string `mapstructure:"port_program_id"`
 }
 
 func (tokenCfg CrossChainTokenConfig) Validate() error {
@@ -37,13 +47,13 @@ func (tokenCfg CrossChainTokenConfig) Validate() error {
 }
 
 type CommonInputConfig struct {
-	Bft int
+	Bft int `mapstructure:"bft"`
 }
 
 type DeployInputConfig struct {
-	CommonInputConfig
-	OriginToken CrossChainTokenConfig
-	DestToken   CrossChainTokenConfig
+	CommonInputConfig `mapstructure:",squash"`
+	OriginToken       CrossChainTokenConfig `mapstructure:"origin_token"`
+	DestToken         CrossChainTokenConfig `mapstructure:"dest_token"`
 }
 
 func (deployConfig DeployInputConfig) Validate() error {
@@ -61,6 +71,9 @@ func (deployConfig DeployInputConfig) Validate() error {
 	if deployConfig.Bft <= 0 {
 		return fmt.Errorf("bft value is less than or equal to zero")
 	}
+	if deployConfig.Bft > len(deployConfig.OriginToken.ConsulsList) || deployConfig.Bft > len(deployConfig.DestToken.ConsulsList) {
+		return fmt.Errorf("bft value exceeds consuls count")
+	}
 
 	return nil
 }
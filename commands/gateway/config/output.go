@@ -6,6 +6,7 @@ type Account struct {
 
 type CrossChainDeploymentOutput struct {
 	Gravity, Nebula, Port Account
+	NebulaMultisig        Account
 	Token                 string
 }
 
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// ReadDeployInputConfig parses any format supported by viper, the format is inferred from the file extension
+func ReadDeployInputConfig(path string) (*DeployInputConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var deployConfig DeployInputConfig
+	if err := v.Unmarshal(&deployConfig); err != nil {
+		return nil, err
+	}
+
+	return &deployConfig, nil
+}
+
+// WriteToFile keeps the output readable by the owner only, it holds the data account private keys.
+// The mode of the existing file is tightened before the keys are written.
+func (output *Output) WriteToFile(path string) error {
+	serialized, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(serialized); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
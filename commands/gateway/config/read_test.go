@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDeployConfig = `
+bft: 2
+origin_token:
+  asset_id: SRMuApVNdxXokk5GT7XD5cUUgXMBCoAz2LHeuAoKWRt
+  node_url: https://api.mainnet-beta.solana.com
+  gravity_address: ErLEJcqRKQdhLpLHLn9zUzx1mu7VfrZbgwsfAL4BG4uQ
+  chain_type: solana
+  private_key_path: deployer.json
+  consuls_list:
+    - EnwGpvfZdCpkjs8jMShjo8evce2LbNfrYvREzdwGh5oc
+    - 5Ng92o7CPPWk5tT2pqrnRMndoD49d51f4QcocgJttGHS
+dest_token:
+  node_url: https://rpc-mainnet.maticvigil.com
+  gravity_address: "0x0000000000000000000000000000000000000001"
+  chain_type: evm
+  consuls_list:
+    - "0x0000000000000000000000000000000000000002"
+    - "0x0000000000000000000000000000000000000003"
+`
+
+func TestReadDeployInputConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deploy-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "deploy.yaml")
+	if err := ioutil.WriteFile(path, []byte(testDeployConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deployConfig, err := ReadDeployInputConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deployConfig.Bft != 2 {
+		t.Fatalf("bft: expected 2, got %v", deployConfig.Bft)
+	}
+	if deployConfig.OriginToken.ChainType != ChainTypeSolana || deployConfig.OriginToken.PrivateKeyPath != "deployer.json" {
+		t.Fatalf("unexpected origin: %+v", deployConfig.OriginToken)
+	}
+	if len(deployConfig.DestToken.ConsulsList) != 2 {
+		t.Fatalf("unexpected destination consuls: %v", deployConfig.DestToken.ConsulsList)
+	}
+	if err := deployConfig.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	deployConfig.Bft = 3
+	if err := deployConfig.Validate(); err == nil {
+		t.Fatal("bft exceeding consuls count must not pass validation")
+	}
+}
+
+func TestWriteOutputToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.json")
+	if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := &Output{Bft: 3, Origin: CrossChainDeploymentOutput{Port: Account{Address: "port", PrivKey: "secret"}}}
+	if err := output.WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("output holds private keys, expected 0600 mode, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,242 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Gravity-Tech/solanoid/commands"
+	"github.com/Gravity-Tech/solanoid/commands/contract"
+	"github.com/Gravity-Tech/solanoid/commands/gateway/config"
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	deployConfigPath  string
+	deployOutputPath  string
+	deployJournalPath string
+
+	gatewayCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "gateway",
+		Short: "Manage Nebula and Port gateway pairs",
+		Long:  ``,
+	}
+	gatewayDeployCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "deploy",
+		Short: "Deploy gateway pair described by the config",
+		Long:  `Creates Nebula and Port data accounts, initializes them, authorizes IB Port to mint and subscribes Port to Nebula on every Solana side of the config. Origin side gets LU Port, destination side gets IB Port.`,
+		Run:   deployGateway,
+	}
+)
+
+func init() {
+	gatewayDeployCmd.Flags().StringVar(&deployConfigPath, "config", "deploy.yaml", "Path to deploy input config (yaml or json)")
+	viper.BindPFlag("gateway-config", gatewayDeployCmd.Flags().Lookup("config"))
+	gatewayDeployCmd.MarkFlagRequired("config")
+
+	gatewayDeployCmd.Flags().StringVarP(&deployOutputPath, "output", "o", "deploy-output.json", "Path to write deployed accounts to")
+	viper.BindPFlag("output", gatewayDeployCmd.Flags().Lookup("output"))
+
+	gatewayDeployCmd.Flags().StringVar(&deployJournalPath, "journal", "deploy-journal.json", "Path to the journal of completed steps, rerun with the same journal and inputs to resume failed deploy, other inputs need a new journal")
+	viper.BindPFlag("journal", gatewayDeployCmd.Flags().Lookup("journal"))
+
+	gatewayCmd.AddCommand(gatewayDeployCmd)
+	commands.SolanoidCmd.AddCommand(gatewayCmd)
+}
+
+func parsePublicKey(address string) (common.PublicKey, error) {
+	decoded, err := base58.Decode(address)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("invalid address %v: %v", address, err)
+	}
+	if len(decoded) != common.PublicKeyLength {
+		return common.PublicKey{}, fmt.Errorf("invalid address %v: expected %d bytes, got %d", address, common.PublicKeyLength, len(decoded))
+	}
+
+	return common.PublicKeyFromBytes(decoded), nil
+}
+
+func parsePublicKeyOrDefault(address, defaultAddress string) (common.PublicKey, error) {
+	if address == "" {
+		address = defaultAddress
+	}
+	return parsePublicKey(address)
+}
+
+// NewSolanaGatewayParams validates the Solana side of the deploy config
+func NewSolanaGatewayParams(bft int, tokenCfg *config.CrossChainTokenConfig, defaultPortProgramID string) (*SolanaGatewayParams, error) {
+	if bft > 255 {
+		return nil, fmt.Errorf("bft value %v overflows u8", bft)
+	}
+
+	keypairPath := tokenCfg.PrivateKeyPath
+	if keypairPath == "" {
+		solanaConfig, err := commands.ReadSystemSolanaConfig()
+		if err != nil {
+			return nil, err
+		}
+		keypairPath = solanaConfig.KeypairPath
+	}
+
+	deployer, err := commands.ReadAccountFromPath(keypairPath)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &SolanaGatewayParams{
+		Deployer: deployer,
+		Endpoint: tokenCfg.NodeURL,
+		Bft:      uint8(bft),
+	}
+
+	params.GravityDataAccount, err = parsePublicKey(tokenCfg.GravityAddress)
+	if err != nil {
+		return nil, err
+	}
+	params.NebulaProgramID, err = parsePublicKeyOrDefault(tokenCfg.NebulaProgramID, contract.NebulaBinary)
+	if err != nil {
+		return nil, err
+	}
+	params.PortProgramID, err = parsePublicKeyOrDefault(tokenCfg.PortProgramID, defaultPortProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, consul := range tokenCfg.ConsulsList {
+		consulPubKey, err := parsePublicKey(consul)
+		if err != nil {
+			return nil, err
+		}
+		params.Consuls = append(params.Consuls, consulPubKey)
+	}
+
+	if tokenCfg.AssetID != "" {
+		tokenMint, err := parsePublicKey(tokenCfg.AssetID)
+		if err != nil {
+			return nil, err
+		}
+		params.TokenMint = &tokenMint
+	}
+
+	return params, nil
+}
+
+func outputAccount(account types.Account) config.Account {
+	return config.Account{
+		Address: account.PublicKey.ToBase58(),
+		PrivKey: base58.Encode(account.PrivateKey),
+	}
+}
+
+func newDeploymentOutput(tokenCfg *config.CrossChainTokenConfig, result *GatewayDeployResult) config.CrossChainDeploymentOutput {
+	output := config.CrossChainDeploymentOutput{
+		Gravity: config.Account{Address: tokenCfg.GravityAddress},
+		Token:   tokenCfg.AssetID,
+	}
+	if result == nil {
+		return output
+	}
+
+	output.Nebula = outputAccount(result.NebulaDataAccount)
+	output.NebulaMultisig = outputAccount(result.NebulaMultisigAccount)
+	output.Port = outputAccount(result.PortDataAccount)
+	output.Token = result.TokenMint.ToBase58()
+
+	return output
+}
+
+// DeployGateway sets up every Solana side of the config, other chains are expected to be deployed with their own tooling.
+// Nil journal disables resuming.
+func DeployGateway(ctx context.Context, deployConfig *config.DeployInputConfig, journal *DeployJournal) (*config.Output, error) {
+	if err := deployConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	origin, destination := &deployConfig.OriginToken, &deployConfig.DestToken
+	if origin.ChainType != config.ChainTypeSolana && destination.ChainType != config.ChainTypeSolana {
+		return nil, fmt.Errorf("neither origin nor destination chain type is %v", config.ChainTypeSolana)
+	}
+
+	var originParams, destinationParams *SolanaGatewayParams
+	var err error
+
+	// both sides are validated before anything is sent
+	if origin.ChainType == config.ChainTypeSolana {
+		originParams, err = NewSolanaGatewayParams(deployConfig.Bft, origin, contract.LUPortBinary)
+		if err != nil {
+			return nil, fmt.Errorf("origin: %v", err)
+		}
+		if originParams.TokenMint == nil {
+			return nil, fmt.Errorf("origin: asset id is required for lu port")
+		}
+		originParams.Journal = journal.Scope("origin")
+	}
+	if destination.ChainType == config.ChainTypeSolana {
+		destinationParams, err = NewSolanaGatewayParams(deployConfig.Bft, destination, contract.IBPortBinary)
+		if err != nil {
+			return nil, fmt.Errorf("destination: %v", err)
+		}
+		destinationParams.Journal = journal.Scope("destination")
+	}
+
+	var originResult, destinationResult *GatewayDeployResult
+
+	if originParams != nil {
+		originResult, err = DeployLUPortGateway(ctx, originParams)
+		if err != nil {
+			return nil, fmt.Errorf("origin: %v", err)
+		}
+	} else {
+		log.Printf("origin chain type is %v, skipping", origin.ChainType)
+	}
+
+	output := &config.Output{
+		Bft:    deployConfig.Bft,
+		Origin: newDeploymentOutput(origin, originResult),
+	}
+
+	if destinationParams != nil {
+		destinationResult, err = DeployIBPortGateway(ctx, destinationParams)
+		if err != nil {
+			// origin accounts are already funded, keep them in the output
+			return output, fmt.Errorf("destination: %v", err)
+		}
+	} else {
+		log.Printf("destination chain type is %v, skipping", destination.ChainType)
+	}
+
+	output.Destination = newDeploymentOutput(destination, destinationResult)
+
+	return output, nil
+}
+
+func deployGateway(ccmd *cobra.Command, args []string) {
+	deployConfig, err := config.ReadDeployInputConfig(deployConfigPath)
+	if err != nil {
+		log.Fatalf("Error on 'ReadDeployInputConfig': %v\n", err)
+	}
+
+	journal, err := OpenDeployJournal(deployJournalPath)
+	if err != nil {
+		log.Fatalf("Error on 'OpenDeployJournal': %v\n", err)
+	}
+
+	output, deployErr := DeployGateway(context.Background(), deployConfig, journal)
+	if output != nil {
+		if err := output.WriteToFile(deployOutputPath); err != nil {
+			log.Fatalf("Error on 'WriteToFile': %v\n", err)
+		}
+		fmt.Printf("Deploy output written to: %s\n", deployOutputPath)
+	}
+	if deployErr != nil {
+		log.Fatalf("Error on 'DeployGateway': %v\nRerun with the same --journal to resume\n", deployErr)
+	}
+}
@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+type DeployStep string
+
+const (
+	StepCreateMint            DeployStep = "create_mint"
+	StepAuthorizeMint         DeployStep = "authorize_mint"
+	StepNebulaDataAccount     DeployStep = "nebula_data_account"
+	StepNebulaMultisigAccount DeployStep = "nebula_multisig_account"
+	StepPortDataAccount       DeployStep = "port_data_account"
+	StepNebulaInit            DeployStep = "nebula_init"
+	StepPortInit              DeployStep = "port_init"
+	StepSubscribe             DeployStep = "subscribe"
+)
+
+// JournalEntry is recorded as pending before the transaction is sent,
+// so keypairs and subscription ids survive a crash between send and confirmation
+type JournalEntry struct {
+	Step       DeployStep `json:"step"`
+	Done       bool       `json:"done"`
+	Signature  string     `json:"signature,omitempty"`
+	Address    string     `json:"address,omitempty"`
+	PrivateKey string     `json:"private_key,omitempty"`
+	Data       string     `json:"data,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (entry *JournalEntry) Account() (types.Account, error) {
+	privateKey, err := base58.Decode(entry.PrivateKey)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("step %v: invalid private key: %v", entry.Step, err)
+	}
+
+	return types.AccountFromPrivateKeyBytes(privateKey), nil
+}
+
+type journalState struct {
+	mu sync.Mutex
+	// Fingerprints are the deploy inputs of the scopes, see Bind
+	Fingerprints map[string]string       `json:"fingerprints,omitempty"`
+	Steps        map[string]JournalEntry `json:"steps"`
+}
+
+// DeployJournal persists completed deploy steps, rerun with the same journal resumes the rollout.
+// Nil journal records nothing, so every step is executed.
+type DeployJournal struct {
+	path  string
+	scope string
+	state *journalState
+}
+
+// OpenDeployJournal reads the journal at path, missing file starts an empty one
+func OpenDeployJournal(path string) (*DeployJournal, error) {
+	state := &journalState{Fingerprints: make(map[string]string), Steps: make(map[string]JournalEntry)}
+
+	content, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(content, state); err != nil {
+			return nil, fmt.Errorf("journal %v: %v", path, err)
+		}
+		if state.Fingerprints == nil {
+			state.Fingerprints = make(map[string]string)
+		}
+		if state.Steps == nil {
+			state.Steps = make(map[string]JournalEntry)
+		}
+	}
+
+	return &DeployJournal{path: path, state: state}, nil
+}
+
+// Scope separates steps of different deployments sharing one journal file
+func (journal *DeployJournal) Scope(scope string) *DeployJournal {
+	if journal == nil {
+		return nil
+	}
+
+	return &DeployJournal{
+		path:  journal.path,
+		scope: journal.scopedKey(DeployStep(scope)),
+		state: journal.state,
+	}
+}
+
+func (journal *DeployJournal) scopedKey(step DeployStep) string {
+	if journal.scope == "" {
+		return string(step)
+	}
+	return journal.scope + "/" + string(step)
+}
+
+// Bind ties the scope to the fingerprint of the deploy inputs, so the journal written for other inputs
+// is refused instead of resumed. Steps recorded with no fingerprint are refused the same way.
+func (journal *DeployJournal) Bind(fingerprint string) error {
+	if journal == nil {
+		return nil
+	}
+
+	journal.state.mu.Lock()
+	defer journal.state.mu.Unlock()
+
+	recorded, ok := journal.state.Fingerprints[journal.scope]
+	switch {
+	case ok && recorded == fingerprint:
+		return nil
+	case ok:
+		return fmt.Errorf("journal %v holds the deploy of other inputs, pass a new journal to start over", journal.path)
+	case journal.hasSteps():
+		return fmt.Errorf("journal %v holds the deploy of unknown inputs, pass a new journal to start over", journal.path)
+	}
+
+	journal.state.Fingerprints[journal.scope] = fingerprint
+	return journal.flush()
+}
+
+func (journal *DeployJournal) hasSteps() bool {
+	for key := range journal.state.Steps {
+		if journal.scope == "" && !strings.Contains(key, "/") || journal.scope != "" && strings.HasPrefix(key, journal.scope+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (journal *DeployJournal) Entry(step DeployStep) (JournalEntry, bool) {
+	if journal == nil {
+		return JournalEntry{}, false
+	}
+
+	journal.state.mu.Lock()
+	defer journal.state.mu.Unlock()
+
+	entry, ok := journal.state.Steps[journal.scopedKey(step)]
+	return entry, ok
+}
+
+func (journal *DeployJournal) Record(entry JournalEntry) error {
+	if journal == nil {
+		return nil
+	}
+
+	journal.state.mu.Lock()
+	defer journal.state.mu.Unlock()
+
+	entry.UpdatedAt = time.Now().UTC()
+	journal.state.Steps[journal.scopedKey(entry.Step)] = entry
+
+	return journal.flush()
+}
+
+// flush replaces the file atomically, journal holds private keys, so it is readable by the owner only
+func (journal *DeployJournal) flush() error {
+	serialized, err := json.MarshalIndent(journal.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(journal.path), filepath.Base(journal.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(serialized); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), journal.path)
+}
@@ -0,0 +1,276 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/solanatest"
+	"github.com/Gravity-Tech/solanoid/commands/tokens"
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestDeployJournalPersistsScopedSteps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deploy-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "journal.json")
+
+	journal, err := OpenDeployJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := types.NewAccount()
+	err = journal.Scope("origin").Record(JournalEntry{
+		Step:       StepNebulaDataAccount,
+		Address:    account.PublicKey.ToBase58(),
+		PrivateKey: base58.Encode(account.PrivateKey),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = journal.Scope("destination").Record(JournalEntry{Step: StepNebulaInit, Done: true, Signature: "sig"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("journal holds private keys, expected 0600 mode, got %v", info.Mode().Perm())
+	}
+
+	reopened, err := OpenDeployJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok := reopened.Scope("origin").Entry(StepNebulaDataAccount)
+	if !ok || entry.Done {
+		t.Fatalf("expected pending nebula data account entry, got %+v", entry)
+	}
+	restored, err := entry.Account()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.PublicKey != account.PublicKey {
+		t.Fatalf("restored account mismatch: %v != %v", restored.PublicKey.ToBase58(), account.PublicKey.ToBase58())
+	}
+
+	if _, ok := reopened.Scope("origin").Entry(StepNebulaInit); ok {
+		t.Fatal("steps of destination scope must not leak into origin")
+	}
+	if entry, ok := reopened.Scope("destination").Entry(StepNebulaInit); !ok || !entry.Done || entry.Signature != "sig" {
+		t.Fatalf("unexpected destination nebula init entry: %+v", entry)
+	}
+}
+
+func TestDeployJournalFingerprint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+
+	raydium, serum := types.NewAccount().PublicKey, types.NewAccount().PublicKey
+	raydiumParams := &SolanaGatewayParams{Endpoint: "http://localhost:8899", Bft: 3, TokenMint: &raydium}
+	serumParams := &SolanaGatewayParams{Endpoint: "http://localhost:8899", Bft: 3, TokenMint: &serum}
+
+	journal, err := OpenDeployJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Scope("origin").Bind(raydiumParams.fingerprint()); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Scope("origin").Record(JournalEntry{Step: StepPortInit, Done: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenDeployJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Scope("origin").Bind(raydiumParams.fingerprint()); err != nil {
+		t.Fatalf("the same inputs must resume: %v", err)
+	}
+	if err := reopened.Scope("origin").Bind(serumParams.fingerprint()); err == nil {
+		t.Fatal("the deploy of another mint must not resume")
+	}
+	if err := reopened.Scope("destination").Bind(serumParams.fingerprint()); err != nil {
+		t.Fatalf("scopes are bound apart: %v", err)
+	}
+
+	// journals written before the fingerprints are of unknown inputs
+	legacy, err := OpenDeployJournal(filepath.Join(t.TempDir(), "journal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Record(JournalEntry{Step: StepPortInit, Done: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Bind(raydiumParams.fingerprint()); err == nil {
+		t.Fatal("steps of unknown inputs must not resume")
+	}
+}
+
+func TestEnsureMintResumesJournaledKeypair(t *testing.T) {
+	server := solanatest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	deployer := types.NewAccount()
+	server.Airdrop(deployer.PublicKey, 1_000_000_000)
+
+	operator, err := tokens.NewTokenOperator(deployer, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journal, err := OpenDeployJournal(filepath.Join(t.TempDir(), "journal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := &SolanaGatewayParams{Deployer: deployer, Endpoint: server.URL, Journal: journal}
+
+	// crash right after the mint is created: the keypair is journaled, the step is pending
+	mint := types.NewAccount()
+	if err := journal.Record(JournalEntry{Step: StepCreateMint, Address: mint.PublicKey.ToBase58(), PrivateKey: base58.Encode(mint.PrivateKey)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := operator.CreateMintAccount(ctx, mint, deployer.PublicKey, nil, executor.DefaultDecimals); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := params.ensureMint(ctx, operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed != mint.PublicKey {
+		t.Fatalf("journaled mint is not reused: %v", resumed.ToBase58())
+	}
+	if entry, _ := journal.Entry(StepCreateMint); !entry.Done {
+		t.Fatal("resumed mint step is not completed")
+	}
+
+	// a fresh journal creates the mint at the keypair it records
+	params.Journal, err = OpenDeployJournal(filepath.Join(t.TempDir(), "journal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := params.ensureMint(ctx, operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := params.Journal.Entry(StepCreateMint)
+	if account, err := entry.Account(); err != nil || account.PublicKey != created || !entry.Done || entry.Signature == "" {
+		t.Fatalf("unexpected mint entry: %+v", entry)
+	}
+	if _, err := operator.GetMint(ctx, created); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// encodeNebulaState lays out the initialized nebula with the ids queued and the ids subscribed
+func encodeNebulaState(queued, subscribed []models.UUID) []byte {
+	var buf bytes.Buffer
+	le := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	// rounds_dict
+	le(uint32(0))
+	le(uint32(len(queued)))
+	for _, id := range queued {
+		buf.Write(id[:])
+	}
+	// oracles, bft, multisig, gravity, data type, last round and pulse
+	le(uint32(0))
+	le(uint8(1))
+	buf.Write(make([]byte, 2*32))
+	le(uint8(0))
+	le(uint64(0))
+	le(uint64(0))
+	le(uint32(len(subscribed)))
+	for _, id := range subscribed {
+		buf.Write(id[:])
+		buf.Write(make([]byte, 2*32))
+		le(uint8(1))
+		le(uint64(0))
+	}
+	// pulses_map, is_pulse_sent
+	le(uint32(0))
+	le(uint32(0))
+	le(uint8(1))
+	buf.Write(make([]byte, 32))
+
+	data := make([]byte, 2000)
+	copy(data, buf.Bytes())
+	return data
+}
+
+func TestEnsureSubscribedChecksNebulaSubscriptions(t *testing.T) {
+	server := solanatest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	nebulaProgramID, nebulaDataAccount := types.NewAccount().PublicKey, types.NewAccount().PublicKey
+
+	journal, err := OpenDeployJournal(filepath.Join(t.TempDir(), "journal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := &SolanaGatewayParams{Endpoint: server.URL, Journal: journal}
+
+	subID := models.UUID{1, 2, 3}
+	if err := journal.Record(JournalEntry{Step: StepSubscribe, Address: nebulaDataAccount.ToBase58(), Data: hex.EncodeToString(subID[:])}); err != nil {
+		t.Fatal(err)
+	}
+
+	var subscribed []models.UUID
+	subscribe := func(id [16]byte) (string, error) {
+		subscribed = append(subscribed, id)
+		return "signature", nil
+	}
+
+	// the id is queued only, the subscription itself is not registered
+	server.SetAccount(nebulaDataAccount, solanatest.Account{Lamports: 1, Owner: nebulaProgramID, Data: encodeNebulaState([]models.UUID{subID}, nil)})
+	if _, err := params.ensureSubscribed(ctx, nebulaDataAccount, subscribe); err != nil {
+		t.Fatal(err)
+	}
+	if len(subscribed) != 1 || subscribed[0] != subID {
+		t.Fatalf("journaled subscription is not sent: %v", subscribed)
+	}
+
+	// the registered subscription is not sent again
+	if err := journal.Record(JournalEntry{Step: StepSubscribe, Address: nebulaDataAccount.ToBase58(), Data: hex.EncodeToString(subID[:])}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetAccount(nebulaDataAccount, solanatest.Account{Lamports: 1, Owner: nebulaProgramID, Data: encodeNebulaState(nil, []models.UUID{subID})})
+	if _, err := params.ensureSubscribed(ctx, nebulaDataAccount, subscribe); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := journal.Entry(StepSubscribe); len(subscribed) != 1 || !entry.Done {
+		t.Fatalf("registered subscription is sent again: %v, %+v", subscribed, entry)
+	}
+}
+
+func TestNilDeployJournal(t *testing.T) {
+	var journal *DeployJournal
+
+	if err := journal.Scope("origin").Record(JournalEntry{Step: StepPortInit, Done: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := journal.Entry(StepPortInit); ok {
+		t.Fatal("nil journal must not keep entries")
+	}
+}
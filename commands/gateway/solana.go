@@ -1,274 +1,559 @@
 package gateway
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/Gravity-Tech/solanoid/commands"
 	"github.com/Gravity-Tech/solanoid/commands/contract"
 	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/tokens"
+	"github.com/Gravity-Tech/solanoid/models"
 	"github.com/Gravity-Tech/solanoid/models/nebula"
+	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/tokenprog"
+	"github.com/portto/solana-go-sdk/types"
 )
 
-func WaitTransactionConfirmations() {
-	time.Sleep(time.Second * 30)
-}
+const ConfirmationTimeout = time.Minute * 2
 
-type GatewayDeployResult struct{}
+func confirmTransactions(ctx context.Context, endpoint string, signatures ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, ConfirmationTimeout)
+	defer cancel()
 
-func DeploySolanaGateway_LUPort(t *testing.T, consuls []string, originTokenMint common.PublicKey) *GatewayDeployResult {
-	var err error
+	for _, signature := range signatures {
+		_, err := executor.AwaitConfirmation(ctx, endpoint, signature, solclient.CommitmentConfirmed, 0)
+		if err != nil {
+			return err
+		}
+	}
 
-	fmt.Printf("Token being wrapped: %v \n", originTokenMint.ToBase58())
-	deployer, err := commands.ReadOperatingAddress(t, "../../private-keys/mainnet/deployer.json")
-	commands.ValidateError(t, err)
+	return nil
+}
 
-	balanceBeforeDeploy, err := commands.ReadAccountBalance(deployer.PublicKey.ToBase58())
-	commands.ValidateError(t, err)
+// SolanaGatewayParams describes the Nebula and Port pair to set up on top of the deployed Gravity
+type SolanaGatewayParams struct {
+	Deployer types.Account
+	Endpoint string
 
-	fmt.Printf("balanceBeforeDeploy: %v SOL;  \n", balanceBeforeDeploy)
+	NebulaProgramID    common.PublicKey
+	PortProgramID      common.PublicKey
+	GravityDataAccount common.PublicKey
 
-	nebulaProgram, err := commands.NewOperatingBinaryAddressFromString(
-		contract.NebulaBinary,
-		[]byte(executor.CommonGravityBumpSeeds),
-	)
-	commands.ValidateError(t, err)
+	Consuls []common.PublicKey
+	Bft     uint8
 
-	luportProgram, err := commands.NewOperatingBinaryAddressFromString(
-		contract.LUPortBinary,
-		[]byte(executor.CommonGravityBumpSeeds),
-	)
+	// TokenMint is locked by LU Port and minted by IB Port, IB Port creates a new one if omitted
+	TokenMint *common.PublicKey
 
-	commands.ValidateError(t, err)
-	fmt.Printf("LU Port Program ID: %v \n", luportProgram.PublicKey.ToBase58())
-	fmt.Printf("LU Port PDA: %v \n", luportProgram.PDA.ToBase58())
+	// Journal makes the deploy resumable, steps recorded as done and confirmed on chain are skipped
+	Journal *DeployJournal
+}
 
-	gravityDataAccount := contract.GravityDataAccount
+type GatewayDeployResult struct {
+	NebulaDataAccount     types.Account
+	NebulaMultisigAccount types.Account
+	PortDataAccount       types.Account
+	PortPDA               common.PublicKey
+	TokenMint             common.PublicKey
+	SubscriptionID        [16]byte
+}
 
-	var consulsAsByteList []byte
-	for _, consul := range consuls {
-		consulsPubKey := common.PublicKeyFromString(consul)
+type portInitializer func(nebulaProgramID, tokenMint common.PublicKey, bft uint8, oracles []byte) interface{}
 
-		consulsAsByteList = append(consulsAsByteList, consulsPubKey.Bytes()...)
+func (params *SolanaGatewayParams) consulsAsByteList() []byte {
+	var consulsAsByteList []byte
+	for _, consul := range params.Consuls {
+		consulsAsByteList = append(consulsAsByteList, consul.Bytes()...)
 	}
+	return consulsAsByteList
+}
 
-	const BFT = 3
+// fingerprint identifies the deploy inputs, the journal is resumed for the same ones only
+func (params *SolanaGatewayParams) fingerprint() string {
+	inputs := []string{
+		params.Endpoint,
+		params.NebulaProgramID.ToBase58(),
+		params.PortProgramID.ToBase58(),
+		params.GravityDataAccount.ToBase58(),
+		strconv.Itoa(int(params.Bft)),
+	}
+	if params.TokenMint != nil {
+		inputs = append(inputs, params.TokenMint.ToBase58())
+	} else {
+		inputs = append(inputs, "")
+	}
+	for _, consul := range params.Consuls {
+		inputs = append(inputs, consul.ToBase58())
+	}
 
-	RPCEndpoint, _ := commands.InferSystemDefinedRPC()
+	hash := sha256.New()
+	for _, input := range inputs {
+		hash.Write([]byte(input))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
 
-	WaitTransactionConfirmations()
+func isZeroed(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
 
-	nebulaDataAccount, err := commands.GenerateNewAccount(deployer.PrivateKey, commands.NebulaAllocation, nebulaProgram.PublicKey.ToBase58(), RPCEndpoint)
-	commands.ValidateError(t, err)
-	fmt.Printf("Nebula Data Account: %v \n", nebulaDataAccount.Account.PublicKey.ToBase58())
+// ensureDataAccount creates the program data account unless the journaled one already exists on chain.
+// Empty signature is returned for the reused account.
+func (params *SolanaGatewayParams) ensureDataAccount(ctx context.Context, step DeployStep, space uint64, programID common.PublicKey) (types.Account, string, error) {
+	var account types.Account
+
+	entry, ok := params.Journal.Entry(step)
+	if ok {
+		var err error
+		account, err = entry.Account()
+		if err != nil {
+			return account, "", err
+		}
+
+		data, owner, err := commands.ReadAccountData(ctx, params.Endpoint, account.PublicKey)
+		if err != nil {
+			return account, "", err
+		}
+		if owner == programID.ToBase58() && uint64(len(data)) == space {
+			fmt.Printf("%v: reusing %v \n", step, account.PublicKey.ToBase58())
+			return account, "", nil
+		}
+		if owner != "" {
+			return account, "", fmt.Errorf("%v: account %v is owned by %v", step, account.PublicKey.ToBase58(), owner)
+		}
+	} else {
+		account = types.NewAccount()
+	}
 
-	nebulaMultisigDataAccount, err := commands.GenerateNewAccount(deployer.PrivateKey, commands.MultisigAllocation, nebulaProgram.PublicKey.ToBase58(), RPCEndpoint)
-	commands.ValidateError(t, err)
-	fmt.Printf("Nebula Multisig Account: %v \n", nebulaMultisigDataAccount.Account.PublicKey.ToBase58())
+	err := params.Journal.Record(JournalEntry{
+		Step:       step,
+		Address:    account.PublicKey.ToBase58(),
+		PrivateKey: base58.Encode(account.PrivateKey),
+	})
+	if err != nil {
+		return account, "", err
+	}
 
-	luportDataAccount, err := commands.GenerateNewAccount(deployer.PrivateKey, commands.LUPortAllocation, luportProgram.PublicKey.ToBase58(), RPCEndpoint)
-	commands.ValidateError(t, err)
-	fmt.Printf("LU Port Data Account: %v \n", luportDataAccount.Account.PublicKey.ToBase58())
+	response, err := commands.GenerateNewAccountWithSeed(base58.Encode(params.Deployer.PrivateKey), account, space, programID.ToBase58(), params.Endpoint)
+	if err != nil {
+		return account, "", err
+	}
 
-	WaitTransactionConfirmations()
+	return account, response.TxSignature, nil
+}
 
-	nebulaBuilder := executor.NebulaInstructionBuilder{}
-	nebulaExecutor, err := commands.InitGenericExecutor(
-		deployer.PrivateKey,
-		nebulaProgram.PublicKey.ToBase58(),
-		nebulaDataAccount.Account.PublicKey.ToBase58(),
-		nebulaMultisigDataAccount.Account.PublicKey.ToBase58(),
-		RPCEndpoint,
-		common.PublicKeyFromString(gravityDataAccount),
-	)
-	commands.ValidateError(t, err)
+func (params *SolanaGatewayParams) completeDataAccount(step DeployStep, account types.Account, txSig string) error {
+	entry, _ := params.Journal.Entry(step)
+	if entry.Done {
+		return nil
+	}
 
-	luportExecutor, err := commands.InitGenericExecutor(
-		deployer.PrivateKey,
-		luportProgram.PublicKey.ToBase58(),
-		luportDataAccount.Account.PublicKey.ToBase58(),
-		"",
-		RPCEndpoint,
-		common.PublicKeyFromString(""),
-	)
-	commands.ValidateError(t, err)
+	return params.Journal.Record(JournalEntry{
+		Step:       step,
+		Done:       true,
+		Signature:  txSig,
+		Address:    account.PublicKey.ToBase58(),
+		PrivateKey: base58.Encode(account.PrivateKey),
+	})
+}
 
-	WaitTransactionConfirmations()
+// ensureInitialized invokes init unless the data account is already written to
+func (params *SolanaGatewayParams) ensureInitialized(ctx context.Context, step DeployStep, dataAccount common.PublicKey, init func() (string, error)) error {
+	data, _, err := commands.ReadAccountData(ctx, params.Endpoint, dataAccount)
+	if err != nil {
+		return err
+	}
 
-	nebulaInitResponse, err := nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Init(BFT, nebula.Bytes, common.PublicKeyFromString(gravityDataAccount), consulsAsByteList),
-	)
-	commands.ValidateError(t, err)
-	fmt.Printf("Nebula Init: %v \n", nebulaInitResponse.TxSignature)
+	if !isZeroed(data) {
+		fmt.Printf("%v: %v is already initialized \n", step, dataAccount.ToBase58())
 
-	WaitTransactionConfirmations()
+		if entry, ok := params.Journal.Entry(step); ok && entry.Done {
+			return nil
+		}
+		return params.Journal.Record(JournalEntry{Step: step, Done: true, Address: dataAccount.ToBase58()})
+	}
 
-	luportInitResult, err := luportExecutor.BuildAndInvoke(
-		executor.LUPortIXBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, originTokenMint, BFT, consulsAsByteList),
-	)
+	txSig, err := init()
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("LU Port Init: %v \n", luportInitResult.TxSignature)
-	commands.ValidateError(t, err)
+	return params.Journal.Record(JournalEntry{Step: step, Done: true, Signature: txSig, Address: dataAccount.ToBase58()})
+}
 
-	WaitTransactionConfirmations()
+// ensureSubscribed keeps the subscription id in the journal, Nebula state holding it means subscribe went through
+func (params *SolanaGatewayParams) ensureSubscribed(ctx context.Context, nebulaDataAccount common.PublicKey, subscribe func(subID [16]byte) (string, error)) ([16]byte, error) {
+	var subID [16]byte
 
-	fmt.Println("LU Port Program is being subscribed to Nebula")
+	entry, ok := params.Journal.Entry(StepSubscribe)
+	if ok {
+		decoded, err := hex.DecodeString(entry.Data)
+		if err != nil || len(decoded) != len(subID) {
+			return subID, fmt.Errorf("%v: invalid subscription id %v", StepSubscribe, entry.Data)
+		}
+		copy(subID[:], decoded)
+
+		nebulaState, err := commands.ReadNebulaContract(ctx, params.Endpoint, nebulaDataAccount)
+		if err != nil {
+			return subID, err
+		}
+		if _, ok := nebulaState.Subscription(models.UUID(subID)); ok {
+			fmt.Printf("%v: subscription %v is already registered \n", StepSubscribe, entry.Data)
+			if entry.Done {
+				return subID, nil
+			}
+			entry.Done = true
+			return subID, params.Journal.Record(entry)
+		}
+	} else {
+		rand.Read(subID[:])
+	}
 
-	var subID [16]byte
-	rand.Read(subID[:])
+	entry = JournalEntry{Step: StepSubscribe, Address: nebulaDataAccount.ToBase58(), Data: hex.EncodeToString(subID[:])}
+	if err := params.Journal.Record(entry); err != nil {
+		return subID, err
+	}
 
-	fmt.Printf("subID: %v \n", subID)
+	txSig, err := subscribe(subID)
+	if err != nil {
+		return subID, err
+	}
 
-	// (4)
-	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Subscribe(luportProgram.PDA, 1, 1, subID),
-	)
-	commands.ValidateError(t, err)
+	entry.Done = true
+	entry.Signature = txSig
+	return subID, params.Journal.Record(entry)
+}
 
-	fmt.Printf("Nebula Subscribe: %v \n", nebulaSubscribePortResponse.TxSignature)
-	// fmt.Println("Now checking for valid double spend prevent")
+func deploySolanaGateway(ctx context.Context, params *SolanaGatewayParams, portAllocation uint64, tokenMint common.PublicKey, initPort portInitializer) (*GatewayDeployResult, error) {
+	deployerPrivateKey := base58.Encode(params.Deployer.PrivateKey)
+	consulsAsByteList := params.consulsAsByteList()
 
-	WaitTransactionConfirmations()
+	portProgram, err := commands.NewOperatingBinaryAddressFromString(
+		params.PortProgramID.ToBase58(),
+		[]byte(executor.CommonGravityBumpSeeds),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	balanceAfterDeploy, err := commands.ReadAccountBalance(deployer.PublicKey.ToBase58())
-	commands.ValidateError(t, err)
+	fmt.Printf("Port Program ID: %v \n", portProgram.PublicKey.ToBase58())
+	fmt.Printf("Port PDA: %v \n", portProgram.PDA.ToBase58())
 
-	fmt.Printf("balanceBeforeDeploy: %v SOL; \n", balanceBeforeDeploy)
-	fmt.Printf("balanceAfterDeploy: %v SOL; \n", balanceAfterDeploy)
-	fmt.Printf("balance diff: %v SOL; \n", balanceBeforeDeploy-balanceAfterDeploy)
+	nebulaDataAccount, nebulaDataAccountTx, err := params.ensureDataAccount(ctx, StepNebulaDataAccount, commands.NebulaAllocation, params.NebulaProgramID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Nebula Data Account: %v \n", nebulaDataAccount.PublicKey.ToBase58())
 
-	return &GatewayDeployResult{}
-}
+	nebulaMultisigDataAccount, nebulaMultisigDataAccountTx, err := params.ensureDataAccount(ctx, StepNebulaMultisigAccount, commands.MultisigAllocation, params.NebulaProgramID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Nebula Multisig Account: %v \n", nebulaMultisigDataAccount.PublicKey.ToBase58())
 
-func DeploySolanaGateway_IBPort(t *testing.T, consuls []string) {
-	var err error
+	portDataAccount, portDataAccountTx, err := params.ensureDataAccount(ctx, StepPortDataAccount, portAllocation, params.PortProgramID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Port Data Account: %v \n", portDataAccount.PublicKey.ToBase58())
 
-	deployer, err := commands.ReadOperatingAddress(t, "../../private-keys/mainnet/deployer.json")
-	commands.ValidateError(t, err)
+	var pendingSignatures []string
+	for _, txSig := range []string{nebulaDataAccountTx, nebulaMultisigDataAccountTx, portDataAccountTx} {
+		if txSig != "" {
+			pendingSignatures = append(pendingSignatures, txSig)
+		}
+	}
 
-	balanceBeforeDeploy, err := commands.ReadAccountBalance(deployer.PublicKey.ToBase58())
-	commands.ValidateError(t, err)
+	err = confirmTransactions(ctx, params.Endpoint, pendingSignatures...)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Printf("balanceBeforeDeploy: %v SOL;  \n", balanceBeforeDeploy)
+	if err := params.completeDataAccount(StepNebulaDataAccount, nebulaDataAccount, nebulaDataAccountTx); err != nil {
+		return nil, err
+	}
+	if err := params.completeDataAccount(StepNebulaMultisigAccount, nebulaMultisigDataAccount, nebulaMultisigDataAccountTx); err != nil {
+		return nil, err
+	}
+	if err := params.completeDataAccount(StepPortDataAccount, portDataAccount, portDataAccountTx); err != nil {
+		return nil, err
+	}
 
-	nebulaProgram, err := commands.NewOperatingBinaryAddressFromString(
-		contract.NebulaBinary,
-		[]byte(executor.CommonGravityBumpSeeds),
+	nebulaExecutor, err := commands.InitGenericExecutor(
+		deployerPrivateKey,
+		params.NebulaProgramID.ToBase58(),
+		nebulaDataAccount.PublicKey.ToBase58(),
+		nebulaMultisigDataAccount.PublicKey.ToBase58(),
+		params.Endpoint,
+		params.GravityDataAccount,
 	)
-	commands.ValidateError(t, err)
+	if err != nil {
+		return nil, err
+	}
 
-	ibportProgram, err := commands.NewOperatingBinaryAddressFromString(
-		contract.IBPortBinary,
-		[]byte(executor.CommonGravityBumpSeeds),
+	portExecutor, err := commands.InitGenericExecutor(
+		deployerPrivateKey,
+		params.PortProgramID.ToBase58(),
+		portDataAccount.PublicKey.ToBase58(),
+		"",
+		params.Endpoint,
+		common.PublicKeyFromString(""),
 	)
-	commands.ValidateError(t, err)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Printf("IB Port Program ID: %v \n", ibportProgram.PublicKey.ToBase58())
-	fmt.Printf("IB Port PDA: %v \n", ibportProgram.PDA.ToBase58())
+	err = params.ensureInitialized(ctx, StepNebulaInit, nebulaDataAccount.PublicKey, func() (string, error) {
+		nebulaInitResponse, err := nebulaExecutor.BuildAndConfirm(
+			ctx,
+			executor.NebulaIXBuilder.Init(params.Bft, nebula.Bytes, params.GravityDataAccount, consulsAsByteList),
+			solclient.CommitmentConfirmed,
+		)
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Nebula Init: %v \n", nebulaInitResponse.TxSignature)
+		return nebulaInitResponse.TxSignature, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// gravityDataAccount := "ErLEJcqRKQdhLpLHLn9zUzx1mu7VfrZbgwsfAL4BG4uQ"
-	gravityDataAccount := contract.GravityDataAccount
+	err = params.ensureInitialized(ctx, StepPortInit, portDataAccount.PublicKey, func() (string, error) {
+		portInitResult, err := portExecutor.BuildAndConfirm(
+			ctx,
+			initPort(params.NebulaProgramID, tokenMint, params.Bft, consulsAsByteList),
+			solclient.CommitmentConfirmed,
+		)
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Port Init: %v \n", portInitResult.TxSignature)
+		return portInitResult.TxSignature, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	var consulsAsByteList []byte
-	for _, consul := range consuls {
-		consulsPubKey := common.PublicKeyFromString(consul)
+	fmt.Println("Port Program is being subscribed to Nebula")
+
+	subID, err := params.ensureSubscribed(ctx, nebulaDataAccount.PublicKey, func(subID [16]byte) (string, error) {
+		fmt.Printf("subID: %v \n", subID)
+
+		nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndConfirm(
+			ctx,
+			executor.NebulaIXBuilder.Subscribe(portProgram.PDA, 1, 1, subID),
+			solclient.CommitmentConfirmed,
+		)
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Nebula Subscribe: %v \n", nebulaSubscribePortResponse.TxSignature)
+		return nebulaSubscribePortResponse.TxSignature, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		consulsAsByteList = append(consulsAsByteList, consulsPubKey.Bytes()...)
+	return &GatewayDeployResult{
+		NebulaDataAccount:     nebulaDataAccount,
+		NebulaMultisigAccount: nebulaMultisigDataAccount,
+		PortDataAccount:       portDataAccount,
+		PortPDA:               portProgram.PDA,
+		TokenMint:             tokenMint,
+		SubscriptionID:        subID,
+	}, nil
+}
+
+// ensureMint creates the wrapped token mint, its keypair is journaled first, so the mint created
+// right before a crash is picked up on resume instead of being left orphaned
+func (params *SolanaGatewayParams) ensureMint(ctx context.Context, operator *tokens.TokenOperator) (common.PublicKey, error) {
+	var mint types.Account
+
+	entry, ok := params.Journal.Entry(StepCreateMint)
+	switch {
+	case ok && entry.Done:
+		fmt.Printf("%v: reusing %v \n", StepCreateMint, entry.Address)
+		return common.PublicKeyFromString(entry.Address), nil
+	case ok:
+		var err error
+		mint, err = entry.Account()
+		if err != nil {
+			return mint.PublicKey, err
+		}
+
+		if _, err := operator.GetMint(ctx, mint.PublicKey); err == nil {
+			fmt.Printf("%v: reusing %v \n", StepCreateMint, entry.Address)
+			entry.Done = true
+			return mint.PublicKey, params.Journal.Record(entry)
+		}
+	default:
+		mint = types.NewAccount()
 	}
 
-	const BFT = 3
+	entry = JournalEntry{Step: StepCreateMint, Address: mint.PublicKey.ToBase58(), PrivateKey: base58.Encode(mint.PrivateKey)}
+	if err := params.Journal.Record(entry); err != nil {
+		return mint.PublicKey, err
+	}
 
-	RPCEndpoint, _ := commands.InferSystemDefinedRPC()
+	createResult, err := operator.CreateMintAccount(ctx, mint, params.Deployer.PublicKey, nil, executor.DefaultDecimals)
+	if err != nil {
+		return mint.PublicKey, err
+	}
 
-	tokenDeployResult, err := commands.CreateToken(deployer.PKPath)
-	commands.ValidateError(t, err)
+	entry.Done = true
+	entry.Signature = createResult.Signature
+	return mint.PublicKey, params.Journal.Record(entry)
+}
 
-	tokenProgramAddress := tokenDeployResult.Token.ToBase58()
-	fmt.Printf("token address: %v \n", tokenProgramAddress)
+// DeployLUPortGateway sets up LU Port locking the existing token
+func DeployLUPortGateway(ctx context.Context, params *SolanaGatewayParams) (*GatewayDeployResult, error) {
+	if params.TokenMint == nil {
+		return nil, fmt.Errorf("token mint is required for lu port")
+	}
+	if err := params.Journal.Bind(params.fingerprint()); err != nil {
+		return nil, err
+	}
 
-	WaitTransactionConfirmations()
+	fmt.Printf("Token being wrapped: %v \n", params.TokenMint.ToBase58())
 
-	nebulaDataAccount, err := commands.GenerateNewAccount(deployer.PrivateKey, commands.NebulaAllocation, nebulaProgram.PublicKey.ToBase58(), RPCEndpoint)
-	commands.ValidateError(t, err)
-	fmt.Printf("Nebula Data Account: %v \n", nebulaDataAccount.Account.PublicKey.ToBase58())
+	return deploySolanaGateway(ctx, params, commands.LUPortAllocation, *params.TokenMint, func(nebulaProgramID, tokenMint common.PublicKey, bft uint8, oracles []byte) interface{} {
+		return executor.LUPortIXBuilder.InitWithOracles(nebulaProgramID, common.TokenProgramID, tokenMint, bft, oracles)
+	})
+}
 
-	nebulaMultisigDataAccount, err := commands.GenerateNewAccount(deployer.PrivateKey, commands.MultisigAllocation, nebulaProgram.PublicKey.ToBase58(), RPCEndpoint)
-	commands.ValidateError(t, err)
-	fmt.Printf("Nebula Multisig Account: %v \n", nebulaMultisigDataAccount.Account.PublicKey.ToBase58())
+// DeployIBPortGateway sets up IB Port and hands it the mint authority of the wrapped token
+func DeployIBPortGateway(ctx context.Context, params *SolanaGatewayParams) (*GatewayDeployResult, error) {
+	if err := params.Journal.Bind(params.fingerprint()); err != nil {
+		return nil, err
+	}
 
-	ibportDataAccount, err := commands.GenerateNewAccount(deployer.PrivateKey, commands.IBPortAllocation, ibportProgram.PublicKey.ToBase58(), RPCEndpoint)
-	commands.ValidateError(t, err)
-	fmt.Printf("IB Port Data Account: %v \n", ibportDataAccount.Account.PublicKey.ToBase58())
+	ibportProgram, err := commands.NewOperatingBinaryAddressFromString(
+		params.PortProgramID.ToBase58(),
+		[]byte(executor.CommonGravityBumpSeeds),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	WaitTransactionConfirmations()
+	operator, err := tokens.NewTokenOperator(params.Deployer, params.Endpoint)
+	if err != nil {
+		return nil, err
+	}
 
-	err = commands.AuthorizeToken(t, deployer.PKPath, tokenProgramAddress, "mint", ibportProgram.PDA.ToBase58())
-	commands.ValidateError(t, err)
-	t.Log("Authorizing IB Port to allow minting")
+	var tokenMint common.PublicKey
+	if params.TokenMint != nil {
+		tokenMint = *params.TokenMint
+	} else {
+		tokenMint, err = params.ensureMint(ctx, operator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fmt.Printf("token address: %v \n", tokenMint.ToBase58())
 
-	nebulaExecutor, err := commands.InitGenericExecutor(
-		deployer.PrivateKey,
-		nebulaProgram.PublicKey.ToBase58(),
-		nebulaDataAccount.Account.PublicKey.ToBase58(),
-		nebulaMultisigDataAccount.Account.PublicKey.ToBase58(),
-		RPCEndpoint,
-		common.PublicKeyFromString(gravityDataAccount),
-	)
+	mint, err := operator.GetMint(ctx, tokenMint)
+	if err != nil {
+		return nil, err
+	}
+	if mint.MintAuthority == nil || *mint.MintAuthority != ibportProgram.PDA {
+		fmt.Println("Authorizing IB Port to allow minting")
+
+		result, err := operator.SetAuthority(ctx, tokenMint, tokenprog.AuthorityTypeMintTokens, params.Deployer, &ibportProgram.PDA)
+		if err != nil {
+			return nil, err
+		}
+
+		err = params.Journal.Record(JournalEntry{Step: StepAuthorizeMint, Done: true, Signature: result.Signature, Address: tokenMint.ToBase58()})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return deploySolanaGateway(ctx, params, commands.IBPortAllocation, tokenMint, func(nebulaProgramID, tokenMint common.PublicKey, bft uint8, oracles []byte) interface{} {
+		return executor.IBPortIXBuilder.InitWithOracles(nebulaProgramID, common.TokenProgramID, tokenMint, bft, oracles)
+	})
+}
+
+// testGatewayParams journals the deploy in the test temp dir, so deploys of the test run never resume each other
+func testGatewayParams(t *testing.T, consuls []string) *SolanaGatewayParams {
+	deployer, err := commands.ReadOperatingAddress(t, "../../private-keys/mainnet/deployer.json")
 	commands.ValidateError(t, err)
 
-	ibportBuilder := executor.IBPortInstructionBuilder{}
-	ibportExecutor, err := commands.InitGenericExecutor(
-		deployer.PrivateKey,
-		ibportProgram.PublicKey.ToBase58(),
-		ibportDataAccount.Account.PublicKey.ToBase58(),
-		"",
-		RPCEndpoint,
-		common.PublicKeyFromString(""),
-	)
+	journal, err := OpenDeployJournal(filepath.Join(t.TempDir(), "journal.json"))
 	commands.ValidateError(t, err)
 
-	WaitTransactionConfirmations()
+	RPCEndpoint, _ := commands.InferSystemDefinedRPC()
 
-	nebulaInitResponse, err := nebulaExecutor.BuildAndInvoke(
-		executor.NebulaIXBuilder.Init(BFT, nebula.Bytes, common.PublicKeyFromString(gravityDataAccount), consulsAsByteList),
-	)
+	params := &SolanaGatewayParams{
+		Deployer:           deployer.Account,
+		Endpoint:           RPCEndpoint,
+		NebulaProgramID:    common.PublicKeyFromString(contract.NebulaBinary),
+		GravityDataAccount: common.PublicKeyFromString(contract.GravityDataAccount),
+		Bft:                3,
+		Journal:            journal,
+	}
+	for _, consul := range consuls {
+		params.Consuls = append(params.Consuls, common.PublicKeyFromString(consul))
+	}
+
+	return params
+}
+
+func printDeployBalanceDiff(t *testing.T, deployer common.PublicKey, balanceBeforeDeploy float64) {
+	balanceAfterDeploy, err := commands.ReadAccountBalance(deployer.ToBase58())
 	commands.ValidateError(t, err)
-	fmt.Printf("Nebula Init: %v \n", nebulaInitResponse.TxSignature)
 
-	WaitTransactionConfirmations()
+	fmt.Printf("balanceBeforeDeploy: %v SOL; \n", balanceBeforeDeploy)
+	fmt.Printf("balanceAfterDeploy: %v SOL; \n", balanceAfterDeploy)
+	fmt.Printf("balance diff: %v SOL; \n", balanceBeforeDeploy-balanceAfterDeploy)
+}
 
-	ibportInitResult, err := ibportExecutor.BuildAndInvoke(
-		ibportBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsAsByteList),
-	)
+func DeploySolanaGateway_LUPort(t *testing.T, consuls []string, originTokenMint common.PublicKey) *GatewayDeployResult {
+	params := testGatewayParams(t, consuls)
+	params.PortProgramID = common.PublicKeyFromString(contract.LUPortBinary)
+	params.TokenMint = &originTokenMint
 
-	fmt.Printf("IB Port Init: %v \n", ibportInitResult.TxSignature)
+	balanceBeforeDeploy, err := commands.ReadAccountBalance(params.Deployer.PublicKey.ToBase58())
 	commands.ValidateError(t, err)
 
-	WaitTransactionConfirmations()
+	fmt.Printf("balanceBeforeDeploy: %v SOL;  \n", balanceBeforeDeploy)
 
-	fmt.Println("IB Port Program is being subscribed to Nebula")
+	result, err := DeployLUPortGateway(context.Background(), params)
+	commands.ValidateError(t, err)
 
-	var subID [16]byte
-	rand.Read(subID[:])
+	printDeployBalanceDiff(t, params.Deployer.PublicKey, balanceBeforeDeploy)
 
-	fmt.Printf("subID: %v \n", subID)
+	return result
+}
 
-	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndInvoke(
-		executor.NebulaIXBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID),
-	)
-	commands.ValidateError(t, err)
+func DeploySolanaGateway_IBPort(t *testing.T, consuls []string) {
+	params := testGatewayParams(t, consuls)
+	params.PortProgramID = common.PublicKeyFromString(contract.IBPortBinary)
 
-	fmt.Printf("Nebula Subscribe: %v \n", nebulaSubscribePortResponse.TxSignature)
+	balanceBeforeDeploy, err := commands.ReadAccountBalance(params.Deployer.PublicKey.ToBase58())
+	commands.ValidateError(t, err)
 
-	WaitTransactionConfirmations()
+	fmt.Printf("balanceBeforeDeploy: %v SOL;  \n", balanceBeforeDeploy)
 
-	balanceAfterDeploy, err := commands.ReadAccountBalance(deployer.PublicKey.ToBase58())
+	_, err = DeployIBPortGateway(context.Background(), params)
 	commands.ValidateError(t, err)
 
-	fmt.Printf("balanceBeforeDeploy: %v SOL; \n", balanceBeforeDeploy)
-	fmt.Printf("balanceAfterDeploy: %v SOL; \n", balanceAfterDeploy)
-	fmt.Printf("balance diff: %v SOL; \n", balanceBeforeDeploy-balanceAfterDeploy)
+	printDeployBalanceDiff(t, params.Deployer.PublicKey, balanceBeforeDeploy)
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	"github.com/Gravity-Tech/solanoid/commands/executor"
 	"github.com/Gravity-Tech/solanoid/models/nebula"
 
+	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 )
 
@@ -62,6 +64,7 @@ func TestRunSolanaGatewayDeployment(t *testing.T) {
 
 	const BFT = 3
 
+	ctx := context.Background()
 	RPCEndpoint, _ := InferSystemDefinedRPC()
 
 	tokenDeployResult, err := CreateToken(deployer.PKPath)
@@ -73,8 +76,6 @@ func TestRunSolanaGatewayDeployment(t *testing.T) {
 	// SuSy Wrapped GTON Token Address
 	// tokenProgramAddress := "FP5MgcQaD3ppWDqfjXouftsWQBSPW2suRzduLAFs712S"
 
-	waitTransactionConfirmations()
-
 	nebulaDataAccount, err := GenerateNewAccount(deployer.PrivateKey, NebulaAllocation, nebulaProgram.PublicKey.ToBase58(), RPCEndpoint)
 	ValidateError(t, err)
 	fmt.Printf("Nebula Data Account: %v \n", nebulaDataAccount.Account.PublicKey.ToBase58())
@@ -87,18 +88,14 @@ func TestRunSolanaGatewayDeployment(t *testing.T) {
 	ValidateError(t, err)
 	fmt.Printf("IB Port Data Account: %v \n", ibportDataAccount.Account.PublicKey.ToBase58())
 
-	waitTransactionConfirmations()
+	awaitTransactions(t, RPCEndpoint, nebulaDataAccount.TxSignature, nebulaMultisigAccount.TxSignature, ibportDataAccount.TxSignature)
 
 	_, err = DeploySolanaProgram(t, "ibport", ibportProgram.PKPath, deployer.PKPath, "../binaries/ibport.so")
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
 	_, err = DeploySolanaProgram(t, "nebula", nebulaProgram.PKPath, deployer.PKPath, "../binaries/nebula.so")
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
 	err = AuthorizeToken(t, deployer.PKPath, tokenProgramAddress, "mint", ibportProgram.PDA.ToBase58())
 	ValidateError(t, err)
 	t.Log("Authorizing IB Port to allow minting")
@@ -125,25 +122,19 @@ func TestRunSolanaGatewayDeployment(t *testing.T) {
 	)
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
-	nebulaInitResponse, err := nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Init(BFT, nebula.Bytes, common.PublicKeyFromString(gravityDataAccount), consulsAsByteList),
+	nebulaInitResponse, err := nebulaExecutor.BuildAndConfirm(
+		ctx, nebulaBuilder.Init(BFT, nebula.Bytes, common.PublicKeyFromString(gravityDataAccount), consulsAsByteList), solclient.CommitmentConfirmed,
 	)
 	ValidateError(t, err)
 	fmt.Printf("Nebula Init: %v \n", nebulaInitResponse.TxSignature)
 
-	waitTransactionConfirmations()
-
-	ibportInitResult, err := ibportExecutor.BuildAndInvoke(
-		ibportBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsAsByteList),
+	ibportInitResult, err := ibportExecutor.BuildAndConfirm(
+		ctx, ibportBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsAsByteList), solclient.CommitmentConfirmed,
 	)
 
 	fmt.Printf("IB Port Init: %v \n", ibportInitResult.TxSignature)
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
 	fmt.Println("IB Port Program is being subscribed to Nebula")
 
 	var subID [16]byte
@@ -152,16 +143,14 @@ func TestRunSolanaGatewayDeployment(t *testing.T) {
 	fmt.Printf("subID: %v \n", subID)
 
 	// (4)
-	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID),
+	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndConfirm(
+		ctx, nebulaBuilder.Subscribe(ibportProgram.PDA, 1, 1, subID), solclient.CommitmentConfirmed,
 	)
 	ValidateError(t, err)
 
 	fmt.Printf("Nebula Subscribe: %v \n", nebulaSubscribePortResponse.TxSignature)
 	// fmt.Println("Now checking for valid double spend prevent")
 
-	waitTransactionConfirmations()
-
 	balanceAfterDeploy, err := ReadAccountBalance(deployer.PublicKey.ToBase58())
 	ValidateError(t, err)
 
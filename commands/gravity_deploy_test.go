@@ -1,15 +1,20 @@
 package commands
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"time"
 
 	"testing"
 
 	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/models/gravity"
+	"github.com/Gravity-Tech/solanoid/models/port/ibport"
 
 	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/types"
 
@@ -94,6 +99,17 @@ func TestGravityContract(t *testing.T) {
 
 	t.Logf("Gravity Init: %v \n", gravityInitResponse.TxSignature)
 
+	executor.RegisterProgramErrors(common.PublicKeyFromString(gravityProgramID), gravity.Errors)
+	_, errFailing = gravityExecutor.BuildAndInvoke(executor.InitGravityContractInstruction{
+		Instruction: 0,
+		Bft:         bft,
+		InitRound:   1,
+		Consuls:     consuls[:],
+	})
+	if !errors.Is(errFailing, gravity.ErrAlreadyInitialized) {
+		t.Fatalf("second init must have been rejected as already initialized, got: %v", errFailing)
+	}
+
 	time.Sleep(time.Second * 20)
 
 	var signers []executor.GravityBftSigner
@@ -151,6 +167,17 @@ func waitTransactionConfirmations() {
 	// time.Sleep(time.Second * 45)
 }
 
+// awaitTransactions blocks until the transactions sent with no confirmation, e.g. by GenerateNewAccount, are confirmed
+func awaitTransactions(t *testing.T, endpoint string, signatures ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	defer cancel()
+
+	for _, signature := range signatures {
+		_, err := executor.AwaitConfirmation(ctx, endpoint, signature, solclient.CommitmentConfirmed, 0)
+		ValidateError(t, err)
+	}
+}
+
 func WrappedFaucet(t *testing.T, callerPath, receiverAddress string, amount uint64) {
 	var err error
 	t.Logf("Faucet %v SOL to %v \n", receiverAddress, fmt.Sprint(amount))
@@ -343,6 +370,7 @@ func TestIBPortAttachValue(t *testing.T) {
 		t.FailNow()
 	}
 	ibportAddress := ibportAddressPubkey.ToBase58()
+	executor.RegisterProgramErrors(ibportAddressPubkey, ibport.Errors)
 
 	fmt.Printf("token  program address: %s\n", tokenProgramAddress)
 
@@ -456,9 +484,8 @@ func TestIBPortAttachValue(t *testing.T) {
 		instructionBuilder.AttachValue(dataHashForAttachThird),
 	)
 
-	if err != nil {
-		t.Logf("Program must fail with error 0x1 \n")
-		t.Logf("If so - double spend has been prevented \n")
+	if !errors.Is(err, ibport.ErrRequestAlreadyProcessed) {
+		t.Fatalf("double spend must have been prevented, got: %v", err)
 	}
 }
 
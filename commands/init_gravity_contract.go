@@ -50,7 +50,7 @@ func init() {
 	viper.BindPFlag("multisig-account", initGravityContractCmd.Flags().Lookup("multisig-account"))
 	initGravityContractCmd.MarkFlagRequired("multisig-account")
 
-	initGravityContractCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding")
+	initGravityContractCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", initGravityContractCmd.Flags().Lookup("private-key"))
 	initGravityContractCmd.MarkFlagRequired("private-key")
 
@@ -157,8 +157,13 @@ func InitGravity(privateKey, programID, stateID, multisigID, clientEndpoint stri
 }
 
 func initGravity(ccmd *cobra.Command, args []string) {
+	pk, err := DecodePrivateKey(UpdateConsulsPrivateKey)
+	if err != nil {
+		log.Fatalf("Error on private key: %v\n", err)
+	}
+
 	endpoint, _ := InferSystemDefinedRPC()
-	_, err := InitGravity(UpdateConsulsPrivateKey, GravityProgramID, GravityDataAccount, MultisigDataAccount, endpoint, make([]byte, 0))
+	_, err = InitGravity(base58.Encode(pk), GravityProgramID, GravityDataAccount, MultisigDataAccount, endpoint, make([]byte, 0))
 	if err != nil {
 		log.Fatalf("Error on 'InitGravity': %v\n", err)
 	}
@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"log"
 
 	// "github.com/Gravity-Tech/solanoid/commands/executor"
 	"github.com/Gravity-Tech/solanoid/commands/executor"
 
+	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -45,7 +47,7 @@ func init() {
 	viper.BindPFlag("multisig-account", initNebulaContractCmd.Flags().Lookup("multisig-account"))
 	initNebulaContractCmd.MarkFlagRequired("multisig-account")
 
-	initNebulaContractCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding")
+	initNebulaContractCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", initNebulaContractCmd.Flags().Lookup("private-key"))
 	initNebulaContractCmd.MarkFlagRequired("private-key")
 
@@ -62,7 +64,12 @@ func InitGenericExecutor(privateKey, programID, dataAccount, multisigDataAccount
 }
 
 func initNebula(ccmd *cobra.Command, args []string) {
+	pk, err := DecodePrivateKey(UpdateConsulsPrivateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	endpoint, _ := InferSystemDefinedRPC()
 
-	_, _ = InitGenericExecutor(UpdateConsulsPrivateKey, GravityDataAccount, NebulaDataAccount, MultisigDataAccount, endpoint, common.PublicKeyFromString(GravityProgramID))
+	_, _ = InitGenericExecutor(base58.Encode(pk), GravityDataAccount, NebulaDataAccount, MultisigDataAccount, endpoint, common.PublicKeyFromString(GravityProgramID))
 }
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Gravity-Tech/solanoid/commands/contract"
+	"github.com/Gravity-Tech/solanoid/models"
+	"github.com/Gravity-Tech/solanoid/models/gravity"
+	"github.com/Gravity-Tech/solanoid/models/nebula"
+	"github.com/Gravity-Tech/solanoid/models/port"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type AccountKind string
+
+const (
+	GravityAccountKind  AccountKind = "gravity"
+	NebulaAccountKind   AccountKind = "nebula"
+	IBPortAccountKind   AccountKind = "ibport"
+	LUPortAccountKind   AccountKind = "luport"
+	MultisigAccountKind AccountKind = "multisig"
+)
+
+var (
+	inspectAccountKind string
+
+	inspectCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "inspect <account>",
+		Short: "Decode and print state of Gravity, Nebula, Port or multisig data account",
+		Long:  `Account kind is detected by the owner program, pass --kind for programs deployed under other addresses.`,
+		Args:  cobra.ExactArgs(1),
+		Run:   inspect,
+	}
+)
+
+func init() {
+	inspectCmd.Flags().StringVar(&inspectAccountKind, "kind", "", "Account kind: gravity, nebula, ibport, luport or multisig")
+	viper.BindPFlag("kind", inspectCmd.Flags().Lookup("kind"))
+
+	inspectCmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	viper.BindPFlag("url", inspectCmd.Flags().Lookup("url"))
+
+	SolanoidCmd.AddCommand(inspectCmd)
+}
+
+// DetectAccountKind relies on the well known program ids, multisig accounts are told apart by size
+func DetectAccountKind(owner string, size int) (AccountKind, error) {
+	var kind AccountKind
+
+	switch owner {
+	case contract.GravityBinary:
+		kind = GravityAccountKind
+	case contract.NebulaBinary:
+		kind = NebulaAccountKind
+	case contract.IBPortBinary:
+		kind = IBPortAccountKind
+	case contract.LUPortBinary:
+		kind = LUPortAccountKind
+	default:
+		return "", fmt.Errorf("unknown owner program %v, pass account kind explicitly", owner)
+	}
+
+	if size == models.MultisigSize && (kind == GravityAccountKind || kind == NebulaAccountKind) {
+		return MultisigAccountKind, nil
+	}
+
+	return kind, nil
+}
+
+func DecodeAccountState(kind AccountKind, data []byte) (interface{}, error) {
+	switch kind {
+	case GravityAccountKind:
+		return gravity.DecodeGravityContract(data)
+	case NebulaAccountKind:
+		return nebula.DecodeNebulaContract(data)
+	case IBPortAccountKind, LUPortAccountKind:
+		return port.DecodePortContract(data)
+	case MultisigAccountKind:
+		return models.DecodeMultisig(data)
+	}
+
+	return nil, fmt.Errorf("unknown account kind: %v", kind)
+}
+
+func readAccountState(ctx context.Context, endpoint string, address common.PublicKey, kind AccountKind) (interface{}, error) {
+	data, owner, err := ReadAccountData(ctx, endpoint, address)
+	if err != nil {
+		return nil, err
+	}
+	if owner == "" {
+		return nil, fmt.Errorf("account %v does not exist", address.ToBase58())
+	}
+
+	return DecodeAccountState(kind, data)
+}
+
+func ReadGravityContract(ctx context.Context, endpoint string, address common.PublicKey) (*gravity.GravityContract, error) {
+	state, err := readAccountState(ctx, endpoint, address, GravityAccountKind)
+	if err != nil {
+		return nil, err
+	}
+	return state.(*gravity.GravityContract), nil
+}
+
+func ReadNebulaContract(ctx context.Context, endpoint string, address common.PublicKey) (*nebula.NebulaContract, error) {
+	state, err := readAccountState(ctx, endpoint, address, NebulaAccountKind)
+	if err != nil {
+		return nil, err
+	}
+	return state.(*nebula.NebulaContract), nil
+}
+
+func ReadPortContract(ctx context.Context, endpoint string, address common.PublicKey) (*port.PortContract, error) {
+	state, err := readAccountState(ctx, endpoint, address, IBPortAccountKind)
+	if err != nil {
+		return nil, err
+	}
+	return state.(*port.PortContract), nil
+}
+
+func ReadMultisig(ctx context.Context, endpoint string, address common.PublicKey) (*models.Multisig, error) {
+	state, err := readAccountState(ctx, endpoint, address, MultisigAccountKind)
+	if err != nil {
+		return nil, err
+	}
+	return state.(*models.Multisig), nil
+}
+
+func inspect(ccmd *cobra.Command, args []string) {
+	address := common.PublicKeyFromString(args[0])
+
+	data, owner, err := ReadAccountData(context.Background(), resolveRPCEndpoint(rpcURL), address)
+	if err != nil {
+		log.Fatalf("Error on 'ReadAccountData': %v\n", err)
+	}
+	if owner == "" {
+		log.Fatalf("Account %v does not exist\n", args[0])
+	}
+
+	kind := AccountKind(inspectAccountKind)
+	if kind == "" {
+		kind, err = DetectAccountKind(owner, len(data))
+		if err != nil {
+			log.Fatalf("Error on 'DetectAccountKind': %v\n", err)
+		}
+	}
+
+	state, err := DecodeAccountState(kind, data)
+	if err != nil {
+		log.Fatalf("Error on 'DecodeAccountState': %v\n", err)
+	}
+
+	serialized, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.Fatalf("Error on 'MarshalIndent': %v\n", err)
+	}
+
+	fmt.Printf("Account: %s\n", address.ToBase58())
+	fmt.Printf("Owner: %s\n", owner)
+	fmt.Printf("Kind: %s\n", kind)
+	fmt.Println(string(serialized))
+}
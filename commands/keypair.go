@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Gravity-Tech/solanoid/commands/keystore"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// ReadAccountFromPath reads the keypair file in the solana-keygen JSON format.
+// Keystore files, either referenced as keystore:<path> or detected by the content,
+// are unlocked with the passphrase from keystore.PassphraseEnv.
+func ReadAccountFromPath(path string) (types.Account, error) {
+	if keystorePath, ok := keystore.ParseReference(path); ok {
+		path = keystorePath
+	}
+
+	result, err := ioutil.ReadFile(path)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	if keystore.IsKeyFile(result) {
+		return readKeystoreAccount(path)
+	}
+
+	var input []byte
+
+	err = json.Unmarshal(result, &input)
+	if err != nil {
+		return types.Account{}, err
+	}
+	if len(input) != ed25519.PrivateKeySize {
+		return types.Account{}, fmt.Errorf("invalid keypair length in %v: %v", path, len(input))
+	}
+
+	return types.AccountFromPrivateKeyBytes(input), nil
+}
+
+// DecodePrivateKey decodes base58 private key flags, keystore:<path> references are unlocked
+// with the passphrase from keystore.PassphraseEnv
+func DecodePrivateKey(value string) ([]byte, error) {
+	if _, ok := keystore.ParseReference(value); ok {
+		account, err := ReadAccountFromPath(value)
+		if err != nil {
+			return nil, err
+		}
+		return account.PrivateKey, nil
+	}
+
+	return base58.Decode(value)
+}
+
+func readKeystoreAccount(path string) (types.Account, error) {
+	passphrase, err := keystore.PassphraseFromEnv()
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	key, err := keystore.LoadKey(path, passphrase)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	privateKey, err := key.Ed25519()
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	return types.AccountFromPrivateKeyBytes(privateKey), nil
+}
+
+// WriteAccountToPath stores the keypair in the solana-keygen JSON format,
+// keystore:<path> is encrypted with the passphrase from keystore.PassphraseEnv instead.
+// Existing file is kept untouched unless forceRewrite is set.
+func WriteAccountToPath(path string, account types.Account, forceRewrite bool) error {
+	keystorePath, encrypted := keystore.ParseReference(path)
+	if encrypted {
+		path = keystorePath
+	}
+
+	if _, err := os.Stat(path); err == nil && !forceRewrite {
+		return fmt.Errorf("refusing to overwrite %v without force", path)
+	}
+
+	if encrypted {
+		passphrase, err := keystore.PassphraseFromEnv()
+		if err != nil {
+			return err
+		}
+
+		return keystore.StoreKey(path, keystore.NewEd25519Key(account.PrivateKey), passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	}
+
+	// json.Marshal encodes []byte as base64, solana-keygen expects a list of numbers
+	keypair := make([]int, len(account.PrivateKey))
+	for i, b := range account.PrivateKey {
+		keypair[i] = int(b)
+	}
+
+	content, err := json.Marshal(keypair)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+func GenerateAccountToPath(path string, forceRewrite bool) (types.Account, error) {
+	account := types.NewAccount()
+
+	if err := WriteAccountToPath(path, account, forceRewrite); err != nil {
+		return types.Account{}, err
+	}
+
+	return account, nil
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Gravity-Tech/solanoid/commands/keystore"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	keystoreOutput  string
+	keystoreKeyType string
+	keystoreForce   bool
+
+	keystoreCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "keystore",
+		Short: "Manage keys encrypted with scrypt and AES-GCM",
+		Long: `Keystore files are unlocked with the passphrase from ` + keystore.PassphraseEnv + `.
+Use keystore:<path> wherever a private key or a keypair path is expected.`,
+	}
+	keystoreNewCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "new",
+		Short: "Generate a key straight into the keystore",
+		Long:  ``,
+		Args:  cobra.NoArgs,
+		Run:   keystoreNew,
+	}
+	keystoreImportCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "import <keypair>",
+		Short: "Encrypt a solana-keygen keypair file or a hex encoded secp256k1 key file",
+		Long:  `The source file is left in place, remove it once the keystore is verified.`,
+		Args:  cobra.ExactArgs(1),
+		Run:   keystoreImport,
+	}
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{keystoreNewCmd, keystoreImportCmd} {
+		cmd.Flags().StringVarP(&keystoreOutput, "output", "o", "", "keystore file path")
+		viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+		cmd.MarkFlagRequired("output")
+
+		cmd.Flags().StringVar(&keystoreKeyType, "type", string(keystore.KeyTypeEd25519), "key type: ed25519 or secp256k1")
+		viper.BindPFlag("type", cmd.Flags().Lookup("type"))
+
+		cmd.Flags().BoolVar(&keystoreForce, "force", false, "overwrite existing keystore file")
+		viper.BindPFlag("force", cmd.Flags().Lookup("force"))
+
+		keystoreCmd.AddCommand(cmd)
+	}
+
+	SolanoidCmd.AddCommand(keystoreCmd)
+}
+
+func storeKeyFromFlags(key *keystore.Key) {
+	if _, err := os.Stat(keystoreOutput); err == nil && !keystoreForce {
+		log.Fatalf("refusing to overwrite %v without --force", keystoreOutput)
+	}
+
+	passphrase, err := keystore.PassphraseFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = keystore.StoreKey(keystoreOutput, key, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%v key %v is stored to %v\n", key.Type, key.Address, keystoreOutput)
+}
+
+func keystoreNew(ccmd *cobra.Command, args []string) {
+	switch keystore.KeyType(keystoreKeyType) {
+	case keystore.KeyTypeEd25519:
+		storeKeyFromFlags(keystore.NewEd25519Key(types.NewAccount().PrivateKey))
+	case keystore.KeyTypeSecp256k1:
+		privateKey, err := ethcrypto.GenerateKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		storeKeyFromFlags(keystore.NewSecp256k1Key(privateKey))
+	default:
+		log.Fatalf("unknown key type: %v", keystoreKeyType)
+	}
+}
+
+func keystoreImport(ccmd *cobra.Command, args []string) {
+	switch keystore.KeyType(keystoreKeyType) {
+	case keystore.KeyTypeEd25519:
+		account, err := ReadAccountFromPath(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		storeKeyFromFlags(keystore.NewEd25519Key(account.PrivateKey))
+	case keystore.KeyTypeSecp256k1:
+		privateKey, err := ethcrypto.LoadECDSA(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		storeKeyFromFlags(keystore.NewSecp256k1Key(privateKey))
+	default:
+		log.Fatalf("unknown key type: %v", keystoreKeyType)
+	}
+}
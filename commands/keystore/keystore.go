@@ -0,0 +1,380 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/scrypt"
+)
+
+type KeyType string
+
+const (
+	KeyTypeEd25519   KeyType = "ed25519"
+	KeyTypeSecp256k1 KeyType = "secp256k1"
+)
+
+const (
+	// Version follows the Ethereum v3 keystore, the layout differs by the authenticated cipher and the key type
+	Version = 3
+
+	// StandardScryptN and StandardScryptP match the Ethereum keystore defaults, ~1s and 256MB to unlock
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+
+	// LightScryptN and LightScryptP are for tests and throwaway keys
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	cipherAESGCM = "aes-256-gcm"
+	kdfScrypt    = "scrypt"
+)
+
+// PassphraseEnv unlocks keystore references, so CI never passes the passphrase in arguments
+const PassphraseEnv = "SOLANOID_KEYSTORE_PASSPHRASE"
+
+// ReferencePrefix marks a keystore file where a keypair path or a private key is expected,
+// e.g. keystore:private-keys/mainnet/deployer.json
+const ReferencePrefix = "keystore:"
+
+var ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+
+type scryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+type cipherParams struct {
+	Nonce string `json:"nonce"`
+}
+
+type cryptoJSON struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams cipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    scryptParams `json:"kdfparams"`
+}
+
+// KeyFile is the encrypted key as stored on disk. Type and Address are authenticated
+// by AES-GCM along with the key, so they cannot be swapped without the passphrase.
+type KeyFile struct {
+	Version int        `json:"version"`
+	ID      string     `json:"id"`
+	Type    KeyType    `json:"type"`
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+}
+
+// Key is the decrypted key. Ed25519 keys are 64 bytes as in solana-keygen files,
+// secp256k1 keys are 32 bytes. Address is base58 for ed25519 and 0x hex for secp256k1.
+type Key struct {
+	Type       KeyType
+	Address    string
+	PrivateKey []byte
+}
+
+func NewEd25519Key(privateKey ed25519.PrivateKey) *Key {
+	return &Key{
+		Type:       KeyTypeEd25519,
+		Address:    base58.Encode(privateKey.Public().(ed25519.PublicKey)),
+		PrivateKey: privateKey,
+	}
+}
+
+func NewSecp256k1Key(privateKey *ecdsa.PrivateKey) *Key {
+	return &Key{
+		Type:       KeyTypeSecp256k1,
+		Address:    ethcrypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		PrivateKey: ethcrypto.FromECDSA(privateKey),
+	}
+}
+
+func (key *Key) Ed25519() (ed25519.PrivateKey, error) {
+	if key.Type != KeyTypeEd25519 {
+		return nil, fmt.Errorf("%v key is not ed25519", key.Type)
+	}
+	if len(key.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 key length: %v", len(key.PrivateKey))
+	}
+
+	return ed25519.PrivateKey(key.PrivateKey), nil
+}
+
+func (key *Key) ECDSA() (*ecdsa.PrivateKey, error) {
+	if key.Type != KeyTypeSecp256k1 {
+		return nil, fmt.Errorf("%v key is not secp256k1", key.Type)
+	}
+
+	return ethcrypto.ToECDSA(key.PrivateKey)
+}
+
+func (key *Key) derivedAddress() (string, error) {
+	switch key.Type {
+	case KeyTypeEd25519:
+		privateKey, err := key.Ed25519()
+		if err != nil {
+			return "", err
+		}
+		return NewEd25519Key(privateKey).Address, nil
+	case KeyTypeSecp256k1:
+		privateKey, err := key.ECDSA()
+		if err != nil {
+			return "", err
+		}
+		return NewSecp256k1Key(privateKey).Address, nil
+	}
+
+	return "", fmt.Errorf("unknown key type: %v", key.Type)
+}
+
+func (keyFile *KeyFile) additionalData() []byte {
+	return []byte(string(keyFile.Type) + ":" + keyFile.Address)
+}
+
+func deriveKey(passphrase string, params scryptParams) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+}
+
+func randomBytes(size int) ([]byte, error) {
+	result := make([]byte, size)
+	if _, err := rand.Read(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func EncryptKey(key *Key, passphrase string, scryptN, scryptP int) (*KeyFile, error) {
+	address, err := key.derivedAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile := &KeyFile{
+		Version: Version,
+		ID:      hex.EncodeToString(id),
+		Type:    key.Type,
+		Address: address,
+		Crypto: cryptoJSON{
+			Cipher: cipherAESGCM,
+			KDF:    kdfScrypt,
+			KDFParams: scryptParams{
+				N:     scryptN,
+				R:     scryptR,
+				P:     scryptP,
+				DKLen: scryptDKLen,
+				Salt:  hex.EncodeToString(salt),
+			},
+		},
+	}
+
+	derivedKey, err := deriveKey(passphrase, keyFile.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile.Crypto.CipherParams.Nonce = hex.EncodeToString(nonce)
+	keyFile.Crypto.CipherText = hex.EncodeToString(aead.Seal(nil, nonce, key.PrivateKey, keyFile.additionalData()))
+
+	return keyFile, nil
+}
+
+func newAEAD(derivedKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func DecryptKey(keyFile *KeyFile, passphrase string) (*Key, error) {
+	if keyFile.Version != Version {
+		return nil, fmt.Errorf("unsupported keystore version: %v", keyFile.Version)
+	}
+	if keyFile.Crypto.Cipher != cipherAESGCM {
+		return nil, fmt.Errorf("unsupported cipher: %v", keyFile.Crypto.Cipher)
+	}
+	if keyFile.Crypto.KDF != kdfScrypt {
+		return nil, fmt.Errorf("unsupported kdf: %v", keyFile.Crypto.KDF)
+	}
+
+	derivedKey, err := deriveKey(passphrase, keyFile.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(keyFile.Crypto.CipherParams.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length: %v", len(nonce))
+	}
+
+	cipherText, err := hex.DecodeString(keyFile.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := aead.Open(nil, nonce, cipherText, keyFile.additionalData())
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	key := &Key{Type: keyFile.Type, Address: keyFile.Address, PrivateKey: privateKey}
+
+	address, err := key.derivedAddress()
+	if err != nil {
+		return nil, err
+	}
+	if address != keyFile.Address {
+		return nil, fmt.Errorf("key address mismatch: expected %v, got %v", keyFile.Address, address)
+	}
+
+	return key, nil
+}
+
+// IsKeyFile tells keystore files from plain keypair files, e.g. solana-keygen JSON arrays
+func IsKeyFile(content []byte) bool {
+	keyFile := struct {
+		Crypto *cryptoJSON `json:"crypto"`
+	}{}
+
+	if err := json.Unmarshal(content, &keyFile); err != nil {
+		return false
+	}
+
+	return keyFile.Crypto != nil
+}
+
+func ParseKeyFile(content []byte) (*KeyFile, error) {
+	keyFile := &KeyFile{}
+	if err := json.Unmarshal(content, keyFile); err != nil {
+		return nil, fmt.Errorf("parse keystore: %v", err)
+	}
+
+	return keyFile, nil
+}
+
+// StoreKey encrypts and writes the key readable by the owner only
+func StoreKey(path string, key *Key, passphrase string, scryptN, scryptP int) error {
+	keyFile, err := EncryptKey(key, passphrase, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(keyFile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+func LoadKey(path, passphrase string) (*Key, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile, err := ParseKeyFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := DecryptKey(keyFile, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	return key, nil
+}
+
+// PassphraseFromEnv reads PassphraseEnv, an empty passphrase is refused
+func PassphraseFromEnv() (string, error) {
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return "", fmt.Errorf("%v is not set", PassphraseEnv)
+	}
+
+	return passphrase, nil
+}
+
+// ParseReference returns the keystore path of keystore:<path>, false for other values
+func ParseReference(value string) (string, bool) {
+	if !strings.HasPrefix(value, ReferencePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(value, ReferencePrefix), true
+}
+
+// LoadReference unlocks keystore:<path> with the passphrase from PassphraseEnv
+func LoadReference(reference string) (*Key, error) {
+	path, ok := ParseReference(reference)
+	if !ok {
+		return nil, fmt.Errorf("not a keystore reference: %v", reference)
+	}
+
+	passphrase, err := PassphraseFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadKey(path, passphrase)
+}
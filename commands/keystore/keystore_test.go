@@ -0,0 +1,132 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	_, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secp256k1PrivateKey, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	for _, key := range []*Key{NewEd25519Key(ed25519PrivateKey), NewSecp256k1Key(secp256k1PrivateKey)} {
+		path := filepath.Join(dir, "keys", string(key.Type)+".json")
+
+		if err := StoreKey(path, key, "correct horse", LightScryptN, LightScryptP); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Fatalf("%v: keystore is readable by others: %v", key.Type, info.Mode())
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsKeyFile(content) || bytes.Contains(content, []byte(hex.EncodeToString(key.PrivateKey))) {
+			t.Fatalf("%v: key is not encrypted: %s", key.Type, content)
+		}
+
+		if _, err := LoadKey(path, "wrong horse"); err == nil {
+			t.Fatalf("%v: wrong passphrase is accepted", key.Type)
+		}
+
+		loaded, err := LoadKey(path, "correct horse")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Type != key.Type || loaded.Address != key.Address || !bytes.Equal(loaded.PrivateKey, key.PrivateKey) {
+			t.Fatalf("%v: unexpected key %v", key.Type, loaded.Address)
+		}
+	}
+
+	ecdsaKey, err := NewSecp256k1Key(secp256k1PrivateKey).ECDSA()
+	if err != nil || ecdsaKey.D.Cmp(secp256k1PrivateKey.D) != 0 {
+		t.Fatalf("ecdsa key is not restored: %v", err)
+	}
+	if _, err := NewSecp256k1Key(secp256k1PrivateKey).Ed25519(); err == nil {
+		t.Fatal("secp256k1 key is returned as ed25519")
+	}
+}
+
+func TestKeystoreAuthenticatesMetadata(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile, err := EncryptKey(NewEd25519Key(privateKey), "passphrase", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a swapped address would make the deployer sign for someone else's account
+	keyFile.Address = NewEd25519Key(otherPrivateKey).Address
+	if _, err := DecryptKey(keyFile, "passphrase"); err != ErrDecrypt {
+		t.Fatalf("tampered address is accepted: %v", err)
+	}
+}
+
+func TestKeystoreReference(t *testing.T) {
+	if IsKeyFile([]byte(`[1,2,3]`)) {
+		t.Fatal("solana-keygen keypair is taken for a keystore")
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "deployer.json")
+	if err := StoreKey(path, NewEd25519Key(privateKey), "passphrase", LightScryptN, LightScryptP); err != nil {
+		t.Fatal(err)
+	}
+
+	reference := ReferencePrefix + path
+	if parsed, ok := ParseReference(reference); !ok || parsed != path {
+		t.Fatalf("unexpected reference path: %v", parsed)
+	}
+	if _, ok := ParseReference(path); ok {
+		t.Fatal("plain path is taken for a reference")
+	}
+
+	os.Unsetenv(PassphraseEnv)
+	if _, err := LoadReference(reference); err == nil {
+		t.Fatal("reference is unlocked without passphrase")
+	}
+
+	os.Setenv(PassphraseEnv, "passphrase")
+	defer os.Unsetenv(PassphraseEnv)
+
+	key, err := LoadReference(reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key.PrivateKey, privateKey) {
+		t.Fatal("unexpected key")
+	}
+}
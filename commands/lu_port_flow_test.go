@@ -1,14 +1,17 @@
 package commands
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"math/rand"
 	"testing"
 
 	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/tokens"
 	"github.com/Gravity-Tech/solanoid/models/nebula"
 
+	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/types"
 )
@@ -66,8 +69,7 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 		WrappedFaucet(t, deployer.PKPath, consul.PublicKey.ToBase58(), 10)
 	}
 
-	waitTransactionConfirmations()
-
+	ctx := context.Background()
 	RPCEndpoint, _ := InferSystemDefinedRPC()
 
 	tokenDeployResult, err := CreateToken(deployer.PKPath)
@@ -80,8 +82,6 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 	deployerTokenAccount, err := CreateTokenAccount(deployer.PKPath, tokenMint.ToBase58())
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
-
 	// mint some tokens for deployer
 	err = MintToken(deployer.PKPath, tokenMint.ToBase58(), 1_000_000, deployerTokenAccount)
 	ValidateError(t, err)
@@ -102,7 +102,13 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 	luportDataAccount, err := GenerateNewAccount(deployer.PrivateKey, LUPortAllocation, luportProgram.PublicKey.ToBase58(), RPCEndpoint)
 	ValidateError(t, err)
 
-	waitTransactionConfirmations()
+	awaitTransactions(t, RPCEndpoint,
+		gravityDataAccount.TxSignature,
+		gravityMultisigAccount.TxSignature,
+		nebulaDataAccount.TxSignature,
+		nebulaMultisigAccount.TxSignature,
+		luportDataAccount.TxSignature,
+	)
 
 	ParallelExecution(
 		[]func(){
@@ -121,8 +127,6 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 		},
 	)
 
-	waitTransactionConfirmations()
-
 	gravityBuilder := executor.GravityInstructionBuilder{}
 	gravityExecutor, err := InitGenericExecutor(
 		deployer.PrivateKey,
@@ -156,28 +160,26 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 
 	oracles := consulsList.ConcatConsuls()
 
-	waitTransactionConfirmations()
-
 	ParallelExecution(
 		[]func(){
 			func() {
-				gravityInitResponse, err := gravityExecutor.BuildAndInvoke(
-					gravityBuilder.Init(BFT, 1, oracles),
+				gravityInitResponse, err := gravityExecutor.BuildAndConfirm(
+					ctx, gravityBuilder.Init(BFT, 1, oracles), solclient.CommitmentConfirmed,
 				)
 				fmt.Printf("Gravity Init: %v \n", gravityInitResponse.TxSignature)
 				ValidateError(t, err)
 			},
 			func() {
 				// (2)
-				nebulaInitResponse, err := nebulaExecutor.BuildAndInvoke(
-					nebulaBuilder.Init(BFT, nebula.Bytes, gravityDataAccount.Account.PublicKey, oracles),
+				nebulaInitResponse, err := nebulaExecutor.BuildAndConfirm(
+					ctx, nebulaBuilder.Init(BFT, nebula.Bytes, gravityDataAccount.Account.PublicKey, oracles), solclient.CommitmentConfirmed,
 				)
 				ValidateError(t, err)
 				fmt.Printf("Nebula Init: %v \n", nebulaInitResponse.TxSignature)
 			},
 			func() {
-				luportInitResult, err := luportExecutor.BuildAndInvoke(
-					executor.LUPortIXBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsList.ConcatConsuls()),
+				luportInitResult, err := luportExecutor.BuildAndConfirm(
+					ctx, executor.LUPortIXBuilder.InitWithOracles(nebulaProgram.PublicKey, common.TokenProgramID, tokenDeployResult.Token, BFT, consulsList.ConcatConsuls()), solclient.CommitmentConfirmed,
 				)
 
 				fmt.Printf("LU Port Init: %v \n", luportInitResult.TxSignature)
@@ -186,8 +188,6 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 		},
 	)
 
-	waitTransactionConfirmations()
-
 	fmt.Println("LU Port Program is being subscribed to Nebula")
 
 	var subID [16]byte
@@ -196,26 +196,21 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 	fmt.Printf("subID: %v \n", subID)
 
 	// (4)
-	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Subscribe(luportProgram.PDA, 1, 1, subID),
+	nebulaSubscribePortResponse, err := nebulaExecutor.BuildAndConfirm(
+		ctx, nebulaBuilder.Subscribe(luportProgram.PDA, 1, 1, subID), solclient.CommitmentConfirmed,
 	)
 	ValidateError(t, err)
 
 	fmt.Printf("Nebula Subscribe: %v \n", nebulaSubscribePortResponse.TxSignature)
 	fmt.Println("Now checking for valid double spend prevent")
 
-	waitTransactionConfirmations()
-	// waitTransactionConfirmations()
-
-	_, err = nebulaExecutor.BuildAndInvoke(
-		nebulaBuilder.Subscribe(luportProgram.PDA, 1, 1, subID),
+	_, err = nebulaExecutor.BuildAndConfirm(
+		ctx, nebulaBuilder.Subscribe(luportProgram.PDA, 1, 1, subID), solclient.CommitmentConfirmed,
 	)
 	ValidateErrorExistence(t, err)
 
 	fmt.Printf("Nebula Subscribe with the same subID must have failed: %v \n", err.Error())
 
-	waitTransactionConfirmations()
-
 	// luportTokenAccount, _ := CreateTokenAccount(luportProgram.PKPath, tokenMint.ToBase58())
 
 	luportTokenAccountCreateResponse := TransferSPLTokensAllowUnfunded(deployer.PKPath, tokenMint.ToBase58(), luportProgram.PDA.ToBase58(), 0)
@@ -241,10 +236,8 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 		1.235,
 	}
 
-	waitTransactionConfirmations()
-
-	lockTokens, err := luportExecutor.BuildAndInvoke(
-		executor.LUPortIXBuilder.CreateTransferWrapRequest(evmReceiver32bytes, lockAmounts[0]),
+	lockTokens, err := luportExecutor.BuildAndConfirm(
+		ctx, executor.LUPortIXBuilder.CreateTransferWrapRequest(evmReceiver32bytes, lockAmounts[0]), solclient.CommitmentConfirmed,
 	)
 	ValidateError(t, err)
 	t.Logf("LUPort #1 CreateTransferWrapRequest (%v): %v \n", lockAmounts[0], lockTokens.TxSignature)
@@ -282,10 +275,8 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 
 		nebulaExecutor.SetDeployerPK(operator)
 
-		waitTransactionConfirmations()
-
-		nebulaSendHashValueResponse, err := nebulaExecutor.BuildAndInvoke(
-			executor.NebulaIXBuilder.SendHashValue(dataHashForAttach),
+		nebulaSendHashValueResponse, err := nebulaExecutor.BuildAndConfirm(
+			ctx, executor.NebulaIXBuilder.SendHashValue(dataHashForAttach), solclient.CommitmentConfirmed,
 		)
 		if err != nil {
 			return err
@@ -307,10 +298,8 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 			{PubKey: common.PublicKeyFromString(luportTokenAccount), IsWritable: true, IsSigner: false},
 		})
 
-		waitTransactionConfirmations()
-
-		nebulaAttachResponse, err := nebulaExecutor.BuildAndInvoke(
-			nebulaBuilder.SendValueToSubs(rawDataValue64bytes, nebula.Bytes, uint64(pulseID), subID),
+		nebulaAttachResponse, err := nebulaExecutor.BuildAndConfirm(
+			ctx, nebulaBuilder.SendValueToSubs(executor.LUPortAttachValueAccounts, rawDataValue64bytes, nebula.Bytes, uint64(pulseID), subID), solclient.CommitmentConfirmed,
 		)
 		if err != nil {
 			return err
@@ -318,25 +307,31 @@ func TestNebulaSendValueToLUPortSubscriber(t *testing.T) {
 
 		fmt.Printf("#%v Nebula SendValueToSubs Call:  %v \n", i, nebulaAttachResponse.TxSignature)
 
-		waitTransactionConfirmations()
 		return nil
 	}
 
+	lockAmount, err := tokens.UIAmountToBaseUnits(lockAmounts[0], 8)
+	ValidateError(t, err)
+
 	baBuilder := executor.SolanaToEVMBABuilder{
-		Amount: lockAmounts[0],
+		Amount: lockAmount,
 		Origin: common.PublicKeyFromString(deployerTokenAccount),
 	}
 	baBuilder.SetCfg(executor.BACfg{
-		OriginDecimals: 8,
-		DestDecimals:   18,
+		OriginDecimals:  8,
+		DestDecimals:    18,
+		AmountFormat:    executor.AmountFormatFloat64,
+		EVMAmountLayout: executor.EVMAmountUnpadded,
 	})
 
-	correctDataHashForAttach := baBuilder.BuildForReverse()[:]
+	correctDataHashForAttach, err := baBuilder.BuildForReverse()
+	ValidateError(t, err)
 
 	// baBuilder.Origin = common.PublicKeyFromBytes(make([]byte, 32))
 	baBuilder.Origin = *new(common.PublicKey)
 
-	failingDataHashForAttach := baBuilder.BuildForReverse()[:]
+	failingDataHashForAttach, err := baBuilder.BuildForReverse()
+	ValidateError(t, err)
 
 	hashQueue := [][]byte{failingDataHashForAttach, correctDataHashForAttach}
 
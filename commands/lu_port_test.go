@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/models/port/luport"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/common"
@@ -127,6 +129,14 @@ func TestLUPortFullFlow(t *testing.T) {
 	ValidateError(t, err)
 	t.Logf("LUPort Init: %v \n", ibportInitResult.TxSignature)
 
+	executor.RegisterProgramErrors(common.PublicKeyFromString(luportAddress), luport.Errors)
+	_, err = luportExecutor.BuildAndInvoke(
+		executor.LUPortIXBuilder.InitWithOracles(mockedNebulaAddress, common.TokenProgramID, tokenMint, 3, consulsList.ConcatConsuls()),
+	)
+	if !errors.Is(err, luport.ErrAlreadyInitialized) {
+		t.Fatalf("second init must have been rejected as already initialized, got: %v", err)
+	}
+
 	luportExecutor.SetAdditionalMeta([]types.AccountMeta{
 		{PubKey: common.TokenProgramID, IsWritable: false, IsSigner: false},
 		{PubKey: tokenMint, IsWritable: true, IsSigner: false},
@@ -3,15 +3,15 @@ package mvp
 import (
 	"fmt"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/Gravity-Tech/solanoid/commands"
-	soltoken "github.com/portto/solana-go-sdk/tokenprog"
 )
 
 func TestDepositAwaiter(t *testing.T) {
-	polygonClient := NewEVMExplorerClient()
+	polygonClient := NewEVMExplorerClient(os.Getenv("POLYGONSCAN_API_KEY"))
 
 	var err error
 	var polygonDepositAwaiter, solanaDepositAwaiter CrossChainTokenDepositAwaiter
@@ -64,7 +64,7 @@ func TestDepositAwaiter(t *testing.T) {
 	}()
 
 	for event := range solanaDepositBuffer {
-		tokenDataState := event.(soltoken.TokenAccount)
-		fmt.Printf("SOL - deposit event: %+v \n", tokenDataState)
+		deposit := event.(SolanaTokenDeposit)
+		fmt.Printf("SOL - deposit event: %+v \n", deposit)
 	}
 }
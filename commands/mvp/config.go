@@ -0,0 +1,303 @@
+package mvp
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands"
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/keystore"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+	soltypes "github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/viper"
+)
+
+// MVPConfigEnvPrefix prefixes the environment overrides, MVP_ORIGIN_NODE_URL overrides origin.node_url
+const MVPConfigEnvPrefix = "MVP"
+
+// MVPConfig is the MVP runner input. Key material is never kept in the config itself,
+// it is referenced by a file path or by the name of the environment variable holding it.
+type MVPConfig struct {
+	Route     string `mapstructure:"route"`
+	Direction string `mapstructure:"direction"`
+	// Amount is in origin token units, e.g. 0.015, a random amount is sent if empty
+	Amount       string        `mapstructure:"amount"`
+	AwaitTimeout time.Duration `mapstructure:"await_timeout"`
+	// ReportPath is the swap report destination: <path>.json is the run, <path>.csv appends a row per step
+	ReportPath string `mapstructure:"report_path"`
+
+	Origin      MVPEVMConfig    `mapstructure:"origin"`
+	Destination MVPSolanaConfig `mapstructure:"destination"`
+}
+
+type MVPEVMConfig struct {
+	Chain         string `mapstructure:"chain"`
+	NodeURL       string `mapstructure:"node_url"`
+	ChainID       int64  `mapstructure:"chain_id"`
+	LUPortAddress string `mapstructure:"luport_address"`
+	TokenAddress  string `mapstructure:"token_address"`
+	Decimals      uint8  `mapstructure:"decimals"`
+	GasLimit      uint64 `mapstructure:"gas_limit"`
+	Confirmations uint64 `mapstructure:"confirmations"`
+
+	// PrivateKeyPath is a file with the hex encoded holder key or a secp256k1 keystore
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	// PrivateKeyEnv names the variable with the hex encoded holder key, used if PrivateKeyPath is empty
+	PrivateKeyEnv string `mapstructure:"private_key_env"`
+}
+
+type MVPSolanaConfig struct {
+	Chain             string `mapstructure:"chain"`
+	NodeURL           string `mapstructure:"node_url"`
+	WebSocketURL      string `mapstructure:"websocket_url"`
+	IBPortProgramID   string `mapstructure:"ibport_program_id"`
+	IBPortDataAccount string `mapstructure:"ibport_data_account"`
+	MintAddress       string `mapstructure:"mint_address"`
+	Decimals          uint8  `mapstructure:"decimals"`
+	// AmountFormat is the amount layout of the deployed IB Port binary, u64 or f64
+	AmountFormat string `mapstructure:"amount_format"`
+	// TokenAccount receives the minted tokens and must be owned by the holder,
+	// the holder associated token account is used and created if empty
+	TokenAccount string `mapstructure:"token_account"`
+
+	// KeypairPath is the holder keypair in the solana-keygen JSON format or an ed25519 keystore
+	KeypairPath string `mapstructure:"keypair_path"`
+	// KeypairEnv names the variable with the holder keypair, either solana-keygen JSON or base58
+	KeypairEnv string `mapstructure:"keypair_env"`
+}
+
+// DefaultMVPConfig points to the Polygon <-> Solana GTON mainnet deployment, holder keys have no defaults
+func DefaultMVPConfig() *MVPConfig {
+	return &MVPConfig{
+		Route:        "polygon-solana",
+		Direction:    SwapRoundTrip.String(),
+		AwaitTimeout: time.Minute * 15,
+		Origin: MVPEVMConfig{
+			Chain:         "polygon",
+			NodeURL:       "https://rpc-mainnet.maticvigil.com",
+			ChainID:       137,
+			LUPortAddress: "0xD2C80231a5E1C7B621c2bb96819b20a00E1be7D2",
+			TokenAddress:  "0xf480f38c366daac4305dc484b2ad7a496ff00cea",
+			Decimals:      18,
+			GasLimit:      1_000_000,
+			Confirmations: DefaultEVMConfirmations,
+		},
+		Destination: MVPSolanaConfig{
+			Chain:             "solana",
+			NodeURL:           "https://api.mainnet-beta.solana.com",
+			IBPortProgramID:   "AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ",
+			IBPortDataAccount: "9kwBfNbrQAEmEqkZbvMCKkefuJBj7nuqWrq6dzUhW5fJ",
+			MintAddress:       "nVZnRKdr3pmcgnJvYDE8iafgiMiBqxiffQMcyv5ETdA",
+			Decimals:          8,
+			// the mainnet port binary predates integer amounts
+			AmountFormat: executor.AmountFormatFloat64.String(),
+		},
+	}
+}
+
+// setDefaults registers every key of the struct with viper, otherwise environment overrides are not seen by Unmarshal
+func setDefaults(v *viper.Viper, prefix string, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		key := prefix + value.Type().Field(i).Tag.Get("mapstructure")
+		field := value.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			setDefaults(v, key+".", field)
+			continue
+		}
+		v.SetDefault(key, field.Interface())
+	}
+}
+
+// ReadMVPConfig reads the YAML or JSON config on top of the defaults, the path may be empty.
+// Environment variables take precedence over the file.
+func ReadMVPConfig(path string) (*MVPConfig, error) {
+	v := viper.New()
+
+	setDefaults(v, "", reflect.ValueOf(*DefaultMVPConfig()))
+
+	v.SetEnvPrefix(MVPConfigEnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read mvp config %v: %v", path, err)
+		}
+	}
+
+	cfg := &MVPConfig{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("parse mvp config: %v", err)
+	}
+
+	return cfg, nil
+}
+
+func (cfg *MVPConfig) Validate() error {
+	if _, err := ParseSwapDirection(cfg.Direction); err != nil {
+		return err
+	}
+	if cfg.Amount != "" {
+		if _, err := cfg.OriginAmount(); err != nil {
+			return err
+		}
+	}
+	if err := cfg.Origin.Validate(); err != nil {
+		return fmt.Errorf("origin: %v", err)
+	}
+	if err := cfg.Destination.Validate(); err != nil {
+		return fmt.Errorf("destination: %v", err)
+	}
+
+	return nil
+}
+
+// OriginAmount is Amount in origin base units, nil if Amount is empty
+func (cfg *MVPConfig) OriginAmount() (*big.Int, error) {
+	if cfg.Amount == "" {
+		return nil, nil
+	}
+
+	amount, ok := new(big.Float).SetPrec(256).SetString(cfg.Amount)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount: %v", cfg.Amount)
+	}
+
+	amount.Mul(amount, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(cfg.Origin.Decimals)), nil)))
+
+	result, accuracy := amount.Int(nil)
+	if accuracy != big.Exact {
+		return nil, fmt.Errorf("amount %v exceeds %v decimals", cfg.Amount, cfg.Origin.Decimals)
+	}
+
+	return result, nil
+}
+
+func (cfg MVPEVMConfig) Validate() error {
+	if cfg.NodeURL == "" {
+		return fmt.Errorf("node url is empty")
+	}
+	if cfg.ChainID <= 0 {
+		return fmt.Errorf("chain id is not set")
+	}
+	if cfg.LUPortAddress == "" || cfg.TokenAddress == "" {
+		return fmt.Errorf("luport or token address is empty")
+	}
+	if cfg.PrivateKeyPath == "" && cfg.PrivateKeyEnv == "" {
+		return fmt.Errorf("neither private key path nor env is set")
+	}
+
+	return nil
+}
+
+func (cfg MVPSolanaConfig) Validate() error {
+	if cfg.NodeURL == "" {
+		return fmt.Errorf("node url is empty")
+	}
+	if cfg.IBPortProgramID == "" || cfg.IBPortDataAccount == "" || cfg.MintAddress == "" {
+		return fmt.Errorf("ibport program, data account or mint is empty")
+	}
+	if _, err := executor.ParseAmountFormat(cfg.AmountFormat); err != nil {
+		return err
+	}
+	if cfg.KeypairPath == "" && cfg.KeypairEnv == "" {
+		return fmt.Errorf("neither keypair path nor env is set")
+	}
+
+	return nil
+}
+
+// readSecret reads the file if the path is set, the environment variable otherwise
+func readSecret(path, env string) (string, error) {
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+
+	value, ok := os.LookupEnv(env)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%v is not set", env)
+	}
+
+	return strings.TrimSpace(value), nil
+}
+
+// Key reads the hex key and returns it with the holder address,
+// keystore files are unlocked with the passphrase from keystore.PassphraseEnv
+func (cfg MVPEVMConfig) Key() (*ecdsa.PrivateKey, ethcommon.Address, error) {
+	path := cfg.PrivateKeyPath
+	if keystorePath, ok := keystore.ParseReference(path); ok {
+		path = keystorePath
+	}
+
+	encoded, err := readSecret(path, cfg.PrivateKeyEnv)
+	if err != nil {
+		return nil, ethcommon.Address{}, fmt.Errorf("read evm key: %v", err)
+	}
+
+	if path != "" && keystore.IsKeyFile([]byte(encoded)) {
+		passphrase, err := keystore.PassphraseFromEnv()
+		if err != nil {
+			return nil, ethcommon.Address{}, err
+		}
+
+		key, err := keystore.LoadKey(path, passphrase)
+		if err != nil {
+			return nil, ethcommon.Address{}, err
+		}
+		if key.Type != keystore.KeyTypeSecp256k1 {
+			return nil, ethcommon.Address{}, fmt.Errorf("%v holds %v key, secp256k1 is expected", path, key.Type)
+		}
+
+		encoded = hex.EncodeToString(key.PrivateKey)
+	}
+
+	privateKey, err := ethcrypto.HexToECDSA(strings.TrimPrefix(encoded, "0x"))
+	if err != nil {
+		return nil, ethcommon.Address{}, err
+	}
+
+	return privateKey, ethcrypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+func (cfg MVPSolanaConfig) Keypair() (soltypes.Account, error) {
+	if cfg.KeypairPath != "" {
+		return commands.ReadAccountFromPath(cfg.KeypairPath)
+	}
+
+	encoded, err := readSecret("", cfg.KeypairEnv)
+	if err != nil {
+		return soltypes.Account{}, fmt.Errorf("read solana keypair: %v", err)
+	}
+
+	var privateKey []byte
+	if strings.HasPrefix(encoded, "[") {
+		err = json.Unmarshal([]byte(encoded), &privateKey)
+	} else {
+		privateKey, err = base58.Decode(encoded)
+	}
+	if err != nil {
+		return soltypes.Account{}, fmt.Errorf("decode solana keypair from %v: %v", cfg.KeypairEnv, err)
+	}
+	if len(privateKey) != 64 {
+		return soltypes.Account{}, fmt.Errorf("invalid keypair length in %v: %v", cfg.KeypairEnv, len(privateKey))
+	}
+
+	return soltypes.AccountFromPrivateKeyBytes(privateKey), nil
+}
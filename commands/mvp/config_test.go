@@ -0,0 +1,156 @@
+package mvp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands"
+	"github.com/Gravity-Tech/solanoid/commands/keystore"
+
+	ethhexutil "github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+	soltypes "github.com/portto/solana-go-sdk/types"
+)
+
+const testMVPConfig = `
+direction: forward
+amount: "0.015"
+await_timeout: 5m
+origin:
+  node_url: http://127.0.0.1:8545
+  chain_id: 1337
+  luport_address: "0x0000000000000000000000000000000000000001"
+  private_key_path: %v
+destination:
+  amount_format: u64
+  keypair_env: MVP_TEST_SOLANA_KEYPAIR
+`
+
+func TestReadMVPConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	evmPrivateKey, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evmKeyPath := filepath.Join(dir, "polygon.key")
+	if err := ioutil.WriteFile(evmKeyPath, []byte(ethhexutil.Encode(ethcrypto.FromECDSA(evmPrivateKey))+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "mvp.yaml")
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf(testMVPConfig, evmKeyPath)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("MVP_DESTINATION_NODE_URL", "http://127.0.0.1:8899")
+	defer os.Unsetenv("MVP_DESTINATION_NODE_URL")
+
+	cfg, err := ReadMVPConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Direction != SwapForward.String() || cfg.AwaitTimeout != time.Minute*5 || cfg.Origin.ChainID != 1337 {
+		t.Fatalf("file values are not read: %+v", cfg)
+	}
+	// omitted keys keep the defaults
+	if cfg.Origin.Decimals != 18 || cfg.Destination.Decimals != 8 || cfg.Destination.IBPortProgramID != DefaultMVPConfig().Destination.IBPortProgramID {
+		t.Fatalf("defaults are lost: %+v", cfg)
+	}
+	if cfg.Destination.NodeURL != "http://127.0.0.1:8899" {
+		t.Fatalf("env override is not applied: %v", cfg.Destination.NodeURL)
+	}
+
+	amount, err := cfg.OriginAmount()
+	if err != nil || amount.String() != "15000000000000000" {
+		t.Fatalf("unexpected amount: %v, %v", amount, err)
+	}
+
+	key, address, err := cfg.Origin.Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if address != ethcrypto.PubkeyToAddress(evmPrivateKey.PublicKey) || !key.Equal(evmPrivateKey) {
+		t.Fatalf("unexpected evm holder: %v", address.String())
+	}
+
+	// the keypair env is unset yet
+	if _, err := cfg.Destination.Keypair(); err == nil {
+		t.Fatal("missing keypair is read")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Direction = "sideways"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("unknown direction passes validation")
+	}
+}
+
+func TestMVPSolanaKeypair(t *testing.T) {
+	account := soltypes.NewAccount()
+
+	keypairPath := filepath.Join(t.TempDir(), "holder.json")
+	if err := commands.WriteAccountToPath(keypairPath, account, false); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("MVP_TEST_SOLANA_KEYPAIR", base58.Encode(account.PrivateKey))
+	defer os.Unsetenv("MVP_TEST_SOLANA_KEYPAIR")
+
+	for _, cfg := range []MVPSolanaConfig{
+		{KeypairPath: keypairPath},
+		{KeypairEnv: "MVP_TEST_SOLANA_KEYPAIR"},
+	} {
+		holder, err := cfg.Keypair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if holder.PublicKey != account.PublicKey {
+			t.Fatalf("%+v: unexpected holder %v", cfg, holder.PublicKey.ToBase58())
+		}
+	}
+
+	keypairJSON, err := ioutil.ReadFile(keypairPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("MVP_TEST_SOLANA_KEYPAIR", string(keypairJSON))
+
+	holder, err := MVPSolanaConfig{KeypairEnv: "MVP_TEST_SOLANA_KEYPAIR"}.Keypair()
+	if err != nil || holder.PublicKey != account.PublicKey {
+		t.Fatalf("keypair JSON in env: %v", err)
+	}
+}
+
+func TestMVPEVMKeystore(t *testing.T) {
+	privateKey, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "polygon.json")
+	err = keystore.StoreKey(path, keystore.NewSecp256k1Key(privateKey), "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(keystore.PassphraseEnv, "passphrase")
+	defer os.Unsetenv(keystore.PassphraseEnv)
+
+	key, address, err := MVPEVMConfig{PrivateKeyPath: keystore.ReferencePrefix + path}.Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if address != ethcrypto.PubkeyToAddress(privateKey.PublicKey) || !key.Equal(privateKey) {
+		t.Fatalf("unexpected evm holder: %v", address.String())
+	}
+}
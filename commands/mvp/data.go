@@ -2,7 +2,6 @@ package mvp
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,37 +9,20 @@ import (
 	"math"
 	"math/big"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/ws"
+
 	ethbind "github.com/ethereum/go-ethereum/accounts/abi/bind"
-	ethhexutil "github.com/ethereum/go-ethereum/common/hexutil"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	ethclient "github.com/ethereum/go-ethereum/ethclient"
 	solclient "github.com/portto/solana-go-sdk/client"
 	solcommon "github.com/portto/solana-go-sdk/common"
 	soltoken "github.com/portto/solana-go-sdk/tokenprog"
 )
 
-type evmKey struct {
-	Address string
-	PubKey  string
-	PrivKey *ecdsa.PrivateKey
-}
-
-func newEVMKey(pk string) (*evmKey, error) {
-	// key := ethcrypto.GenerateKey()
-	decodedPK, err := ethcrypto.HexToECDSA(pk)
-	if err != nil {
-		return nil, err
-	}
-
-	return &evmKey{
-		Address: ethcrypto.PubkeyToAddress(decodedPK.PublicKey).String(),
-		PubKey:  ethhexutil.Encode(ethcrypto.CompressPubkey(&decodedPK.PublicKey)),
-		PrivKey: decodedPK,
-	}, nil
-}
-
 type extractorCfg struct {
 	originDecimals      int
 	destinationDecimals int
@@ -181,6 +163,9 @@ type CrossChainTokenDepositAwaiter interface {
 	SetCfg(*CrossChainDepositAwaiterConfig)
 }
 
+// EVMExplorerClient awaits deposits through the polygonscan API.
+//
+// Deprecated: use EVMLogsDepositAwaiter, it works with any EVM node.
 type EVMExplorerClient struct {
 	apiKey        string
 	crossChainCfg *CrossChainDepositAwaiterConfig
@@ -269,64 +254,217 @@ func (eec *EVMExplorerClient) RequestLastDeposits(watchAddress string, startBloc
 	return &result, nil
 }
 
-func NewEVMExplorerClient() *EVMExplorerClient {
+func NewEVMExplorerClient(apiKey string) *EVMExplorerClient {
 	return &EVMExplorerClient{
-		apiKey: "TU1S16Q38IJJA5A6SKZ2G6R84YHVTXITQK",
+		apiKey: apiKey,
 	}
 }
 
+// SolanaDepositAwaiter watches the token account with accountSubscribe and finds the deposit
+// by parsing token instructions of every transaction that touched the account since BlockStart slot
 type SolanaDepositAwaiter struct {
 	nodeURL       string
+	wsURL         string
 	crossChainCfg *CrossChainDepositAwaiterConfig
 	client        *solclient.Client
 	ctx           context.Context
+
+	// lastSignature is the newest scanned transaction, the next scan stops there
+	lastSignature string
+	lastSlot      uint64
+
+	// Commitment the deposit is reported at, finalized by default
+	Commitment solclient.Commitment
 }
 
 func NewSolanaDepositAwaiter(nodeURL string) *SolanaDepositAwaiter {
 	client := solclient.NewClient(nodeURL)
 
-	return &SolanaDepositAwaiter{nodeURL: nodeURL, client: client, ctx: context.Background()}
+	return &SolanaDepositAwaiter{
+		nodeURL:    nodeURL,
+		wsURL:      inferWebSocketURL(nodeURL),
+		client:     client,
+		ctx:        context.Background(),
+		Commitment: solclient.CommitmentFinalized,
+	}
+}
+
+// inferWebSocketURL follows solana cli convention: ws scheme and rpc port + 1
+func inferWebSocketURL(nodeURL string) string {
+	u, err := url.Parse(nodeURL)
+	if err != nil {
+		return nodeURL
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		u.Host = fmt.Sprintf("%v:%v", u.Hostname(), port+1)
+	}
+
+	return u.String()
+}
+
+func (sda *SolanaDepositAwaiter) SetWebSocketURL(wsURL string) {
+	sda.wsURL = wsURL
+}
+
+func (sda *SolanaDepositAwaiter) SetContext(ctx context.Context) {
+	sda.ctx = ctx
 }
 
 func (sda *SolanaDepositAwaiter) SetCfg(cfg *CrossChainDepositAwaiterConfig) {
 	sda.crossChainCfg = cfg
+	sda.lastSignature = ""
+	sda.lastSlot = 0
+}
+
+// LastSlot is the slot of the latest account notification
+func (sda *SolanaDepositAwaiter) LastSlot() uint64 {
+	return sda.lastSlot
 }
 
 func (sda *SolanaDepositAwaiter) AwaitTokenDeposit(pipe chan<- interface{}) error {
+	return sda.AwaitTokenDepositWithContext(sda.ctx, pipe)
+}
+
+// AwaitTokenDepositWithContext sends the matched SolanaTokenDeposit to the pipe and closes it.
+// Zero BlockStart means the deposit is expected after the current slot.
+// PerAwaitTimeout, when set, rescans the account even without notifications (i.e. after reconnect).
+func (sda *SolanaDepositAwaiter) AwaitTokenDepositWithContext(ctx context.Context, pipe chan<- interface{}) error {
+	defer close(pipe)
+
 	if sda.crossChainCfg == nil {
 		return fmt.Errorf("cross chain cfg is not set")
 	}
 
-	var prevTokenAccountState *soltoken.TokenAccount
-
-	for {
-		tokenAccountState, err := sda.RequestTokenDataAccount()
+	startSlot := sda.crossChainCfg.BlockStart
+	if startSlot == 0 {
+		epochInfo, err := sda.client.GetEpochInfo(ctx, sda.Commitment)
 		if err != nil {
 			return err
 		}
+		startSlot = uint64(epochInfo.AbsoluteSlot)
+	}
 
-		if prevTokenAccountState == nil {
-			prevTokenAccountState = tokenAccountState
-			time.Sleep(sda.crossChainCfg.PerAwaitTimeout)
-			continue
-		}
+	wsClient, err := ws.Dial(ctx, sda.wsURL)
+	if err != nil {
+		return err
+	}
+	defer wsClient.Close()
 
-		balanceDiff := tokenAccountState.Amount - prevTokenAccountState.Amount
+	accountSub, err := wsClient.AccountSubscribe(ctx, sda.crossChainCfg.WatchAddress, string(sda.Commitment))
+	if err != nil {
+		return err
+	}
+	defer accountSub.Unsubscribe(context.Background())
 
-		prevTokenAccountState = tokenAccountState
+	var rescan <-chan time.Time
+	if sda.crossChainCfg.PerAwaitTimeout > 0 {
+		ticker := time.NewTicker(sda.crossChainCfg.PerAwaitTimeout)
+		defer ticker.Stop()
+		rescan = ticker.C
+	}
 
-		if balanceDiff != sda.crossChainCfg.WatchAmount.Uint64() {
-			time.Sleep(sda.crossChainCfg.PerAwaitTimeout)
-			continue
+	// the deposit may have landed before the subscription was made
+	deposit, err := sda.scanDeposits(ctx, startSlot)
+
+	for deposit == nil && err == nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case notification, ok := <-accountSub.C:
+			if !ok {
+				if err := accountSub.Err(); err != nil {
+					return err
+				}
+				return ws.ErrSubscriptionClosed
+			}
+			if notification.Context.Slot < startSlot {
+				continue
+			}
+			sda.lastSlot = notification.Context.Slot
+		case <-rescan:
 		}
 
-		var result interface{}
-		result = *tokenAccountState
+		deposit, err = sda.scanDeposits(ctx, startSlot)
+	}
+	if err != nil {
+		return err
+	}
 
-		pipe <- result
-		close(pipe)
+	select {
+	case pipe <- *deposit:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// scanDeposits walks account signatures from the newest down to the last scanned one or the start slot,
+// then inspects transactions oldest first
+func (sda *SolanaDepositAwaiter) scanDeposits(ctx context.Context, startSlot uint64) (*SolanaTokenDeposit, error) {
+	const pageLimit = 100
+
+	var signatures []solclient.GetConfirmedSignaturesForAddress
+	before := ""
+
+	for {
+		page, err := sda.client.GetConfirmedSignaturesForAddress(ctx, sda.crossChainCfg.WatchAddress, solclient.GetConfirmedSignaturesForAddressConfig{
+			Limit:      pageLimit,
+			Before:     before,
+			Until:      sda.lastSignature,
+			Commitment: sda.Commitment,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		reachedStart := false
+		for _, signature := range page {
+			if uint64(signature.Slot) < startSlot {
+				reachedStart = true
+				break
+			}
+			signatures = append(signatures, signature)
+		}
+
+		if reachedStart || len(page) < pageLimit {
+			break
+		}
+		before = page[len(page)-1].Signature
+	}
+
+	for i := len(signatures) - 1; i >= 0; i-- {
+		signature := signatures[i]
+
+		if signature.Err == nil {
+			tx, err := executor.GetTransaction(ctx, sda.nodeURL, signature.Signature, sda.Commitment)
+			if err != nil {
+				return nil, err
+			}
+			if tx == nil {
+				// listed but not served yet, retry on the next scan
+				return nil, nil
+			}
+
+			for _, deposit := range ParseTokenDeposits(*tx, sda.crossChainCfg.WatchAddress) {
+				if deposit.Matches(sda.crossChainCfg) {
+					sda.lastSignature = signature.Signature
+					return &deposit, nil
+				}
+			}
+		}
+
+		sda.lastSignature = signature.Signature
 	}
+
+	return nil, nil
 }
 
 func (sda *SolanaDepositAwaiter) RequestTokenDataAccount() (*soltoken.TokenAccount, error) {
@@ -0,0 +1,115 @@
+package mvp
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
+	solcommon "github.com/portto/solana-go-sdk/common"
+	soltoken "github.com/portto/solana-go-sdk/tokenprog"
+)
+
+// SolanaTokenDeposit is a single SPL token transfer or mint found in a transaction
+type SolanaTokenDeposit struct {
+	Signature   string
+	Slot        uint64
+	Instruction soltoken.Instruction
+	Source      string
+	Destination string
+	// Mint is empty for plain Transfer, the instruction does not reference it
+	Mint   string
+	Amount uint64
+}
+
+// ParseTokenDeposits extracts token program transfers and mints into the destination,
+// both top level and inner (CPI) instructions are inspected
+func ParseTokenDeposits(tx solclient.GetConfirmedTransactionResponse, destination string) []SolanaTokenDeposit {
+	if tx.Meta.Err != nil {
+		return nil
+	}
+
+	instructions := append([]solclient.Instruction{}, tx.Transaction.Message.Instructions...)
+	for _, inner := range tx.Meta.InnerInstructions {
+		instructions = append(instructions, inner.Instructions...)
+	}
+
+	var signature string
+	if len(tx.Transaction.Signatures) > 0 {
+		signature = tx.Transaction.Signatures[0]
+	}
+
+	keys := tx.Transaction.Message.AccountKeys
+	var deposits []SolanaTokenDeposit
+
+	for _, ix := range instructions {
+		deposit, ok := parseTokenInstruction(keys, ix)
+		if !ok || deposit.Destination != destination {
+			continue
+		}
+
+		deposit.Signature = signature
+		deposit.Slot = tx.Slot
+		deposits = append(deposits, deposit)
+	}
+
+	return deposits
+}
+
+func parseTokenInstruction(keys []string, ix solclient.Instruction) (SolanaTokenDeposit, bool) {
+	if int(ix.ProgramIDIndex) >= len(keys) || keys[ix.ProgramIDIndex] != solcommon.TokenProgramID.ToBase58() {
+		return SolanaTokenDeposit{}, false
+	}
+
+	data, err := base58.Decode(ix.Data)
+	if err != nil || len(data) < 9 {
+		return SolanaTokenDeposit{}, false
+	}
+
+	account := func(i int) string {
+		if i >= len(ix.Accounts) || int(ix.Accounts[i]) >= len(keys) {
+			return ""
+		}
+		return keys[ix.Accounts[i]]
+	}
+
+	deposit := SolanaTokenDeposit{
+		Instruction: soltoken.Instruction(data[0]),
+		Amount:      binary.LittleEndian.Uint64(data[1:9]),
+	}
+
+	switch deposit.Instruction {
+	case soltoken.InstructionTransfer:
+		// source, destination, authority
+		deposit.Source, deposit.Destination = account(0), account(1)
+	case soltoken.InstructionTransferChecked:
+		// source, mint, destination, authority
+		deposit.Source, deposit.Mint, deposit.Destination = account(0), account(1), account(2)
+	case soltoken.InstructionMintTo, soltoken.InstructionMintToChecked:
+		// mint, destination, authority
+		deposit.Mint, deposit.Destination = account(0), account(1)
+	default:
+		return SolanaTokenDeposit{}, false
+	}
+
+	if deposit.Destination == "" {
+		return SolanaTokenDeposit{}, false
+	}
+
+	return deposit, true
+}
+
+// Matches reports whether the deposit is the awaited one, the mint is checked when the instruction carries it
+func (deposit *SolanaTokenDeposit) Matches(cfg *CrossChainDepositAwaiterConfig) bool {
+	if deposit.Destination != cfg.WatchAddress {
+		return false
+	}
+	if cfg.WatchAssetID != "" && deposit.Mint != "" && deposit.Mint != cfg.WatchAssetID {
+		return false
+	}
+	if cfg.WatchAmount == nil || !cfg.WatchAmount.IsUint64() {
+		return false
+	}
+
+	return new(big.Int).SetUint64(deposit.Amount).Cmp(cfg.WatchAmount) == 0
+}
@@ -0,0 +1,98 @@
+package mvp
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
+	solcommon "github.com/portto/solana-go-sdk/common"
+	soltoken "github.com/portto/solana-go-sdk/tokenprog"
+)
+
+func tokenInstructionData(instruction soltoken.Instruction, amount uint64, extra ...byte) string {
+	data := make([]byte, 9)
+	data[0] = byte(instruction)
+	binary.LittleEndian.PutUint64(data[1:], amount)
+
+	return base58.Encode(append(data, extra...))
+}
+
+func TestParseTokenDeposits(t *testing.T) {
+	const (
+		watched = "FMtjwGs2V6j3eWvZhLA18tkHuzvBHfpjFcCuuvsweuwC"
+		mint    = "nVZnRKdr3pmcgnJvYDE8iafgiMiBqxiffQMcyv5ETdA"
+		other   = "9kwBfNbrQAEmEqkZbvMCKkefuJBj7nuqWrq6dzUhW5fJ"
+		source  = "AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ"
+		owner   = "BPFLoaderUpgradeab1e11111111111111111111111"
+	)
+
+	var tx solclient.GetConfirmedTransactionResponse
+	tx.Slot = 42
+	tx.Transaction.Signatures = []string{"sig"}
+	tx.Transaction.Message.AccountKeys = []string{owner, source, watched, mint, other, solcommon.TokenProgramID.ToBase58()}
+	tx.Transaction.Message.Instructions = []solclient.Instruction{
+		// two transfers into the watched account in one transaction
+		{ProgramIDIndex: 5, Accounts: []uint64{1, 2, 0}, Data: tokenInstructionData(soltoken.InstructionTransfer, 10)},
+		{ProgramIDIndex: 5, Accounts: []uint64{1, 3, 2, 0}, Data: tokenInstructionData(soltoken.InstructionTransferChecked, 20, 8)},
+		// transfer elsewhere
+		{ProgramIDIndex: 5, Accounts: []uint64{1, 4, 0}, Data: tokenInstructionData(soltoken.InstructionTransfer, 30)},
+		// not a token program instruction
+		{ProgramIDIndex: 0, Accounts: []uint64{1, 2}, Data: tokenInstructionData(soltoken.InstructionTransfer, 40)},
+		// approve is not a deposit
+		{ProgramIDIndex: 5, Accounts: []uint64{2, 1, 0}, Data: tokenInstructionData(soltoken.InstructionApprove, 50)},
+	}
+	tx.Meta.InnerInstructions = []struct {
+		Index        uint64                  `json:"index"`
+		Instructions []solclient.Instruction `json:"instructions"`
+	}{
+		{Index: 0, Instructions: []solclient.Instruction{
+			{ProgramIDIndex: 5, Accounts: []uint64{3, 2, 0}, Data: tokenInstructionData(soltoken.InstructionMintTo, 60)},
+		}},
+	}
+
+	deposits := ParseTokenDeposits(tx, watched)
+
+	expected := []SolanaTokenDeposit{
+		{Signature: "sig", Slot: 42, Instruction: soltoken.InstructionTransfer, Source: source, Destination: watched, Amount: 10},
+		{Signature: "sig", Slot: 42, Instruction: soltoken.InstructionTransferChecked, Source: source, Mint: mint, Destination: watched, Amount: 20},
+		{Signature: "sig", Slot: 42, Instruction: soltoken.InstructionMintTo, Mint: mint, Destination: watched, Amount: 60},
+	}
+	if len(deposits) != len(expected) {
+		t.Fatalf("expected %d deposits, got %+v", len(expected), deposits)
+	}
+	for i := range expected {
+		if deposits[i] != expected[i] {
+			t.Fatalf("deposit #%d: expected %+v, got %+v", i, expected[i], deposits[i])
+		}
+	}
+
+	cfg := &CrossChainDepositAwaiterConfig{WatchAddress: watched, WatchAssetID: mint, WatchAmount: big.NewInt(20)}
+	if !deposits[1].Matches(cfg) || deposits[0].Matches(cfg) {
+		t.Fatalf("deposit is matched by amount")
+	}
+
+	cfg.WatchAssetID = other
+	if deposits[1].Matches(cfg) {
+		t.Fatalf("deposit of another mint is matched")
+	}
+
+	tx.Meta.Err = map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}
+	if len(ParseTokenDeposits(tx, watched)) != 0 {
+		t.Fatalf("failed transaction is parsed")
+	}
+}
+
+func TestInferWebSocketURL(t *testing.T) {
+	cases := map[string]string{
+		"http://127.0.0.1:8899":               "ws://127.0.0.1:8900",
+		"https://api.mainnet-beta.solana.com": "wss://api.mainnet-beta.solana.com",
+	}
+
+	for rpc, expected := range cases {
+		if actual := inferWebSocketURL(rpc); actual != expected {
+			t.Errorf("%v: expected %v, got %v", rpc, expected, actual)
+		}
+	}
+}
@@ -9,30 +9,76 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-type MVPInputConfig struct {
-	SolanaRecipient string
-}
-
 func RunMVP() {
-	mvpCfg := &MVPInputConfig{
-		SolanaRecipient: "98tnzivwLxfb7ThDegaZciHF6Dzk99q8Fr9F5ZksVcgN",
-	}
+	var configPath string
 
 	app := &cli.App{
 		Name:  "Polygon -> Solana MVP",
 		Usage: "This App shows how fast cross-chain swaps occur (SuSy Wrapped $GTON)",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:        "sol-recipient",
-				Value:       "98tnzivwLxfb7ThDegaZciHF6Dzk99q8Fr9F5ZksVcgN",
-				Usage:       "Solana $GTON Recipient",
-				Destination: &mvpCfg.SolanaRecipient,
+				Name:        "config",
+				Usage:       "MVP config path (yaml or json), MVP_* environment variables override it",
+				Destination: &configPath,
+			},
+			&cli.StringFlag{
+				Name:  "sol-recipient",
+				Usage: "Solana $GTON Recipient token account, owned by the Solana holder",
+			},
+			&cli.StringFlag{
+				Name:  "direction",
+				Usage: "Swap direction: round-trip, forward or backward",
+			},
+			&cli.StringFlag{
+				Name:  "amount",
+				Usage: "Amount in origin token units, random if omitted",
+			},
+			&cli.StringFlag{
+				Name:  "report",
+				Usage: "Swap report path, both <path>.json with the run and <path>.csv with the history of runs are written",
 			},
 		},
 		Action: func(c *cli.Context) error {
-			err := ProcessMVP_PolygonSolana()
-			fmt.Printf("Error occured during MVP: %v \n", err)
-			debug.PrintStack()
+			cfg, err := ReadMVPConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			// flags take precedence over both the config file and the environment
+			for flag, target := range map[string]*string{
+				"sol-recipient": &cfg.Destination.TokenAccount,
+				"direction":     &cfg.Direction,
+				"amount":        &cfg.Amount,
+				"report":        &cfg.ReportPath,
+			} {
+				if c.IsSet(flag) {
+					*target = c.String(flag)
+				}
+			}
+
+			report, err := ProcessMVP_PolygonSolana(cfg)
+			if err != nil {
+				fmt.Printf("Error occured during MVP: %v \n", err)
+				debug.PrintStack()
+			}
+			if report == nil {
+				return err
+			}
+
+			for _, step := range report.Steps {
+				fmt.Printf("%v (%v): %v at %v - %v \n", step.Kind, step.Chain, step.TxID, step.Block, step.Duration)
+			}
+			fmt.Printf("Total: %v, fees: %v \n", report.Duration, report.Fees)
+
+			if cfg.ReportPath != "" {
+				if saveErr := SaveSwapReport(cfg.ReportPath, report); saveErr != nil {
+					fmt.Printf("save report error, err: %v\n", saveErr)
+					if err == nil {
+						err = saveErr
+					}
+				}
+			}
+
 			return err
 		},
 	}
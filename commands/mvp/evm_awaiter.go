@@ -0,0 +1,293 @@
+package mvp
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// DefaultEVMConfirmations is enough for Polygon and BSC, local dev nodes may use 0
+	DefaultEVMConfirmations = 12
+	// DefaultEVMMaxBlockRange keeps eth_getLogs under the range limit of public nodes
+	DefaultEVMMaxBlockRange = 2000
+	DefaultEVMPollInterval  = time.Second * 5
+)
+
+var (
+	// ERC20TransferTopic is keccak256 of Transfer(address indexed from, address indexed to, uint256 value)
+	ERC20TransferTopic = ethcrypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	// LUPortRequestCreatedTopic is keccak256 of the LU Port lock event, none of its fields are indexed:
+	//
+	//	event RequestCreated(uint, address, bytes32, uint);
+	//	emit RequestCreated(id, msg.sender, receiver, amount);
+	LUPortRequestCreatedTopic = ethcrypto.Keccak256Hash([]byte("RequestCreated(uint256,address,bytes32,uint256)"))
+)
+
+// EVMChainReader is the part of ethclient.Client the awaiter relies on
+type EVMChainReader interface {
+	ethereum.LogFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+type EVMDepositEventKind uint8
+
+const (
+	EVMERC20Transfer EVMDepositEventKind = iota
+	EVMLUPortRequestCreated
+)
+
+// EVMDepositEvent is a decoded ERC-20 Transfer or LU Port RequestCreated log
+type EVMDepositEvent struct {
+	Kind        EVMDepositEventKind
+	BlockNumber uint64
+	BlockHash   ethcommon.Hash
+	TxHash      ethcommon.Hash
+	LogIndex    uint
+	Contract    ethcommon.Address
+	From        ethcommon.Address
+	// To is the ERC-20 recipient, empty for LU Port requests
+	To     ethcommon.Address
+	Amount *big.Int
+	// RequestID and Receiver are set for LU Port requests only
+	RequestID *big.Int
+	Receiver  [32]byte
+}
+
+// DecodeEVMDepositEvent decodes the log by its first topic
+func DecodeEVMDepositEvent(log ethtypes.Log) (*EVMDepositEvent, error) {
+	if len(log.Topics) == 0 {
+		return nil, fmt.Errorf("log has no topics")
+	}
+
+	event := &EVMDepositEvent{
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+		Contract:    log.Address,
+	}
+
+	switch log.Topics[0] {
+	case ERC20TransferTopic:
+		if len(log.Topics) != 3 || len(log.Data) != 32 {
+			return nil, fmt.Errorf("malformed Transfer log")
+		}
+		event.Kind = EVMERC20Transfer
+		event.From = ethcommon.BytesToAddress(log.Topics[1].Bytes())
+		event.To = ethcommon.BytesToAddress(log.Topics[2].Bytes())
+		event.Amount = new(big.Int).SetBytes(log.Data)
+	case LUPortRequestCreatedTopic:
+		if len(log.Data) != 32*4 {
+			return nil, fmt.Errorf("malformed RequestCreated log")
+		}
+		event.Kind = EVMLUPortRequestCreated
+		event.RequestID = new(big.Int).SetBytes(log.Data[:32])
+		event.From = ethcommon.BytesToAddress(log.Data[32:64])
+		copy(event.Receiver[:], log.Data[64:96])
+		event.Amount = new(big.Int).SetBytes(log.Data[96:128])
+	default:
+		return nil, fmt.Errorf("unknown event topic: %v", log.Topics[0].Hex())
+	}
+
+	return event, nil
+}
+
+// EVMLogsDepositAwaiter finds deposits with eth_getLogs on any EVM node.
+// Only blocks buried under Confirmations are scanned and the matched block is checked to be canonical,
+// so a reorged deposit is never reported.
+//
+// For EVMERC20Transfer WatchAddress is the recipient and WatchAssetID is the token.
+// For EVMLUPortRequestCreated WatchAddress is the LU Port contract.
+type EVMLogsDepositAwaiter struct {
+	client        EVMChainReader
+	kind          EVMDepositEventKind
+	crossChainCfg *CrossChainDepositAwaiterConfig
+	ctx           context.Context
+
+	Confirmations uint64
+	MaxBlockRange uint64
+
+	// nextBlock is the first block not scanned yet
+	nextBlock uint64
+}
+
+func NewEVMLogsDepositAwaiter(client EVMChainReader, kind EVMDepositEventKind) *EVMLogsDepositAwaiter {
+	return &EVMLogsDepositAwaiter{
+		client:        client,
+		kind:          kind,
+		ctx:           context.Background(),
+		Confirmations: DefaultEVMConfirmations,
+		MaxBlockRange: DefaultEVMMaxBlockRange,
+	}
+}
+
+func (ela *EVMLogsDepositAwaiter) SetContext(ctx context.Context) {
+	ela.ctx = ctx
+}
+
+func (ela *EVMLogsDepositAwaiter) SetCfg(cfg *CrossChainDepositAwaiterConfig) {
+	ela.crossChainCfg = cfg
+	ela.nextBlock = cfg.BlockStart
+}
+
+func (ela *EVMLogsDepositAwaiter) AwaitTokenDeposit(pipe chan<- interface{}) error {
+	return ela.AwaitTokenDepositWithContext(ela.ctx, pipe)
+}
+
+// AwaitTokenDepositWithContext sends the matched *EVMDepositEvent to the pipe and closes it
+func (ela *EVMLogsDepositAwaiter) AwaitTokenDepositWithContext(ctx context.Context, pipe chan<- interface{}) error {
+	defer close(pipe)
+
+	if ela.crossChainCfg == nil {
+		return fmt.Errorf("cross chain cfg is not set")
+	}
+
+	pollInterval := ela.crossChainCfg.PerAwaitTimeout
+	if pollInterval == 0 {
+		pollInterval = DefaultEVMPollInterval
+	}
+
+	for {
+		event, err := ela.scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		if event != nil {
+			select {
+			case pipe <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (ela *EVMLogsDepositAwaiter) query(from, to uint64) ethereum.FilterQuery {
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+	}
+
+	switch ela.kind {
+	case EVMLUPortRequestCreated:
+		query.Addresses = []ethcommon.Address{ethcommon.HexToAddress(ela.crossChainCfg.WatchAddress)}
+		query.Topics = [][]ethcommon.Hash{{LUPortRequestCreatedTopic}}
+	default:
+		query.Addresses = []ethcommon.Address{ethcommon.HexToAddress(ela.crossChainCfg.WatchAssetID)}
+		query.Topics = [][]ethcommon.Hash{
+			{ERC20TransferTopic},
+			nil,
+			{ethcommon.BytesToHash(ethcommon.HexToAddress(ela.crossChainCfg.WatchAddress).Bytes())},
+		}
+	}
+
+	return query
+}
+
+// scan walks the confirmed blocks from nextBlock in MaxBlockRange chunks
+func (ela *EVMLogsDepositAwaiter) scan(ctx context.Context) (*EVMDepositEvent, error) {
+	head, err := ela.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	headNumber := head.Number.Uint64()
+	if headNumber < ela.Confirmations {
+		return nil, nil
+	}
+	safeHead := headNumber - ela.Confirmations
+
+	maxRange := ela.MaxBlockRange
+	if maxRange == 0 {
+		maxRange = DefaultEVMMaxBlockRange
+	}
+
+	for ela.nextBlock <= safeHead {
+		to := ela.nextBlock + maxRange - 1
+		if to > safeHead {
+			to = safeHead
+		}
+
+		logs, err := ela.client.FilterLogs(ctx, ela.query(ela.nextBlock, to))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, log := range logs {
+			event, ok := ela.match(log)
+			if !ok {
+				continue
+			}
+
+			canonical, err := ela.isCanonical(ctx, event)
+			if err != nil {
+				return nil, err
+			}
+			if canonical {
+				ela.nextBlock = event.BlockNumber + 1
+				return event, nil
+			}
+
+			// the range is reorged under us, rescan it on the next poll
+			return nil, nil
+		}
+
+		ela.nextBlock = to + 1
+	}
+
+	return nil, nil
+}
+
+func (ela *EVMLogsDepositAwaiter) match(log ethtypes.Log) (*EVMDepositEvent, bool) {
+	if log.Removed {
+		return nil, false
+	}
+
+	event, err := DecodeEVMDepositEvent(log)
+	if err != nil || event.Kind != ela.kind {
+		return nil, false
+	}
+
+	// addresses are compared as bytes, checksum casing does not matter
+	switch ela.kind {
+	case EVMLUPortRequestCreated:
+		if event.Contract != ethcommon.HexToAddress(ela.crossChainCfg.WatchAddress) {
+			return nil, false
+		}
+	default:
+		if event.Contract != ethcommon.HexToAddress(ela.crossChainCfg.WatchAssetID) ||
+			event.To != ethcommon.HexToAddress(ela.crossChainCfg.WatchAddress) {
+			return nil, false
+		}
+	}
+
+	if ela.crossChainCfg.WatchAmount == nil || event.Amount.Cmp(ela.crossChainCfg.WatchAmount) != 0 {
+		return nil, false
+	}
+
+	return event, true
+}
+
+func (ela *EVMLogsDepositAwaiter) isCanonical(ctx context.Context, event *EVMDepositEvent) (bool, error) {
+	header, err := ela.client.HeaderByNumber(ctx, new(big.Int).SetUint64(event.BlockNumber))
+	if err != nil {
+		return false, err
+	}
+
+	return header.Hash() == event.BlockHash, nil
+}
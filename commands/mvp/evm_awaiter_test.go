@@ -0,0 +1,178 @@
+package mvp
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeEVMChain serves logs of its blocks, header hashes are derived from the header extra data
+type fakeEVMChain struct {
+	head    uint64
+	headers map[uint64]*ethtypes.Header
+	logs    []ethtypes.Log
+	queries []ethereum.FilterQuery
+}
+
+func newFakeEVMChain(head uint64) *fakeEVMChain {
+	chain := &fakeEVMChain{head: head, headers: make(map[uint64]*ethtypes.Header)}
+	for i := uint64(0); i <= head; i++ {
+		chain.headers[i] = &ethtypes.Header{Number: new(big.Int).SetUint64(i), Extra: []byte{byte(i)}}
+	}
+	return chain
+}
+
+func (chain *fakeEVMChain) HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error) {
+	if number == nil {
+		return chain.headers[chain.head], nil
+	}
+	return chain.headers[number.Uint64()], nil
+}
+
+func (chain *fakeEVMChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	chain.queries = append(chain.queries, q)
+
+	var result []ethtypes.Log
+	for _, log := range chain.logs {
+		if log.BlockNumber >= q.FromBlock.Uint64() && log.BlockNumber <= q.ToBlock.Uint64() && log.Address == q.Addresses[0] {
+			result = append(result, log)
+		}
+	}
+	return result, nil
+}
+
+func (chain *fakeEVMChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error) {
+	panic("not used")
+}
+
+func (chain *fakeEVMChain) transfer(block uint64, token, from, to ethcommon.Address, amount int64) {
+	chain.logs = append(chain.logs, ethtypes.Log{
+		Address:     token,
+		Topics:      []ethcommon.Hash{ERC20TransferTopic, ethcommon.BytesToHash(from.Bytes()), ethcommon.BytesToHash(to.Bytes())},
+		Data:        ethcommon.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+		BlockNumber: block,
+		BlockHash:   chain.headers[block].Hash(),
+		TxHash:      ethcommon.BytesToHash([]byte{byte(block), byte(len(chain.logs))}),
+	})
+}
+
+func TestEVMLogsDepositAwaiterTransfer(t *testing.T) {
+	token := ethcommon.HexToAddress("0xf480f38c366daac4305dc484b2ad7a496ff00cea")
+	holder := ethcommon.HexToAddress("0xbbc3d3f8c70c1a558bd0b5c25662aa3226b863e9")
+	port := ethcommon.HexToAddress("0xD2C80231a5E1C7B621c2bb96819b20a00E1be7D2")
+
+	chain := newFakeEVMChain(120)
+	// before BlockStart
+	chain.transfer(5, token, port, holder, 437)
+	// wrong amount
+	chain.transfer(20, token, port, holder, 1)
+	// matching, but not confirmed yet
+	chain.transfer(115, token, port, holder, 437)
+
+	awaiter := NewEVMLogsDepositAwaiter(chain, EVMERC20Transfer)
+	awaiter.Confirmations = 10
+	awaiter.MaxBlockRange = 50
+	// checksum casing differs from the logs on purpose
+	awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+		WatchAddress: strings.ToUpper(holder.Hex()[2:]),
+		WatchAssetID: token.Hex(),
+		WatchAmount:  big.NewInt(437),
+		BlockStart:   10,
+	})
+
+	event, err := awaiter.scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event != nil {
+		t.Fatalf("unconfirmed or mismatched transfer is reported: %+v", event)
+	}
+	// 10..110 scanned in chunks of 50
+	if len(chain.queries) != 3 || chain.queries[2].ToBlock.Uint64() != 110 {
+		t.Fatalf("unexpected queries: %+v", chain.queries)
+	}
+
+	// the chain grows and the transfer gets enough confirmations
+	for i := uint64(121); i <= 125; i++ {
+		chain.headers[i] = &ethtypes.Header{Number: new(big.Int).SetUint64(i)}
+	}
+	chain.head = 125
+
+	pipe := make(chan interface{}, 1)
+	awaiter.crossChainCfg.PerAwaitTimeout = time.Millisecond
+	if err := awaiter.AwaitTokenDepositWithContext(context.Background(), pipe); err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := (<-pipe).(*EVMDepositEvent)
+	if deposit.BlockNumber != 115 || deposit.From != port || deposit.To != holder || deposit.Amount.Int64() != 437 {
+		t.Fatalf("unexpected deposit: %+v", deposit)
+	}
+	if _, ok := <-pipe; ok {
+		t.Fatal("pipe is not closed")
+	}
+}
+
+func TestEVMLogsDepositAwaiterSkipsReorgedBlock(t *testing.T) {
+	token := ethcommon.HexToAddress("0xf480f38c366daac4305dc484b2ad7a496ff00cea")
+	holder := ethcommon.HexToAddress("0xbbc3d3f8c70c1a558bd0b5c25662aa3226b863e9")
+
+	chain := newFakeEVMChain(50)
+	chain.transfer(30, token, ethcommon.Address{}, holder, 7)
+
+	// block 30 is replaced, the node still serves the stale log
+	chain.headers[30] = &ethtypes.Header{Number: big.NewInt(30), Extra: []byte("reorg")}
+
+	awaiter := NewEVMLogsDepositAwaiter(chain, EVMERC20Transfer)
+	awaiter.Confirmations = 0
+	awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+		WatchAddress: holder.Hex(),
+		WatchAssetID: token.Hex(),
+		WatchAmount:  big.NewInt(7),
+		BlockStart:   1,
+	})
+
+	event, err := awaiter.scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event != nil {
+		t.Fatalf("reorged transfer is reported: %+v", event)
+	}
+	if awaiter.nextBlock != 1 {
+		t.Fatalf("reorged range is not rescanned, next block: %v", awaiter.nextBlock)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	awaiter.crossChainCfg.PerAwaitTimeout = time.Millisecond
+	if err := awaiter.AwaitTokenDepositWithContext(ctx, make(chan interface{})); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline, got %v", err)
+	}
+}
+
+func TestDecodeLUPortRequestCreated(t *testing.T) {
+	sender := ethcommon.HexToAddress("0xbbc3d3f8c70c1a558bd0b5c25662aa3226b863e9")
+	var receiver [32]byte
+	receiver[0], receiver[31] = 1, 2
+
+	data := append(ethcommon.LeftPadBytes(big.NewInt(99).Bytes(), 32), ethcommon.LeftPadBytes(sender.Bytes(), 32)...)
+	data = append(data, receiver[:]...)
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(1000).Bytes(), 32)...)
+
+	event, err := DecodeEVMDepositEvent(ethtypes.Log{Topics: []ethcommon.Hash{LUPortRequestCreatedTopic}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Kind != EVMLUPortRequestCreated || event.RequestID.Int64() != 99 || event.From != sender ||
+		event.Receiver != receiver || event.Amount.Int64() != 1000 {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
@@ -13,37 +13,28 @@ import (
 	"github.com/Gravity-Tech/solanoid/commands/executor"
 
 	luport "github.com/Gravity-Tech/gateway/abi/ethereum/luport"
+	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	solcommon "github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/types"
 
 	ethbind "github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethclient "github.com/ethereum/go-ethereum/ethclient"
 )
 
-func waitTransactionConfirmations() {
-	// time.Sleep(time.Millisecond * 500)
-	// time.Sleep(time.Second * 5)
-	// time.Sleep(time.Second * 3) // the most safe timeout
-	time.Sleep(time.Second * 30)
-	// time.Sleep(time.Second * 25)
-	// time.Sleep(time.Second * 45)
-}
-
-type MVPConfigMeta struct {
+type gatewayMVPConfigMeta struct {
 	SolanaGTONTokenRecever string
 	PolygonGTONReceiver    string
 }
 
-type MVPConfig struct {
+type gatewayMVPConfig struct {
 	Token     *crossChainToken
 	Extractor *extractorCfg
-	Meta      *MVPConfigMeta
+	Meta      *gatewayMVPConfigMeta
 }
 
-func BuildMVPConfig() (*MVPConfig, error) {
+func buildGatewayMVPConfig() (*gatewayMVPConfig, error) {
 	gtonToken, err := NewCrossChainToken(&crossChainTokenCfg{
 		originDecimals:      18,
 		destinationDecimals: 8,
@@ -65,10 +56,10 @@ func BuildMVPConfig() (*MVPConfig, error) {
 		ibportProgramID:     "AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ",
 	}
 
-	return &MVPConfig{
+	return &gatewayMVPConfig{
 		Token:     gtonToken,
 		Extractor: extractorCfg,
-		Meta: &MVPConfigMeta{
+		Meta: &gatewayMVPConfigMeta{
 			SolanaGTONTokenRecever: "FMtjwGs2V6j3eWvZhLA18tkHuzvBHfpjFcCuuvsweuwC",
 			PolygonGTONReceiver:    "0xBbc3D3F8C70C1A558bD0B5C25662aa3226b863e9",
 		},
@@ -85,7 +76,7 @@ func BuildMVPConfig() (*MVPConfig, error) {
  *
  */
 func TestRunPolygonToSolanaGatewayMVP(t *testing.T) {
-	cfg, err := BuildMVPConfig()
+	cfg, err := buildGatewayMVPConfig()
 	commands.ValidateError(t, err)
 	gtonToken, extractorCfg := cfg.Token, cfg.Extractor
 
@@ -95,10 +86,10 @@ func TestRunPolygonToSolanaGatewayMVP(t *testing.T) {
 	polygonClient, err := ethclient.DialContext(polygonCtx, extractorCfg.originNodeURL)
 	commands.ValidateError(t, err)
 
-	polygonGTONHolder, err := newEVMKey("76b77e0673cdf31a4bbfa0f0cdd9ed1fe02f036697d91dbf6293767f630e3b47")
+	polygonGTONHolder, polygonGTONHolderAddress, err := MVPEVMConfig{PrivateKeyEnv: "MVP_TEST_POLYGON_PRIVATE_KEY"}.Key()
 	commands.ValidateError(t, err)
 
-	transactor, err := ethbind.NewKeyedTransactorWithChainID(polygonGTONHolder.PrivKey, big.NewInt(extractorCfg.chainID))
+	transactor, err := ethbind.NewKeyedTransactorWithChainID(polygonGTONHolder, big.NewInt(extractorCfg.chainID))
 	transactor.GasLimit = 10 * 150000
 	transactor.Context = polygonCtx
 
@@ -177,22 +168,21 @@ func TestRunPolygonToSolanaGatewayMVP(t *testing.T) {
 	)
 	commands.ValidateError(t, err)
 
-	polygonAddressDecoded, err := hexutil.Decode(polygonGTONHolder.Address)
-	commands.ValidateError(t, err)
+	polygonAddressDecoded := polygonGTONHolderAddress.Bytes()
 
 	var polygonTargetAddress [32]byte
 	copy(polygonTargetAddress[:], polygonAddressDecoded)
 
-	burnFundsResponse, err := ibportExecutor.BuildAndInvoke(
+	burnFundsResponse, err := ibportExecutor.BuildAndConfirm(
+		context.Background(),
 		ibportBuilder.CreateTransferUnwrapRequest(
 			polygonTargetAddress,
 			gtonToken.Float(),
 		),
+		solclient.CommitmentConfirmed,
 	)
 	commands.ValidateError(t, err)
 
-	time.Sleep(time.Second * 15)
-
 	// print
 	// (5)
 	t.Logf("Burn %v GTON tx (Solana): %v \n", gtonToken.Float(), burnFundsResponse.TxSignature)
@@ -205,7 +195,7 @@ func TestRunPolygonToSolanaGatewayMVP(t *testing.T) {
 }
 
 func TestBurn_PolygonSolana(t *testing.T) {
-	cfg, err := BuildMVPConfig()
+	cfg, err := buildGatewayMVPConfig()
 	commands.ValidateError(t, err)
 	gtonToken, extractorCfg, meta := cfg.Token, cfg.Extractor, cfg.Meta
 
@@ -225,17 +215,17 @@ func TestBurn_PolygonSolana(t *testing.T) {
 	// burnAmount := 0.00003
 
 	_ = solanaGTONTokenAccount
+	// the token account is owned by the keypair of solana CLI config, the holder pays the fee
+	solanaCfg, err := commands.ReadSystemSolanaConfig()
+	commands.ValidateError(t, err)
+
 	// delegate amount to port BINARY for burning and request creation
-	err = commands.DelegateSPLTokenAmountWithFeePayer(solanaGTONHolder.PKPath, solanaGTONTokenAccount, ibPortPDA.ToBase58(), burnAmount)
+	err = commands.DelegateSPLTokenAmountWithFeePayer(solanaGTONHolder.PKPath, solanaCfg.KeypairPath, solanaGTONTokenAccount, ibPortPDA.ToBase58(), burnAmount)
 	commands.ValidateError(t, err)
 
 	t.Log("Delegated some tokens to ibport from  deployer")
 	t.Log("Creating cross chain transfer tx")
 
-	// executor.waitTransactionConfirmations()
-	// waitTransactionConfirmations()
-	time.Sleep(time.Second * 30)
-
 	// ethReceiverPK, err := ethcrypto.GenerateKey()
 	// commands.ValidateError(t, err)
 
@@ -264,8 +254,10 @@ func TestBurn_PolygonSolana(t *testing.T) {
 		{PubKey: ibPortPDA, IsWritable: false, IsSigner: false},
 	})
 
-	ibportCreateTransferUnwrapRequestResult, err := ibportExecutor.BuildAndInvoke(
+	ibportCreateTransferUnwrapRequestResult, err := ibportExecutor.BuildAndConfirm(
+		context.Background(),
 		executor.IBPortIXBuilder.CreateTransferUnwrapRequest(ethReceiverAddress, burnAmount),
+		solclient.CommitmentConfirmed,
 	)
 	commands.ValidateError(t, err)
 
@@ -2,30 +2,19 @@ package mvp
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"math/rand"
-	"os"
 	"time"
 
-	erc20 "github.com/Gravity-Tech/gateway/abi/ethereum/erc20"
-	commands "github.com/Gravity-Tech/solanoid/commands"
 	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/tokens"
 
-	luport "github.com/Gravity-Tech/gateway/abi/ethereum/luport"
-	"github.com/portto/solana-go-sdk/common"
 	solcommon "github.com/portto/solana-go-sdk/common"
-	soltoken "github.com/portto/solana-go-sdk/tokenprog"
-	"github.com/portto/solana-go-sdk/types"
-	soltypes "github.com/portto/solana-go-sdk/types"
 
 	ethbind "github.com/ethereum/go-ethereum/accounts/abi/bind"
-	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethclient "github.com/ethereum/go-ethereum/ethclient"
-	"github.com/mr-tron/base58"
 )
 
 /*
@@ -37,274 +26,115 @@ import (
  * 5. Print TX of burn and unlock.
  *
  */
-func ProcessMVP_PolygonSolana() error {
-	gtonToken, err := NewCrossChainToken(&crossChainTokenCfg{
-		originDecimals:      18,
-		destinationDecimals: 8,
-		originAddress:       "0xf480f38c366daac4305dc484b2ad7a496ff00cea",
-		destinationAddress:  "nVZnRKdr3pmcgnJvYDE8iafgiMiBqxiffQMcyv5ETdA",
-	}, 0)
-
-	if err != nil {
-		return err
-	}
-
-	// IBPortProgramPDA := solcommon.PublicKeyFromString("CYEnZhJdYaUjgFtGQ2FgXe4vp4zMiqY8RsdqwNFduxdm")
-
-	extractorCfg := &extractorCfg{
-		originDecimals:      18,
-		destinationDecimals: 8,
-		chainID:             137,
-		originNodeURL:       "https://rpc-mainnet.maticvigil.com",
-		// originNodeURL: "https://rpc-mainnet.matic.quiknode.pro",
-		// originNodeURL: "https://apis.ankr.com/6052791850e6426392593b0ddba45bf5/d37735e535d9d051230799cae45aeb6a/polygon/full/main",
-		// originNodeURL: "https://matic-mainnet.chainstacklabs.com",
-		destinationNodeURL: "https://api.mainnet-beta.solana.com",
-		luportAddress:      "0xD2C80231a5E1C7B621c2bb96819b20a00E1be7D2",
-		ibportDataAccount:  "9kwBfNbrQAEmEqkZbvMCKkefuJBj7nuqWrq6dzUhW5fJ",
-		ibportProgramID:    "AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ",
-	}
-
-	polygonCtx, cancelCtx := context.WithCancel(context.Background())
-	defer cancelCtx()
-
-	polygonClient, err := ethclient.DialContext(polygonCtx, extractorCfg.originNodeURL)
-	if err != nil {
-		return err
+func ProcessMVP_PolygonSolana(cfg *MVPConfig) (*SwapReport, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
-	polygonGTONHolder, err := newEVMKey("76b77e0673cdf31a4bbfa0f0cdd9ed1fe02f036697d91dbf6293767f630e3b47")
+	direction, err := ParseSwapDirection(cfg.Direction)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	transactor, err := ethbind.NewKeyedTransactorWithChainID(polygonGTONHolder.PrivKey, big.NewInt(extractorCfg.chainID))
-	transactor.GasLimit = 1_000_000
-	// transactor.GasPrice = big.NewInt(30000)
-	transactor.Context = polygonCtx
-
+	amountFormat, err := executor.ParseAmountFormat(cfg.Destination.AmountFormat)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	polygonTransactor := NewEVMTransactor(polygonClient, transactor)
+	ctx := context.Background()
 
-	solanaGTONHolderPrivKeyEncoded := "v2THMqDAX3SrM0o0GqzlaEDVdG2zMuB5fYKj/qCrUml44x+mNvktmjXQlP7AzuhuM3k+EJvHBtNgx/OcCU4UNw=="
-	decodedSolanaGTONHolderPrivKey, err := base64.StdEncoding.DecodeString(solanaGTONHolderPrivKeyEncoded)
+	polygonClient, err := ethclient.DialContext(ctx, cfg.Origin.NodeURL)
 	if err != nil {
-		return err
-	}
-
-	// solanaGTONHolderPrivKey := ed25519.NewKeyFromSeed(solanaGTONHolderPrivKey)
-	solanaGTONHolderAccount := soltypes.AccountFromPrivateKeyBytes(decodedSolanaGTONHolderPrivKey)
-
-	solanaPKPath := "./mvp-output/public_from-polygon-gton-recipient.json"
-
-	/*
-	 * Workaround specifically for Solana PK
-	 */
-	asNumberDecodedSolanaGTONHolderPrivKey := make([]int64, len(decodedSolanaGTONHolderPrivKey))
-	for i, b := range decodedSolanaGTONHolderPrivKey {
-		asNumberDecodedSolanaGTONHolderPrivKey[i] = int64(b)
+		return nil, err
 	}
 
-	marshalledSolanaPK, err := json.Marshal(asNumberDecodedSolanaGTONHolderPrivKey)
+	polygonGTONHolder, _, err := cfg.Origin.Key()
 	if err != nil {
-		return err
-	}
-
-	os.Mkdir("mvp-output", 0777)
-	os.WriteFile(solanaPKPath, marshalledSolanaPK, 0777)
-
-	// PublicKey  common.PublicKey
-	// PrivateKey ed25519.PrivateKey
-	solanaGTONHolder := &commands.OperatingAddress{
-		Account:    solanaGTONHolderAccount,
-		PublicKey:  solanaGTONHolderAccount.PublicKey,
-		PrivateKey: base58.Encode(decodedSolanaGTONHolderPrivKey),
-		PKPath:     solanaPKPath,
+		return nil, err
 	}
 
-	solanaGTONTokenAccountCreateResult := commands.CreateTokenAccountWithFeePayer(solanaGTONHolder.PKPath, gtonToken.cfg.destinationAddress)
-	solanaGTONTokenAccount := solanaGTONTokenAccountCreateResult.TokenAccount
-
-	luportClient, err := luport.NewLUPort(ethcommon.HexToAddress(extractorCfg.luportAddress), polygonClient)
+	transactor, err := ethbind.NewKeyedTransactorWithChainID(polygonGTONHolder, big.NewInt(cfg.Origin.ChainID))
 	if err != nil {
-		return err
-	}
-
-	randomFloat := func() float64 {
-		return (rand.NormFloat64() + (float64(time.Now().Second()) / 60)) / 10
+		return nil, err
 	}
+	transactor.GasLimit = cfg.Origin.GasLimit
 
-	transferAmount := float64(int64(randomFloat()*1000)) / 1e6
-
-	// gtonToken.Set(0.0000227)
-	gtonToken.Set(transferAmount)
-
-	fmt.Printf("As Origin: %v GTON \n", gtonToken.AsOriginBigInt())
-	fmt.Printf("As Destination: %v GTON \n", gtonToken.AsDestinationBigInt())
-
-	// // approve token spend
-	gtonERC20, err := erc20.NewToken(ethcommon.HexToAddress(gtonToken.cfg.originAddress), polygonClient)
+	solanaGTONHolderAccount, err := cfg.Destination.Keypair()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Printf("Approving %v GTON spend (Polygon) \n", gtonToken.Float())
+	solanaGTONTokenAccount := cfg.Destination.TokenAccount
+	if solanaGTONTokenAccount == "" {
+		operator, err := tokens.NewTokenOperator(solanaGTONHolderAccount, cfg.Destination.NodeURL)
+		if err != nil {
+			return nil, err
+		}
 
-	polygonTransactor.transactor.Context = context.Background()
-	polygonTransactor.transactor.GasLimit = 1_000_000
-	// polygonTransactor.transactor.GasPrice = big.NewInt(100000)
+		result, err := operator.CreateTokenAccount(ctx, solcommon.PublicKeyFromString(cfg.Destination.MintAddress), solanaGTONHolderAccount.PublicKey)
+		if err != nil {
+			fmt.Printf("create token account error, err: %v\n", err)
+			return nil, err
+		}
 
-	approveTx, err := gtonERC20.Approve(
-		polygonTransactor.transactor,
-		ethcommon.HexToAddress(extractorCfg.luportAddress),
-		gtonToken.AsOriginBigInt(),
-	)
-	if err != nil {
-		return err
+		solanaGTONTokenAccount = result.TokenAccount.ToBase58()
+		fmt.Printf("Solana token account: %v \n", solanaGTONTokenAccount)
 	}
 
-	// time.Sleep(time.Second * 30)
-	_, err = ethbind.WaitMined(polygonTransactor.transactor.Context, polygonTransactor.ethClient, approveTx)
+	polygonPort, err := NewEVMLUPort(cfg.Origin.Chain, polygonClient, transactor, cfg.Origin.LUPortAddress, cfg.Origin.TokenAddress, cfg.Origin.Decimals)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	polygonPort.Confirmations = cfg.Origin.Confirmations
 
-	fmt.Printf("Approve %v GTON spend tx (Polygon): %v \n", gtonToken.Float(), approveTx.Hash().Hex())
-
-	fmt.Printf("Locking %v GTON \n", gtonToken.Float())
-
-	// (1)
-	lockFundsTx, err := luportClient.CreateTransferUnwrapRequest(
-		polygonTransactor.transactor,
-		gtonToken.AsOriginBigInt(),
-		solcommon.PublicKeyFromString(solanaGTONTokenAccount),
+	solanaPort, err := NewSolanaIBPort(
+		cfg.Destination.Chain,
+		cfg.Destination.NodeURL,
+		solanaGTONHolderAccount,
+		cfg.Destination.IBPortProgramID,
+		cfg.Destination.IBPortDataAccount,
+		cfg.Destination.MintAddress,
+		solanaGTONTokenAccount,
+		cfg.Destination.Decimals,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	solanaPort.AmountFormat = amountFormat
+	solanaPort.WebSocketURL = cfg.Destination.WebSocketURL
 
-	_, err = ethbind.WaitMined(polygonTransactor.transactor.Context, polygonTransactor.ethClient, lockFundsTx)
+	amount, err := cfg.OriginAmount()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if amount == nil {
+		randomFloat := func() float64 {
+			return (rand.NormFloat64() + (float64(time.Now().Second()) / 60)) / 10
+		}
 
-	fmt.Printf("Lock %v GTON tx (Polygon): %v \n", gtonToken.Float(), lockFundsTx.Hash().Hex())
-
-	var solanaDepositAwaiter, polygonDepositAwaiter CrossChainTokenDepositAwaiter
+		gtonToken, err := NewCrossChainToken(&crossChainTokenCfg{
+			originDecimals:      int(cfg.Origin.Decimals),
+			destinationDecimals: int(cfg.Destination.Decimals),
+			originAddress:       cfg.Origin.TokenAddress,
+			destinationAddress:  cfg.Destination.MintAddress,
+		}, math.Abs(float64(int64(randomFloat()*1000))/1e6))
+		if err != nil {
+			return nil, err
+		}
 
-	solanaEndpoint, _ := commands.InferSystemDefinedRPC()
-	solanaDepositAwaiter = NewSolanaDepositAwaiter(solanaEndpoint)
-
-	solanaDepositAwaiter.SetCfg(
-		&CrossChainDepositAwaiterConfig{
-			WatchAddress:    solanaGTONTokenAccount,
-			WatchAssetID:    gtonToken.cfg.destinationAddress,
-			WatchAmount:     gtonToken.AsDestinationBigInt(),
-			PerAwaitTimeout: time.Second * 10,
-		},
-	)
-
-	polygonDepositAwaiter = NewEVMExplorerClient()
-	polygonDepositAwaiter.SetCfg(
-		&CrossChainDepositAwaiterConfig{
-			WatchAddress:    polygonGTONHolder.Address,
-			WatchAssetID:    gtonToken.cfg.originAddress,
-			WatchAmount:     gtonToken.AsOriginBigInt(),
-			PerAwaitTimeout: time.Second * 10,
-		},
-	)
-
-	polygonDepositBuffer := make(chan interface{})
-	solanaDepositBuffer := make(chan interface{})
-
-	go func() {
-		err = solanaDepositAwaiter.AwaitTokenDeposit(solanaDepositBuffer)
-	}()
-
-	// if err != nil {
-	// 	return err
-	// }
-
-	for event := range solanaDepositBuffer {
-		tokenDataState := event.(soltoken.TokenAccount)
-		fmt.Printf("Deposit event (Solana): %+v \n", tokenDataState)
-		break
-	}
-
-	// (2)
-	ibportExecutor, err := commands.InitGenericExecutor(
-		solanaGTONHolder.PKPath,
-		extractorCfg.ibportProgramID,
-		extractorCfg.ibportDataAccount,
-		"",
-		extractorCfg.destinationNodeURL,
-		solcommon.PublicKeyFromString(""),
-	)
-	if err != nil {
-		return err
+		amount = gtonToken.AsOriginBigInt()
 	}
 
-	ibPortPDA, err := common.CreateProgramAddress([][]byte{[]byte(executor.IBPortPDABumpSeeds)}, common.PublicKeyFromString(extractorCfg.ibportProgramID))
-	if err != nil {
-		return err
-	}
+	fmt.Printf("As Origin: %v GTON \n", amount)
 
-	ibportExecutor.SetAdditionalMeta([]types.AccountMeta{
-		{PubKey: common.TokenProgramID, IsWritable: false, IsSigner: false},
-		{PubKey: common.PublicKeyFromString(gtonToken.cfg.destinationAddress), IsWritable: true, IsSigner: false},
-		{PubKey: common.PublicKeyFromString(solanaGTONTokenAccount), IsWritable: true, IsSigner: false},
-		{PubKey: ibPortPDA, IsWritable: false, IsSigner: false},
+	scenario := NewSwapScenario(SwapRoute{
+		Name:        cfg.Route,
+		Origin:      polygonPort,
+		Destination: solanaPort,
+		Amount:      amount,
+		Direction:   direction,
 	})
+	scenario.AwaitTimeout = cfg.AwaitTimeout
 
-	err = commands.DelegateSPLTokenAmountWithFeePayer(solanaGTONHolder.PKPath, solanaGTONTokenAccount, ibPortPDA.ToBase58(), gtonToken.Float())
-	if err != nil {
-		return err
-	}
-
-	time.Sleep(time.Second * 45)
-
-	fmt.Printf("Approved %v GTON spend (Solana) \n", gtonToken.Float())
-
-	polygonAddressDecoded, err := hexutil.Decode(polygonGTONHolder.Address)
-	if err != nil {
-		return err
-	}
-
-	var polygonTargetAddress [32]byte
-	copy(polygonTargetAddress[:], polygonAddressDecoded)
-
-	burnFundsResponse, err := ibportExecutor.BuildAndInvoke(
-		executor.IBPortIXBuilder.CreateTransferUnwrapRequest(
-			polygonTargetAddress,
-			gtonToken.Float(),
-		),
-	)
-	if err != nil {
-		return err
-	}
-
-	time.Sleep(time.Second * 45)
-
-	fmt.Printf("Lock %v GTON tx (Solana): %v \n", gtonToken.Float(), burnFundsResponse.TxSignature)
-
-	// print
-	// (3)
-	go func() {
-		err = polygonDepositAwaiter.AwaitTokenDeposit(solanaDepositBuffer)
-	}()
-
-	if err != nil {
-		return err
-	}
-
-	for event := range polygonDepositBuffer {
-		depositEvent := event.(*EVMTokenTransferEvent)
-		fmt.Printf("Polygon - deposit event (burn from Solana) - TX: %+v \n", depositEvent.Hash)
-		break
-	}
-
-	return nil
+	return scenario.Run(ctx)
 }
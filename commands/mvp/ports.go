@@ -0,0 +1,463 @@
+package mvp
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	erc20 "github.com/Gravity-Tech/gateway/abi/ethereum/erc20"
+	luport "github.com/Gravity-Tech/gateway/abi/ethereum/luport"
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+
+	ethbind "github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethclient "github.com/ethereum/go-ethereum/ethclient"
+	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
+	solcommon "github.com/portto/solana-go-sdk/common"
+	soltoken "github.com/portto/solana-go-sdk/tokenprog"
+	soltypes "github.com/portto/solana-go-sdk/types"
+)
+
+// SwapPort is one side of a bridge pair: the holder sends tokens through the port
+// and receives tokens sent from the other side. Steps are returned along with the error,
+// the failed step carries it too.
+type SwapPort interface {
+	Chain() string
+	Decimals() uint8
+	// Head is the current block or slot, deposits are awaited from it
+	Head(ctx context.Context) (uint64, error)
+	// Recipient is the holder address in the port request format
+	Recipient() [32]byte
+	// Send locks (LU Port) or burns (IB Port) the amount in base units for the receiver on the other side
+	Send(ctx context.Context, amount *big.Int, receiver [32]byte) ([]SwapStep, error)
+	// AwaitDeposit blocks until the holder receives the amount since the head
+	AwaitDeposit(ctx context.Context, amount *big.Int, since uint64) ([]SwapStep, error)
+	Balance(ctx context.Context) (*SwapBalance, error)
+}
+
+// EVMLUPort locks ERC-20 tokens with the LU Port contract
+type EVMLUPort struct {
+	chain      string
+	client     *ethclient.Client
+	transactor *ethbind.TransactOpts
+	port       ethcommon.Address
+	token      ethcommon.Address
+	decimals   uint8
+
+	Confirmations uint64
+}
+
+func NewEVMLUPort(chain string, client *ethclient.Client, transactor *ethbind.TransactOpts, portAddress, tokenAddress string, decimals uint8) (*EVMLUPort, error) {
+	if !ethcommon.IsHexAddress(portAddress) {
+		return nil, fmt.Errorf("invalid LU Port address: %v", portAddress)
+	}
+	if !ethcommon.IsHexAddress(tokenAddress) {
+		return nil, fmt.Errorf("invalid token address: %v", tokenAddress)
+	}
+
+	return &EVMLUPort{
+		chain:         chain,
+		client:        client,
+		transactor:    transactor,
+		port:          ethcommon.HexToAddress(portAddress),
+		token:         ethcommon.HexToAddress(tokenAddress),
+		decimals:      decimals,
+		Confirmations: DefaultEVMConfirmations,
+	}, nil
+}
+
+func (port *EVMLUPort) Chain() string {
+	return port.chain
+}
+
+func (port *EVMLUPort) Decimals() uint8 {
+	return port.decimals
+}
+
+func (port *EVMLUPort) Head(ctx context.Context) (uint64, error) {
+	header, err := port.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Number.Uint64(), nil
+}
+
+// Recipient is the holder address left aligned, the way IB Port requests carry it
+func (port *EVMLUPort) Recipient() [32]byte {
+	var recipient [32]byte
+	copy(recipient[:], port.transactor.From.Bytes())
+
+	return recipient
+}
+
+func (port *EVMLUPort) transactOpts(ctx context.Context) *ethbind.TransactOpts {
+	opts := *port.transactor
+	opts.Context = ctx
+
+	return &opts
+}
+
+// mined waits for the transaction receipt and fills the step with it
+func (port *EVMLUPort) mined(ctx context.Context, step *SwapStep, tx *ethtypes.Transaction) error {
+	step.TxID = tx.Hash().Hex()
+
+	receipt, err := ethbind.WaitMined(ctx, port.client, tx)
+	if err != nil {
+		return err
+	}
+
+	step.Block = receipt.BlockNumber.Uint64()
+	step.Fee = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), tx.GasPrice()).String()
+
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %v reverted", step.TxID)
+	}
+
+	return nil
+}
+
+// Send approves the spend and creates the LU Port request, both are awaited to be mined
+func (port *EVMLUPort) Send(ctx context.Context, amount *big.Int, receiver [32]byte) ([]SwapStep, error) {
+	approve := newSwapStep(SwapStepApprove, port.chain, amount, time.Now())
+
+	err := func() error {
+		token, err := erc20.NewToken(port.token, port.client)
+		if err != nil {
+			return err
+		}
+
+		approveTx, err := token.Approve(port.transactOpts(ctx), port.port, amount)
+		if err != nil {
+			return err
+		}
+
+		return port.mined(ctx, &approve, approveTx)
+	}()
+	approve.finish(time.Now(), err)
+	if err != nil {
+		return []SwapStep{approve}, err
+	}
+
+	lock := newSwapStep(SwapStepLock, port.chain, amount, time.Now())
+
+	err = func() error {
+		luportClient, err := luport.NewLUPort(port.port, port.client)
+		if err != nil {
+			return err
+		}
+
+		lockTx, err := luportClient.CreateTransferUnwrapRequest(port.transactOpts(ctx), amount, receiver)
+		if err != nil {
+			return err
+		}
+
+		return port.mined(ctx, &lock, lockTx)
+	}()
+	lock.finish(time.Now(), err)
+
+	return []SwapStep{approve, lock}, err
+}
+
+// AwaitDeposit reports the unlock as observed in the head block first,
+// then as final once it is buried under Confirmations. A reorged unlock is awaited again.
+func (port *EVMLUPort) AwaitDeposit(ctx context.Context, amount *big.Int, since uint64) ([]SwapStep, error) {
+	observed := newSwapStep(SwapStepRelayObserved, port.chain, amount, time.Now())
+
+	for {
+		awaiter := NewEVMLogsDepositAwaiter(port.client, EVMERC20Transfer)
+		awaiter.Confirmations = 0
+		awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+			WatchAddress: port.transactor.From.Hex(),
+			WatchAssetID: port.token.Hex(),
+			WatchAmount:  amount,
+			BlockStart:   since,
+		})
+
+		pipe := make(chan interface{}, 1)
+		if err := awaiter.AwaitTokenDepositWithContext(ctx, pipe); err != nil {
+			observed.finish(time.Now(), err)
+			return []SwapStep{observed}, err
+		}
+
+		event := (<-pipe).(*EVMDepositEvent)
+		observed.TxID = event.TxHash.Hex()
+		observed.Block = event.BlockNumber
+		observed.finish(time.Now(), nil)
+
+		unlock := newSwapStep(SwapStepUnlock, port.chain, amount, observed.FinishedAt)
+		unlock.TxID = observed.TxID
+		unlock.Block = observed.Block
+
+		canonical, err := port.awaitConfirmations(ctx, event)
+		if err != nil {
+			unlock.finish(time.Now(), err)
+			return []SwapStep{observed, unlock}, err
+		}
+		if canonical {
+			unlock.finish(time.Now(), nil)
+			return []SwapStep{observed, unlock}, nil
+		}
+
+		fmt.Printf("%v: unlock %v is reorged, awaiting again \n", port.chain, observed.TxID)
+		observed.Error = ""
+	}
+}
+
+// awaitConfirmations blocks until the event block is buried under Confirmations, reports whether it stays canonical
+func (port *EVMLUPort) awaitConfirmations(ctx context.Context, event *EVMDepositEvent) (bool, error) {
+	for {
+		head, err := port.Head(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		if head >= event.BlockNumber+port.Confirmations {
+			header, err := port.client.HeaderByNumber(ctx, new(big.Int).SetUint64(event.BlockNumber))
+			if err != nil {
+				return false, err
+			}
+			return header.Hash() == event.BlockHash, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(DefaultEVMPollInterval):
+		}
+	}
+}
+
+func (port *EVMLUPort) Balance(ctx context.Context) (*SwapBalance, error) {
+	token, err := erc20.NewToken(port.token, port.client)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenBalance, err := token.BalanceOf(&ethbind.CallOpts{Context: ctx}, port.transactor.From)
+	if err != nil {
+		return nil, err
+	}
+
+	nativeBalance, err := port.client.BalanceAt(ctx, port.transactor.From, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapBalance{Chain: port.chain, Token: tokenBalance.String(), Native: nativeBalance.String()}, nil
+}
+
+// SolanaIBPort burns SPL tokens with the IB Port program
+type SolanaIBPort struct {
+	chain        string
+	nodeURL      string
+	holder       soltypes.Account
+	programID    solcommon.PublicKey
+	dataAccount  solcommon.PublicKey
+	mint         solcommon.PublicKey
+	tokenAccount solcommon.PublicKey
+	decimals     uint8
+
+	// AmountFormat has to match the deployed IB Port binary
+	AmountFormat executor.AmountFormat
+	// WebSocketURL overrides the one inferred from the node URL
+	WebSocketURL string
+}
+
+func NewSolanaIBPort(chain, nodeURL string, holder soltypes.Account, programID, dataAccount, mint, tokenAccount string, decimals uint8) (*SolanaIBPort, error) {
+	port := &SolanaIBPort{
+		chain:    chain,
+		nodeURL:  nodeURL,
+		holder:   holder,
+		decimals: decimals,
+	}
+
+	for _, key := range []struct {
+		name    string
+		address string
+		target  *solcommon.PublicKey
+	}{
+		{"IB Port program", programID, &port.programID},
+		{"IB Port data account", dataAccount, &port.dataAccount},
+		{"mint", mint, &port.mint},
+		{"token account", tokenAccount, &port.tokenAccount},
+	} {
+		decoded, err := base58.Decode(key.address)
+		if err != nil || len(decoded) != solcommon.PublicKeyLength {
+			return nil, fmt.Errorf("invalid %v address: %v", key.name, key.address)
+		}
+		*key.target = solcommon.PublicKeyFromBytes(decoded)
+	}
+
+	return port, nil
+}
+
+func (port *SolanaIBPort) Chain() string {
+	return port.chain
+}
+
+func (port *SolanaIBPort) Decimals() uint8 {
+	return port.decimals
+}
+
+func (port *SolanaIBPort) Head(ctx context.Context) (uint64, error) {
+	epochInfo, err := solclient.NewClient(port.nodeURL).GetEpochInfo(ctx, solclient.CommitmentFinalized)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(epochInfo.AbsoluteSlot), nil
+}
+
+// Recipient is the holder token account, LU Port requests carry it as is
+func (port *SolanaIBPort) Recipient() [32]byte {
+	return port.tokenAccount
+}
+
+func (port *SolanaIBPort) executor() (*executor.GenericExecutor, error) {
+	return executor.NewNebulaExecutor(
+		base58.Encode(port.holder.PrivateKey),
+		port.programID.ToBase58(),
+		port.dataAccount.ToBase58(),
+		"",
+		port.nodeURL,
+		solcommon.PublicKey{},
+	)
+}
+
+// confirmed sends the instructions, awaits the confirmed commitment and fills the step
+func (port *SolanaIBPort) confirmed(ctx context.Context, step *SwapStep, ibportExecutor *executor.GenericExecutor, ixs ...soltypes.Instruction) error {
+	response, err := ibportExecutor.SendAndConfirm(ctx, ixs, solclient.CommitmentConfirmed)
+	if response != nil {
+		step.TxID = response.TxSignature
+		step.Block = response.Slot
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := executor.GetTransaction(ctx, port.nodeURL, response.TxSignature, solclient.CommitmentConfirmed)
+	if err == nil && tx != nil {
+		step.Fee = strconv.FormatUint(tx.Meta.Fee, 10)
+	}
+
+	return nil
+}
+
+// Send delegates the amount to the port PDA and then creates the unwrap request, which burns it
+func (port *SolanaIBPort) Send(ctx context.Context, amount *big.Int, receiver [32]byte) ([]SwapStep, error) {
+	delegate := newSwapStep(SwapStepDelegate, port.chain, amount, time.Now())
+
+	if !amount.IsUint64() {
+		err := fmt.Errorf("amount %v overflows u64", amount)
+		delegate.finish(time.Now(), err)
+		return []SwapStep{delegate}, err
+	}
+
+	portPDA, err := solcommon.CreateProgramAddress([][]byte{[]byte(executor.IBPortPDABumpSeeds)}, port.programID)
+	if err != nil {
+		delegate.finish(time.Now(), err)
+		return []SwapStep{delegate}, err
+	}
+
+	ibportExecutor, err := port.executor()
+	if err != nil {
+		delegate.finish(time.Now(), err)
+		return []SwapStep{delegate}, err
+	}
+
+	err = port.confirmed(ctx, &delegate, ibportExecutor,
+		soltoken.Approve(port.tokenAccount, portPDA, port.holder.PublicKey, []solcommon.PublicKey{}, amount.Uint64()),
+	)
+	delegate.finish(time.Now(), err)
+	if err != nil {
+		return []SwapStep{delegate}, err
+	}
+
+	burn := newSwapStep(SwapStepBurn, port.chain, amount, time.Now())
+
+	err = func() error {
+		unwrapRequest, err := executor.IBPortIXBuilder.CreateTransferUnwrapRequestWithCodec(
+			receiver,
+			amount.Uint64(),
+			executor.NewAmountCodec(port.AmountFormat, port.decimals),
+		)
+		if err != nil {
+			return err
+		}
+
+		unwrapIX, err := ibportExecutor.BuildInstruction(unwrapRequest.WithAccounts(executor.Accounts{
+			executor.RoleTokenProgram: solcommon.TokenProgramID,
+			executor.RoleMint:         port.mint,
+			executor.RoleTokenAccount: port.tokenAccount,
+			executor.RolePortPDA:      portPDA,
+		}))
+		if err != nil {
+			return err
+		}
+
+		return port.confirmed(ctx, &burn, ibportExecutor, *unwrapIX)
+	}()
+	burn.finish(time.Now(), err)
+
+	return []SwapStep{delegate, burn}, err
+}
+
+// AwaitDeposit reports the mint as observed at the confirmed commitment, then awaits its finalization
+func (port *SolanaIBPort) AwaitDeposit(ctx context.Context, amount *big.Int, since uint64) ([]SwapStep, error) {
+	observed := newSwapStep(SwapStepRelayObserved, port.chain, amount, time.Now())
+
+	awaiter := NewSolanaDepositAwaiter(port.nodeURL)
+	awaiter.Commitment = solclient.CommitmentConfirmed
+	if port.WebSocketURL != "" {
+		awaiter.SetWebSocketURL(port.WebSocketURL)
+	}
+	awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+		WatchAddress: port.tokenAccount.ToBase58(),
+		WatchAssetID: port.mint.ToBase58(),
+		WatchAmount:  amount,
+		BlockStart:   since,
+	})
+
+	pipe := make(chan interface{}, 1)
+	if err := awaiter.AwaitTokenDepositWithContext(ctx, pipe); err != nil {
+		observed.finish(time.Now(), err)
+		return []SwapStep{observed}, err
+	}
+
+	deposit := (<-pipe).(SolanaTokenDeposit)
+	observed.TxID = deposit.Signature
+	observed.Block = deposit.Slot
+	observed.finish(time.Now(), nil)
+
+	finalized := newSwapStep(SwapStepMintFinalized, port.chain, amount, observed.FinishedAt)
+	finalized.TxID = deposit.Signature
+
+	confirmation, err := executor.AwaitConfirmation(ctx, port.nodeURL, deposit.Signature, solclient.CommitmentFinalized, 0)
+	if confirmation != nil {
+		finalized.Block = confirmation.Slot
+	}
+	finalized.finish(time.Now(), err)
+
+	return []SwapStep{observed, finalized}, err
+}
+
+func (port *SolanaIBPort) Balance(ctx context.Context) (*SwapBalance, error) {
+	client := solclient.NewClient(port.nodeURL)
+
+	tokenBalance, err := client.GetTokenAccountBalance(ctx, port.tokenAccount.ToBase58(), solclient.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+
+	nativeBalance, err := client.GetBalance(ctx, port.holder.PublicKey.ToBase58())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapBalance{Chain: port.chain, Token: tokenBalance.Amount, Native: strconv.FormatUint(nativeBalance, 10)}, nil
+}
@@ -0,0 +1,216 @@
+package mvp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SwapStepKind string
+
+const (
+	SwapStepApprove SwapStepKind = "approve"
+	SwapStepLock    SwapStepKind = "lock"
+	// SwapStepRelayObserved is the deposit seen at the lowest commitment, the relayer latency ends here
+	SwapStepRelayObserved SwapStepKind = "relay_observed"
+	SwapStepMintFinalized SwapStepKind = "mint_finalized"
+	SwapStepDelegate      SwapStepKind = "delegate"
+	SwapStepBurn          SwapStepKind = "burn"
+	SwapStepUnlock        SwapStepKind = "unlock"
+)
+
+// SwapStep is a single sent transaction or an awaited deposit.
+// Block is the EVM block or the Solana slot, Fee is in native base units (wei, lamports)
+// and is set for transactions paid by the holder only.
+type SwapStep struct {
+	Kind       SwapStepKind  `json:"kind"`
+	Chain      string        `json:"chain"`
+	Amount     string        `json:"amount"`
+	TxID       string        `json:"tx_id,omitempty"`
+	Block      uint64        `json:"block,omitempty"`
+	Fee        string        `json:"fee,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func newSwapStep(kind SwapStepKind, chain string, amount *big.Int, startedAt time.Time) SwapStep {
+	return SwapStep{
+		Kind:      kind,
+		Chain:     chain,
+		Amount:    amount.String(),
+		StartedAt: startedAt,
+	}
+}
+
+func (step *SwapStep) finish(finishedAt time.Time, err error) {
+	step.FinishedAt = finishedAt
+	step.Duration = finishedAt.Sub(step.StartedAt)
+	if err != nil {
+		step.Error = err.Error()
+	}
+}
+
+// SwapBalance is the holder balance in base units
+type SwapBalance struct {
+	Chain  string `json:"chain"`
+	Token  string `json:"token"`
+	Native string `json:"native"`
+}
+
+type SwapReport struct {
+	Route      string        `json:"route"`
+	Direction  SwapDirection `json:"direction"`
+	Steps      []SwapStep    `json:"steps"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration"`
+	// Fees sums step fees per chain
+	Fees     map[string]string `json:"fees"`
+	Balances []SwapBalance     `json:"balances"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func (report *SwapReport) sumFees() {
+	totals := make(map[string]*big.Int)
+
+	for _, step := range report.Steps {
+		fee, ok := new(big.Int).SetString(step.Fee, 10)
+		if !ok {
+			continue
+		}
+		if totals[step.Chain] == nil {
+			totals[step.Chain] = new(big.Int)
+		}
+		totals[step.Chain].Add(totals[step.Chain], fee)
+	}
+
+	report.Fees = make(map[string]string, len(totals))
+	for chain, total := range totals {
+		report.Fees[chain] = total.String()
+	}
+}
+
+// Step returns the first step of the kind, nil if the scenario has not reached it
+func (report *SwapReport) Step(kind SwapStepKind) *SwapStep {
+	for i := range report.Steps {
+		if report.Steps[i].Kind == kind {
+			return &report.Steps[i]
+		}
+	}
+	return nil
+}
+
+func (report *SwapReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+var SwapReportCSVHeader = []string{
+	"route", "direction", "run_started_at", "step", "chain", "amount", "tx_id", "block", "fee",
+	"started_at", "finished_at", "duration_ms", "error", "final_token_balance", "final_native_balance",
+}
+
+// Balance returns the final balance on the chain, nil if it has not been collected
+func (report *SwapReport) Balance(chain string) *SwapBalance {
+	for i := range report.Balances {
+		if report.Balances[i].Chain == chain {
+			return &report.Balances[i]
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes a row per step, the run start ties rows of a single run together.
+// Final balances are the ones of the step chain, repeated on every row of the run.
+func (report *SwapReport) WriteCSV(w io.Writer, header bool) error {
+	writer := csv.NewWriter(w)
+
+	if header {
+		if err := writer.Write(SwapReportCSVHeader); err != nil {
+			return err
+		}
+	}
+
+	runStartedAt := report.StartedAt.UTC().Format(time.RFC3339Nano)
+
+	for _, step := range report.Steps {
+		balance := SwapBalance{}
+		if final := report.Balance(step.Chain); final != nil {
+			balance = *final
+		}
+
+		row := []string{
+			report.Route,
+			report.Direction.String(),
+			runStartedAt,
+			string(step.Kind),
+			step.Chain,
+			step.Amount,
+			step.TxID,
+			strconv.FormatUint(step.Block, 10),
+			step.Fee,
+			step.StartedAt.UTC().Format(time.RFC3339Nano),
+			step.FinishedAt.UTC().Format(time.RFC3339Nano),
+			strconv.FormatInt(step.Duration.Milliseconds(), 10),
+			step.Error,
+			balance.Token,
+			balance.Native,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SaveSwapReport writes both formats next to each other: the path with .json extension is overwritten
+// with the report of the run, CSV rows are appended to the path with .csv extension, so that file keeps
+// the history of runs. The path extension, if it is one of the two, is replaced.
+func SaveSwapReport(path string, report *SwapReport) error {
+	base := path
+	if ext := filepath.Ext(path); strings.EqualFold(ext, ".json") || strings.EqualFold(ext, ".csv") {
+		base = strings.TrimSuffix(path, ext)
+	}
+
+	if err := saveSwapReportJSON(base+".json", report); err != nil {
+		return err
+	}
+	return saveSwapReportCSV(base+".csv", report)
+}
+
+func saveSwapReportJSON(path string, report *SwapReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return report.WriteJSON(file)
+}
+
+func saveSwapReportCSV(path string, report *SwapReport) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return report.WriteCSV(file, info.Size() == 0)
+}
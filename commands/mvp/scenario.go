@@ -0,0 +1,194 @@
+package mvp
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+)
+
+type SwapDirection uint8
+
+const (
+	// SwapRoundTrip runs lock -> await mint -> burn -> await unlock
+	SwapRoundTrip SwapDirection = iota
+	// SwapForward runs lock -> await mint
+	SwapForward
+	// SwapBackward runs burn -> await unlock
+	SwapBackward
+)
+
+func (direction SwapDirection) String() string {
+	switch direction {
+	case SwapRoundTrip:
+		return "round-trip"
+	case SwapForward:
+		return "forward"
+	case SwapBackward:
+		return "backward"
+	default:
+		return fmt.Sprintf("SwapDirection(%d)", uint8(direction))
+	}
+}
+
+func (direction SwapDirection) MarshalText() ([]byte, error) {
+	return []byte(direction.String()), nil
+}
+
+func (direction *SwapDirection) UnmarshalText(text []byte) error {
+	parsed, err := ParseSwapDirection(string(text))
+	if err != nil {
+		return err
+	}
+
+	*direction = parsed
+	return nil
+}
+
+func ParseSwapDirection(direction string) (SwapDirection, error) {
+	for _, candidate := range []SwapDirection{SwapRoundTrip, SwapForward, SwapBackward} {
+		if candidate.String() == direction {
+			return candidate, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown swap direction: %v", direction)
+}
+
+// SwapRoute is a bridge pair, Origin holds the LU Port and Destination holds the IB Port
+type SwapRoute struct {
+	Name        string
+	Origin      SwapPort
+	Destination SwapPort
+	// Amount is in Origin base units, Destination amount is derived by decimals
+	Amount    *big.Int
+	Direction SwapDirection
+}
+
+// SwapScenario runs the route steps one by one, recording tx ids, blocks, fees and timings.
+// The report is returned even when a step fails, the failed step carries the error.
+type SwapScenario struct {
+	Route SwapRoute
+	// AwaitTimeout bounds every awaited deposit, zero waits for the parent context only
+	AwaitTimeout time.Duration
+
+	now func() time.Time
+}
+
+func NewSwapScenario(route SwapRoute) *SwapScenario {
+	return &SwapScenario{
+		Route: route,
+		now:   time.Now,
+	}
+}
+
+func (scenario *SwapScenario) validate() error {
+	route := scenario.Route
+
+	if route.Origin == nil || route.Destination == nil {
+		return fmt.Errorf("route has no origin or destination port")
+	}
+	if route.Amount == nil || route.Amount.Sign() <= 0 {
+		return fmt.Errorf("route amount must be positive")
+	}
+
+	return nil
+}
+
+func (scenario *SwapScenario) Run(ctx context.Context) (*SwapReport, error) {
+	route := scenario.Route
+
+	report := &SwapReport{
+		Route:     route.Name,
+		Direction: route.Direction,
+		StartedAt: scenario.now(),
+	}
+
+	err := scenario.run(ctx, report)
+
+	report.FinishedAt = scenario.now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt)
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	report.sumFees()
+	if scenario.validate() == nil {
+		scenario.collectBalances(ctx, report)
+	}
+
+	return report, err
+}
+
+// collectBalances is best effort, the report of a failed run is still worth saving
+func (scenario *SwapScenario) collectBalances(ctx context.Context, report *SwapReport) {
+	for _, port := range []SwapPort{scenario.Route.Origin, scenario.Route.Destination} {
+		balance, err := port.Balance(ctx)
+		if err != nil {
+			fmt.Printf("%v balance error, err: %v\n", port.Chain(), err)
+			continue
+		}
+		report.Balances = append(report.Balances, *balance)
+	}
+}
+
+func (scenario *SwapScenario) run(ctx context.Context, report *SwapReport) error {
+	if err := scenario.validate(); err != nil {
+		return err
+	}
+
+	route := scenario.Route
+
+	destinationAmount, err := executor.ConvertDecimals(route.Amount, route.Origin.Decimals(), route.Destination.Decimals())
+	if err != nil {
+		return err
+	}
+
+	if route.Direction != SwapBackward {
+		err := scenario.hop(ctx, report, route.Origin, route.Destination, route.Amount, destinationAmount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if route.Direction != SwapForward {
+		err := scenario.hop(ctx, report, route.Destination, route.Origin, destinationAmount, route.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hop sends the amount from one port and awaits it on the other
+func (scenario *SwapScenario) hop(ctx context.Context, report *SwapReport, from, to SwapPort, sendAmount, receiveAmount *big.Int) error {
+	// deposits are searched from the head taken before sending, so a fast bridge is not missed
+	since, err := to.Head(ctx)
+	if err != nil {
+		return fmt.Errorf("%v head: %v", to.Chain(), err)
+	}
+
+	steps, err := from.Send(ctx, sendAmount, to.Recipient())
+	report.Steps = append(report.Steps, steps...)
+	if err != nil {
+		return fmt.Errorf("send on %v: %v", from.Chain(), err)
+	}
+
+	awaitCtx := ctx
+	if scenario.AwaitTimeout > 0 {
+		var cancel context.CancelFunc
+		awaitCtx, cancel = context.WithTimeout(ctx, scenario.AwaitTimeout)
+		defer cancel()
+	}
+
+	steps, err = to.AwaitDeposit(awaitCtx, receiveAmount, since)
+	report.Steps = append(report.Steps, steps...)
+	if err != nil {
+		return fmt.Errorf("await on %v: %v", to.Chain(), err)
+	}
+
+	return nil
+}
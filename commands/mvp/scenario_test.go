@@ -0,0 +1,269 @@
+package mvp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSwapPort struct {
+	chain     string
+	decimals  uint8
+	head      uint64
+	recipient [32]byte
+	awaitErr  error
+
+	calls []string
+}
+
+func (port *fakeSwapPort) Chain() string {
+	return port.chain
+}
+
+func (port *fakeSwapPort) Decimals() uint8 {
+	return port.decimals
+}
+
+func (port *fakeSwapPort) Head(ctx context.Context) (uint64, error) {
+	port.head++
+	return port.head, nil
+}
+
+func (port *fakeSwapPort) Recipient() [32]byte {
+	return port.recipient
+}
+
+// fakeSwapPort sends as the EVM port when it has an LU Port side, otherwise as the Solana port
+func (port *fakeSwapPort) kinds() (send, await [2]SwapStepKind) {
+	if port.chain == "evm" {
+		return [2]SwapStepKind{SwapStepApprove, SwapStepLock}, [2]SwapStepKind{SwapStepRelayObserved, SwapStepUnlock}
+	}
+	return [2]SwapStepKind{SwapStepDelegate, SwapStepBurn}, [2]SwapStepKind{SwapStepRelayObserved, SwapStepMintFinalized}
+}
+
+func (port *fakeSwapPort) Send(ctx context.Context, amount *big.Int, receiver [32]byte) ([]SwapStep, error) {
+	port.calls = append(port.calls, fmt.Sprintf("send %v to %02x", amount, receiver[0]))
+
+	kinds, _ := port.kinds()
+	steps := make([]SwapStep, len(kinds))
+	for i, kind := range kinds {
+		steps[i] = newSwapStep(kind, port.chain, amount, time.Unix(int64(i), 0))
+		steps[i].TxID = fmt.Sprintf("%v-%v", port.chain, kind)
+		steps[i].Fee = "5000"
+		steps[i].finish(time.Unix(int64(i)+1, 0), nil)
+	}
+	return steps, nil
+}
+
+func (port *fakeSwapPort) AwaitDeposit(ctx context.Context, amount *big.Int, since uint64) ([]SwapStep, error) {
+	port.calls = append(port.calls, fmt.Sprintf("await %v since %v", amount, since))
+
+	_, kinds := port.kinds()
+	observed := newSwapStep(kinds[0], port.chain, amount, time.Unix(0, 0))
+	observed.finish(time.Unix(1, 0), port.awaitErr)
+	if port.awaitErr != nil {
+		return []SwapStep{observed}, port.awaitErr
+	}
+	observed.TxID = port.chain + "-deposit"
+	observed.Block = since + 1
+
+	final := newSwapStep(kinds[1], port.chain, amount, observed.FinishedAt)
+	final.TxID = observed.TxID
+	final.Block = observed.Block
+	final.finish(time.Unix(2, 0), nil)
+
+	return []SwapStep{observed, final}, nil
+}
+
+func (port *fakeSwapPort) Balance(ctx context.Context) (*SwapBalance, error) {
+	return &SwapBalance{Chain: port.chain, Token: "100", Native: "1"}, nil
+}
+
+// fakeClock ticks a second on every reading
+func fakeClock() func() time.Time {
+	current := time.Unix(0, 0)
+	return func() time.Time {
+		current = current.Add(time.Second)
+		return current
+	}
+}
+
+func TestSwapScenarioRoundTrip(t *testing.T) {
+	origin := &fakeSwapPort{chain: "evm", decimals: 18, head: 100, recipient: [32]byte{0xe}}
+	destination := &fakeSwapPort{chain: "solana", decimals: 8, head: 500, recipient: [32]byte{0x5}}
+
+	scenario := NewSwapScenario(SwapRoute{
+		Name:        "evm-solana",
+		Origin:      origin,
+		Destination: destination,
+		Amount:      new(big.Int).Mul(big.NewInt(15), big.NewInt(1e17)),
+		Direction:   SwapRoundTrip,
+	})
+	scenario.now = fakeClock()
+
+	report, err := scenario.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOrigin := []string{"send 1500000000000000000 to 05", "await 1500000000000000000 since 101"}
+	expectedDestination := []string{"await 150000000 since 501", "send 150000000 to 0e"}
+	if strings.Join(origin.calls, "; ") != strings.Join(expectedOrigin, "; ") {
+		t.Fatalf("unexpected origin calls: %v", origin.calls)
+	}
+	if strings.Join(destination.calls, "; ") != strings.Join(expectedDestination, "; ") {
+		t.Fatalf("unexpected destination calls: %v", destination.calls)
+	}
+
+	expectedSteps := []struct {
+		kind  SwapStepKind
+		chain string
+		txID  string
+	}{
+		{SwapStepApprove, "evm", "evm-approve"},
+		{SwapStepLock, "evm", "evm-lock"},
+		{SwapStepRelayObserved, "solana", "solana-deposit"},
+		{SwapStepMintFinalized, "solana", "solana-deposit"},
+		{SwapStepDelegate, "solana", "solana-delegate"},
+		{SwapStepBurn, "solana", "solana-burn"},
+		{SwapStepRelayObserved, "evm", "evm-deposit"},
+		{SwapStepUnlock, "evm", "evm-deposit"},
+	}
+	if len(report.Steps) != len(expectedSteps) {
+		t.Fatalf("unexpected steps: %+v", report.Steps)
+	}
+	for i, expected := range expectedSteps {
+		step := report.Steps[i]
+		if step.Kind != expected.kind || step.Chain != expected.chain || step.TxID != expected.txID || step.Duration != time.Second {
+			t.Fatalf("step #%d: unexpected %+v", i, step)
+		}
+	}
+	if report.Step(SwapStepMintFinalized).Block != 502 {
+		t.Fatalf("unexpected mint slot: %+v", report.Step(SwapStepMintFinalized))
+	}
+	// started + finished
+	if report.Duration != time.Second {
+		t.Fatalf("unexpected total duration: %v", report.Duration)
+	}
+	if report.Fees["evm"] != "10000" || report.Fees["solana"] != "10000" {
+		t.Fatalf("unexpected fees: %v", report.Fees)
+	}
+	if len(report.Balances) != 2 || report.Balances[0].Chain != "evm" || report.Balances[1].Token != "100" {
+		t.Fatalf("unexpected balances: %+v", report.Balances)
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"direction":"round-trip"`) {
+		t.Fatalf("unexpected report encoding: %s", encoded)
+	}
+}
+
+func TestSwapScenarioRecordsFailedStep(t *testing.T) {
+	origin := &fakeSwapPort{chain: "evm", decimals: 18}
+	destination := &fakeSwapPort{chain: "solana", decimals: 8, awaitErr: errors.New("deadline")}
+
+	scenario := NewSwapScenario(SwapRoute{
+		Origin:      origin,
+		Destination: destination,
+		Amount:      big.NewInt(1e10),
+		Direction:   SwapForward,
+	})
+
+	report, err := scenario.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected await error")
+	}
+	if len(report.Steps) != 3 || report.Steps[2].Error == "" || report.Steps[2].Kind != SwapStepRelayObserved || report.Error == "" {
+		t.Fatalf("failed step is not recorded: %+v", report)
+	}
+	if report.Step(SwapStepMintFinalized) != nil {
+		t.Fatal("unreached step is reported")
+	}
+
+	// amounts that do not fit the destination decimals are rejected before sending
+	origin.calls = nil
+	scenario.Route.Amount = big.NewInt(1e10 + 1)
+	if _, err := scenario.Run(context.Background()); err == nil || len(origin.calls) != 0 {
+		t.Fatalf("lossy amount is sent: %v", err)
+	}
+}
+
+func TestParseSwapDirection(t *testing.T) {
+	for _, direction := range []SwapDirection{SwapRoundTrip, SwapForward, SwapBackward} {
+		parsed, err := ParseSwapDirection(direction.String())
+		if err != nil || parsed != direction {
+			t.Fatalf("%v: got %v, %v", direction, parsed, err)
+		}
+	}
+
+	if _, err := ParseSwapDirection("sideways"); err == nil {
+		t.Fatal("unknown direction is parsed")
+	}
+}
+
+func TestSaveSwapReport(t *testing.T) {
+	scenario := NewSwapScenario(SwapRoute{
+		Name:        "evm-solana",
+		Origin:      &fakeSwapPort{chain: "evm", decimals: 18},
+		Destination: &fakeSwapPort{chain: "solana", decimals: 8},
+		Amount:      big.NewInt(1e10),
+		Direction:   SwapForward,
+	})
+	scenario.now = fakeClock()
+
+	report, err := scenario.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	// both formats are written whatever the extension is
+	jsonPath, csvPath := filepath.Join(dir, "report.json"), filepath.Join(dir, "report.csv")
+	if err := SaveSwapReport(filepath.Join(dir, "report"), report); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSwapReport(jsonPath, report); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := SwapReport{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Steps) != 4 || decoded.Steps[1].Kind != SwapStepLock || decoded.Fees["evm"] != "10000" {
+		t.Fatalf("unexpected decoded report: %+v", decoded)
+	}
+
+	// runs are appended under a single header
+	if err := SaveSwapReport(csvPath, report); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err = os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(encoded)), "\n")
+	if len(lines) != 1+3*4 || lines[0] != strings.Join(SwapReportCSVHeader, ",") {
+		t.Fatalf("unexpected csv: %s", encoded)
+	}
+	if !strings.HasPrefix(lines[2], "evm-solana,forward,") || !strings.Contains(lines[2], ",lock,evm,10000000000,evm-lock,") {
+		t.Fatalf("unexpected csv row: %v", lines[2])
+	}
+	if !strings.HasSuffix(lines[2], ",100,1") {
+		t.Fatalf("final balances are missing in the csv row: %v", lines[2])
+	}
+}
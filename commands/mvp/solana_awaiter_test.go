@@ -0,0 +1,286 @@
+package mvp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands/solanatest"
+	"github.com/Gravity-Tech/solanoid/commands/tokens"
+
+	"github.com/gorilla/websocket"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// fakeAccountPubSub answers accountSubscribe and pushes account notifications on demand
+type fakeAccountPubSub struct {
+	mu         sync.Mutex
+	conn       *websocket.Conn
+	subscribed chan struct{}
+}
+
+func newFakeAccountPubSub(t *testing.T) (*fakeAccountPubSub, string) {
+	fake := &fakeAccountPubSub{subscribed: make(chan struct{}, 1)}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	return fake, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func (f *fakeAccountPubSub) serve(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.conn = conn
+	f.mu.Unlock()
+
+	for {
+		var request struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		var result interface{} = 1
+		if request.Method == "accountUnsubscribe" {
+			result = true
+		}
+		f.write(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+
+		if request.Method == "accountSubscribe" {
+			f.subscribed <- struct{}{}
+		}
+	}
+}
+
+func (f *fakeAccountPubSub) write(message interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.conn.WriteJSON(message)
+}
+
+func (f *fakeAccountPubSub) notify(slot uint64) {
+	f.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "accountNotification",
+		"params": map[string]interface{}{
+			"subscription": 1,
+			"result": map[string]interface{}{
+				"context": map[string]interface{}{"slot": slot},
+				"value":   map[string]interface{}{"data": []string{"", "base64"}, "owner": common.TokenProgramID.ToBase58()},
+			},
+		},
+	})
+}
+
+// scanObserver proxies the RPC endpoint and reports every signatures lookup, that is the start of a scan
+func scanObserver(t *testing.T, endpoint string) (string, <-chan struct{}) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	scans := make(chan struct{}, 64)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		request := struct {
+			Method string `json:"method"`
+		}{}
+		json.Unmarshal(body, &request)
+		if request.Method == "getConfirmedSignaturesForAddress2" {
+			scans <- struct{}{}
+		}
+
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, scans
+}
+
+type solanaDepositFixture struct {
+	server        *solanatest.Server
+	op            *tokens.TokenOperator
+	feePayer      types.Account
+	mint          common.PublicKey
+	mintAuthority types.Account
+	source        common.PublicKey
+	destination   common.PublicKey
+}
+
+func newSolanaDepositFixture(t *testing.T) *solanaDepositFixture {
+	ctx := context.Background()
+
+	server := solanatest.NewServer()
+	t.Cleanup(server.Close)
+
+	feePayer, mintAuthority, holder := types.NewAccount(), types.NewAccount(), types.NewAccount()
+	server.Airdrop(feePayer.PublicKey, 10_000_000_000)
+
+	op, err := tokens.NewTokenOperator(feePayer, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := op.CreateMint(ctx, mintAuthority.PublicKey, nil, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := op.CreateTokenAccount(ctx, created.Mint, feePayer.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination, err := op.CreateTokenAccount(ctx, created.Mint, holder.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := op.MintTo(ctx, created.Mint, source.TokenAccount, mintAuthority, 1_000_000); err != nil {
+		t.Fatal(err)
+	}
+
+	return &solanaDepositFixture{
+		server:        server,
+		op:            op,
+		feePayer:      feePayer,
+		mint:          created.Mint,
+		mintAuthority: mintAuthority,
+		source:        source.TokenAccount,
+		destination:   destination.TokenAccount,
+	}
+}
+
+func (fixture *solanaDepositFixture) transfer(t *testing.T, amount uint64) string {
+	result, err := fixture.op.Transfer(context.Background(), fixture.source, fixture.destination, fixture.feePayer, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Signature
+}
+
+func (fixture *solanaDepositFixture) mintTo(t *testing.T, amount uint64) string {
+	result, err := fixture.op.MintTo(context.Background(), fixture.mint, fixture.destination, fixture.mintAuthority, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Signature
+}
+
+// await runs the loop in the background, the result is the deposit or the error
+func (fixture *solanaDepositFixture) await(t *testing.T, awaiter *SolanaDepositAwaiter) <-chan interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		pipe := make(chan interface{}, 1)
+		if err := awaiter.AwaitTokenDepositWithContext(ctx, pipe); err != nil {
+			result <- err
+			return
+		}
+		result <- <-pipe
+	}()
+
+	return result
+}
+
+func expectSolanaDeposit(t *testing.T, result <-chan interface{}, signature string, amount uint64) {
+	deposit, ok := (<-result).(SolanaTokenDeposit)
+	if !ok {
+		t.Fatalf("deposit is not found: %v", deposit)
+	}
+	if deposit.Signature != signature || deposit.Amount != amount {
+		t.Fatalf("unexpected deposit: %+v", deposit)
+	}
+}
+
+func TestSolanaDepositAwaiterNotification(t *testing.T) {
+	fixture := newSolanaDepositFixture(t)
+	pubsub, wsURL := newFakeAccountPubSub(t)
+	endpoint, scans := scanObserver(t, fixture.server.URL)
+
+	awaiter := NewSolanaDepositAwaiter(endpoint)
+	awaiter.SetWebSocketURL(wsURL)
+	awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+		WatchAddress: fixture.destination.ToBase58(),
+		WatchAssetID: fixture.mint.ToBase58(),
+		WatchAmount:  big.NewInt(250_000),
+	})
+
+	result := fixture.await(t, awaiter)
+	<-pubsub.subscribed
+	<-scans
+
+	// no rescan is set up, the notifications are the only trigger once the first scan is done
+	fixture.transfer(t, 1000)
+	pubsub.notify(fixture.server.Slot())
+	<-scans
+
+	signature := fixture.mintTo(t, 250_000)
+	pubsub.notify(fixture.server.Slot())
+
+	expectSolanaDeposit(t, result, signature, 250_000)
+	if awaiter.LastSlot() != fixture.server.Slot() {
+		t.Fatalf("unexpected last slot: %v", awaiter.LastSlot())
+	}
+}
+
+func TestSolanaDepositAwaiterRescan(t *testing.T) {
+	fixture := newSolanaDepositFixture(t)
+	pubsub, wsURL := newFakeAccountPubSub(t)
+	endpoint, scans := scanObserver(t, fixture.server.URL)
+
+	awaiter := NewSolanaDepositAwaiter(endpoint)
+	awaiter.SetWebSocketURL(wsURL)
+
+	// the matching deposit before the start slot is not the awaited one
+	fixture.transfer(t, 500)
+	startSlot := fixture.server.Slot() + 1
+
+	// the deposit landed before the subscription, no notification is coming for it
+	signature := fixture.transfer(t, 500)
+	awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+		WatchAddress: fixture.destination.ToBase58(),
+		WatchAmount:  big.NewInt(500),
+		BlockStart:   startSlot,
+	})
+	expectSolanaDeposit(t, fixture.await(t, awaiter), signature, 500)
+	<-pubsub.subscribed
+
+	// the notification is missed, the periodic rescan finds the deposit
+	awaiter.SetCfg(&CrossChainDepositAwaiterConfig{
+		WatchAddress:    fixture.destination.ToBase58(),
+		WatchAmount:     big.NewInt(700),
+		BlockStart:      fixture.server.Slot() + 1,
+		PerAwaitTimeout: 10 * time.Millisecond,
+	})
+	for len(scans) > 0 {
+		<-scans
+	}
+
+	result := fixture.await(t, awaiter)
+	<-pubsub.subscribed
+	<-scans
+
+	signature = fixture.transfer(t, 700)
+	expectSolanaDeposit(t, result, signature, 700)
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/Gravity-Tech/solanoid/commands/executor"
@@ -68,8 +70,27 @@ func TestNebulaDeployment(t *testing.T) {
 
 	t.Logf("Init: %v \n", nebulaInitResponse.SerializedMessage)
 
+	executor.RegisterProgramErrors(common.PublicKeyFromString(nebulaProgramID), nebula.Errors)
+	_, err = nebulaExecutor.BuildAndInvoke(executor.InitNebulaContractInstruction{
+		Instruction:              0,
+		Bft:                      1,
+		NebulaDataType:           nebula.Bytes,
+		GravityContractProgramID: common.PublicKeyFromString(gravityProgramID),
+		InitialOracles:           nebulaExecutor.Deployer().Bytes(),
+	})
+	if !errors.Is(err, nebula.ErrAlreadyInitialized) {
+		t.Fatalf("second init must have been rejected as already initialized, got: %v", err)
+	}
+
 	time.Sleep(time.Second * 25)
 
+	nebulaState, err := ReadNebulaContract(context.Background(), endpoint, nebulaStateAccount.Account.PublicKey)
+	ValidateError(t, err)
+
+	if !nebulaState.IsStateInitialized || nebulaState.Bft != 1 || len(nebulaState.Oracles) != 1 || nebulaState.Oracles[0] != nebulaExecutor.Deployer() {
+		t.Fatalf("unexpected nebula state after init: %+v", nebulaState)
+	}
+
 	// Vital for update oracles (multisig)
 	nebulaExecutor.SetAdditionalSigners([]executor.GravityBftSigner{
 		*executor.NewGravityBftSigner(deployerPrivateKey),
@@ -51,7 +51,7 @@ func init() {
 	viper.BindPFlag("program", SolanoidCmd.Flags().Lookup("program"))
 	newDataAccCmd.MarkFlagRequired("program")
 
-	newDataAccCmd.Flags().StringVarP(&newDataAccPrivateKey, "private-key", "k", "", "private key in base58 encoding")
+	newDataAccCmd.Flags().StringVarP(&newDataAccPrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", SolanoidCmd.Flags().Lookup("private-key"))
 	newDataAccCmd.MarkFlagRequired("private-key")
 
@@ -63,8 +63,13 @@ func init() {
 }
 
 func newAccCommand(ccmd *cobra.Command, args []string) {
+	pk, err := DecodePrivateKey(newDataAccPrivateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	endpoint, _ := InferSystemDefinedRPC()
-	_, _ = GenerateNewAccount(newDataAccPrivateKey, space, programID, endpoint)
+	_, _ = GenerateNewAccount(base58.Encode(pk), space, programID, endpoint)
 	// if err != nil {
 	// 	return
 	// }
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	noncePrivateKey   string
+	nonceAuthority    string
+	nonceRecipient    string
+	nonceLamports     uint64
+	nonceNewAuthority string
+
+	nonceCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "nonce",
+		Short: "Durable nonce accounts for transactions signed longer than the blockhash lives",
+	}
+	nonceCreateCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "create",
+		Short: "Create and initialize the nonce account paid by the key",
+		Args:  cobra.NoArgs,
+		Run:   nonceCreate,
+	}
+	nonceShowCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "show <nonce-account>",
+		Short: "Print the stored nonce and the authority",
+		Args:  cobra.ExactArgs(1),
+		Run:   nonceShow,
+	}
+	nonceAuthorizeCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "authorize <nonce-account>",
+		Short: "Pass the nonce authority, the key must be the current authority",
+		Args:  cobra.ExactArgs(1),
+		Run:   nonceAuthorize,
+	}
+	nonceWithdrawCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "withdraw <nonce-account>",
+		Short: "Withdraw lamports from the nonce account, the key must be the authority",
+		Args:  cobra.ExactArgs(1),
+		Run:   nonceWithdraw,
+	}
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{nonceCreateCmd, nonceAuthorizeCmd, nonceWithdrawCmd} {
+		cmd.Flags().StringVarP(&noncePrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
+		viper.BindPFlag("private-key", cmd.Flags().Lookup("private-key"))
+		cmd.MarkFlagRequired("private-key")
+	}
+	for _, cmd := range []*cobra.Command{nonceCreateCmd, nonceShowCmd, nonceAuthorizeCmd, nonceWithdrawCmd} {
+		cmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	}
+
+	nonceCreateCmd.Flags().StringVar(&nonceAuthority, "authority", "", "nonce authority public key, the payer if omitted")
+
+	nonceAuthorizeCmd.Flags().StringVar(&nonceNewAuthority, "new-authority", "", "new nonce authority public key")
+	nonceAuthorizeCmd.MarkFlagRequired("new-authority")
+
+	nonceWithdrawCmd.Flags().StringVar(&nonceRecipient, "to", "", "recipient public key, the authority if omitted")
+	nonceWithdrawCmd.Flags().Uint64Var(&nonceLamports, "lamports", 0, "lamports to withdraw")
+	nonceWithdrawCmd.MarkFlagRequired("lamports")
+
+	nonceCmd.AddCommand(nonceCreateCmd, nonceShowCmd, nonceAuthorizeCmd, nonceWithdrawCmd)
+	SolanoidCmd.AddCommand(nonceCmd)
+}
+
+func nonceExecutor(endpoint string) (*executor.GenericExecutor, types.Account) {
+	pk, err := DecodePrivateKey(noncePrivateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	account := types.AccountFromPrivateKeyBytes(pk)
+
+	return executor.NewSignerExecutor(executor.NewGravityBftSignerFromAccount(account), endpoint), account
+}
+
+func sendNonceInstructions(ge *executor.GenericExecutor, instructions ...types.Instruction) {
+	response, err := ge.SendAndConfirm(context.Background(), instructions, solclient.CommitmentConfirmed)
+	if response != nil {
+		fmt.Printf("txHash: %v \n", response.TxSignature)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printDurableNonce(endpoint string, account common.PublicKey) {
+	nonce, err := executor.GetDurableNonce(context.Background(), endpoint, account)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Nonce account: %v \n", nonce.Account.ToBase58())
+	fmt.Printf("Nonce: %v \n", nonce.Nonce)
+	fmt.Printf("Authority: %v \n", nonce.Authority.ToBase58())
+	fmt.Printf("Lamports per signature: %v \n", nonce.LamportsPerSignature)
+}
+
+func nonceCreate(ccmd *cobra.Command, args []string) {
+	endpoint := resolveRPCEndpoint(rpcURL)
+	ge, payer := nonceExecutor(endpoint)
+
+	authority := payer.PublicKey
+	if nonceAuthority != "" {
+		authority = common.PublicKeyFromString(nonceAuthority)
+	}
+
+	rent, err := executor.GetNonceAccountRent(context.Background(), endpoint)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the nonce account key signs the creation only, the authority controls it afterwards
+	nonceAccount := types.NewAccount()
+	ge.SetAdditionalSignerDelegates([]executor.SignerDelegate{executor.NewGravityBftSignerFromAccount(nonceAccount)})
+
+	sendNonceInstructions(ge, executor.CreateNonceAccountInstructions(payer.PublicKey, nonceAccount.PublicKey, authority, rent)...)
+	printDurableNonce(endpoint, nonceAccount.PublicKey)
+}
+
+func nonceShow(ccmd *cobra.Command, args []string) {
+	printDurableNonce(resolveRPCEndpoint(rpcURL), common.PublicKeyFromString(args[0]))
+}
+
+func nonceAuthorize(ccmd *cobra.Command, args []string) {
+	endpoint := resolveRPCEndpoint(rpcURL)
+	ge, authority := nonceExecutor(endpoint)
+	nonceAccount := common.PublicKeyFromString(args[0])
+
+	sendNonceInstructions(ge, sysprog.AuthorizeNonceAccount(nonceAccount, authority.PublicKey, common.PublicKeyFromString(nonceNewAuthority)))
+	printDurableNonce(endpoint, nonceAccount)
+}
+
+func nonceWithdraw(ccmd *cobra.Command, args []string) {
+	endpoint := resolveRPCEndpoint(rpcURL)
+	ge, authority := nonceExecutor(endpoint)
+
+	recipient := authority.PublicKey
+	if nonceRecipient != "" {
+		recipient = common.PublicKeyFromString(nonceRecipient)
+	}
+
+	sendNonceInstructions(ge, sysprog.WithdrawNonceAccount(common.PublicKeyFromString(args[0]), authority.PublicKey, recipient, nonceLamports))
+}
@@ -24,26 +24,17 @@ type OperatingAddress struct {
 	PKPath     string
 }
 
+// ReadOperatingAddress reads the keypair once, a keystore reference is unlocked a single time
 func ReadOperatingAddress(t *testing.T, path string) (*OperatingAddress, error) {
-	pubkey, err := ReadAccountAddress(path)
-	if err != nil {
-		return nil, err
-	}
-
-	privateKey, err := ReadPKFromPath(t, path)
-	if err != nil {
-		return nil, err
-	}
-
-	decodedPrivKey, err := base58.Decode(privateKey)
+	account, err := ReadAccountFromPath(path)
 	if err != nil {
 		return nil, err
 	}
 
 	address := &OperatingAddress{
-		Account:    types.AccountFromPrivateKeyBytes(decodedPrivKey),
-		PublicKey:  common.PublicKeyFromString(pubkey),
-		PrivateKey: privateKey,
+		Account:    account,
+		PublicKey:  account.PublicKey,
+		PrivateKey: base58.Encode(account.PrivateKey),
 		PKPath:     path,
 	}
 
@@ -101,29 +92,7 @@ func NewOperatingAddress(t *testing.T, path string, options *OperatingAddressBui
 		}
 	}
 
-	pubkey, err := ReadAccountAddress(path)
-	if err != nil {
-		return nil, err
-	}
-
-	privateKey, err := ReadPKFromPath(t, path)
-	if err != nil {
-		return nil, err
-	}
-
-	decodedPrivKey, err := base58.Decode(privateKey)
-	if err != nil {
-		return nil, err
-	}
-
-	address := &OperatingAddress{
-		Account:    types.AccountFromPrivateKeyBytes(decodedPrivKey),
-		PublicKey:  common.PublicKeyFromString(pubkey),
-		PrivateKey: privateKey,
-		PKPath:     path,
-	}
-
-	return address, nil
+	return ReadOperatingAddress(t, path)
 }
 
 type ConsulsHandler struct {
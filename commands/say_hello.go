@@ -5,7 +5,6 @@ import (
 	"crypto/ed25519"
 	"log"
 
-	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/types"
@@ -39,7 +38,7 @@ func init() {
 	viper.BindPFlag("to", SolanoidCmd.Flags().Lookup("to"))
 	sayHelloCmd.MarkFlagRequired("to")
 
-	sayHelloCmd.Flags().StringVarP(&helloPrivateKey, "private-key", "k", "", "private key in base58 encoding")
+	sayHelloCmd.Flags().StringVarP(&helloPrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", SolanoidCmd.Flags().Lookup("private-key"))
 	sayHelloCmd.MarkFlagRequired("private-key")
 
@@ -47,7 +46,7 @@ func init() {
 }
 
 func hello(ccmd *cobra.Command, args []string) {
-	pk, err := base58.Decode(helloPrivateKey)
+	pk, err := DecodePrivateKey(helloPrivateKey)
 	if err != nil {
 		zap.L().Fatal(err.Error())
 	}
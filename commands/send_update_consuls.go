@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
 	"github.com/portto/solana-go-sdk/types"
@@ -46,7 +45,7 @@ func init() {
 	viper.BindPFlag("multisig-account", updateConsulsCmd.Flags().Lookup("multisig-account"))
 	updateConsulsCmd.MarkFlagRequired("multisig-account")
 
-	updateConsulsCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding")
+	updateConsulsCmd.Flags().StringVarP(&UpdateConsulsPrivateKey, "private-key", "k", "", "private key in base58 encoding or keystore:<path>")
 	viper.BindPFlag("private-key", updateConsulsCmd.Flags().Lookup("private-key"))
 	updateConsulsCmd.MarkFlagRequired("private-key")
 
@@ -96,7 +95,7 @@ func NewUpdateConsulsInstruction(fromAccount, programData, targetProgramID, mult
 }
 
 func updateConsuls(ccmd *cobra.Command, args []string) {
-	pk, err := base58.Decode(UpdateConsulsPrivateKey)
+	pk, err := DecodePrivateKey(UpdateConsulsPrivateKey)
 	if err != nil {
 		zap.L().Fatal(err.Error())
 	}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/Gravity-Tech/solanoid/commands/signer"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// SignerTokenEnv holds the bearer token of the signer server and its clients
+const SignerTokenEnv = "SOLANOID_SIGNER_TOKEN"
+
+var (
+	signerListen   string
+	signerKeys     []string
+	signerPrograms []string
+
+	signerCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "signer",
+		Short: "Remote signer for keys kept off the deployment box",
+	}
+	signerServeCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "serve",
+		Short: "Serve signatures of the keys over HTTP",
+		Long: `Clients authenticate with the bearer token from ` + SignerTokenEnv + `.
+Keys are keypair paths or keystore:<path> references. Serve over TLS or a private network only.`,
+		Args: cobra.NoArgs,
+		Run:  signerServe,
+	}
+)
+
+func init() {
+	signerServeCmd.Flags().StringVarP(&signerListen, "listen", "l", "127.0.0.1:8711", "listen address")
+	viper.BindPFlag("listen", signerServeCmd.Flags().Lookup("listen"))
+
+	signerServeCmd.Flags().StringArrayVar(&signerKeys, "key", nil, "keypair path or keystore:<path>, repeatable")
+	viper.BindPFlag("key", signerServeCmd.Flags().Lookup("key"))
+	signerServeCmd.MarkFlagRequired("key")
+
+	signerServeCmd.Flags().StringArrayVar(&signerPrograms, "allow-program", nil, "sign messages calling these programs only, repeatable")
+	viper.BindPFlag("allow-program", signerServeCmd.Flags().Lookup("allow-program"))
+
+	signerCmd.AddCommand(signerServeCmd)
+	SolanoidCmd.AddCommand(signerCmd)
+}
+
+func signerServe(ccmd *cobra.Command, args []string) {
+	accounts := make([]types.Account, len(signerKeys))
+	for i, path := range signerKeys {
+		account, err := ReadAccountFromPath(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		accounts[i] = account
+		fmt.Printf("Serving key: %v \n", account.PublicKey.ToBase58())
+	}
+
+	server, err := signer.NewServer(os.Getenv(SignerTokenEnv), accounts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(signerPrograms) > 0 {
+		programIDs := make([]common.PublicKey, len(signerPrograms))
+		for i, programID := range signerPrograms {
+			programIDs[i] = common.PublicKeyFromString(programID)
+		}
+		server.Policy = signer.AllowPrograms(programIDs...)
+	}
+
+	log.Fatal(http.ListenAndServe(signerListen, server.Handler()))
+}
@@ -0,0 +1,155 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// DefaultRemoteSignerTimeout bounds a single signing round trip
+const DefaultRemoteSignerTimeout = 10 * time.Second
+
+const (
+	keysPath = "/v1/keys"
+	signPath = "/v1/sign"
+)
+
+var ErrInvalidSignature = errors.New("remote signer returned invalid signature")
+
+type KeysResponse struct {
+	Keys []string `json:"keys"`
+}
+
+// SignRequest carries the serialized transaction message, []byte is encoded as base64
+type SignRequest struct {
+	Pubkey  string `json:"pubkey"`
+	Message []byte `json:"message"`
+}
+
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// RemoteSigner is executor.SignerDelegate of a key held by the signer server.
+// Returned signatures are verified, so a misconfigured server cannot slip in a wrong key.
+type RemoteSigner struct {
+	endpoint string
+	token    string
+	pubkey   common.PublicKey
+	client   *http.Client
+}
+
+// NewRemoteSigner does not reach the server, use ListRemoteKeys to check the key is served
+func NewRemoteSigner(endpoint, token, pubkey string) *RemoteSigner {
+	return &RemoteSigner{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		token:    token,
+		pubkey:   common.PublicKeyFromString(pubkey),
+		client:   &http.Client{Timeout: DefaultRemoteSignerTimeout},
+	}
+}
+
+// DialRemoteSigners returns a signer for every key the server holds
+func DialRemoteSigners(ctx context.Context, endpoint, token string) ([]*RemoteSigner, error) {
+	keys, err := ListRemoteKeys(ctx, endpoint, token)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := make([]*RemoteSigner, len(keys))
+	for i, key := range keys {
+		signers[i] = NewRemoteSigner(endpoint, token, key)
+	}
+
+	return signers, nil
+}
+
+func ListRemoteKeys(ctx context.Context, endpoint, token string) ([]string, error) {
+	signer := NewRemoteSigner(endpoint, token, "")
+
+	response := KeysResponse{}
+	if err := signer.call(ctx, http.MethodGet, keysPath, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Keys, nil
+}
+
+func (signer *RemoteSigner) call(ctx context.Context, method, path string, request, response interface{}) error {
+	var body []byte
+	if request != nil {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return err
+		}
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, method, signer.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if signer.token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+signer.token)
+	}
+
+	httpResponse, err := signer.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	content, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+
+	if httpResponse.StatusCode != http.StatusOK {
+		errorResponse := ErrorResponse{}
+		if json.Unmarshal(content, &errorResponse) == nil && errorResponse.Error != "" {
+			return fmt.Errorf("remote signer: %v", errorResponse.Error)
+		}
+		return fmt.Errorf("remote signer: %v", httpResponse.Status)
+	}
+
+	return json.Unmarshal(content, response)
+}
+
+func (signer *RemoteSigner) Sign(message []byte) ([]byte, error) {
+	response := SignResponse{}
+	err := signer.call(context.Background(), http.MethodPost, signPath, SignRequest{
+		Pubkey:  signer.Pubkey(),
+		Message: message,
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ed25519.Verify(signer.pubkey.Bytes(), message, response.Signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	return response.Signature, nil
+}
+
+func (signer *RemoteSigner) Pubkey() string {
+	return signer.pubkey.ToBase58()
+}
+
+func (signer *RemoteSigner) Meta() types.AccountMeta {
+	return types.AccountMeta{PubKey: signer.pubkey, IsSigner: true, IsWritable: false}
+}
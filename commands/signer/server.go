@@ -0,0 +1,160 @@
+package signer
+
+import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// maxSignRequestSize fits the 1232 bytes transaction limit with base64 and JSON overhead
+const maxSignRequestSize = 4096
+
+// Policy vets the message before the key signs it, e.g. allows known programs only
+type Policy func(pubkey string, message []byte) error
+
+// AllowPrograms lets through transaction messages calling the listed programs only
+func AllowPrograms(programIDs ...common.PublicKey) Policy {
+	allowed := make(map[common.PublicKey]bool, len(programIDs))
+	for _, programID := range programIDs {
+		allowed[programID] = true
+	}
+
+	return func(pubkey string, message []byte) error {
+		programIDs, err := messagePrograms(message)
+		if err != nil {
+			return fmt.Errorf("not a transaction message: %v", err)
+		}
+
+		for _, programID := range programIDs {
+			if !allowed[programID] {
+				return fmt.Errorf("program %v is not allowed", programID.ToBase58())
+			}
+		}
+
+		return nil
+	}
+}
+
+// messagePrograms recovers from the deserializer, it slices the input unchecked
+func messagePrograms(message []byte) (programIDs []common.PublicKey, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed message: %v", r)
+		}
+	}()
+
+	decoded, err := types.MessageDeserialize(message)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instruction := range decoded.DecompileInstructions() {
+		programIDs = append(programIDs, instruction.ProgramID)
+	}
+
+	return programIDs, nil
+}
+
+// Server is the reference signer: it holds ed25519 keys in memory and signs
+// whatever the policy lets through for clients presenting the token
+type Server struct {
+	keys  map[string]ed25519.PrivateKey
+	token string
+
+	Policy Policy
+}
+
+// NewServer refuses to start without a token, the signing box is reachable over the network
+func NewServer(token string, accounts ...types.Account) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("signer token is empty")
+	}
+
+	server := &Server{
+		keys:  make(map[string]ed25519.PrivateKey, len(accounts)),
+		token: token,
+	}
+	for _, account := range accounts {
+		server.keys[account.PublicKey.ToBase58()] = account.PrivateKey
+	}
+
+	return server, nil
+}
+
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(keysPath, server.authorized(http.MethodGet, server.listKeys))
+	mux.HandleFunc(signPath, server.authorized(http.MethodPost, server.sign))
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+func (server *Server) authorized(method string, handler http.HandlerFunc) http.HandlerFunc {
+	expected := []byte("Bearer " + server.token)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%v is expected", method))
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid token"))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (server *Server) listKeys(w http.ResponseWriter, r *http.Request) {
+	keys := make([]string, 0, len(server.keys))
+	for key := range server.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	writeJSON(w, http.StatusOK, KeysResponse{Keys: keys})
+}
+
+func (server *Server) sign(w http.ResponseWriter, r *http.Request) {
+	request := SignRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSignRequestSize)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %v", err))
+		return
+	}
+
+	privateKey, ok := server.keys[request.Pubkey]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown key: %v", request.Pubkey))
+		return
+	}
+
+	if server.Policy != nil {
+		if err := server.Policy(request.Pubkey, request.Message); err != nil {
+			log.Printf("signer: %v refused: %v", request.Pubkey, err)
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	log.Printf("signer: %v signs %v bytes message", request.Pubkey, len(request.Message))
+	writeJSON(w, http.StatusOK, SignResponse{Signature: ed25519.Sign(privateKey, request.Message)})
+}
@@ -0,0 +1,104 @@
+package signer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	consul := types.NewAccount()
+
+	server, err := NewServer("secret", consul)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	signers, err := DialRemoteSigners(context.Background(), httpServer.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 1 || signers[0].Pubkey() != consul.PublicKey.ToBase58() {
+		t.Fatalf("unexpected keys: %+v", signers)
+	}
+
+	var delegate executor.SignerDelegate = signers[0]
+
+	message := []byte("message")
+	signature, err := delegate.Sign(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(consul.PublicKey.Bytes(), message, signature) {
+		t.Fatal("invalid signature")
+	}
+	if meta := delegate.Meta(); meta.PubKey != consul.PublicKey || !meta.IsSigner {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+
+	if _, err := ListRemoteKeys(context.Background(), httpServer.URL, "wrong"); err == nil {
+		t.Fatal("wrong token is accepted")
+	}
+	if _, err := NewRemoteSigner(httpServer.URL, "secret", types.NewAccount().PublicKey.ToBase58()).Sign(message); err == nil {
+		t.Fatal("unknown key signs")
+	}
+	if _, err := NewServer("", consul); err == nil {
+		t.Fatal("server starts without token")
+	}
+}
+
+func TestRemoteSignerVerifiesSignature(t *testing.T) {
+	served, expected := types.NewAccount(), types.NewAccount()
+
+	server, err := NewServer("secret", served)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the server answers for the expected key with a different one
+	server.keys[expected.PublicKey.ToBase58()] = served.PrivateKey
+
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	_, err = NewRemoteSigner(httpServer.URL, "secret", expected.PublicKey.ToBase58()).Sign([]byte("message"))
+	if err != ErrInvalidSignature {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+}
+
+func TestAllowPrograms(t *testing.T) {
+	feePayer := types.NewAccount()
+	allowedProgram := types.NewAccount().PublicKey
+
+	transfer := sysprog.Transfer(feePayer.PublicKey, types.NewAccount().PublicKey, 1)
+	serialize := func(instructions ...types.Instruction) []byte {
+		message := types.NewMessage(feePayer.PublicKey, instructions, common.PublicKey{}.ToBase58())
+		serialized, err := message.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return serialized
+	}
+
+	policy := AllowPrograms(common.SystemProgramID, allowedProgram)
+	if err := policy(feePayer.PublicKey.ToBase58(), serialize(transfer)); err != nil {
+		t.Fatal(err)
+	}
+
+	policy = AllowPrograms(allowedProgram)
+	if err := policy(feePayer.PublicKey.ToBase58(), serialize(transfer)); err == nil {
+		t.Fatal("system program is not allowed")
+	}
+	if err := policy(feePayer.PublicKey.ToBase58(), []byte{1, 0, 1, 200}); err == nil {
+		t.Fatal("malformed message is allowed")
+	}
+}
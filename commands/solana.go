@@ -1,19 +1,20 @@
 package commands
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
-	"os/exec"
-	"regexp"
 	"runtime/debug"
-	"strconv"
-	"strings"
 	"testing"
+	"time"
 
 	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/tokens"
 	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
 )
 
 func ValidateError(t *testing.T, err error) {
@@ -35,81 +36,106 @@ func ValidateErrorExistence(t *testing.T, err error) {
 	t.Logf("Error: %v \n", err)
 }
 
-func SystemAirdropTo(t *testing.T, callerKeyPairPath string, recipient string, amount uint64) error {
-	// t.Logf("transfer %v SOL to %v address \n", amount)
+const LamportsPerSOL = 1_000_000_000
+
+const AirdropConfirmationTimeout = time.Minute
+
+func requestAirdrop(recipient string, lamports uint64) (string, error) {
+	endpoint, err := InferSystemDefinedRPC()
+	if err != nil {
+		return "", err
+	}
 
-	cmd := exec.Command("solana", "airdrop", fmt.Sprint(amount), recipient, "--keypair", callerKeyPairPath)
+	ctx, cancel := context.WithTimeout(context.Background(), AirdropConfirmationTimeout)
+	defer cancel()
+
+	txSig, err := solclient.NewClient(endpoint).RequestAirdrop(ctx, recipient, lamports)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = executor.AwaitConfirmation(ctx, endpoint, txSig, solclient.CommitmentConfirmed, 0)
+	if err != nil {
+		return txSig, err
+	}
 
-	output, err := cmd.CombinedOutput()
-	t.Log(string(output))
+	return txSig, nil
+}
 
+func SystemAirdropTo(t *testing.T, callerKeyPairPath string, recipient string, amount uint64) error {
+	txSig, err := requestAirdrop(recipient, amount*LamportsPerSOL)
 	if err != nil {
 		t.Log(err.Error())
 		return err
 	}
 
+	t.Logf("airdrop %v SOL to %v: %v \n", amount, recipient, txSig)
 	return nil
 }
 
 func SystemAirdrop(t *testing.T, callerKeyPairPath string, amount uint64) error {
-	cmd := exec.Command("solana", "airdrop", fmt.Sprint(amount), "--keypair", callerKeyPairPath)
-
-	output, err := cmd.CombinedOutput()
-	t.Log(string(output))
-
+	recipient, err := ReadAccountAddress(callerKeyPairPath)
 	if err != nil {
 		t.Log(err.Error())
 		return err
 	}
 
-	return nil
+	return SystemAirdropTo(t, callerKeyPairPath, recipient, amount)
 }
 
+// SystemFaucet transfers SOL from the keypair configured in solana CLI config
 func SystemFaucet(t *testing.T, recipient string, amount uint64) error {
 	t.Logf("transfer %v SOL to %v address \n", amount, recipient)
 
-	cmd := exec.Command("solana", "transfer", recipient, fmt.Sprint(amount), "--allow-unfunded-recipient")
+	cfg, err := ReadSystemSolanaConfig()
+	if err != nil {
+		t.Log(err.Error())
+		return err
+	}
 
-	output, err := cmd.CombinedOutput()
-	t.Log(string(output))
+	payer, err := ReadAccountFromPath(cfg.KeypairPath)
+	if err != nil {
+		t.Log(err.Error())
+		return err
+	}
+
+	payerExecutor, err := executor.NewEmptyExecutor(base58.Encode(payer.PrivateKey), cfg.JSONRPCURL)
+	if err != nil {
+		return err
+	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), AirdropConfirmationTimeout)
+	defer cancel()
+
+	response, err := payerExecutor.SendAndConfirm(ctx, []types.Instruction{
+		sysprog.Transfer(payer.PublicKey, common.PublicKeyFromString(recipient), amount*LamportsPerSOL),
+	}, solclient.CommitmentConfirmed)
 	if err != nil {
 		debug.PrintStack()
 		t.Log(err.Error())
 		return err
 	}
 
+	t.Logf("Signature: %v \n", response.TxSignature)
 	return nil
 }
 
-func inferSystemDefinedSolanaConfigParam(prefix string) (string, error) {
-	cmd := exec.Command("solana", "config", "get")
-	output, err := cmd.CombinedOutput()
-
-	rgx, _ := regexp.Compile(prefix + ": .+")
-	result := rgx.Find(output)
-	resultStr := strings.Trim(string(result), "\n\r ")
-	resultList := strings.Split(resultStr, " ")
-
-	fmt.Printf("%v \n", resultList)
-	matchResult := resultList[2]
-
-	fmt.Println(resultList)
-	matchResult = strings.Trim(matchResult, "\n\r")
-
+func InferSystemDefinedWebSocketURL() (string, error) {
+	cfg, err := ReadSystemSolanaConfig()
 	if err != nil {
 		return "", err
 	}
 
-	return matchResult, nil
-}
-
-func InferSystemDefinedWebSocketURL() (string, error) {
-	return inferSystemDefinedSolanaConfigParam("WebSocket URL")
+	return cfg.WebSocketURL, nil
 }
 
 func InferSystemDefinedRPC() (string, error) {
-	return inferSystemDefinedSolanaConfigParam("RPC URL")
+	cfg, err := ReadSystemSolanaConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return cfg.JSONRPCURL, nil
 }
 
 type TokenCreateResult struct {
@@ -118,87 +144,81 @@ type TokenCreateResult struct {
 	Signature string
 }
 
-func trimAndTakeLast(str, del string) string {
-	resultStr := strings.Trim(str, "\n\r ")
-	resultList := strings.Split(resultStr, del)
-	lastEl := resultList[len(resultList)-1]
-	return lastEl
+func newTokenOperator(feePayerPrivateKeysPath string) (*tokens.TokenOperator, types.Account, error) {
+	feePayer, err := ReadAccountFromPath(feePayerPrivateKeysPath)
+	if err != nil {
+		return nil, feePayer, err
+	}
+
+	endpoint, err := InferSystemDefinedRPC()
+	if err != nil {
+		return nil, feePayer, err
+	}
+
+	operator, err := tokens.NewTokenOperator(feePayer, endpoint)
+	if err != nil {
+		return nil, feePayer, err
+	}
+
+	return operator, feePayer, nil
 }
 
-func trimAndTakeAtIndex(str, del string, i int) string {
-	resultStr := strings.Trim(str, "\n\r ")
-	resultList := strings.Split(resultStr, del)
-	lastEl := resultList[i]
-	return lastEl
+func uiAmountForMint(operator *tokens.TokenOperator, mint common.PublicKey, amount float64) (uint64, error) {
+	mintState, err := operator.GetMint(context.Background(), mint)
+	if err != nil {
+		return 0, err
+	}
+
+	return tokens.UIAmountToBaseUnits(amount, mintState.Decimals)
 }
 
 func CreateToken(ownerPrivateKeysPath string) (*TokenCreateResult, error) {
-	decimals := executor.DefaultDecimals
-	cmd := exec.Command("spl-token", "create-token", "--owner", ownerPrivateKeysPath, "--decimals", fmt.Sprintf("%v", decimals))
-	output, err := cmd.CombinedOutput()
-
+	operator, owner, err := newTokenOperator(ownerPrivateKeysPath)
 	if err != nil {
-		fmt.Println(string(output))
 		return nil, err
 	}
 
-	tokenCatchRegex, _ := regexp.Compile("Creating token .+")
-	signatureCatchRegex, _ := regexp.Compile("Signature: .+")
-	tokenAddress := trimAndTakeLast(string(tokenCatchRegex.Find(output)), " ")
-	signature := trimAndTakeLast(string(signatureCatchRegex.Find(output)), " ")
-
-	fmt.Println(tokenAddress)
-	fmt.Println(signature)
-
-	owner, err := ReadAccountAddress(ownerPrivateKeysPath)
+	result, err := operator.CreateMint(context.Background(), owner.PublicKey, nil, executor.DefaultDecimals)
 	if err != nil {
 		return nil, err
 	}
 
+	fmt.Println(result.Mint.ToBase58())
+	fmt.Println(result.Signature)
+
 	return &TokenCreateResult{
-		Token:     common.PublicKeyFromString(tokenAddress),
-		Owner:     common.PublicKeyFromString(owner),
-		Signature: signature,
+		Token:     result.Mint,
+		Owner:     owner.PublicKey,
+		Signature: result.Signature,
 	}, nil
-	// spl-token create-token --owner private-keys/main-deployer.json
 }
 
 func ReadAccountAddress(privateKeysPath string) (string, error) {
-	cmd := exec.Command("solana-keygen", "pubkey", privateKeysPath)
-	output, err := cmd.CombinedOutput()
-
+	account, err := ReadAccountFromPath(privateKeysPath)
 	if err != nil {
-		fmt.Println(string(output))
 		return "", err
 	}
-	result := string(output)
-	account := strings.Trim(result, "\n\r ")
 
-	fmt.Println(account)
-	return account, nil
+	return account.PublicKey.ToBase58(), nil
 }
 
+// ReadAccountBalance returns the balance in SOL
 func ReadAccountBalance(address string) (float64, error) {
-	cmd := exec.Command("solana", "balance", address)
-	output, err := cmd.CombinedOutput()
-
+	lamports, err := ReadAccountBalanceLamports(address)
 	if err != nil {
-		fmt.Println(string(output))
 		return 0, err
 	}
-	result := string(output)
-	resultStr := strings.Trim(string(result), "\n\r ")
-	resultList := strings.Split(resultStr, " ")
-	balance := resultList[0]
 
-	balance = strings.Trim(balance, "\n\r")
-	castedBalance, err := strconv.ParseFloat(balance, 64)
+	return float64(lamports) / LamportsPerSOL, nil
+}
 
+func ReadAccountBalanceLamports(address string) (uint64, error) {
+	endpoint, err := InferSystemDefinedRPC()
 	if err != nil {
 		return 0, err
 	}
 
-	return castedBalance, nil
+	return solclient.NewClient(endpoint).GetBalance(context.Background(), address)
 }
 
 // AccountAddress, PDA, error
@@ -229,125 +249,178 @@ func CreatePersistentAccountWithPDA(path string, forceRewrite bool, seeds [][]by
 }
 
 func CreatePersistedAccount(path string, forceRewrite bool) error {
-	var forceArg string
-	if forceRewrite {
-		forceArg = "--force"
-	}
-
-	cmd := exec.Command("solana-keygen", "new", "-o", path, "--no-bip39-passphrase", forceArg)
-
-	_, err := cmd.CombinedOutput()
+	_, err := GenerateAccountToPath(path, forceRewrite)
+	return err
+}
 
+func ReadSPLTokenBalance(ownerPrivateKeysPath, tokenProgramAddress string) (float64, error) {
+	operator, owner, err := newTokenOperator(ownerPrivateKeysPath)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
-}
-
-func ReadSPLTokenBalance(ownerPrivateKeysPath, tokenProgramAddress string) (float64, error) {
-	cmd := exec.Command("spl-token", "balance", "--owner", ownerPrivateKeysPath, tokenProgramAddress)
-	output, err := cmd.CombinedOutput()
+	mint := common.PublicKeyFromString(tokenProgramAddress)
+	tokenAccount, _, err := common.FindAssociatedTokenAddress(owner.PublicKey, mint)
+	if err != nil {
+		return 0, err
+	}
 
+	mintState, err := operator.GetMint(context.Background(), mint)
 	if err != nil {
 		return 0, err
 	}
-	result := string(output)
-	balance := strings.Trim(result, "\n\r")
-	castedBalance, err := strconv.ParseFloat(balance, 64)
 
+	tokenAccountState, err := operator.GetTokenAccount(context.Background(), tokenAccount)
 	if err != nil {
 		return 0, err
 	}
 
-	return castedBalance, nil
+	return tokens.BaseUnitsToUIAmount(tokenAccountState.Amount, mintState.Decimals), nil
 }
 
-// spl-token transfer <TOKEN_ADDRESS> <TOKEN_AMOUNT> <RECIPIENT_ADDRESS or RECIPIENT_TOKEN_ACCOUNT_ADDRESS> --config <PATH>
+// Transfers from the holder associated token account, recipient associated token account is created if missing
 func TransferSPLTokensAllowUnfunded(tokenHolderPath, tokenAddress, recipient string, amount float64) CreateTokenAccountResponse {
-	cmd := exec.Command("spl-token", "transfer", "--fund-recipient", "--allow-unfunded-recipient", "--owner",
-		tokenHolderPath, tokenAddress, fmt.Sprintf("%v", amount), recipient)
-	output, err := cmd.CombinedOutput()
-	fmt.Printf(string(output))
+	operator, holder, err := newTokenOperator(tokenHolderPath)
+	if err != nil {
+		return CreateTokenAccountResponse{Error: err}
+	}
 
+	mint := common.PublicKeyFromString(tokenAddress)
+	baseAmount, err := uiAmountForMint(operator, mint, amount)
 	if err != nil {
 		return CreateTokenAccountResponse{Error: err}
 	}
 
-	dataAccountCatchRegex, _ := regexp.Compile("Recipient associated token account: .+")
-	tokenDataAccount := trimAndTakeAtIndex(string(dataAccountCatchRegex.Find(output)), " ", 4)
+	source, _, err := common.FindAssociatedTokenAddress(holder.PublicKey, mint)
+	if err != nil {
+		return CreateTokenAccountResponse{Error: err}
+	}
+
+	recipientAccount, err := operator.CreateTokenAccount(context.Background(), mint, common.PublicKeyFromString(recipient))
+	if err != nil {
+		return CreateTokenAccountResponse{Error: err}
+	}
+
+	result, err := operator.Transfer(context.Background(), source, recipientAccount.TokenAccount, holder, baseAmount)
+	if err != nil {
+		return CreateTokenAccountResponse{Error: err}
+	}
+	fmt.Printf("Signature: %v \n", result.Signature)
 
 	return CreateTokenAccountResponse{
-		TokenAccount: tokenDataAccount,
+		TokenAccount: recipientAccount.TokenAccount.ToBase58(),
 	}
 }
 
-// spl-token transfer <TOKEN_ADDRESS> <TOKEN_AMOUNT> <RECIPIENT_ADDRESS or RECIPIENT_TOKEN_ACCOUNT_ADDRESS> --config <PATH>
+// Transfers from the delegate token account, signed by the token holder (either owner or delegate)
 func TransferSPLTokens(tokenHolderPath, tokenAddress, recipientTokenAccountAddress, delegate string, amount float64) error {
-	cmd := exec.Command("spl-token", "transfer", "--owner", tokenHolderPath, "--from", delegate, tokenAddress, fmt.Sprintf("%v", amount), recipientTokenAccountAddress)
-	output, err := cmd.CombinedOutput()
-	fmt.Printf(string(output))
+	operator, holder, err := newTokenOperator(tokenHolderPath)
+	if err != nil {
+		return err
+	}
+
+	baseAmount, err := uiAmountForMint(operator, common.PublicKeyFromString(tokenAddress), amount)
+	if err != nil {
+		return err
+	}
 
+	result, err := operator.Transfer(
+		context.Background(),
+		common.PublicKeyFromString(delegate),
+		common.PublicKeyFromString(recipientTokenAccountAddress),
+		holder,
+		baseAmount,
+	)
 	if err != nil {
 		return err
 	}
+	fmt.Printf("Signature: %v \n", result.Signature)
 
 	return nil
 }
 
-// spl-token approve [FLAGS] [OPTIONS] <TOKEN_ACCOUNT_ADDRESS> <TOKEN_AMOUNT> <DELEGATE_TOKEN_ACCOUNT_ADDRESS>
-func DelegateSPLTokenAmountWithFeePayer(tokenOwnerPath, tokenAccountAddress, delegateTokenAccountAddress string, amount float64) error {
-	cmd := exec.Command("spl-token", "approve", "--fee-payer", tokenOwnerPath, tokenAccountAddress, fmt.Sprintf("%v", amount), delegateTokenAccountAddress)
-	output, err := cmd.CombinedOutput()
-	fmt.Println(string(output))
+// DelegateSPLTokenAmountWithFeePayer approves the delegate on behalf of the token owner, the fee payer pays for the transaction
+func DelegateSPLTokenAmountWithFeePayer(feePayerPath, tokenOwnerPath, tokenAccountAddress, delegateTokenAccountAddress string, amount float64) error {
+	operator, _, err := newTokenOperator(feePayerPath)
+	if err != nil {
+		return err
+	}
 
+	owner, err := ReadAccountFromPath(tokenOwnerPath)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
+	tokenAccount := common.PublicKeyFromString(tokenAccountAddress)
+	tokenAccountState, err := operator.GetTokenAccount(context.Background(), tokenAccount)
+	if err != nil {
+		return err
+	}
 
-// spl-token approve [FLAGS] [OPTIONS] <TOKEN_ACCOUNT_ADDRESS> <TOKEN_AMOUNT> <DELEGATE_TOKEN_ACCOUNT_ADDRESS>
-func DelegateSPLTokenAmount(tokenOwnerPath, tokenAccountAddress, delegateTokenAccountAddress string, amount float64) error {
-	cmd := exec.Command("spl-token", "approve", "--owner", tokenOwnerPath, tokenAccountAddress, fmt.Sprintf("%v", amount), delegateTokenAccountAddress)
-	output, err := cmd.CombinedOutput()
-	fmt.Println(string(output))
+	baseAmount, err := uiAmountForMint(operator, tokenAccountState.Mint, amount)
+	if err != nil {
+		return err
+	}
 
+	result, err := operator.Approve(context.Background(), tokenAccount, common.PublicKeyFromString(delegateTokenAccountAddress), owner, baseAmount)
 	if err != nil {
 		return err
 	}
+	fmt.Printf("Signature: %v \n", result.Signature)
 
 	return nil
 }
 
+func DelegateSPLTokenAmount(tokenOwnerPath, tokenAccountAddress, delegateTokenAccountAddress string, amount float64) error {
+	return DelegateSPLTokenAmountWithFeePayer(tokenOwnerPath, tokenOwnerPath, tokenAccountAddress, delegateTokenAccountAddress, amount)
+}
+
 // On mint we provide token program address & account data address
-// spl-token mint --owner private-keys/token-owner.json $TOKEN_PROGRAM 10 GMuGCTYcCV7FiKg3kQ7LArfZQdhagvUYWNXb1DNZQSGK
 func MintToken(minterPrivateKeysPath, tokenProgramAddress string, amount float64, tokenDataAccount string) error {
-	cmd := exec.Command("spl-token", "mint", "--owner", minterPrivateKeysPath, tokenProgramAddress, fmt.Sprintf("%v", amount), tokenDataAccount)
-	output, err := cmd.CombinedOutput()
-	fmt.Printf(string(output))
+	operator, minter, err := newTokenOperator(minterPrivateKeysPath)
+	if err != nil {
+		return err
+	}
 
+	mint := common.PublicKeyFromString(tokenProgramAddress)
+	baseAmount, err := uiAmountForMint(operator, mint, amount)
 	if err != nil {
-		fmt.Printf(string(output))
+		return err
+	}
 
+	result, err := operator.MintTo(context.Background(), mint, common.PublicKeyFromString(tokenDataAccount), minter, baseAmount)
+	if err != nil {
 		return err
 	}
+	fmt.Printf("Signature: %v \n", result.Signature)
 
 	return nil
 }
 
 // On burn - only token data account address
-// spl-token burn GMuGCTYcCV7FiKg3kQ7LArfZQdhagvUYWNXb1DNZQSGK 1 --owner private-keys/token-owner.json
 func BurnToken(burnerPrivateKeysPath, tokenDataAccount string, amount float64) error {
-	cmd := exec.Command("spl-token", "burn", "--owner", burnerPrivateKeysPath, tokenDataAccount, fmt.Sprintf("%v", amount))
-	output, err := cmd.CombinedOutput()
-	fmt.Printf(string(output))
+	operator, burner, err := newTokenOperator(burnerPrivateKeysPath)
+	if err != nil {
+		return err
+	}
+
+	tokenAccount := common.PublicKeyFromString(tokenDataAccount)
+	tokenAccountState, err := operator.GetTokenAccount(context.Background(), tokenAccount)
+	if err != nil {
+		return err
+	}
 
+	baseAmount, err := uiAmountForMint(operator, tokenAccountState.Mint, amount)
 	if err != nil {
 		return err
 	}
 
+	result, err := operator.Burn(context.Background(), tokenAccount, tokenAccountState.Mint, burner, baseAmount)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Signature: %v \n", result.Signature)
+
 	return nil
 }
 
@@ -357,114 +430,137 @@ type CreateTokenAccountResponse struct {
 }
 
 func CreateTokenAccountWithFeePayer(currentOwnerPrivateKeyPath, tokenAddress string) CreateTokenAccountResponse {
-	cmd := exec.Command("spl-token", "create-account", tokenAddress, "--fee-payer", currentOwnerPrivateKeyPath)
-	output, err := cmd.CombinedOutput()
-	// t.Log(string(output))
-
-	// Creating account GMuGCTYcCV7FiKg3kQ7LArfZQdhagvUYWNXb1DNZQSGK
-	dataAccountCatchRegex, _ := regexp.Compile("Creating account .+")
-	tokenDataAccount := trimAndTakeAtIndex(string(dataAccountCatchRegex.Find(output)), " ", 2)
+	tokenDataAccount, err := CreateTokenAccount(currentOwnerPrivateKeyPath, tokenAddress)
 
 	fmt.Printf("TDA: %v \n", tokenDataAccount)
 
-	if err != nil {
-		return CreateTokenAccountResponse{
-			TokenAccount: tokenDataAccount,
-			Error:        err,
-		}
-	}
-
 	return CreateTokenAccountResponse{
 		TokenAccount: tokenDataAccount,
-		Error:        nil,
+		Error:        err,
 	}
 }
 
 func CreateTokenAccount(currentOwnerPrivateKeyPath, tokenAddress string) (string, error) {
-	cmd := exec.Command("spl-token", "create-account", "--owner", currentOwnerPrivateKeyPath, tokenAddress)
-	output, err := cmd.CombinedOutput()
-
-	// Creating account GMuGCTYcCV7FiKg3kQ7LArfZQdhagvUYWNXb1DNZQSGK
-	dataAccountCatchRegex, _ := regexp.Compile("Creating account .+")
-	tokenDataAccount := trimAndTakeLast(string(dataAccountCatchRegex.Find(output)), " ")
-
-	fmt.Println(tokenDataAccount)
+	operator, owner, err := newTokenOperator(currentOwnerPrivateKeyPath)
+	if err != nil {
+		return "", err
+	}
 
+	result, err := operator.CreateTokenAccount(context.Background(), common.PublicKeyFromString(tokenAddress), owner.PublicKey)
 	if err != nil {
-		fmt.Println(string(output))
 		return "", err
 	}
 
-	return tokenDataAccount, nil
+	fmt.Println(result.TokenAccount.ToBase58())
+
+	return result.TokenAccount.ToBase58(), nil
 }
 
 func AuthorizeToken(t *testing.T, currentOwnerPrivateKeyPath, tokenAddress, authority, recipient string) error {
-	cmd := exec.Command("spl-token", "authorize", "--owner", currentOwnerPrivateKeyPath, tokenAddress, authority, recipient)
-	output, err := cmd.CombinedOutput()
-	t.Log(string(output))
+	authorityType, err := tokens.ParseAuthorityType(authority)
+	if err != nil {
+		return err
+	}
+
+	operator, owner, err := newTokenOperator(currentOwnerPrivateKeyPath)
+	if err != nil {
+		return err
+	}
 
+	newAuthority := common.PublicKeyFromString(recipient)
+	result, err := operator.SetAuthority(context.Background(), common.PublicKeyFromString(tokenAddress), authorityType, owner, &newAuthority)
 	if err != nil {
 		return err
 	}
+	t.Logf("Signature: %v \n", result.Signature)
 
 	return nil
 }
 
-func UpgradeDeployedSolanaProgram(t *testing.T, tag string, deployedProgramAddress, deployerPrivateKeysPath, programBinaryPath string) (string, error) {
-	t.Logf("upgrading program: %v \n", tag)
+func newUpgradeableDeployerFromPath(deployerPrivateKeysPath string) (*UpgradeableDeployer, types.Account, error) {
+	deployer, err := ReadAccountFromPath(deployerPrivateKeysPath)
+	if err != nil {
+		return nil, deployer, err
+	}
+
+	endpoint, err := InferSystemDefinedRPC()
+	if err != nil {
+		return nil, deployer, err
+	}
 
-	cmd := exec.Command("solana", "program", "deploy", "--keypair", deployerPrivateKeysPath, "--program-id", deployedProgramAddress, programBinaryPath)
+	upgradeableDeployer, err := NewUpgradeableDeployer(deployer, endpoint)
+	return upgradeableDeployer, deployer, err
+}
 
-	output, err := cmd.CombinedOutput()
+func UpgradeDeployedSolanaProgram(t *testing.T, tag string, deployedProgramAddress, deployerPrivateKeysPath, programBinaryPath string) (string, error) {
+	t.Logf("upgrading program: %v \n", tag)
 
-	outputList := strings.Split(string(output), " ")
-	programID := outputList[len(outputList)-1]
-	programID = strings.Trim(programID, "\n\r")
+	data, err := ioutil.ReadFile(programBinaryPath)
+	if err != nil {
+		return "", err
+	}
 
-	t.Logf("Program: %v; Upgraded Program ID is: %v\n", tag, programID)
+	upgradeableDeployer, deployer, err := newUpgradeableDeployerFromPath(deployerPrivateKeysPath)
+	if err != nil {
+		return "", err
+	}
 
+	result, err := upgradeableDeployer.UpgradeProgram(context.Background(), common.PublicKeyFromString(deployedProgramAddress), deployer, data)
 	if err != nil {
 		return "", err
 	}
 
+	programID := result.ProgramID.ToBase58()
+	t.Logf("Program: %v; Upgraded Program ID is: %v\n", tag, programID)
+
 	return programID, nil
 }
 
+// DeploySolanaProgram upgrades the program in place when it is already deployed, same as solana program deploy
 func DeploySolanaProgram(t *testing.T, tag string, programPrivateKeysPath, deployerPrivateKeysPath, programBinaryPath string) (string, error) {
 	t.Logf("deploying program: %v \n", tag)
 
-	cmd := exec.Command("solana", "program", "deploy", "--keypair", deployerPrivateKeysPath, "--program-id", programPrivateKeysPath, programBinaryPath)
+	data, err := ioutil.ReadFile(programBinaryPath)
+	if err != nil {
+		return "", err
+	}
 
-	output, err := cmd.CombinedOutput()
+	program, err := ReadAccountFromPath(programPrivateKeysPath)
+	if err != nil {
+		return "", err
+	}
 
-	outputList := strings.Split(string(output), " ")
-	programID := outputList[len(outputList)-1]
-	programID = strings.Trim(programID, "\n\r")
+	upgradeableDeployer, deployer, err := newUpgradeableDeployerFromPath(deployerPrivateKeysPath)
+	if err != nil {
+		return "", err
+	}
 
-	t.Logf("Program: %v; Deployed Program ID is: %v\n", tag, programID)
+	deployed, err := upgradeableDeployer.IsUpgradeableProgramDeployed(context.Background(), program.PublicKey)
+	if err != nil {
+		return "", err
+	}
 
+	var result *ProgramDeployResult
+	if deployed {
+		result, err = upgradeableDeployer.UpgradeProgram(context.Background(), program.PublicKey, deployer, data)
+	} else {
+		result, err = upgradeableDeployer.DeployProgram(context.Background(), program, deployer, data, 0)
+	}
 	if err != nil {
-		t.Log(string(output))
 		return "", err
 	}
 
+	programID := result.ProgramID.ToBase58()
+	t.Logf("Program: %v; Deployed Program ID is: %v\n", tag, programID)
+
 	return programID, nil
 }
 
 func ReadPKFromPath(t *testing.T, path string) (string, error) {
-	result, err := ioutil.ReadFile(path)
+	account, err := ReadAccountFromPath(path)
 	if err != nil {
-		t.Logf("res: %v \n", result)
 		return "", err
 	}
-	var input []byte
-
-	err = json.Unmarshal(result, &input)
-	if err != nil {
-		return "", err
-	}
-
-	encodedPrivKey := base58.Encode(input)
 
-	return encodedPrivKey, nil
+	return base58.Encode(account.PrivateKey), nil
 }
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SolanaCLIConfig is the subset of ~/.config/solana/cli/config.yml used by solanoid
+type SolanaCLIConfig struct {
+	JSONRPCURL   string `yaml:"json_rpc_url"`
+	WebSocketURL string `yaml:"websocket_url"`
+	KeypairPath  string `yaml:"keypair_path"`
+	Commitment   string `yaml:"commitment"`
+}
+
+// SolanaConfigPathEnv overrides the default config location, same as --config of solana CLI
+const SolanaConfigPathEnv = "SOLANA_CONFIG"
+
+func DefaultSolanaConfigPath() (string, error) {
+	if path := os.Getenv(SolanaConfigPathEnv); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "solana", "cli", "config.yml"), nil
+}
+
+func ReadSolanaConfig(path string) (*SolanaCLIConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read solana config: %v", err)
+	}
+
+	cfg := &SolanaCLIConfig{}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("parse solana config %v: %v", path, err)
+	}
+	if cfg.JSONRPCURL == "" {
+		return nil, fmt.Errorf("json_rpc_url is missing in %v", path)
+	}
+
+	if cfg.WebSocketURL == "" {
+		cfg.WebSocketURL, err = WebSocketURLFromRPC(cfg.JSONRPCURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func ReadSystemSolanaConfig() (*SolanaCLIConfig, error) {
+	path, err := DefaultSolanaConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadSolanaConfig(path)
+}
+
+// WebSocketURLFromRPC derives the websocket endpoint the same way solana CLI does:
+// the scheme is switched to ws(s) and an explicit port is incremented by one.
+func WebSocketURLFromRPC(rpcURL string) (string, error) {
+	u, err := url.Parse(rpcURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("unsupported rpc url scheme: %v", rpcURL)
+	}
+
+	if port := u.Port(); port != "" {
+		portNumber, err := strconv.Atoi(port)
+		if err != nil {
+			return "", err
+		}
+		u.Host = fmt.Sprintf("%v:%v", u.Hostname(), portNumber+1)
+	}
+
+	return u.String(), nil
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/keystore"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestReadSolanaConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+
+	content := `---
+json_rpc_url: "http://localhost:8899"
+websocket_url: ""
+keypair_path: /root/.config/solana/id.json
+address_labels:
+  "11111111111111111111111111111111": System Program
+commitment: confirmed
+`
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	ValidateError(t, err)
+
+	cfg, err := ReadSolanaConfig(path)
+	ValidateError(t, err)
+
+	if cfg.JSONRPCURL != "http://localhost:8899" || cfg.WebSocketURL != "ws://localhost:8900" {
+		t.Fatalf("unexpected endpoints: %+v", cfg)
+	}
+	if cfg.KeypairPath != "/root/.config/solana/id.json" || cfg.Commitment != "confirmed" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	_, err = ReadSolanaConfig(filepath.Join(dir, "missing.yml"))
+	ValidateErrorExistence(t, err)
+}
+
+func TestWebSocketURLFromRPC(t *testing.T) {
+	cases := map[string]string{
+		"https://api.devnet.solana.com": "wss://api.devnet.solana.com",
+		"http://127.0.0.1:8899":         "ws://127.0.0.1:8900",
+	}
+
+	for rpc, expected := range cases {
+		ws, err := WebSocketURLFromRPC(rpc)
+		ValidateError(t, err)
+
+		if ws != expected {
+			t.Fatalf("%v: expected %v, got %v", rpc, expected, ws)
+		}
+	}
+}
+
+func TestKeypairRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "id.json")
+
+	account, err := GenerateAccountToPath(path, false)
+	ValidateError(t, err)
+
+	_, err = GenerateAccountToPath(path, false)
+	ValidateErrorExistence(t, err)
+
+	address, err := ReadAccountAddress(path)
+	ValidateError(t, err)
+
+	if address != account.PublicKey.ToBase58() {
+		t.Fatalf("expected %v, got %v", account.PublicKey.ToBase58(), address)
+	}
+}
+
+func TestKeystoreKeypair(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployer.json")
+	account := types.NewAccount()
+
+	err := keystore.StoreKey(path, keystore.NewEd25519Key(account.PrivateKey), "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+	ValidateError(t, err)
+
+	os.Setenv(keystore.PassphraseEnv, "passphrase")
+	defer os.Unsetenv(keystore.PassphraseEnv)
+
+	// keystore files are detected by the content too
+	for _, reference := range []string{keystore.ReferencePrefix + path, path} {
+		address, err := ReadOperatingAddress(t, reference)
+		ValidateError(t, err)
+
+		if address.PublicKey != account.PublicKey || address.Account.PublicKey != account.PublicKey || address.PrivateKey != base58.Encode(account.PrivateKey) {
+			t.Fatalf("%v: unexpected address %v", reference, address.PublicKey.ToBase58())
+		}
+	}
+
+	privateKey, err := DecodePrivateKey(keystore.ReferencePrefix + path)
+	ValidateError(t, err)
+
+	if base58.Encode(privateKey) != base58.Encode(account.PrivateKey) {
+		t.Fatal("unexpected private key")
+	}
+
+	os.Setenv(keystore.PassphraseEnv, "wrong")
+	_, err = ReadAccountFromPath(path)
+	ValidateErrorExistence(t, err)
+}
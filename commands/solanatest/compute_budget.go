@@ -0,0 +1,152 @@
+package solanatest
+
+import (
+	"github.com/portto/solana-go-sdk/common"
+)
+
+var ComputeBudgetProgramID = common.PublicKeyFromString("ComputeBudget111111111111111111111111111111")
+
+const (
+	// DefaultInstructionComputeUnitLimit is the limit every instruction adds when the transaction requests none
+	DefaultInstructionComputeUnitLimit = 200_000
+	MaxComputeUnitLimit                = 1_400_000
+	// InstructionComputeUnits is what any instruction consumes, processors consume more with InvokeContext.ConsumeUnits
+	InstructionComputeUnits = 150
+
+	microLamportsPerLamport = 1_000_000
+	// maxRecentPrioritizationFees is the amount of slots getRecentPrioritizationFees reports, the validator keeps 150
+	maxRecentPrioritizationFees = 150
+)
+
+// compute_budget::ComputeBudgetInstruction tags
+const (
+	computeBudgetRequestUnitsDeprecated = iota
+	computeBudgetRequestHeapFrame
+	computeBudgetSetComputeUnitLimit
+	computeBudgetSetComputeUnitPrice
+)
+
+// computeBudget is what the ComputeBudget instructions of the message request, the runtime reads them before the execution
+type computeBudget struct {
+	unitLimit uint64
+	unitPrice uint64
+}
+
+// fee is the priority fee of the requested limit, in lamports rounded up
+func (budget computeBudget) fee() uint64 {
+	return (budget.unitLimit*budget.unitPrice + microLamportsPerLamport - 1) / microLamportsPerLamport
+}
+
+// computeBudget decodes the requested budget, the error is the JSON form of the transaction error
+func (state *txState) computeBudget() (computeBudget, interface{}) {
+	var (
+		budget                 computeBudget
+		limitIndex, priceIndex = -1, -1
+		instructions           int
+	)
+
+	for i, instruction := range state.message.Instructions {
+		if instruction.ProgramIDIndex >= len(state.message.Accounts) || state.message.Accounts[instruction.ProgramIDIndex] != ComputeBudgetProgramID {
+			instructions++
+			continue
+		}
+
+		data := &instructionData{data: instruction.Data}
+		invalid := map[string]interface{}{"InstructionError": []interface{}{i, ErrInvalidInstructionData.Kind}}
+
+		switch data.u8() {
+		case computeBudgetSetComputeUnitLimit:
+			if limitIndex >= 0 {
+				return budget, map[string]interface{}{"DuplicateInstruction": i}
+			}
+			limitIndex, budget.unitLimit = i, uint64(data.u32())
+		case computeBudgetSetComputeUnitPrice:
+			if priceIndex >= 0 {
+				return budget, map[string]interface{}{"DuplicateInstruction": i}
+			}
+			priceIndex, budget.unitPrice = i, data.u64()
+		default:
+			return budget, invalid
+		}
+		if data.err || len(data.data) > 0 {
+			return budget, invalid
+		}
+	}
+
+	if limitIndex < 0 {
+		budget.unitLimit = uint64(instructions) * DefaultInstructionComputeUnitLimit
+	}
+	if budget.unitLimit > MaxComputeUnitLimit {
+		budget.unitLimit = MaxComputeUnitLimit
+	}
+
+	return budget, nil
+}
+
+// consumeUnits meters the transaction, the meter stops at the limit
+func (state *txState) consumeUnits(units uint64) error {
+	if state.unitsConsumed+units > state.budget.unitLimit {
+		state.unitsConsumed = state.budget.unitLimit
+		return ErrComputationalBudgetExceeded
+	}
+	state.unitsConsumed += units
+	return nil
+}
+
+// processComputeBudget is a no-op, the instructions are decoded before the execution
+func processComputeBudget(ctx *InvokeContext) error {
+	return nil
+}
+
+// PrioritizationFee is the entry of getRecentPrioritizationFees, the fee is in micro-lamports per compute unit
+type PrioritizationFee struct {
+	Slot              uint64 `json:"slot"`
+	PrioritizationFee uint64 `json:"prioritizationFee"`
+}
+
+type slotPrioritizationFee struct {
+	PrioritizationFee
+	writable []common.PublicKey
+}
+
+func (ledger *Ledger) recordPrioritizationFee(slot uint64, state *txState) {
+	fee := slotPrioritizationFee{PrioritizationFee: PrioritizationFee{Slot: slot, PrioritizationFee: state.budget.unitPrice}}
+	for i, pubkey := range state.message.Accounts {
+		if state.isWritable(i) {
+			fee.writable = append(fee.writable, pubkey)
+		}
+	}
+
+	ledger.prioritizationFees = append(ledger.prioritizationFees, fee)
+	if len(ledger.prioritizationFees) > maxRecentPrioritizationFees {
+		ledger.prioritizationFees = ledger.prioritizationFees[1:]
+	}
+}
+
+// RecentPrioritizationFees reports the unit price of the recent transactions, every one lands in a slot of its own.
+// Slots of the transactions which lock none of the accounts report zero, no accounts match every transaction.
+func (ledger *Ledger) RecentPrioritizationFees(accounts []common.PublicKey) []PrioritizationFee {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	fees := make([]PrioritizationFee, 0, len(ledger.prioritizationFees))
+	for _, recorded := range ledger.prioritizationFees {
+		fee := recorded.PrioritizationFee
+		if len(accounts) > 0 && !locksAny(recorded.writable, accounts) {
+			fee.PrioritizationFee = 0
+		}
+		fees = append(fees, fee)
+	}
+	return fees
+}
+
+func locksAny(writable, accounts []common.PublicKey) bool {
+	for _, locked := range writable {
+		for _, account := range accounts {
+			if locked == account {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package solanatest
+
+import "fmt"
+
+// InstructionError is the InstructionError variant of the validator, Custom is set for program errors
+type InstructionError struct {
+	Kind   string
+	Custom *uint32
+}
+
+func (e *InstructionError) Error() string {
+	if e.Custom != nil {
+		return fmt.Sprintf("custom program error: %#x", *e.Custom)
+	}
+	return e.Kind
+}
+
+func CustomError(code uint32) *InstructionError {
+	return &InstructionError{Kind: "Custom", Custom: &code}
+}
+
+var (
+	ErrGenericError              = &InstructionError{Kind: "GenericError"}
+	ErrInvalidArgument           = &InstructionError{Kind: "InvalidArgument"}
+	ErrInvalidInstructionData    = &InstructionError{Kind: "InvalidInstructionData"}
+	ErrInvalidAccountData        = &InstructionError{Kind: "InvalidAccountData"}
+	ErrInsufficientFunds         = &InstructionError{Kind: "InsufficientFunds"}
+	ErrIncorrectProgramID        = &InstructionError{Kind: "IncorrectProgramId"}
+	ErrMissingRequiredSignature  = &InstructionError{Kind: "MissingRequiredSignature"}
+	ErrAccountAlreadyInitialized = &InstructionError{Kind: "AccountAlreadyInitialized"}
+	ErrUninitializedAccount      = &InstructionError{Kind: "UninitializedAccount"}
+	ErrUnbalancedInstruction     = &InstructionError{Kind: "UnbalancedInstruction"}
+	ErrReadonlyLamportChange     = &InstructionError{Kind: "ReadonlyLamportChange"}
+	ErrReadonlyDataModified      = &InstructionError{Kind: "ReadonlyDataModified"}
+	ErrNotEnoughAccountKeys      = &InstructionError{Kind: "NotEnoughAccountKeys"}
+	ErrInvalidSeeds              = &InstructionError{Kind: "InvalidSeeds"}
+	ErrUnsupportedProgramID      = &InstructionError{Kind: "UnsupportedProgramId"}
+	// ErrComputationalBudgetExceeded is the failure of the instruction run out of the compute units
+	ErrComputationalBudgetExceeded = &InstructionError{Kind: "ComputationalBudgetExceeded"}
+)
+
+// system_instruction::SystemError codes
+var (
+	ErrAccountAlreadyInUse        = CustomError(0)
+	ErrResultWithNegativeLamports = CustomError(1)
+	ErrAddressWithSeedMismatch    = CustomError(5)
+	ErrNonceBlockhashNotExpired   = CustomError(7)
+)
+
+// spl_token::error::TokenError codes
+var (
+	ErrTokenInsufficientFunds    = CustomError(1)
+	ErrTokenMintMismatch         = CustomError(3)
+	ErrTokenOwnerMismatch        = CustomError(4)
+	ErrTokenFixedSupply          = CustomError(5)
+	ErrTokenUninitializedState   = CustomError(9)
+	ErrTokenNonNativeHasBalance  = CustomError(11)
+	ErrTokenInvalidInstruction   = CustomError(12)
+	ErrTokenOverflow             = CustomError(14)
+	ErrTokenAuthorityType        = CustomError(15)
+	ErrTokenAccountFrozen        = CustomError(17)
+	ErrTokenMintDecimalsMismatch = CustomError(18)
+)
+
+// instructionErrorJSON is the JSON form of the error as getSignatureStatuses returns it
+func instructionErrorJSON(err error) interface{} {
+	ixErr, ok := err.(*InstructionError)
+	if !ok {
+		ixErr = ErrGenericError
+	}
+
+	if ixErr.Custom != nil {
+		return map[string]interface{}{"Custom": *ixErr.Custom}
+	}
+	return ixErr.Kind
+}
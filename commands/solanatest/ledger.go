@@ -0,0 +1,655 @@
+package solanatest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+const (
+	// LamportsPerSignature is the flat fee charged from the fee payer
+	LamportsPerSignature = 5000
+	// MaxRecentBlockhashes is the amount of blockhashes a message may refer to, the validator keeps 150
+	MaxRecentBlockhashes = 150
+
+	rentLamportsPerByteYear  = 3480
+	rentExemptionYears       = 2
+	rentAccountStorageOffset = 128
+)
+
+// MinimumBalanceForRentExemption follows the default rent of solana-test-validator
+func MinimumBalanceForRentExemption(dataLen uint64) uint64 {
+	return (dataLen + rentAccountStorageOffset) * rentLamportsPerByteYear * rentExemptionYears
+}
+
+// Account is the ledger state of an address, a missing account is the empty system account
+type Account struct {
+	Lamports   uint64
+	Owner      common.PublicKey
+	Data       []byte
+	Executable bool
+}
+
+func (account *Account) clone() *Account {
+	copied := *account
+	copied.Data = append([]byte(nil), account.Data...)
+	return &copied
+}
+
+func (account *Account) equal(other *Account) bool {
+	return account.Lamports == other.Lamports &&
+		account.Owner == other.Owner &&
+		account.Executable == other.Executable &&
+		bytes.Equal(account.Data, other.Data)
+}
+
+func (account *Account) isEmpty() bool {
+	return account.Lamports == 0 && len(account.Data) == 0 && account.Owner == common.SystemProgramID
+}
+
+// SignatureStatus is the outcome of the processed transaction, Err follows the JSON form of
+// the validator TransactionError, e.g. {"InstructionError": [0, {"Custom": 1}]}
+type SignatureStatus struct {
+	Slot uint64
+	Err  interface{}
+}
+
+// Transaction is the landed transaction as getTransaction reports it
+type Transaction struct {
+	Signatures []string
+	Message    types.Message
+	Slot       uint64
+	Fee        uint64
+	Err        interface{}
+	Logs       []string
+}
+
+// Processor executes the instruction of the program against the transaction state.
+// Returned *InstructionError is reported as is, any other error becomes GenericError.
+type Processor func(ctx *InvokeContext) error
+
+// Ledger is the in-memory bank: it verifies signatures, checks the blockhash and applies the instructions
+// of the registered programs. Every transaction is a slot of its own and finalized right away.
+type Ledger struct {
+	mu sync.Mutex
+
+	accounts    map[common.PublicKey]*Account
+	programs    map[common.PublicKey]Processor
+	statuses    map[string]SignatureStatus
+	blockhashes []string
+
+	// transactions are kept in the landing order, the history is the way getSignaturesForAddress walks them
+	transactions map[string]*Transaction
+	history      []string
+
+	prioritizationFees []slotPrioritizationFee
+
+	slot uint64
+}
+
+func NewLedger() *Ledger {
+	ledger := &Ledger{
+		accounts:     make(map[common.PublicKey]*Account),
+		programs:     make(map[common.PublicKey]Processor),
+		statuses:     make(map[string]SignatureStatus),
+		transactions: make(map[string]*Transaction),
+	}
+
+	seed := sha256.Sum256([]byte("solanatest"))
+	ledger.blockhashes = []string{base58.Encode(seed[:])}
+
+	ledger.RegisterProgram(common.SystemProgramID, processSystem)
+	ledger.RegisterProgram(common.TokenProgramID, processToken)
+	ledger.RegisterProgram(common.SPLAssociatedTokenAccountProgramID, processAssociatedToken)
+	ledger.RegisterProgram(ComputeBudgetProgramID, processComputeBudget)
+
+	return ledger
+}
+
+// RegisterProgram makes the ledger execute the program instructions with the processor,
+// so programs under test can be faked in Go
+func (ledger *Ledger) RegisterProgram(programID common.PublicKey, processor Processor) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	ledger.programs[programID] = processor
+	if _, ok := ledger.accounts[programID]; !ok {
+		ledger.accounts[programID] = &Account{Lamports: 1, Owner: common.BPFLoaderProgramID, Executable: true}
+	}
+}
+
+func (ledger *Ledger) Slot() uint64 {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	return ledger.slot
+}
+
+// Blockhash is the latest blockhash, it changes with every processed transaction
+func (ledger *Ledger) Blockhash() string {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	return ledger.latestBlockhash()
+}
+
+func (ledger *Ledger) latestBlockhash() string {
+	return ledger.blockhashes[len(ledger.blockhashes)-1]
+}
+
+func (ledger *Ledger) isRecentBlockhash(blockhash string) bool {
+	for _, recent := range ledger.blockhashes {
+		if recent == blockhash {
+			return true
+		}
+	}
+	return false
+}
+
+func (ledger *Ledger) advanceSlot() uint64 {
+	ledger.slot++
+
+	hash := sha256.Sum256(append([]byte(ledger.latestBlockhash()), byte(ledger.slot), byte(ledger.slot>>8), byte(ledger.slot>>16)))
+	ledger.blockhashes = append(ledger.blockhashes, base58.Encode(hash[:]))
+	if len(ledger.blockhashes) > MaxRecentBlockhashes {
+		ledger.blockhashes = ledger.blockhashes[1:]
+	}
+
+	return ledger.slot
+}
+
+// GetAccount returns a copy of the account state
+func (ledger *Ledger) GetAccount(pubkey common.PublicKey) (Account, bool) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	account, ok := ledger.accounts[pubkey]
+	if !ok {
+		return Account{}, false
+	}
+	return *account.clone(), true
+}
+
+// SetAccount overrides the account state, e.g. to load the state of the program under test
+func (ledger *Ledger) SetAccount(pubkey common.PublicKey, account Account) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	ledger.accounts[pubkey] = account.clone()
+}
+
+func (ledger *Ledger) Balance(pubkey common.PublicKey) uint64 {
+	account, _ := ledger.GetAccount(pubkey)
+	return account.Lamports
+}
+
+// Airdrop credits the lamports in a slot of its own and returns the pseudo signature
+func (ledger *Ledger) Airdrop(pubkey common.PublicKey, lamports uint64) string {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	account, ok := ledger.accounts[pubkey]
+	if !ok {
+		account = &Account{Owner: common.SystemProgramID}
+		ledger.accounts[pubkey] = account
+	}
+	account.Lamports += lamports
+
+	slot := ledger.advanceSlot()
+	hash := sha512.Sum512(append(pubkey.Bytes(), ledger.latestBlockhash()...))
+	signature := base58.Encode(hash[:])
+	ledger.statuses[signature] = SignatureStatus{Slot: slot}
+
+	return signature
+}
+
+func (ledger *Ledger) SignatureStatus(signature string) (SignatureStatus, bool) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	status, ok := ledger.statuses[signature]
+	return status, ok
+}
+
+// TransactionRejected is returned for transactions which do not reach the ledger,
+// the server reports it as the JSON-RPC error
+type TransactionRejected struct {
+	Code    int
+	Message string
+	// Err is the TransactionError of the failed preflight simulation
+	Err  interface{}
+	Logs []string
+}
+
+func (e *TransactionRejected) Error() string {
+	return e.Message
+}
+
+const (
+	rpcSendTransactionPreflightFailure = -32002
+	rpcSignatureVerificationFailure    = -32003
+	rpcInvalidParams                   = -32602
+)
+
+func rejected(code int, err interface{}, format string, args ...interface{}) *TransactionRejected {
+	return &TransactionRejected{Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// decodeTransaction recovers from the deserializer, it slices the input unchecked
+func decodeTransaction(rawTx []byte) (tx types.Transaction, message []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed transaction: %v", r)
+		}
+	}()
+
+	tx, err = types.TransactionDeserialize(rawTx)
+	if err != nil {
+		return tx, nil, err
+	}
+
+	offset := len(common.UintToVarLenBytes(uint64(len(tx.Signatures)))) + len(tx.Signatures)*ed25519.SignatureSize
+	if offset > len(rawTx) {
+		return tx, nil, errors.New("malformed transaction")
+	}
+
+	return tx, rawTx[offset:], nil
+}
+
+// SendTransaction processes the transaction. Transactions failed on execution are rejected as the preflight
+// simulation does, unless skipPreflight is set: then they land with the error status and the fee charged.
+func (ledger *Ledger) SendTransaction(rawTx []byte, skipPreflight bool) (string, error) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	signature, state, txErr, err := ledger.execute(rawTx)
+	if err != nil {
+		return "", err
+	}
+	if txErr != nil && !skipPreflight {
+		rejectedErr := rejected(rpcSendTransactionPreflightFailure, txErr, "Transaction simulation failed: %v", describeTransactionError(txErr))
+		rejectedErr.Logs = state.logs
+		return "", rejectedErr
+	}
+
+	if txErr == nil {
+		state.commit()
+	} else {
+		state.commitFee()
+	}
+
+	slot := ledger.advanceSlot()
+	ledger.statuses[signature] = SignatureStatus{Slot: slot, Err: txErr}
+	ledger.recordPrioritizationFee(slot, state)
+	ledger.recordTransaction(signature, slot, rawTx, state, txErr)
+
+	return signature, nil
+}
+
+func (ledger *Ledger) recordTransaction(signature string, slot uint64, rawTx []byte, state *txState, txErr interface{}) {
+	// the transaction has been decoded by execute already
+	tx, _, _ := decodeTransaction(rawTx)
+
+	signatures := make([]string, len(tx.Signatures))
+	for i, sig := range tx.Signatures {
+		signatures[i] = base58.Encode(sig)
+	}
+
+	ledger.transactions[signature] = &Transaction{
+		Signatures: signatures,
+		Message:    state.message,
+		Slot:       slot,
+		Fee:        state.fee,
+		Err:        txErr,
+		Logs:       append([]string(nil), state.logs...),
+	}
+	ledger.history = append(ledger.history, signature)
+}
+
+// Transaction returns the landed transaction, airdrops are not transactions of the ledger
+func (ledger *Ledger) Transaction(signature string) (Transaction, bool) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	tx, ok := ledger.transactions[signature]
+	if !ok {
+		return Transaction{}, false
+	}
+	return *tx, true
+}
+
+// SignaturesForAddress lists the landed transactions referencing the address, newest first
+func (ledger *Ledger) SignaturesForAddress(pubkey common.PublicKey) []string {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	var signatures []string
+	for i := len(ledger.history) - 1; i >= 0; i-- {
+		signature := ledger.history[i]
+		for _, account := range ledger.transactions[signature].Message.Accounts {
+			if account == pubkey {
+				signatures = append(signatures, signature)
+				break
+			}
+		}
+	}
+	return signatures
+}
+
+// Simulation is the outcome of the transaction executed without committing
+type Simulation struct {
+	Err           interface{}
+	Logs          []string
+	UnitsConsumed uint64
+}
+
+// SimulateTransaction executes the transaction without committing, signatures are verified
+func (ledger *Ledger) SimulateTransaction(rawTx []byte) (*Simulation, error) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	_, state, txErr, err := ledger.execute(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return &Simulation{Err: txErr, Logs: state.logs, UnitsConsumed: state.unitsConsumed}, nil
+}
+
+func (ledger *Ledger) execute(rawTx []byte) (string, *txState, interface{}, error) {
+	tx, serializedMessage, err := decodeTransaction(rawTx)
+	if err != nil {
+		return "", nil, nil, rejected(rpcInvalidParams, nil, "failed to deserialize transaction: %v", err)
+	}
+
+	message := tx.Message
+	for i, signature := range tx.Signatures {
+		if i >= len(message.Accounts) || !ed25519.Verify(message.Accounts[i].Bytes(), serializedMessage, signature) {
+			return "", nil, nil, rejected(rpcSignatureVerificationFailure, nil, "Transaction signature verification failure")
+		}
+	}
+
+	signature := base58.Encode(tx.Signatures[0])
+	if _, ok := ledger.statuses[signature]; ok {
+		return "", nil, nil, rejected(rpcSendTransactionPreflightFailure, "AlreadyProcessed", "Transaction simulation failed: This transaction has already been processed")
+	}
+
+	state := newTxState(ledger, message)
+
+	if !ledger.isRecentBlockhash(message.RecentBlockHash) && !state.isDurableNonce() {
+		return "", nil, nil, rejected(rpcSendTransactionPreflightFailure, "BlockhashNotFound", "Transaction simulation failed: Blockhash not found")
+	}
+
+	budget, budgetErr := state.computeBudget()
+	if budgetErr != nil {
+		return "", nil, nil, rejected(rpcSendTransactionPreflightFailure, budgetErr, "Transaction simulation failed: %v", describeTransactionError(budgetErr))
+	}
+	state.budget = budget
+
+	feePayer := state.load(0)
+	fee := uint64(LamportsPerSignature*len(tx.Signatures)) + budget.fee()
+	if feePayer.Lamports == 0 {
+		return "", nil, nil, rejected(rpcSendTransactionPreflightFailure, "AccountNotFound", "Transaction simulation failed: Attempt to debit an account but found no record of a prior credit.")
+	}
+	if feePayer.Lamports < fee {
+		return "", nil, nil, rejected(rpcSendTransactionPreflightFailure, "InsufficientFundsForFee", "Transaction simulation failed: Insufficient funds for fee")
+	}
+	feePayer.Lamports -= fee
+	state.fee = fee
+
+	for i, instruction := range message.Instructions {
+		if err := state.invoke(instruction); err != nil {
+			return signature, state, map[string]interface{}{"InstructionError": []interface{}{i, instructionErrorJSON(err)}}, nil
+		}
+	}
+
+	if err := state.checkReadonly(); err != nil {
+		return signature, state, map[string]interface{}{"InstructionError": []interface{}{len(message.Instructions) - 1, instructionErrorJSON(err)}}, nil
+	}
+
+	return signature, state, nil, nil
+}
+
+func describeTransactionError(txErr interface{}) string {
+	if value, ok := txErr.(map[string]interface{}); ok {
+		if pair, ok := value["InstructionError"].([]interface{}); ok && len(pair) == 2 {
+			return fmt.Sprintf("Error processing Instruction %v: %v", pair[0], pair[1])
+		}
+	}
+	return fmt.Sprintf("%v", txErr)
+}
+
+// txState is the copy-on-write view of the accounts touched by the transaction
+type txState struct {
+	ledger  *Ledger
+	message types.Message
+
+	accounts map[common.PublicKey]*Account
+	fee      uint64
+
+	budget        computeBudget
+	unitsConsumed uint64
+	// logs follow the validator format, programs add theirs with InvokeContext.Log
+	logs []string
+}
+
+func newTxState(ledger *Ledger, message types.Message) *txState {
+	return &txState{
+		ledger:   ledger,
+		message:  message,
+		accounts: make(map[common.PublicKey]*Account),
+	}
+}
+
+func (state *txState) account(pubkey common.PublicKey) *Account {
+	if account, ok := state.accounts[pubkey]; ok {
+		return account
+	}
+
+	account, ok := state.ledger.accounts[pubkey]
+	if ok {
+		account = account.clone()
+	} else {
+		account = &Account{Owner: common.SystemProgramID}
+	}
+	state.accounts[pubkey] = account
+
+	return account
+}
+
+func (state *txState) load(index int) *Account {
+	return state.account(state.message.Accounts[index])
+}
+
+func (state *txState) isSigner(index int) bool {
+	return index < int(state.message.Header.NumRequireSignatures)
+}
+
+func (state *txState) isWritable(index int) bool {
+	header := state.message.Header
+	signers := int(header.NumRequireSignatures)
+
+	if index < signers {
+		return index < signers-int(header.NumReadonlySignedAccounts)
+	}
+	return index < len(state.message.Accounts)-int(header.NumReadonlyUnsignedAccounts)
+}
+
+// isDurableNonce checks the message is bound to the stored nonce: AdvanceNonceAccount goes first
+// and the message blockhash is the nonce value
+func (state *txState) isDurableNonce() bool {
+	if len(state.message.Instructions) == 0 {
+		return false
+	}
+
+	instruction := state.message.Instructions[0]
+	if instruction.ProgramIDIndex >= len(state.message.Accounts) || len(instruction.Accounts) == 0 ||
+		state.message.Accounts[instruction.ProgramIDIndex] != common.SystemProgramID ||
+		!bytes.Equal(instruction.Data, []byte{systemAdvanceNonceAccount, 0, 0, 0}) {
+		return false
+	}
+
+	nonce, err := decodeNonce(state.load(instruction.Accounts[0]).Data)
+	return err == nil && nonce.initialized && nonce.blockhash.ToBase58() == state.message.RecentBlockHash
+}
+
+func (state *txState) invoke(instruction types.CompiledInstruction) error {
+	if instruction.ProgramIDIndex >= len(state.message.Accounts) {
+		return ErrNotEnoughAccountKeys
+	}
+	programID := state.message.Accounts[instruction.ProgramIDIndex]
+
+	processor, ok := state.ledger.programs[programID]
+	if !ok {
+		return ErrUnsupportedProgramID
+	}
+
+	// the accounts are loaded upfront for the lamports sum to cover them all
+	for _, index := range instruction.Accounts {
+		if index >= len(state.message.Accounts) {
+			return ErrNotEnoughAccountKeys
+		}
+		state.load(index)
+	}
+
+	state.logs = append(state.logs, fmt.Sprintf("Program %v invoke [1]", programID.ToBase58()))
+
+	err := state.consumeUnits(InstructionComputeUnits)
+	if err == nil {
+		before := state.lamports()
+		err = processor(&InvokeContext{ProgramID: programID, Data: instruction.Data, indices: instruction.Accounts, state: state})
+		if err == nil && state.lamports() != before {
+			err = ErrUnbalancedInstruction
+		}
+	}
+	if err != nil {
+		state.logs = append(state.logs, fmt.Sprintf("Program %v failed: %v", programID.ToBase58(), err))
+		return err
+	}
+
+	state.logs = append(state.logs, fmt.Sprintf("Program %v success", programID.ToBase58()))
+	return nil
+}
+
+func (state *txState) lamports() uint64 {
+	var total uint64
+	for _, account := range state.accounts {
+		total += account.Lamports
+	}
+	return total
+}
+
+func (state *txState) checkReadonly() error {
+	for i, pubkey := range state.message.Accounts {
+		account, touched := state.accounts[pubkey]
+		if !touched || state.isWritable(i) {
+			continue
+		}
+
+		original, ok := state.ledger.accounts[pubkey]
+		if !ok {
+			original = &Account{Owner: common.SystemProgramID}
+		}
+		if original.Lamports != account.Lamports {
+			return ErrReadonlyLamportChange
+		}
+		if !original.equal(account) {
+			return ErrReadonlyDataModified
+		}
+	}
+	return nil
+}
+
+func (state *txState) commit() {
+	for pubkey, account := range state.accounts {
+		if account.isEmpty() {
+			delete(state.ledger.accounts, pubkey)
+			continue
+		}
+		state.ledger.accounts[pubkey] = account
+	}
+}
+
+// commitFee charges the fee payer of the failed transaction only
+func (state *txState) commitFee() {
+	feePayer := state.message.Accounts[0]
+	if account, ok := state.ledger.accounts[feePayer]; ok {
+		account.Lamports -= state.fee
+	}
+}
+
+// InvokeContext is the instruction being executed, accounts are addressed by their instruction index
+type InvokeContext struct {
+	ProgramID common.PublicKey
+	Data      []byte
+
+	indices []int
+	state   *txState
+}
+
+func (ctx *InvokeContext) NumAccounts() int {
+	return len(ctx.indices)
+}
+
+func (ctx *InvokeContext) checkIndex(i int) error {
+	if i >= len(ctx.indices) {
+		return ErrNotEnoughAccountKeys
+	}
+	return nil
+}
+
+func (ctx *InvokeContext) Key(i int) (common.PublicKey, error) {
+	if err := ctx.checkIndex(i); err != nil {
+		return common.PublicKey{}, err
+	}
+	return ctx.state.message.Accounts[ctx.indices[i]], nil
+}
+
+// Account is the mutable state of the instruction account, changes are committed if the transaction succeeds
+func (ctx *InvokeContext) Account(i int) (*Account, error) {
+	if err := ctx.checkIndex(i); err != nil {
+		return nil, err
+	}
+	return ctx.state.load(ctx.indices[i]), nil
+}
+
+func (ctx *InvokeContext) IsSigner(i int) bool {
+	return i < len(ctx.indices) && ctx.state.isSigner(ctx.indices[i])
+}
+
+func (ctx *InvokeContext) IsWritable(i int) bool {
+	return i < len(ctx.indices) && ctx.state.isWritable(ctx.indices[i])
+}
+
+// Signed tells whether the key signed the transaction, authorities are checked this way
+func (ctx *InvokeContext) Signed(pubkey common.PublicKey) bool {
+	for i := 0; i < int(ctx.state.message.Header.NumRequireSignatures); i++ {
+		if ctx.state.message.Accounts[i] == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// ConsumeUnits charges the compute meter, so heavy handlers can be faked. The error is to be returned
+// by the processor, the validator aborts the instruction once the limit is exceeded.
+func (ctx *InvokeContext) ConsumeUnits(units uint64) error {
+	return ctx.state.consumeUnits(units)
+}
+
+// Log adds the "Program log:" line, the way msg! does
+func (ctx *InvokeContext) Log(format string, args ...interface{}) {
+	ctx.state.logs = append(ctx.state.logs, "Program log: "+fmt.Sprintf(format, args...))
+}
+
+// Blockhash is the blockhash of the slot the transaction is processed in
+func (ctx *InvokeContext) Blockhash() string {
+	return ctx.state.ledger.latestBlockhash()
+}
@@ -0,0 +1,283 @@
+package solanatest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func signedTransfer(t *testing.T, from types.Account, to common.PublicKey, lamports uint64, blockhash string) []byte {
+	rawTx, err := types.CreateRawTransaction(types.CreateRawTransactionParam{
+		Instructions:    []types.Instruction{sysprog.Transfer(from.PublicKey, to, lamports)},
+		Signers:         []types.Account{from},
+		FeePayer:        from.PublicKey,
+		RecentBlockHash: blockhash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rawTx
+}
+
+func TestServerTransfer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	c := solclient.NewClient(server.URL)
+	payer, recipient := types.NewAccount(), types.NewAccount()
+
+	if _, err := c.RequestAirdrop(ctx, payer.PublicKey.ToBase58(), 1_000_000_000); err != nil {
+		t.Fatal(err)
+	}
+
+	ge := executor.NewSignerExecutor(executor.NewGravityBftSignerFromAccount(payer), server.URL)
+	response, err := ge.SendAndConfirm(ctx, []types.Instruction{
+		sysprog.Transfer(payer.PublicKey, recipient.PublicKey, 1000),
+	}, solclient.CommitmentFinalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ConfirmationStatus != string(solclient.CommitmentFinalized) {
+		t.Fatalf("unexpected confirmation: %+v", response)
+	}
+
+	balance, err := c.GetBalance(ctx, recipient.PublicKey.ToBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 1000 || server.Balance(payer.PublicKey) != 1_000_000_000-1000-LamportsPerSignature {
+		t.Fatalf("unexpected balances: %v, %v", balance, server.Balance(payer.PublicKey))
+	}
+
+	// the same transaction is processed once
+	recent, err := c.GetRecentBlockhash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawTx := signedTransfer(t, payer, recipient.PublicKey, 1, recent.Blockhash)
+	if _, err := c.SendRawTransaction(ctx, rawTx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendRawTransaction(ctx, rawTx); err == nil {
+		t.Fatal("transaction is processed twice")
+	}
+
+	// tampered signature
+	rawTx = signedTransfer(t, payer, recipient.PublicKey, 2, server.Blockhash())
+	rawTx[1] ^= 0xff
+	if _, err := c.SendRawTransaction(ctx, rawTx); err == nil {
+		t.Fatal("invalid signature is accepted")
+	}
+
+	if _, err := c.SendRawTransaction(ctx, signedTransfer(t, payer, recipient.PublicKey, 2, types.NewAccount().PublicKey.ToBase58())); err == nil {
+		t.Fatal("unknown blockhash is accepted")
+	}
+}
+
+func TestServerFailedTransaction(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	payer, recipient := types.NewAccount(), types.NewAccount()
+	server.Airdrop(payer.PublicKey, 1_000_000)
+
+	// the second transfer fails, so the first one is rolled back
+	ge := executor.NewSignerExecutor(executor.NewGravityBftSignerFromAccount(payer), server.URL)
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{
+		sysprog.Transfer(payer.PublicKey, recipient.PublicKey, 1000),
+		sysprog.Transfer(payer.PublicKey, recipient.PublicKey, 10_000_000),
+	}, solclient.CommitmentFinalized); err == nil {
+		t.Fatal("overdraft is accepted")
+	}
+	if server.Balance(recipient.PublicKey) != 0 || server.Balance(payer.PublicKey) != 1_000_000 {
+		t.Fatal("failed transaction changed the balances")
+	}
+
+	// skipped preflight lands the failure with the fee charged
+	rawTx := signedTransfer(t, payer, recipient.PublicKey, 10_000_000, server.Blockhash())
+	signature, err := server.SendTransaction(rawTx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmation, err := executor.AwaitConfirmation(ctx, server.URL, signature, solclient.CommitmentFinalized, 0)
+	if err == nil || confirmation == nil || confirmation.Err == nil {
+		t.Fatalf("expected failed confirmation, got %+v, %v", confirmation, err)
+	}
+	if server.Balance(payer.PublicKey) != 1_000_000-LamportsPerSignature {
+		t.Fatalf("fee is not charged: %v", server.Balance(payer.PublicKey))
+	}
+
+	// the simulation reports the error without applying the transaction
+	ge.SetSimulate(true)
+	response, err := ge.SendAndConfirm(ctx, []types.Instruction{
+		sysprog.Transfer(payer.PublicKey, recipient.PublicKey, 10_000_000),
+	}, solclient.CommitmentFinalized)
+	if err == nil {
+		t.Fatal("simulation error is not returned")
+	}
+	if response == nil || response.Simulation == nil || response.Simulation.Err == nil || response.Simulation.Err.Custom == nil || *response.Simulation.Err.Custom != 1 {
+		t.Fatalf("unexpected simulation: %+v", response.Simulation)
+	}
+}
+
+func TestServerDurableNonce(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	payer, nonceAccount := types.NewAccount(), types.NewAccount()
+	server.Airdrop(payer.PublicKey, 1_000_000_000)
+
+	ge := executor.NewSignerExecutor(executor.NewGravityBftSignerFromAccount(payer), server.URL)
+	ge.SetAdditionalSignerDelegates([]executor.SignerDelegate{executor.NewGravityBftSignerFromAccount(nonceAccount)})
+
+	rent, err := executor.GetNonceAccountRent(ctx, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ge.SendAndConfirm(ctx, executor.CreateNonceAccountInstructions(payer.PublicKey, nonceAccount.PublicKey, payer.PublicKey, rent), solclient.CommitmentFinalized); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, err := executor.GetDurableNonce(ctx, server.URL, nonceAccount.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := executor.NewOfflineNonceTransaction(payer.PublicKey, nonce, []types.Instruction{
+		sysprog.Transfer(payer.PublicKey, types.NewAccount().PublicKey, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Sign(executor.NewGravityBftSignerFromAccount(payer)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the nonce outlives the recent blockhashes
+	for i := 0; i <= MaxRecentBlockhashes; i++ {
+		server.Airdrop(types.NewAccount().PublicKey, 1)
+	}
+
+	if _, err := tx.Send(ctx, server.URL, solclient.CommitmentFinalized); err != nil {
+		t.Fatal(err)
+	}
+
+	advanced, err := executor.GetDurableNonce(ctx, server.URL, nonceAccount.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advanced.Nonce == nonce.Nonce {
+		t.Fatal("nonce is not advanced")
+	}
+	if _, err := tx.Send(ctx, server.URL, solclient.CommitmentFinalized); err == nil {
+		t.Fatal("used nonce is accepted")
+	}
+}
+
+func TestServerComputeBudget(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	payer, nonceAccount := types.NewAccount(), types.NewAccount()
+	server.Airdrop(payer.PublicKey, 1_000_000_000)
+
+	ge := executor.NewSignerExecutor(executor.NewGravityBftSignerFromAccount(payer), server.URL)
+	ge.SetAdditionalSignerDelegates([]executor.SignerDelegate{executor.NewGravityBftSignerFromAccount(nonceAccount)})
+
+	rent, err := executor.GetNonceAccountRent(ctx, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ge.SendAndConfirm(ctx, executor.CreateNonceAccountInstructions(payer.PublicKey, nonceAccount.PublicKey, payer.PublicKey, rent), solclient.CommitmentFinalized); err != nil {
+		t.Fatal(err)
+	}
+	ge.EraseAdditionalSigners()
+
+	// the budget instructions follow AdvanceNonceAccount
+	ge.SetDurableNonceAccount(nonceAccount.PublicKey)
+	ge.SetComputeBudget(executor.ComputeBudget{AutoUnitLimit: true, UnitPrice: 1_000_000})
+
+	before := server.Balance(payer.PublicKey)
+	response, err := ge.SendAndConfirm(ctx, []types.Instruction{
+		sysprog.Transfer(payer.PublicKey, types.NewAccount().PublicKey, 1),
+	}, solclient.CommitmentFinalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// advance, limit, price and transfer consume the flat units, the limit gets the default margin
+	unitLimit := uint64(4 * InstructionComputeUnits * 6 / 5)
+	if fee := before - server.Balance(payer.PublicKey) - 1; fee != LamportsPerSignature+unitLimit {
+		t.Fatalf("unexpected fee: %v", fee)
+	}
+
+	fees := server.RecentPrioritizationFees([]common.PublicKey{nonceAccount.PublicKey})
+	if status, _ := server.SignatureStatus(response.TxSignature); fees[len(fees)-1] != (PrioritizationFee{Slot: status.Slot, PrioritizationFee: 1_000_000}) {
+		t.Fatalf("unexpected recent fees: %+v", fees)
+	}
+
+	ge.EraseDurableNonceAccount()
+	ge.EraseComputeBudget()
+
+	_, err = ge.SendAndConfirm(ctx, []types.Instruction{
+		executor.SetComputeUnitLimit(1_000),
+		executor.SetComputeUnitLimit(1_000),
+		sysprog.Transfer(payer.PublicKey, types.NewAccount().PublicKey, 1),
+	}, solclient.CommitmentFinalized)
+	if err == nil || !strings.Contains(err.Error(), "DuplicateInstruction") {
+		t.Fatalf("expected duplicate instruction error, got %v", err)
+	}
+}
+
+func TestServerTransactionHistory(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	c := solclient.NewClient(server.URL)
+	payer, recipient := types.NewAccount(), types.NewAccount()
+	server.Airdrop(payer.PublicKey, 1_000_000_000)
+
+	var sent []string
+	for i := uint64(1); i <= 3; i++ {
+		signature, err := server.SendTransaction(signedTransfer(t, payer, recipient.PublicKey, i, server.Blockhash()), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, signature)
+	}
+
+	// newest first, until is exclusive
+	signatures, err := c.GetConfirmedSignaturesForAddress(ctx, recipient.PublicKey.ToBase58(), solclient.GetConfirmedSignaturesForAddressConfig{Until: sent[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != 2 || signatures[0].Signature != sent[2] || signatures[1].Signature != sent[1] {
+		t.Fatalf("unexpected signatures: %+v", signatures)
+	}
+
+	tx, err := executor.GetTransaction(ctx, server.URL, sent[2], solclient.CommitmentFinalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx == nil || tx.Slot != uint64(signatures[0].Slot) || tx.Transaction.Signatures[0] != sent[2] || tx.Meta.Fee != LamportsPerSignature ||
+		tx.Transaction.Message.AccountKeys[1] != recipient.PublicKey.ToBase58() {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+
+	if tx, err := executor.GetTransaction(ctx, server.URL, payer.PublicKey.ToBase58(), solclient.CommitmentFinalized); err != nil || tx != nil {
+		t.Fatalf("unknown transaction is served: %+v, %v", tx, err)
+	}
+}
@@ -0,0 +1,482 @@
+package solanatest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/common"
+)
+
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+
+	slotsPerEpoch = 432000
+)
+
+// Server is the JSON-RPC stand-in of the validator over the in-memory ledger,
+// pass URL wherever the RPC endpoint is expected
+type Server struct {
+	*Ledger
+
+	URL string
+
+	server  *httptest.Server
+	methods map[string]func(params []json.RawMessage) (interface{}, error)
+}
+
+func NewServer() *Server {
+	return NewLedgerServer(NewLedger())
+}
+
+// NewLedgerServer serves the prepared ledger, e.g. with the programs under test registered
+func NewLedgerServer(ledger *Ledger) *Server {
+	server := &Server{Ledger: ledger}
+	server.methods = map[string]func(params []json.RawMessage) (interface{}, error){
+		"getRecentBlockhash":                server.getRecentBlockhash,
+		"getLatestBlockhash":                server.getLatestBlockhash,
+		"getBlockHeight":                    server.getSlot,
+		"getSlot":                           server.getSlot,
+		"sendTransaction":                   server.sendTransaction,
+		"simulateTransaction":               server.simulateTransaction,
+		"getAccountInfo":                    server.getAccountInfo,
+		"getBalance":                        server.getBalance,
+		"getMinimumBalanceForRentExemption": server.getMinimumBalanceForRentExemption,
+		"requestAirdrop":                    server.requestAirdrop,
+		"getSignatureStatuses":              server.getSignatureStatuses,
+		"getRecentPrioritizationFees":       server.getRecentPrioritizationFees,
+		"getEpochInfo":                      server.getEpochInfo,
+		"getSignaturesForAddress":           server.getSignaturesForAddress,
+		"getConfirmedSignaturesForAddress2": server.getSignaturesForAddress,
+		"getTransaction":                    server.getTransaction,
+		"getConfirmedTransaction":           server.getTransaction,
+	}
+	server.server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	server.URL = server.server.URL
+
+	return server
+}
+
+func (server *Server) Close() {
+	server.server.Close()
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (server *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{"jsonrpc": "2.0"}
+
+	request := rpcRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response["id"] = nil
+		response["error"] = rpcError{Code: rpcParseError, Message: err.Error()}
+		writeResponse(w, response)
+		return
+	}
+	response["id"] = request.ID
+
+	method, ok := server.methods[request.Method]
+	if !ok {
+		response["error"] = rpcError{Code: rpcMethodNotFound, Message: "Method not found"}
+		writeResponse(w, response)
+		return
+	}
+
+	result, err := method(request.Params)
+	switch err := err.(type) {
+	case nil:
+		response["result"] = result
+	case *TransactionRejected:
+		var data interface{}
+		if err.Err != nil {
+			logs := err.Logs
+			if logs == nil {
+				logs = []string{}
+			}
+			data = map[string]interface{}{"err": err.Err, "logs": logs}
+		}
+		response["error"] = rpcError{Code: err.Code, Message: err.Message, Data: data}
+	default:
+		response["error"] = rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+
+	writeResponse(w, response)
+}
+
+func writeResponse(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// param decodes the positional parameter, missing optional parameters are left zero
+func param(params []json.RawMessage, i int, value interface{}, required bool) error {
+	if i >= len(params) || string(params[i]) == "null" {
+		if required {
+			return fmt.Errorf("missing parameter #%d", i)
+		}
+		return nil
+	}
+	if err := json.Unmarshal(params[i], value); err != nil {
+		return fmt.Errorf("invalid parameter #%d: %v", i, err)
+	}
+	return nil
+}
+
+func pubkeyParam(params []json.RawMessage, i int) (common.PublicKey, error) {
+	var address string
+	if err := param(params, i, &address, true); err != nil {
+		return common.PublicKey{}, err
+	}
+
+	decoded, err := base58.Decode(address)
+	if err != nil || len(decoded) != 32 {
+		return common.PublicKey{}, fmt.Errorf("Invalid param: WrongSize")
+	}
+	return common.PublicKeyFromBytes(decoded), nil
+}
+
+func (server *Server) withContext(value interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"context": map[string]interface{}{"slot": server.Slot()},
+		"value":   value,
+	}
+}
+
+func (server *Server) getRecentBlockhash(params []json.RawMessage) (interface{}, error) {
+	return server.withContext(map[string]interface{}{
+		"blockhash":     server.Blockhash(),
+		"feeCalculator": map[string]interface{}{"lamportsPerSignature": LamportsPerSignature},
+	}), nil
+}
+
+func (server *Server) getLatestBlockhash(params []json.RawMessage) (interface{}, error) {
+	return server.withContext(map[string]interface{}{
+		"blockhash":            server.Blockhash(),
+		"lastValidBlockHeight": server.Slot() + MaxRecentBlockhashes,
+	}), nil
+}
+
+// getEpochInfo reports the single epoch of the ledger, every slot has a block
+func (server *Server) getEpochInfo(params []json.RawMessage) (interface{}, error) {
+	slot := server.Slot()
+
+	return map[string]interface{}{
+		"absoluteSlot": slot,
+		"blockHeight":  slot,
+		"epoch":        0,
+		"slotIndex":    slot,
+		"slotsInEpoch": slotsPerEpoch,
+	}, nil
+}
+
+func (server *Server) getSlot(params []json.RawMessage) (interface{}, error) {
+	return server.Slot(), nil
+}
+
+type transactionConfig struct {
+	Encoding      string `json:"encoding"`
+	SkipPreflight bool   `json:"skipPreflight"`
+}
+
+func decodeWire(encoded, encoding string) ([]byte, error) {
+	switch encoding {
+	case "base64":
+		return base64.StdEncoding.DecodeString(encoded)
+	case "", "base58":
+		return base58.Decode(encoded)
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %v", encoding)
+	}
+}
+
+func transactionParams(params []json.RawMessage) ([]byte, transactionConfig, error) {
+	var encoded string
+	config := transactionConfig{}
+	if err := param(params, 0, &encoded, true); err != nil {
+		return nil, config, err
+	}
+	if err := param(params, 1, &config, false); err != nil {
+		return nil, config, err
+	}
+
+	rawTx, err := decodeWire(encoded, config.Encoding)
+	if err != nil {
+		return nil, config, fmt.Errorf("invalid transaction encoding: %v", err)
+	}
+	return rawTx, config, nil
+}
+
+func (server *Server) sendTransaction(params []json.RawMessage) (interface{}, error) {
+	rawTx, config, err := transactionParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.SendTransaction(rawTx, config.SkipPreflight)
+}
+
+func (server *Server) simulateTransaction(params []json.RawMessage) (interface{}, error) {
+	rawTx, _, err := transactionParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation, err := server.SimulateTransaction(rawTx)
+	if rejectedErr, ok := err.(*TransactionRejected); ok && rejectedErr.Err != nil {
+		simulation, err = &Simulation{Err: rejectedErr.Err, Logs: rejectedErr.Logs}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if simulation.Logs == nil {
+		simulation.Logs = []string{}
+	}
+
+	return server.withContext(map[string]interface{}{
+		"err":           simulation.Err,
+		"logs":          simulation.Logs,
+		"unitsConsumed": simulation.UnitsConsumed,
+	}), nil
+}
+
+func (server *Server) getAccountInfo(params []json.RawMessage) (interface{}, error) {
+	pubkey, err := pubkeyParam(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	config := struct {
+		Encoding string `json:"encoding"`
+	}{}
+	if err := param(params, 1, &config, false); err != nil {
+		return nil, err
+	}
+
+	account, ok := server.GetAccount(pubkey)
+	if !ok {
+		return server.withContext(nil), nil
+	}
+
+	var data interface{}
+	switch config.Encoding {
+	case "base64", "jsonParsed":
+		data = []string{base64.StdEncoding.EncodeToString(account.Data), "base64"}
+	case "base58":
+		data = []string{base58.Encode(account.Data), "base58"}
+	case "", "binary":
+		data = base58.Encode(account.Data)
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %v", config.Encoding)
+	}
+
+	return server.withContext(map[string]interface{}{
+		"data":       data,
+		"owner":      account.Owner.ToBase58(),
+		"lamports":   account.Lamports,
+		"executable": account.Executable,
+		"rentEpoch":  0,
+	}), nil
+}
+
+func (server *Server) getBalance(params []json.RawMessage) (interface{}, error) {
+	pubkey, err := pubkeyParam(params, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.withContext(server.Balance(pubkey)), nil
+}
+
+func (server *Server) getMinimumBalanceForRentExemption(params []json.RawMessage) (interface{}, error) {
+	var dataLen uint64
+	if err := param(params, 0, &dataLen, true); err != nil {
+		return nil, err
+	}
+
+	return MinimumBalanceForRentExemption(dataLen), nil
+}
+
+func (server *Server) requestAirdrop(params []json.RawMessage) (interface{}, error) {
+	pubkey, err := pubkeyParam(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	var lamports uint64
+	if err := param(params, 1, &lamports, true); err != nil {
+		return nil, err
+	}
+
+	return server.Airdrop(pubkey, lamports), nil
+}
+
+func (server *Server) getSignatureStatuses(params []json.RawMessage) (interface{}, error) {
+	var signatures []string
+	if err := param(params, 0, &signatures, true); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]interface{}, len(signatures))
+	for i, signature := range signatures {
+		status, ok := server.SignatureStatus(signature)
+		if !ok {
+			continue
+		}
+
+		result := map[string]interface{}{"Ok": nil}
+		if status.Err != nil {
+			result = map[string]interface{}{"Err": status.Err}
+		}
+		statuses[i] = map[string]interface{}{
+			"slot":               status.Slot,
+			"confirmations":      nil,
+			"err":                status.Err,
+			"status":             result,
+			"confirmationStatus": "finalized",
+		}
+	}
+
+	return server.withContext(statuses), nil
+}
+
+func (server *Server) getRecentPrioritizationFees(params []json.RawMessage) (interface{}, error) {
+	var addresses []string
+	if err := param(params, 0, &addresses, false); err != nil {
+		return nil, err
+	}
+
+	accounts := make([]common.PublicKey, len(addresses))
+	for i, address := range addresses {
+		decoded, err := base58.Decode(address)
+		if err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("Invalid param: WrongSize")
+		}
+		accounts[i] = common.PublicKeyFromBytes(decoded)
+	}
+
+	return server.RecentPrioritizationFees(accounts), nil
+}
+
+// getSignaturesForAddress pages the history newest first, before and until signatures are exclusive
+func (server *Server) getSignaturesForAddress(params []json.RawMessage) (interface{}, error) {
+	pubkey, err := pubkeyParam(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	config := struct {
+		Limit  int    `json:"limit"`
+		Before string `json:"before"`
+		Until  string `json:"until"`
+	}{}
+	if err := param(params, 1, &config, false); err != nil {
+		return nil, err
+	}
+	if config.Limit <= 0 || config.Limit > 1000 {
+		config.Limit = 1000
+	}
+
+	signatures := server.SignaturesForAddress(pubkey)
+	if config.Before != "" {
+		for i, signature := range signatures {
+			if signature == config.Before {
+				signatures = signatures[i+1:]
+				break
+			}
+		}
+	}
+
+	result := []interface{}{}
+	for _, signature := range signatures {
+		if signature == config.Until || len(result) == config.Limit {
+			break
+		}
+
+		tx, _ := server.Transaction(signature)
+		result = append(result, map[string]interface{}{
+			"signature": signature,
+			"slot":      tx.Slot,
+			"err":       tx.Err,
+			"memo":      nil,
+			"blockTime": nil,
+		})
+	}
+
+	return result, nil
+}
+
+// getTransaction serves the json encoding only, the ledger does not run CPI so inner instructions are empty
+func (server *Server) getTransaction(params []json.RawMessage) (interface{}, error) {
+	var signature string
+	if err := param(params, 0, &signature, true); err != nil {
+		return nil, err
+	}
+
+	tx, ok := server.Transaction(signature)
+	if !ok {
+		return nil, nil
+	}
+
+	message := tx.Message
+	accountKeys := make([]string, len(message.Accounts))
+	for i, account := range message.Accounts {
+		accountKeys[i] = account.ToBase58()
+	}
+	instructions := make([]interface{}, len(message.Instructions))
+	for i, instruction := range message.Instructions {
+		accounts := instruction.Accounts
+		if accounts == nil {
+			accounts = []int{}
+		}
+		instructions[i] = map[string]interface{}{
+			"programIdIndex": instruction.ProgramIDIndex,
+			"accounts":       accounts,
+			"data":           base58.Encode(instruction.Data),
+		}
+	}
+
+	status := map[string]interface{}{"Ok": nil}
+	if tx.Err != nil {
+		status = map[string]interface{}{"Err": tx.Err}
+	}
+	logs := tx.Logs
+	if logs == nil {
+		logs = []string{}
+	}
+
+	return map[string]interface{}{
+		"slot":      tx.Slot,
+		"blockTime": nil,
+		"transaction": map[string]interface{}{
+			"signatures": tx.Signatures,
+			"message": map[string]interface{}{
+				"header": map[string]interface{}{
+					"numRequiredSignatures":       message.Header.NumRequireSignatures,
+					"numReadonlySignedAccounts":   message.Header.NumReadonlySignedAccounts,
+					"numReadonlyUnsignedAccounts": message.Header.NumReadonlyUnsignedAccounts,
+				},
+				"accountKeys":     accountKeys,
+				"recentBlockhash": message.RecentBlockHash,
+				"instructions":    instructions,
+			},
+		},
+		"meta": map[string]interface{}{
+			"err":               tx.Err,
+			"status":            status,
+			"fee":               tx.Fee,
+			"logMessages":       logs,
+			"innerInstructions": []interface{}{},
+		},
+	}, nil
+}
@@ -0,0 +1,383 @@
+package solanatest
+
+import (
+	"encoding/binary"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+)
+
+const (
+	systemCreateAccount = iota
+	systemAssign
+	systemTransfer
+	systemCreateAccountWithSeed
+	systemAdvanceNonceAccount
+	systemWithdrawNonceAccount
+	systemInitializeNonceAccount
+	systemAuthorizeNonceAccount
+	systemAllocate
+)
+
+// maxPermittedDataLength is the account size limit of the system program
+const maxPermittedDataLength = 10 * 1024 * 1024
+
+// instructionData reads the fixed layout of the borsh/bincode encoded instruction data
+type instructionData struct {
+	data []byte
+	err  bool
+}
+
+func (d *instructionData) take(n int) []byte {
+	if d.err || len(d.data) < n {
+		d.err = true
+		return make([]byte, n)
+	}
+	chunk := d.data[:n]
+	d.data = d.data[n:]
+	return chunk
+}
+
+func (d *instructionData) u8() uint8 {
+	return d.take(1)[0]
+}
+
+func (d *instructionData) u32() uint32 {
+	return binary.LittleEndian.Uint32(d.take(4))
+}
+
+func (d *instructionData) u64() uint64 {
+	return binary.LittleEndian.Uint64(d.take(8))
+}
+
+func (d *instructionData) pubkey() common.PublicKey {
+	return common.PublicKeyFromBytes(d.take(32))
+}
+
+// str is the bincode string: u64 length and the bytes
+func (d *instructionData) str() string {
+	n := d.u64()
+	if n > uint64(len(d.data)) {
+		d.err = true
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func transferLamports(from, to *Account, lamports uint64) error {
+	if from.Lamports < lamports {
+		return ErrResultWithNegativeLamports
+	}
+	from.Lamports -= lamports
+	to.Lamports += lamports
+	return nil
+}
+
+func processSystem(ctx *InvokeContext) error {
+	data := &instructionData{data: ctx.Data}
+	instruction := data.u32()
+
+	var err error
+	switch instruction {
+	case systemCreateAccount:
+		lamports, space, owner := data.u64(), data.u64(), data.pubkey()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = createAccount(ctx, 0, 1, 1, lamports, space, owner)
+	case systemAssign:
+		owner := data.pubkey()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = assign(ctx, 0, owner)
+	case systemTransfer:
+		lamports := data.u64()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = transfer(ctx, lamports)
+	case systemCreateAccountWithSeed:
+		base, seed, lamports, space, owner := data.pubkey(), data.str(), data.u64(), data.u64(), data.pubkey()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = createAccountWithSeed(ctx, base, seed, lamports, space, owner)
+	case systemAdvanceNonceAccount:
+		err = advanceNonce(ctx)
+	case systemWithdrawNonceAccount:
+		lamports := data.u64()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = withdrawNonce(ctx, lamports)
+	case systemInitializeNonceAccount:
+		authority := data.pubkey()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = initializeNonce(ctx, authority)
+	case systemAuthorizeNonceAccount:
+		authority := data.pubkey()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = authorizeNonce(ctx, authority)
+	case systemAllocate:
+		space := data.u64()
+		if data.err {
+			return ErrInvalidInstructionData
+		}
+		err = allocate(ctx, 0, 0, space)
+	default:
+		return ErrInvalidInstructionData
+	}
+
+	return err
+}
+
+func allocate(ctx *InvokeContext, index, signer int, space uint64) error {
+	account, err := ctx.Account(index)
+	if err != nil {
+		return err
+	}
+	if !ctx.IsSigner(signer) {
+		return ErrMissingRequiredSignature
+	}
+	if len(account.Data) > 0 || account.Owner != common.SystemProgramID {
+		return ErrAccountAlreadyInUse
+	}
+	if space > maxPermittedDataLength {
+		return ErrInvalidArgument
+	}
+
+	account.Data = make([]byte, space)
+	return nil
+}
+
+func assign(ctx *InvokeContext, index int, owner common.PublicKey) error {
+	account, err := ctx.Account(index)
+	if err != nil {
+		return err
+	}
+	if account.Owner == owner {
+		return nil
+	}
+	if !ctx.IsSigner(index) {
+		return ErrMissingRequiredSignature
+	}
+
+	account.Owner = owner
+	return nil
+}
+
+// createAccount allocates and assigns the account at index funded from the first account,
+// signer is the index of the key which authorizes the address, the base key for seeded accounts
+func createAccount(ctx *InvokeContext, from, index, signer int, lamports, space uint64, owner common.PublicKey) error {
+	funder, err := ctx.Account(from)
+	if err != nil {
+		return err
+	}
+	account, err := ctx.Account(index)
+	if err != nil {
+		return err
+	}
+	if !ctx.IsSigner(from) {
+		return ErrMissingRequiredSignature
+	}
+	if account.Lamports > 0 {
+		return ErrAccountAlreadyInUse
+	}
+
+	if err := allocate(ctx, index, signer, space); err != nil {
+		return err
+	}
+	account.Owner = owner
+
+	return transferLamports(funder, account, lamports)
+}
+
+func createAccountWithSeed(ctx *InvokeContext, base common.PublicKey, seed string, lamports, space uint64, owner common.PublicKey) error {
+	address, err := ctx.Key(1)
+	if err != nil {
+		return err
+	}
+	if common.CreateWithSeed(base, seed, owner) != address {
+		return ErrAddressWithSeedMismatch
+	}
+
+	// the base account is passed third unless the funder is the base
+	signer := 2
+	if funder, _ := ctx.Key(0); funder == base {
+		signer = 0
+	}
+	if key, err := ctx.Key(signer); err != nil || key != base {
+		return ErrMissingRequiredSignature
+	}
+
+	return createAccount(ctx, 0, 1, signer, lamports, space, owner)
+}
+
+func transfer(ctx *InvokeContext, lamports uint64) error {
+	from, err := ctx.Account(0)
+	if err != nil {
+		return err
+	}
+	to, err := ctx.Account(1)
+	if err != nil {
+		return err
+	}
+	if !ctx.IsSigner(0) {
+		return ErrMissingRequiredSignature
+	}
+	if len(from.Data) > 0 {
+		return ErrInvalidArgument
+	}
+
+	return transferLamports(from, to, lamports)
+}
+
+type nonceState struct {
+	initialized          bool
+	authority            common.PublicKey
+	blockhash            common.PublicKey
+	lamportsPerSignature uint64
+}
+
+func decodeNonce(data []byte) (*nonceState, error) {
+	decoded, err := sysprog.NonceAccountDeserialize(data)
+	if err != nil {
+		return nil, ErrInvalidAccountData
+	}
+
+	return &nonceState{
+		initialized:          decoded.State == 1,
+		authority:            decoded.AuthorizedPubkey,
+		blockhash:            decoded.Nonce,
+		lamportsPerSignature: decoded.FeeCalculator.LamportsPerSignature,
+	}, nil
+}
+
+func (nonce *nonceState) encode(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+	if nonce.initialized {
+		binary.LittleEndian.PutUint32(data[4:8], 1)
+	}
+	copy(data[8:40], nonce.authority.Bytes())
+	copy(data[40:72], nonce.blockhash.Bytes())
+	binary.LittleEndian.PutUint64(data[72:80], nonce.lamportsPerSignature)
+}
+
+func blockhashKey(blockhash string) common.PublicKey {
+	decoded, _ := base58.Decode(blockhash)
+	return common.PublicKeyFromBytes(decoded)
+}
+
+// loadNonce returns the nonce account at index 0, the authority is checked to sign the transaction
+func loadNonce(ctx *InvokeContext, authorized bool) (*Account, *nonceState, error) {
+	account, err := ctx.Account(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if account.Owner != common.SystemProgramID || len(account.Data) != sysprog.NonceAccountSize {
+		return nil, nil, ErrInvalidAccountData
+	}
+
+	nonce, err := decodeNonce(account.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if authorized {
+		if !nonce.initialized {
+			return nil, nil, ErrInvalidAccountData
+		}
+		if !ctx.Signed(nonce.authority) {
+			return nil, nil, ErrMissingRequiredSignature
+		}
+	}
+
+	return account, nonce, nil
+}
+
+func initializeNonce(ctx *InvokeContext, authority common.PublicKey) error {
+	account, nonce, err := loadNonce(ctx, false)
+	if err != nil {
+		return err
+	}
+	if nonce.initialized {
+		return ErrInvalidAccountData
+	}
+	if account.Lamports < MinimumBalanceForRentExemption(sysprog.NonceAccountSize) {
+		return ErrInsufficientFunds
+	}
+
+	nonce.initialized = true
+	nonce.authority = authority
+	nonce.blockhash = blockhashKey(ctx.Blockhash())
+	nonce.lamportsPerSignature = LamportsPerSignature
+	nonce.encode(account.Data)
+
+	return nil
+}
+
+func advanceNonce(ctx *InvokeContext) error {
+	account, nonce, err := loadNonce(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	next := blockhashKey(ctx.Blockhash())
+	if nonce.blockhash == next {
+		return ErrNonceBlockhashNotExpired
+	}
+
+	nonce.blockhash = next
+	nonce.encode(account.Data)
+
+	return nil
+}
+
+func authorizeNonce(ctx *InvokeContext, authority common.PublicKey) error {
+	account, nonce, err := loadNonce(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	nonce.authority = authority
+	nonce.encode(account.Data)
+
+	return nil
+}
+
+// withdrawNonce keeps the account rent exempt unless it is drained and closed
+func withdrawNonce(ctx *InvokeContext, lamports uint64) error {
+	account, nonce, err := loadNonce(ctx, false)
+	if err != nil {
+		return err
+	}
+	if nonce.initialized && !ctx.Signed(nonce.authority) {
+		return ErrMissingRequiredSignature
+	}
+	if !nonce.initialized && !ctx.IsSigner(0) {
+		return ErrMissingRequiredSignature
+	}
+
+	to, err := ctx.Account(1)
+	if err != nil {
+		return err
+	}
+	if lamports > account.Lamports {
+		return ErrInsufficientFunds
+	}
+
+	if lamports == account.Lamports {
+		account.Data = nil
+	} else if nonce.initialized && account.Lamports-lamports < MinimumBalanceForRentExemption(sysprog.NonceAccountSize) {
+		return ErrInsufficientFunds
+	}
+
+	return transferLamports(account, to, lamports)
+}
@@ -0,0 +1,639 @@
+package solanatest
+
+import (
+	"encoding/binary"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/tokenprog"
+)
+
+const (
+	tokenInitializeMint      = 0
+	tokenInitializeAccount   = 1
+	tokenTransfer            = 3
+	tokenApprove             = 4
+	tokenRevoke              = 5
+	tokenSetAuthority        = 6
+	tokenMintTo              = 7
+	tokenBurn                = 8
+	tokenCloseAccount        = 9
+	tokenTransferChecked     = 12
+	tokenMintToChecked       = 14
+	tokenBurnChecked         = 15
+	tokenInitializeAccount2  = 16
+	tokenCOptionPubkeyLength = 36
+)
+
+// mintState mirrors spl_token::state::Mint
+type mintState struct {
+	mintAuthority   *common.PublicKey
+	supply          uint64
+	decimals        uint8
+	initialized     bool
+	freezeAuthority *common.PublicKey
+}
+
+func unpackCOption(data []byte) *common.PublicKey {
+	if binary.LittleEndian.Uint32(data[:4]) == 0 {
+		return nil
+	}
+	key := common.PublicKeyFromBytes(data[4:36])
+	return &key
+}
+
+func packCOption(data []byte, key *common.PublicKey) {
+	for i := range data[:tokenCOptionPubkeyLength] {
+		data[i] = 0
+	}
+	if key != nil {
+		binary.LittleEndian.PutUint32(data[:4], 1)
+		copy(data[4:36], key.Bytes())
+	}
+}
+
+func decodeMint(account *Account) (*mintState, error) {
+	if account.Owner != common.TokenProgramID {
+		return nil, ErrIncorrectProgramID
+	}
+	if len(account.Data) != tokenprog.MintAccountSize {
+		return nil, ErrInvalidAccountData
+	}
+
+	data := account.Data
+	return &mintState{
+		mintAuthority:   unpackCOption(data[0:36]),
+		supply:          binary.LittleEndian.Uint64(data[36:44]),
+		decimals:        data[44],
+		initialized:     data[45] == 1,
+		freezeAuthority: unpackCOption(data[46:82]),
+	}, nil
+}
+
+func (mint *mintState) encode(data []byte) {
+	packCOption(data[0:36], mint.mintAuthority)
+	binary.LittleEndian.PutUint64(data[36:44], mint.supply)
+	data[44] = mint.decimals
+	data[45] = 0
+	if mint.initialized {
+		data[45] = 1
+	}
+	packCOption(data[46:82], mint.freezeAuthority)
+}
+
+func decodeTokenAccount(account *Account) (*tokenprog.TokenAccount, error) {
+	if account.Owner != common.TokenProgramID {
+		return nil, ErrIncorrectProgramID
+	}
+
+	state, err := tokenprog.TokenAccountFromData(account.Data)
+	if err != nil {
+		return nil, ErrInvalidAccountData
+	}
+	return state, nil
+}
+
+func encodeTokenAccount(data []byte, state *tokenprog.TokenAccount) {
+	for i := range data {
+		data[i] = 0
+	}
+
+	copy(data[0:32], state.Mint.Bytes())
+	copy(data[32:64], state.Owner.Bytes())
+	binary.LittleEndian.PutUint64(data[64:72], state.Amount)
+	packCOption(data[72:108], state.Delegate)
+	data[108] = uint8(state.State)
+	if state.IsNative != nil {
+		binary.LittleEndian.PutUint32(data[109:113], 1)
+		binary.LittleEndian.PutUint64(data[113:121], *state.IsNative)
+	}
+	binary.LittleEndian.PutUint64(data[121:129], state.DelegatedAmount)
+	packCOption(data[129:165], state.CloseAuthority)
+}
+
+// tokenAccountAt loads the initialized token account with its ledger state
+func tokenAccountAt(ctx *InvokeContext, i int) (*Account, *tokenprog.TokenAccount, error) {
+	account, err := ctx.Account(i)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := decodeTokenAccount(account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.State == tokenprog.TokenAccountStateUninitialized {
+		return nil, nil, ErrTokenUninitializedState
+	}
+	return account, state, nil
+}
+
+func mintAt(ctx *InvokeContext, i int) (*Account, *mintState, error) {
+	account, err := ctx.Account(i)
+	if err != nil {
+		return nil, nil, err
+	}
+	mint, err := decodeMint(account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !mint.initialized {
+		return nil, nil, ErrTokenUninitializedState
+	}
+	return account, mint, nil
+}
+
+// authorize checks the authority account signed, multisig authorities are not supported
+func authorize(ctx *InvokeContext, i int, expected common.PublicKey) error {
+	authority, err := ctx.Key(i)
+	if err != nil {
+		return err
+	}
+	if authority != expected {
+		return ErrTokenOwnerMismatch
+	}
+	if !ctx.IsSigner(i) {
+		return ErrMissingRequiredSignature
+	}
+	return nil
+}
+
+// authorizeSpend lets the owner or the delegate within its allowance spend the amount
+func authorizeSpend(ctx *InvokeContext, i int, source *tokenprog.TokenAccount, amount uint64) error {
+	authority, err := ctx.Key(i)
+	if err != nil {
+		return err
+	}
+
+	if source.Delegate != nil && *source.Delegate == authority && authority != source.Owner {
+		if err := authorize(ctx, i, authority); err != nil {
+			return err
+		}
+		if source.DelegatedAmount < amount {
+			return ErrTokenInsufficientFunds
+		}
+		source.DelegatedAmount -= amount
+		if source.DelegatedAmount == 0 {
+			source.Delegate = nil
+		}
+		return nil
+	}
+
+	return authorize(ctx, i, source.Owner)
+}
+
+func processToken(ctx *InvokeContext) error {
+	data := &instructionData{data: ctx.Data}
+	instruction := data.u8()
+	if data.err {
+		return ErrTokenInvalidInstruction
+	}
+
+	switch instruction {
+	case tokenInitializeMint:
+		decimals, mintAuthority, freezeOption, freezeAuthority := data.u8(), data.pubkey(), data.u8(), data.pubkey()
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		var freeze *common.PublicKey
+		if freezeOption == 1 {
+			freeze = &freezeAuthority
+		}
+		return initializeMint(ctx, decimals, mintAuthority, freeze)
+	case tokenInitializeAccount:
+		owner, err := ctx.Key(2)
+		if err != nil {
+			return err
+		}
+		return initializeTokenAccount(ctx, owner)
+	case tokenInitializeAccount2:
+		owner := data.pubkey()
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		return initializeTokenAccount(ctx, owner)
+	case tokenTransfer:
+		amount := data.u64()
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		return transferTokens(ctx, 0, 1, 2, -1, amount, 0)
+	case tokenTransferChecked:
+		amount, decimals := data.u64(), data.u8()
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		return transferTokens(ctx, 0, 2, 3, 1, amount, decimals)
+	case tokenApprove:
+		amount := data.u64()
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		return approve(ctx, amount)
+	case tokenRevoke:
+		return revoke(ctx)
+	case tokenSetAuthority:
+		authorityType, option, newAuthority := data.u8(), data.u8(), data.pubkey()
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		var authority *common.PublicKey
+		if option == 1 {
+			authority = &newAuthority
+		}
+		return setAuthority(ctx, tokenprog.AuthorityType(authorityType), authority)
+	case tokenMintTo, tokenMintToChecked:
+		amount := data.u64()
+		decimals := -1
+		if instruction == tokenMintToChecked {
+			decimals = int(data.u8())
+		}
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		return mintTo(ctx, amount, decimals)
+	case tokenBurn, tokenBurnChecked:
+		amount := data.u64()
+		decimals := -1
+		if instruction == tokenBurnChecked {
+			decimals = int(data.u8())
+		}
+		if data.err {
+			return ErrTokenInvalidInstruction
+		}
+		return burn(ctx, amount, decimals)
+	case tokenCloseAccount:
+		return closeTokenAccount(ctx)
+	default:
+		return ErrTokenInvalidInstruction
+	}
+}
+
+func initializeMint(ctx *InvokeContext, decimals uint8, mintAuthority common.PublicKey, freezeAuthority *common.PublicKey) error {
+	account, err := ctx.Account(0)
+	if err != nil {
+		return err
+	}
+	mint, err := decodeMint(account)
+	if err != nil {
+		return err
+	}
+	if mint.initialized {
+		return ErrAccountAlreadyInitialized
+	}
+
+	mint = &mintState{
+		mintAuthority:   &mintAuthority,
+		decimals:        decimals,
+		initialized:     true,
+		freezeAuthority: freezeAuthority,
+	}
+	mint.encode(account.Data)
+
+	return nil
+}
+
+func initializeTokenAccount(ctx *InvokeContext, owner common.PublicKey) error {
+	account, err := ctx.Account(0)
+	if err != nil {
+		return err
+	}
+	state, err := decodeTokenAccount(account)
+	if err != nil {
+		return err
+	}
+	if state.State != tokenprog.TokenAccountStateUninitialized {
+		return ErrAccountAlreadyInitialized
+	}
+
+	mintKey, err := ctx.Key(1)
+	if err != nil {
+		return err
+	}
+	if _, _, err := mintAt(ctx, 1); err != nil {
+		return err
+	}
+
+	encodeTokenAccount(account.Data, &tokenprog.TokenAccount{
+		Mint:  mintKey,
+		Owner: owner,
+		State: tokenprog.TokenAccountStateInitialized,
+	})
+
+	return nil
+}
+
+// transferTokens takes the mint index for the checked version, -1 otherwise
+func transferTokens(ctx *InvokeContext, from, to, authority, mintIndex int, amount uint64, decimals uint8) error {
+	sourceAccount, source, err := tokenAccountAt(ctx, from)
+	if err != nil {
+		return err
+	}
+	destinationAccount, destination, err := tokenAccountAt(ctx, to)
+	if err != nil {
+		return err
+	}
+	if source.State == tokenprog.TokenAccountFrozen || destination.State == tokenprog.TokenAccountFrozen {
+		return ErrTokenAccountFrozen
+	}
+	if source.Mint != destination.Mint {
+		return ErrTokenMintMismatch
+	}
+
+	if mintIndex >= 0 {
+		mintKey, err := ctx.Key(mintIndex)
+		if err != nil {
+			return err
+		}
+		if mintKey != source.Mint {
+			return ErrTokenMintMismatch
+		}
+		_, mint, err := mintAt(ctx, mintIndex)
+		if err != nil {
+			return err
+		}
+		if mint.decimals != decimals {
+			return ErrTokenMintDecimalsMismatch
+		}
+	}
+
+	if source.Amount < amount {
+		return ErrTokenInsufficientFunds
+	}
+	if err := authorizeSpend(ctx, authority, source, amount); err != nil {
+		return err
+	}
+
+	source.Amount -= amount
+	encodeTokenAccount(sourceAccount.Data, source)
+
+	// self transfer reloads the state debited above
+	if sourceAccount == destinationAccount {
+		destination = source
+	}
+	if destination.Amount+amount < destination.Amount {
+		return ErrTokenOverflow
+	}
+	destination.Amount += amount
+	encodeTokenAccount(destinationAccount.Data, destination)
+
+	return nil
+}
+
+func approve(ctx *InvokeContext, amount uint64) error {
+	account, state, err := tokenAccountAt(ctx, 0)
+	if err != nil {
+		return err
+	}
+	delegate, err := ctx.Key(1)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, 2, state.Owner); err != nil {
+		return err
+	}
+
+	state.Delegate = &delegate
+	state.DelegatedAmount = amount
+	encodeTokenAccount(account.Data, state)
+
+	return nil
+}
+
+func revoke(ctx *InvokeContext) error {
+	account, state, err := tokenAccountAt(ctx, 0)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, 1, state.Owner); err != nil {
+		return err
+	}
+
+	state.Delegate = nil
+	state.DelegatedAmount = 0
+	encodeTokenAccount(account.Data, state)
+
+	return nil
+}
+
+func setAuthority(ctx *InvokeContext, authorityType tokenprog.AuthorityType, newAuthority *common.PublicKey) error {
+	account, err := ctx.Account(0)
+	if err != nil {
+		return err
+	}
+
+	if len(account.Data) == tokenprog.MintAccountSize {
+		_, mint, err := mintAt(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		var current **common.PublicKey
+		switch authorityType {
+		case tokenprog.AuthorityTypeMintTokens:
+			current = &mint.mintAuthority
+		case tokenprog.AuthorityTypeFreezeAccount:
+			current = &mint.freezeAuthority
+		default:
+			return ErrTokenAuthorityType
+		}
+		if *current == nil {
+			return ErrTokenFixedSupply
+		}
+		if err := authorize(ctx, 1, **current); err != nil {
+			return err
+		}
+
+		*current = newAuthority
+		mint.encode(account.Data)
+		return nil
+	}
+
+	_, state, err := tokenAccountAt(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	switch authorityType {
+	case tokenprog.AuthorityTypeAccountOwner:
+		if err := authorize(ctx, 1, state.Owner); err != nil {
+			return err
+		}
+		if newAuthority == nil {
+			return ErrInvalidArgument
+		}
+		state.Owner = *newAuthority
+		state.Delegate = nil
+		state.DelegatedAmount = 0
+	case tokenprog.AuthorityTypeCloseAccount:
+		closeAuthority := state.Owner
+		if state.CloseAuthority != nil {
+			closeAuthority = *state.CloseAuthority
+		}
+		if err := authorize(ctx, 1, closeAuthority); err != nil {
+			return err
+		}
+		state.CloseAuthority = newAuthority
+	default:
+		return ErrTokenAuthorityType
+	}
+
+	encodeTokenAccount(account.Data, state)
+	return nil
+}
+
+// mintTo takes the decimals of the checked version, -1 otherwise
+func mintTo(ctx *InvokeContext, amount uint64, decimals int) error {
+	mintAccount, mint, err := mintAt(ctx, 0)
+	if err != nil {
+		return err
+	}
+	destinationAccount, destination, err := tokenAccountAt(ctx, 1)
+	if err != nil {
+		return err
+	}
+
+	mintKey, _ := ctx.Key(0)
+	if destination.Mint != mintKey {
+		return ErrTokenMintMismatch
+	}
+	if destination.State == tokenprog.TokenAccountFrozen {
+		return ErrTokenAccountFrozen
+	}
+	if decimals >= 0 && uint8(decimals) != mint.decimals {
+		return ErrTokenMintDecimalsMismatch
+	}
+	if mint.mintAuthority == nil {
+		return ErrTokenFixedSupply
+	}
+	if err := authorize(ctx, 2, *mint.mintAuthority); err != nil {
+		return err
+	}
+
+	if mint.supply+amount < mint.supply || destination.Amount+amount < destination.Amount {
+		return ErrTokenOverflow
+	}
+	mint.supply += amount
+	destination.Amount += amount
+
+	mint.encode(mintAccount.Data)
+	encodeTokenAccount(destinationAccount.Data, destination)
+
+	return nil
+}
+
+// burn takes the decimals of the checked version, -1 otherwise
+func burn(ctx *InvokeContext, amount uint64, decimals int) error {
+	sourceAccount, source, err := tokenAccountAt(ctx, 0)
+	if err != nil {
+		return err
+	}
+	mintAccount, mint, err := mintAt(ctx, 1)
+	if err != nil {
+		return err
+	}
+
+	mintKey, _ := ctx.Key(1)
+	if source.Mint != mintKey {
+		return ErrTokenMintMismatch
+	}
+	if source.State == tokenprog.TokenAccountFrozen {
+		return ErrTokenAccountFrozen
+	}
+	if decimals >= 0 && uint8(decimals) != mint.decimals {
+		return ErrTokenMintDecimalsMismatch
+	}
+	if source.Amount < amount {
+		return ErrTokenInsufficientFunds
+	}
+	if err := authorizeSpend(ctx, 2, source, amount); err != nil {
+		return err
+	}
+
+	source.Amount -= amount
+	mint.supply -= amount
+
+	encodeTokenAccount(sourceAccount.Data, source)
+	mint.encode(mintAccount.Data)
+
+	return nil
+}
+
+func closeTokenAccount(ctx *InvokeContext) error {
+	account, state, err := tokenAccountAt(ctx, 0)
+	if err != nil {
+		return err
+	}
+	destination, err := ctx.Account(1)
+	if err != nil {
+		return err
+	}
+	if state.Amount != 0 {
+		return ErrTokenNonNativeHasBalance
+	}
+
+	closeAuthority := state.Owner
+	if state.CloseAuthority != nil {
+		closeAuthority = *state.CloseAuthority
+	}
+	if err := authorize(ctx, 2, closeAuthority); err != nil {
+		return err
+	}
+
+	destination.Lamports += account.Lamports
+	account.Lamports = 0
+	account.Data = nil
+	account.Owner = common.SystemProgramID
+
+	return nil
+}
+
+// processAssociatedToken creates the associated token account, the only instruction of the program
+func processAssociatedToken(ctx *InvokeContext) error {
+	funderAccount, err := ctx.Account(0)
+	if err != nil {
+		return err
+	}
+	address, err := ctx.Key(1)
+	if err != nil {
+		return err
+	}
+	wallet, err := ctx.Key(2)
+	if err != nil {
+		return err
+	}
+	mintKey, err := ctx.Key(3)
+	if err != nil {
+		return err
+	}
+	if !ctx.IsSigner(0) {
+		return ErrMissingRequiredSignature
+	}
+
+	expected, _, err := common.FindAssociatedTokenAddress(wallet, mintKey)
+	if err != nil || expected != address {
+		return ErrInvalidSeeds
+	}
+	if _, _, err := mintAt(ctx, 3); err != nil {
+		return err
+	}
+
+	account, err := ctx.Account(1)
+	if err != nil {
+		return err
+	}
+	if !account.isEmpty() {
+		return ErrAccountAlreadyInUse
+	}
+
+	if err := transferLamports(funderAccount, account, MinimumBalanceForRentExemption(tokenprog.TokenAccountSize)); err != nil {
+		return err
+	}
+	account.Owner = common.TokenProgramID
+	account.Data = make([]byte, tokenprog.TokenAccountSize)
+	encodeTokenAccount(account.Data, &tokenprog.TokenAccount{
+		Mint:  mintKey,
+		Owner: wallet,
+		State: tokenprog.TokenAccountStateInitialized,
+	})
+
+	return nil
+}
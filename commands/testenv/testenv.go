@@ -0,0 +1,345 @@
+package testenv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands/contract"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+const (
+	// ValidatorBinaryEnv overrides the solana-test-validator looked up in PATH
+	ValidatorBinaryEnv = "SOLANA_TEST_VALIDATOR"
+	// BinariesDirEnv overrides DefaultBinariesDir
+	BinariesDirEnv = "SOLANOID_BINARIES_DIR"
+
+	// DefaultBinariesDir is relative to the commands package tests, the same place DeploySolanaProgram reads from
+	DefaultBinariesDir = "../binaries"
+
+	DefaultStartTimeout = 2 * time.Minute
+
+	// dynamicPortRange is the amount of ports the validator binds for gossip, tpu and repair
+	dynamicPortRange = 25
+	shutdownTimeout  = 10 * time.Second
+	healthPollPeriod = 250 * time.Millisecond
+)
+
+// DefaultPrograms maps the contract program IDs to their binaries in the directory
+func DefaultPrograms(binariesDir string) map[string]string {
+	return map[string]string{
+		contract.GravityBinary: filepath.Join(binariesDir, "gravity.so"),
+		contract.NebulaBinary:  filepath.Join(binariesDir, "nebula.so"),
+		contract.IBPortBinary:  filepath.Join(binariesDir, "ibport.so"),
+		contract.LUPortBinary:  filepath.Join(binariesDir, "luport.so"),
+	}
+}
+
+type Options struct {
+	// Programs maps program IDs to the .so files preloaded at genesis,
+	// nil means DefaultPrograms of the binaries dir, empty map starts a bare validator
+	Programs map[string]string
+	// BinariesDir is where DefaultPrograms are looked up, BinariesDirEnv or DefaultBinariesDir if empty
+	BinariesDir string
+	// StartTimeout bounds the wait for the validator to report healthy, DefaultStartTimeout if zero
+	StartTimeout time.Duration
+}
+
+// Validator is the solana-test-validator owned by a single test
+type Validator struct {
+	RPCURL string
+	WSURL  string
+
+	// Payer is the genesis mint, so it holds the whole supply of the cluster
+	Payer types.Account
+	// PayerPath is the payer keypair in the solana-keygen format, for helpers which take key files
+	PayerPath string
+
+	LedgerDir string
+	// Programs are the program IDs and binaries loaded at genesis
+	Programs map[string]string
+
+	cmd    *exec.Cmd
+	exited chan error
+}
+
+// StartValidator runs solana-test-validator in a fresh ledger under the test temp dir and stops it on cleanup.
+// The test is skipped when the validator or the program binaries are not installed.
+func StartValidator(t *testing.T, options *Options) *Validator {
+	t.Helper()
+
+	if options == nil {
+		options = &Options{}
+	}
+
+	binary := os.Getenv(ValidatorBinaryEnv)
+	if binary == "" {
+		binary = "solana-test-validator"
+	}
+	binary, err := exec.LookPath(binary)
+	if err != nil {
+		t.Skipf("solana-test-validator is not available: %v", err)
+	}
+
+	programs := options.Programs
+	if programs == nil {
+		binariesDir := options.BinariesDir
+		if binariesDir == "" {
+			binariesDir = os.Getenv(BinariesDirEnv)
+		}
+		if binariesDir == "" {
+			binariesDir = DefaultBinariesDir
+		}
+		programs = DefaultPrograms(binariesDir)
+	}
+	for programID, path := range programs {
+		if _, err := os.Stat(path); err != nil {
+			t.Skipf("program %v binary is not available: %v", programID, err)
+		}
+	}
+
+	validator, err := startValidator(t.TempDir(), binary, programs)
+	if err != nil {
+		t.Fatalf("start solana-test-validator: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := validator.stop(); err != nil {
+			t.Logf("stop solana-test-validator: %v", err)
+		}
+	})
+
+	startTimeout := options.StartTimeout
+	if startTimeout == 0 {
+		startTimeout = DefaultStartTimeout
+	}
+	if err := validator.awaitHealthy(startTimeout); err != nil {
+		t.Logf("solana-test-validator log:\n%s", validator.logTail())
+		t.Fatalf("solana-test-validator is not healthy: %v", err)
+	}
+
+	return validator
+}
+
+func startValidator(dir, binary string, programs map[string]string) (*Validator, error) {
+	validator := &Validator{
+		Payer:     types.NewAccount(),
+		PayerPath: filepath.Join(dir, "payer.json"),
+		LedgerDir: filepath.Join(dir, "ledger"),
+		Programs:  programs,
+		exited:    make(chan error, 1),
+	}
+	if err := writeKeypair(validator.PayerPath, validator.Payer); err != nil {
+		return nil, err
+	}
+
+	// the websocket listens on the port next to RPC, so both have to be free
+	rpcPort, err := freePortPair()
+	if err != nil {
+		return nil, err
+	}
+	faucetPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	gossipPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	dynamicPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	validator.RPCURL = fmt.Sprintf("http://127.0.0.1:%d", rpcPort)
+	validator.WSURL = fmt.Sprintf("ws://127.0.0.1:%d", rpcPort+1)
+
+	args := []string{
+		"--ledger", validator.LedgerDir,
+		"--reset",
+		"--quiet",
+		"--mint", validator.Payer.PublicKey.ToBase58(),
+		"--rpc-port", strconv.Itoa(rpcPort),
+		"--faucet-port", strconv.Itoa(faucetPort),
+		"--gossip-port", strconv.Itoa(gossipPort),
+		"--dynamic-port-range", fmt.Sprintf("%d-%d", dynamicPort, dynamicPort+dynamicPortRange),
+	}
+	for programID, path := range programs {
+		args = append(args, "--bpf-program", programID, path)
+	}
+
+	logFile, err := os.Create(filepath.Join(dir, "validator.log"))
+	if err != nil {
+		return nil, err
+	}
+
+	validator.cmd = exec.Command(binary, args...)
+	validator.cmd.Dir = dir
+	validator.cmd.Stdout = logFile
+	validator.cmd.Stderr = logFile
+
+	if err := validator.cmd.Start(); err != nil {
+		logFile.Close()
+		return nil, err
+	}
+	go func() {
+		validator.exited <- validator.cmd.Wait()
+		logFile.Close()
+	}()
+
+	return validator, nil
+}
+
+func writeKeypair(path string, account types.Account) error {
+	// json.Marshal encodes []byte as base64, solana-keygen expects a list of numbers
+	keypair := make([]int, len(account.PrivateKey))
+	for i, b := range account.PrivateKey {
+		keypair[i] = int(b)
+	}
+
+	content, err := json.Marshal(keypair)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+func freePortPair() (int, error) {
+	for i := 0; i < 10; i++ {
+		port, err := freePort()
+		if err != nil {
+			return 0, err
+		}
+
+		next, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port+1))
+		if err != nil {
+			continue
+		}
+		next.Close()
+
+		return port, nil
+	}
+	return 0, fmt.Errorf("no free pair of ports")
+}
+
+// awaitHealthy polls getHealth until the validator reports ok, it fails early if the process exits
+func (validator *Validator) awaitHealthy(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthPollPeriod)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case err := <-validator.exited:
+			validator.exited <- err
+			return fmt.Errorf("validator exited: %v", err)
+		case <-ctx.Done():
+			return fmt.Errorf("%v, last error: %v", ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+
+		if lastErr = validator.health(ctx); lastErr == nil {
+			return nil
+		}
+	}
+}
+
+func (validator *Validator) health(ctx context.Context) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "getHealth",
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, validator.RPCURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	result := struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return fmt.Errorf("%v", result.Error.Message)
+	}
+	if result.Result != "ok" {
+		return fmt.Errorf("health: %v", result.Result)
+	}
+
+	return nil
+}
+
+// stop interrupts the validator so it flushes the ledger, then kills it if it hangs
+func (validator *Validator) stop() error {
+	if err := validator.cmd.Process.Signal(os.Interrupt); err != nil {
+		// the process has already exited
+		return nil
+	}
+
+	select {
+	case <-validator.exited:
+		return nil
+	case <-time.After(shutdownTimeout):
+	}
+
+	if err := validator.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-validator.exited
+
+	return fmt.Errorf("killed after %v", shutdownTimeout)
+}
+
+func (validator *Validator) logTail() string {
+	const tailSize = 4096
+
+	content, err := ioutil.ReadFile(filepath.Join(filepath.Dir(validator.LedgerDir), "validator.log"))
+	if err != nil {
+		return err.Error()
+	}
+	if len(content) > tailSize {
+		content = content[len(content)-tailSize:]
+	}
+	return string(content)
+}
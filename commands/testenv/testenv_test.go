@@ -0,0 +1,57 @@
+package testenv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestStartValidator(t *testing.T) {
+	t.Parallel()
+
+	validator := StartValidator(t, &Options{Programs: map[string]string{}})
+
+	ctx := context.Background()
+	recipient := types.NewAccount()
+
+	ge := executor.NewSignerExecutor(executor.NewGravityBftSignerFromAccount(validator.Payer), validator.RPCURL)
+	if _, err := ge.SendAndConfirm(ctx, []types.Instruction{
+		sysprog.Transfer(validator.Payer.PublicKey, recipient.PublicKey, 1_000_000_000),
+	}, solclient.CommitmentConfirmed); err != nil {
+		t.Fatal(err)
+	}
+
+	balance, err := solclient.NewClient(validator.RPCURL).GetBalance(ctx, recipient.PublicKey.ToBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 1_000_000_000 {
+		t.Fatalf("unexpected balance: %v", balance)
+	}
+}
+
+func TestStartValidatorPrograms(t *testing.T) {
+	t.Parallel()
+
+	validator := StartValidator(t, &Options{BinariesDir: "../../binaries"})
+
+	c := solclient.NewClient(validator.RPCURL)
+	for programID := range validator.Programs {
+		info, err := c.GetAccountInfo(context.Background(), programID, solclient.GetAccountInfoConfig{
+			Encoding: solclient.GetAccountInfoConfigEncodingBase64,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the client does not decode the executable flag, loaded programs are owned by a loader
+		if info.Owner == "" || info.Owner == common.SystemProgramID.ToBase58() {
+			t.Fatalf("program %v is not loaded", programID)
+		}
+	}
+}
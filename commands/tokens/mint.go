@@ -0,0 +1,82 @@
+package tokens
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/tokenprog"
+)
+
+// Mint mirrors spl_token::state::Mint
+//
+//	pub struct Mint {
+//	    pub mint_authority: COption<Pubkey>,
+//	    pub supply: u64,
+//	    pub decimals: u8,
+//	    pub is_initialized: bool,
+//	    pub freeze_authority: COption<Pubkey>,
+//	}
+type Mint struct {
+	MintAuthority   *common.PublicKey
+	Supply          uint64
+	Decimals        uint8
+	IsInitialized   bool
+	FreezeAuthority *common.PublicKey
+}
+
+func unpackCOptionPubkey(data []byte) *common.PublicKey {
+	if binary.LittleEndian.Uint32(data[:4]) == 0 {
+		return nil
+	}
+	key := common.PublicKeyFromBytes(data[4:36])
+	return &key
+}
+
+func MintFromData(data []byte) (*Mint, error) {
+	if len(data) != tokenprog.MintAccountSize {
+		return nil, fmt.Errorf("mint data length mismatch: expected %v, got %v", tokenprog.MintAccountSize, len(data))
+	}
+
+	return &Mint{
+		MintAuthority:   unpackCOptionPubkey(data[0:36]),
+		Supply:          binary.LittleEndian.Uint64(data[36:44]),
+		Decimals:        data[44],
+		IsInitialized:   data[45] == 1,
+		FreezeAuthority: unpackCOptionPubkey(data[46:82]),
+	}, nil
+}
+
+// UIAmountToBaseUnits converts the amount as accepted by spl-token CLI (e.g. 1.5) to the token base units.
+func UIAmountToBaseUnits(amount float64, decimals uint8) (uint64, error) {
+	if amount < 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid token amount: %v", amount)
+	}
+
+	// going through the decimal string representation keeps 0.1 from becoming 0.09999999
+	value, ok := new(big.Float).SetPrec(256).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	if !ok {
+		return 0, fmt.Errorf("invalid token amount: %v", amount)
+	}
+
+	multiplier := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	value.Mul(value, multiplier)
+
+	if !value.IsInt() {
+		return 0, fmt.Errorf("amount %v exceeds token precision of %v decimals", amount, decimals)
+	}
+
+	result, accuracy := value.Uint64()
+	if accuracy != big.Exact {
+		return 0, fmt.Errorf("amount %v overflows u64 base units", amount)
+	}
+
+	return result, nil
+}
+
+func BaseUnitsToUIAmount(amount uint64, decimals uint8) float64 {
+	return float64(amount) / math.Pow10(int(decimals))
+}
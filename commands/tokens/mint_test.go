@@ -0,0 +1,68 @@
+package tokens
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/tokenprog"
+)
+
+func TestMintFromData(t *testing.T) {
+	authority := common.PublicKeyFromString("SRMuApVNdxXokk5GT7XD5cUUgXMBCoAz2LHeuAoKWRt")
+
+	data := make([]byte, tokenprog.MintAccountSize)
+	binary.LittleEndian.PutUint32(data[0:4], 1)
+	copy(data[4:36], authority.Bytes())
+	binary.LittleEndian.PutUint64(data[36:44], 1_000_000)
+	data[44] = 8
+	data[45] = 1
+
+	mint, err := MintFromData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mint.MintAuthority == nil || *mint.MintAuthority != authority {
+		t.Fatalf("unexpected mint authority: %v", mint.MintAuthority)
+	}
+	if mint.Supply != 1_000_000 || mint.Decimals != 8 || !mint.IsInitialized || mint.FreezeAuthority != nil {
+		t.Fatalf("unexpected mint state: %+v", mint)
+	}
+
+	if _, err := MintFromData(data[:80]); err == nil {
+		t.Fatalf("expected length mismatch error")
+	}
+}
+
+func TestUIAmountToBaseUnits(t *testing.T) {
+	cases := []struct {
+		amount   float64
+		decimals uint8
+		expected uint64
+	}{
+		{1, 8, 100_000_000},
+		{0.1, 8, 10_000_000},
+		{1.23456789, 8, 123_456_789},
+		{1_000_000, 0, 1_000_000},
+	}
+
+	for _, c := range cases {
+		result, err := UIAmountToBaseUnits(c.amount, c.decimals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != c.expected {
+			t.Fatalf("%v with %v decimals: expected %v, got %v", c.amount, c.decimals, c.expected, result)
+		}
+	}
+
+	if _, err := UIAmountToBaseUnits(0.123, 2); err == nil {
+		t.Fatalf("expected precision error")
+	}
+	if _, err := UIAmountToBaseUnits(-1, 2); err == nil {
+		t.Fatalf("expected negative amount error")
+	}
+	if _, err := UIAmountToBaseUnits(1e20, 8); err == nil {
+		t.Fatalf("expected overflow error")
+	}
+}
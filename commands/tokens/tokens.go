@@ -0,0 +1,283 @@
+package tokens
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/assotokenprog"
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/tokenprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+type CreateMintResult struct {
+	Mint          common.PublicKey
+	MintAuthority common.PublicKey
+	Decimals      uint8
+	Signature     string
+}
+
+type CreateTokenAccountResult struct {
+	TokenAccount common.PublicKey
+	Mint         common.PublicKey
+	Owner        common.PublicKey
+	// Signature is empty when the account already existed
+	Signature string
+}
+
+type OperationResult struct {
+	Signature string
+	Amount    uint64
+}
+
+// TokenOperator sends SPL Token program instructions on behalf of the fee payer.
+type TokenOperator struct {
+	feePayer types.Account
+
+	executor *executor.GenericExecutor
+	client   *solclient.Client
+
+	Commitment solclient.Commitment
+}
+
+func NewTokenOperator(feePayer types.Account, endpoint string) (*TokenOperator, error) {
+	ge, err := executor.NewEmptyExecutor(base58.Encode(feePayer.PrivateKey), endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenOperator{
+		feePayer:   feePayer,
+		executor:   ge,
+		client:     solclient.NewClient(endpoint),
+		Commitment: solclient.CommitmentConfirmed,
+	}, nil
+}
+
+func (op *TokenOperator) FeePayer() common.PublicKey {
+	return op.feePayer.PublicKey
+}
+
+func (op *TokenOperator) send(ctx context.Context, ixs []types.Instruction, signers ...types.Account) (*models.CommandResponse, error) {
+	additionalSigners := make([]executor.GravityBftSigner, 0, len(signers))
+	for _, signer := range signers {
+		if signer.PublicKey == op.feePayer.PublicKey {
+			continue
+		}
+		additionalSigners = append(additionalSigners, *executor.NewGravityBftSignerFromAccount(signer))
+	}
+
+	op.executor.SetAdditionalSigners(additionalSigners)
+	defer op.executor.EraseAdditionalSigners()
+
+	return op.executor.SendAndConfirm(ctx, ixs, op.Commitment)
+}
+
+func (op *TokenOperator) readAccountData(ctx context.Context, address common.PublicKey) ([]byte, string, error) {
+	info, err := op.client.GetAccountInfo(ctx, address.ToBase58(), solclient.GetAccountInfoConfig{
+		Encoding: solclient.GetAccountInfoConfigEncodingBase64,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	encoded, ok := info.Data.([]interface{})
+	if !ok || len(encoded) == 0 {
+		return nil, "", fmt.Errorf("account %v not found", address.ToBase58())
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded[0].(string))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, info.Owner, nil
+}
+
+func (op *TokenOperator) GetMint(ctx context.Context, mint common.PublicKey) (*Mint, error) {
+	data, owner, err := op.readAccountData(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+	if owner != common.TokenProgramID.ToBase58() {
+		return nil, fmt.Errorf("account %v is not owned by token program", mint.ToBase58())
+	}
+
+	return MintFromData(data)
+}
+
+func (op *TokenOperator) GetTokenAccount(ctx context.Context, tokenAccount common.PublicKey) (*tokenprog.TokenAccount, error) {
+	data, owner, err := op.readAccountData(ctx, tokenAccount)
+	if err != nil {
+		return nil, err
+	}
+	if owner != common.TokenProgramID.ToBase58() {
+		return nil, fmt.Errorf("account %v is not owned by token program", tokenAccount.ToBase58())
+	}
+
+	return tokenprog.TokenAccountFromData(data)
+}
+
+func (op *TokenOperator) accountExists(ctx context.Context, address common.PublicKey) (bool, error) {
+	info, err := op.client.GetAccountInfo(ctx, address.ToBase58(), solclient.GetAccountInfoConfig{
+		Encoding: solclient.GetAccountInfoConfigEncodingBase64,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return info.Owner != "", nil
+}
+
+// CreateMint allocates a new mint account and initializes it. Pass nil freezeAuthority to disable freezing.
+func (op *TokenOperator) CreateMint(ctx context.Context, mintAuthority common.PublicKey, freezeAuthority *common.PublicKey, decimals uint8) (*CreateMintResult, error) {
+	return op.CreateMintAccount(ctx, types.NewAccount(), mintAuthority, freezeAuthority, decimals)
+}
+
+// CreateMintAccount is CreateMint at the given keypair, e.g. the one saved before sending for the retry
+func (op *TokenOperator) CreateMintAccount(ctx context.Context, mint types.Account, mintAuthority common.PublicKey, freezeAuthority *common.PublicKey, decimals uint8) (*CreateMintResult, error) {
+	rentExemption, err := op.client.GetMinimumBalanceForRentExemption(ctx, tokenprog.MintAccountSize)
+	if err != nil {
+		fmt.Printf("get min balance for rent exemption, err: %v\n", err)
+		return nil, err
+	}
+
+	var freeze common.PublicKey
+	if freezeAuthority != nil {
+		freeze = *freezeAuthority
+	}
+
+	response, err := op.send(ctx, []types.Instruction{
+		sysprog.CreateAccount(op.feePayer.PublicKey, mint.PublicKey, common.TokenProgramID, rentExemption, tokenprog.MintAccountSize),
+		tokenprog.InitializeMint(decimals, mint.PublicKey, mintAuthority, freeze),
+	}, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateMintResult{
+		Mint:          mint.PublicKey,
+		MintAuthority: mintAuthority,
+		Decimals:      decimals,
+		Signature:     response.TxSignature,
+	}, nil
+}
+
+// CreateTokenAccount creates the associated token account of the owner, the same way spl-token create-account does.
+// Nothing is sent if the account already exists.
+func (op *TokenOperator) CreateTokenAccount(ctx context.Context, mint, owner common.PublicKey) (*CreateTokenAccountResult, error) {
+	tokenAccount, _, err := common.FindAssociatedTokenAddress(owner, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CreateTokenAccountResult{
+		TokenAccount: tokenAccount,
+		Mint:         mint,
+		Owner:        owner,
+	}
+
+	exists, err := op.accountExists(ctx, tokenAccount)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return result, nil
+	}
+
+	response, err := op.send(ctx, []types.Instruction{
+		assotokenprog.CreateAssociatedTokenAccount(op.feePayer.PublicKey, owner, mint),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Signature = response.TxSignature
+	return result, nil
+}
+
+func (op *TokenOperator) MintTo(ctx context.Context, mint, destination common.PublicKey, mintAuthority types.Account, amount uint64) (*OperationResult, error) {
+	response, err := op.send(ctx, []types.Instruction{
+		tokenprog.MintTo(mint, destination, mintAuthority.PublicKey, []common.PublicKey{}, amount),
+	}, mintAuthority)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperationResult{Signature: response.TxSignature, Amount: amount}, nil
+}
+
+// Burn is signed by either the token account owner or its delegate.
+func (op *TokenOperator) Burn(ctx context.Context, tokenAccount, mint common.PublicKey, authority types.Account, amount uint64) (*OperationResult, error) {
+	response, err := op.send(ctx, []types.Instruction{
+		tokenprog.Burn(tokenAccount, mint, authority.PublicKey, []common.PublicKey{}, amount),
+	}, authority)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperationResult{Signature: response.TxSignature, Amount: amount}, nil
+}
+
+// Transfer is signed by either the source owner or its delegate.
+func (op *TokenOperator) Transfer(ctx context.Context, source, destination common.PublicKey, authority types.Account, amount uint64) (*OperationResult, error) {
+	response, err := op.send(ctx, []types.Instruction{
+		tokenprog.Transfer(source, destination, authority.PublicKey, []common.PublicKey{}, amount),
+	}, authority)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperationResult{Signature: response.TxSignature, Amount: amount}, nil
+}
+
+func (op *TokenOperator) Approve(ctx context.Context, tokenAccount, delegate common.PublicKey, owner types.Account, amount uint64) (*OperationResult, error) {
+	response, err := op.send(ctx, []types.Instruction{
+		tokenprog.Approve(tokenAccount, delegate, owner.PublicKey, []common.PublicKey{}, amount),
+	}, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperationResult{Signature: response.TxSignature, Amount: amount}, nil
+}
+
+// SetAuthority with nil newAuthority revokes the authority for good.
+func (op *TokenOperator) SetAuthority(ctx context.Context, target common.PublicKey, authorityType tokenprog.AuthorityType, currentAuthority types.Account, newAuthority *common.PublicKey) (*OperationResult, error) {
+	var authority common.PublicKey
+	if newAuthority != nil {
+		authority = *newAuthority
+	}
+
+	response, err := op.send(ctx, []types.Instruction{
+		tokenprog.SetAuthority(target, authority, authorityType, currentAuthority.PublicKey, []common.PublicKey{}),
+	}, currentAuthority)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperationResult{Signature: response.TxSignature}, nil
+}
+
+// ParseAuthorityType accepts the authority names used by spl-token authorize.
+func ParseAuthorityType(authority string) (tokenprog.AuthorityType, error) {
+	switch authority {
+	case "mint":
+		return tokenprog.AuthorityTypeMintTokens, nil
+	case "freeze":
+		return tokenprog.AuthorityTypeFreezeAccount, nil
+	case "owner":
+		return tokenprog.AuthorityTypeAccountOwner, nil
+	case "close":
+		return tokenprog.AuthorityTypeCloseAccount, nil
+	}
+
+	return 0, fmt.Errorf("unknown authority type: %v", authority)
+}
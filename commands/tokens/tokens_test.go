@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/solanatest"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestTokenOperator(t *testing.T) {
+	server := solanatest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	feePayer, mintAuthority, holder := types.NewAccount(), types.NewAccount(), types.NewAccount()
+	server.Airdrop(feePayer.PublicKey, 10_000_000_000)
+
+	op, err := NewTokenOperator(feePayer, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := op.CreateMint(ctx, mintAuthority.PublicKey, nil, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := op.CreateTokenAccount(ctx, created.Mint, feePayer.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination, err := op.CreateTokenAccount(ctx, created.Mint, holder.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := op.CreateTokenAccount(ctx, created.Mint, holder.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Signature != "" || again.TokenAccount != destination.TokenAccount {
+		t.Fatalf("existing token account is created again: %+v", again)
+	}
+
+	if _, err := op.MintTo(ctx, created.Mint, source.TokenAccount, mintAuthority, 1_000_000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := op.Transfer(ctx, source.TokenAccount, destination.TokenAccount, feePayer, 400_000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := op.Burn(ctx, destination.TokenAccount, created.Mint, holder, 100_000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := op.Transfer(ctx, source.TokenAccount, destination.TokenAccount, feePayer, 1_000_000); err == nil {
+		t.Fatal("transfer over the balance is accepted")
+	}
+	if _, err := op.MintTo(ctx, created.Mint, source.TokenAccount, holder, 1); err == nil {
+		t.Fatal("mint by a foreign authority is accepted")
+	}
+
+	mint, err := op.GetMint(ctx, created.Mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mint.Supply != 900_000 || mint.Decimals != 6 || mint.FreezeAuthority != nil {
+		t.Fatalf("unexpected mint: %+v", mint)
+	}
+
+	for tokenAccount, expected := range map[*CreateTokenAccountResult]uint64{source: 600_000, destination: 300_000} {
+		account, err := op.GetTokenAccount(ctx, tokenAccount.TokenAccount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if account.Amount != expected || account.Owner != tokenAccount.Owner || account.Mint != created.Mint {
+			t.Fatalf("unexpected token account: %+v", account)
+		}
+	}
+}
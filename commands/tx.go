@@ -0,0 +1,324 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+	"github.com/Gravity-Tech/solanoid/commands/signer"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	txOutput       string
+	txFeePayer     string
+	txBlockhash    string
+	txNonceAccount string
+	txInstructions string
+	txConsuls      []string
+	txKeys         []string
+	txRemoteSigner string
+	txRemoteKeys   []string
+	txCommitment   string
+	txTimeout      time.Duration
+
+	txCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "tx",
+		Short: "Offline signing of transactions required to be signed by several parties",
+		Long: `Build the unsigned message to a file, pass it to every signer, merge the signed copies and send.
+The message is bound to the blockhash, so all of the signatures have to be collected in ~2 minutes,
+unless it is built with --nonce-account. The nonce authority signs such a transaction too.`,
+	}
+	txBuildCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "build",
+		Short: "Build the unsigned transaction from the JSON instructions file",
+		Long: `Instructions file is the JSON list of {"programId", "accounts": [{"pubkey", "isSigner", "isWritable"}], "data"},
+data is base64 encoded.`,
+		Args: cobra.NoArgs,
+		Run:  txBuild,
+	}
+	txBuildUpdateConsulsCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "update-consuls",
+		Short: "Build the unsigned Gravity update consuls transaction",
+		Args:  cobra.NoArgs,
+		Run:   txBuildUpdateConsuls,
+	}
+	txSignCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "sign <tx-file>",
+		Short: "Sign the transaction file with the keys, the file is updated in place unless --output is set",
+		Args:  cobra.ExactArgs(1),
+		Run:   txSign,
+	}
+	txMergeCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "merge <tx-file>...",
+		Short: "Merge the signatures of the copies of the same transaction",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   txMerge,
+	}
+	txSendCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "send <tx-file>",
+		Short: "Broadcast the fully signed transaction and await the commitment",
+		Args:  cobra.ExactArgs(1),
+		Run:   txSend,
+	}
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{txBuildCmd, txBuildUpdateConsulsCmd} {
+		cmd.Flags().StringVarP(&txOutput, "output", "o", "", "transaction file to write")
+		cmd.MarkFlagRequired("output")
+
+		cmd.Flags().StringVar(&txFeePayer, "fee-payer", "", "fee payer public key")
+		cmd.MarkFlagRequired("fee-payer")
+
+		cmd.Flags().StringVar(&txBlockhash, "blockhash", "", "recent blockhash, fetched from --url if omitted")
+		cmd.Flags().StringVar(&txNonceAccount, "nonce-account", "", "durable nonce account, the message does not expire then; excludes --blockhash")
+		cmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	}
+
+	txBuildCmd.Flags().StringVarP(&txInstructions, "instructions", "i", "", "JSON instructions file")
+	viper.BindPFlag("instructions", txBuildCmd.Flags().Lookup("instructions"))
+	txBuildCmd.MarkFlagRequired("instructions")
+
+	txBuildUpdateConsulsCmd.Flags().StringVarP(&GravityProgramID, "program", "p", "", "Program ID")
+	txBuildUpdateConsulsCmd.MarkFlagRequired("program")
+	txBuildUpdateConsulsCmd.Flags().StringVarP(&GravityDataAccount, "data-account", "d", "", "Gravity Data Account")
+	txBuildUpdateConsulsCmd.MarkFlagRequired("data-account")
+	txBuildUpdateConsulsCmd.Flags().StringVarP(&MultisigDataAccount, "multisig-account", "m", "", "Gravity multisig Account")
+	txBuildUpdateConsulsCmd.MarkFlagRequired("multisig-account")
+	txBuildUpdateConsulsCmd.Flags().StringArrayVar(&txConsuls, "consul", nil, "consul public key, repeatable, up to 5")
+	txBuildUpdateConsulsCmd.MarkFlagRequired("consul")
+	txBuildUpdateConsulsCmd.Flags().Uint64VarP(&Round, "round", "r", 4, "consuls round")
+
+	txSignCmd.Flags().StringArrayVar(&txKeys, "key", nil, "keypair path or keystore:<path>, repeatable")
+	txSignCmd.Flags().StringVar(&txRemoteSigner, "remote-signer", "", "signer server URL, token is read from "+SignerTokenEnv)
+	txSignCmd.Flags().StringArrayVar(&txRemoteKeys, "remote-key", nil, "public key held by the signer server, repeatable; all required keys it serves if omitted")
+	txSignCmd.Flags().StringVarP(&txOutput, "output", "o", "", "signed transaction file, the input is overwritten if omitted")
+
+	txMergeCmd.Flags().StringVarP(&txOutput, "output", "o", "", "merged transaction file")
+	txMergeCmd.MarkFlagRequired("output")
+
+	txSendCmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	txSendCmd.Flags().StringVar(&txCommitment, "commitment", string(solclient.CommitmentConfirmed), "commitment to await: processed, confirmed or finalized")
+	txSendCmd.Flags().DurationVar(&txTimeout, "timeout", 2*time.Minute, "confirmation timeout")
+
+	txBuildCmd.AddCommand(txBuildUpdateConsulsCmd)
+	txCmd.AddCommand(txBuildCmd, txSignCmd, txMergeCmd, txSendCmd)
+	SolanoidCmd.AddCommand(txCmd)
+}
+
+func buildOfflineTransaction(instructions []types.Instruction) {
+	if txNonceAccount != "" && txBlockhash != "" {
+		log.Fatal("--blockhash and --nonce-account are mutually exclusive, the nonce replaces the blockhash")
+	}
+	if txNonceAccount != "" {
+		buildOfflineNonceTransaction(instructions)
+		return
+	}
+
+	blockhash := txBlockhash
+	var lastValidBlockHeight uint64
+	if blockhash == "" {
+		latest, err := executor.GetLatestBlockhash(context.Background(), resolveRPCEndpoint(rpcURL))
+		if err != nil {
+			log.Fatalf("get latest block hash error, err: %v\n", err)
+		}
+		blockhash, lastValidBlockHeight = latest.Blockhash, latest.LastValidBlockHeight
+	}
+
+	tx, err := executor.NewOfflineTransaction(common.PublicKeyFromString(txFeePayer), instructions, blockhash)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tx.LastValidBlockHeight = lastValidBlockHeight
+
+	if err := tx.Save(txOutput); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Transaction: %v \n", txOutput)
+	fmt.Printf("Blockhash: %v \n", blockhash)
+	printOfflineSigners(tx)
+}
+
+func buildOfflineNonceTransaction(instructions []types.Instruction) {
+	nonce, err := executor.GetDurableNonce(context.Background(), resolveRPCEndpoint(rpcURL), common.PublicKeyFromString(txNonceAccount))
+	if err != nil {
+		log.Fatalf("get durable nonce error, err: %v\n", err)
+	}
+
+	tx, err := executor.NewOfflineNonceTransaction(common.PublicKeyFromString(txFeePayer), nonce, instructions)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := tx.Save(txOutput); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Transaction: %v \n", txOutput)
+	fmt.Printf("Nonce: %v \n", nonce.Nonce)
+	fmt.Printf("Nonce authority: %v \n", nonce.Authority.ToBase58())
+	printOfflineSigners(tx)
+}
+
+func printOfflineSigners(tx *executor.OfflineTransaction) {
+	for _, pubkey := range tx.SignedBy() {
+		fmt.Printf("Signed: %v \n", pubkey)
+	}
+	for _, pubkey := range tx.Missing() {
+		fmt.Printf("Missing: %v \n", pubkey)
+	}
+}
+
+func txBuild(ccmd *cobra.Command, args []string) {
+	instructions, err := executor.ReadOfflineInstructions(txInstructions)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	buildOfflineTransaction(instructions)
+}
+
+func txBuildUpdateConsuls(ccmd *cobra.Command, args []string) {
+	if len(txConsuls) > 5 {
+		log.Fatalf("up to 5 consuls are supported, got %v", len(txConsuls))
+	}
+
+	consulsAddrs := [5][32]byte{}
+	for i, consul := range txConsuls {
+		copy(consulsAddrs[i][:], common.PublicKeyFromString(consul).Bytes())
+	}
+
+	buildOfflineTransaction([]types.Instruction{
+		NewUpdateConsulsInstruction(
+			common.PublicKeyFromString(txFeePayer),
+			common.PublicKeyFromString(GravityDataAccount),
+			common.PublicKeyFromString(GravityProgramID),
+			common.PublicKeyFromString(MultisigDataAccount),
+			uint8(len(txConsuls)), Round, consulsAddrs,
+		),
+	})
+}
+
+func txSign(ccmd *cobra.Command, args []string) {
+	tx, err := executor.ReadOfflineTransaction(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var delegates []executor.SignerDelegate
+	for _, path := range txKeys {
+		account, err := ReadAccountFromPath(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		delegates = append(delegates, executor.NewGravityBftSignerFromAccount(account))
+	}
+
+	if txRemoteSigner != "" {
+		token := os.Getenv(SignerTokenEnv)
+
+		remoteKeys := txRemoteKeys
+		if len(remoteKeys) == 0 {
+			served, err := signer.ListRemoteKeys(context.Background(), txRemoteSigner, token)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, key := range served {
+				for _, required := range tx.Missing() {
+					if key == required {
+						remoteKeys = append(remoteKeys, key)
+					}
+				}
+			}
+		}
+
+		for _, key := range remoteKeys {
+			delegates = append(delegates, signer.NewRemoteSigner(txRemoteSigner, token, key))
+		}
+	}
+
+	if len(delegates) == 0 {
+		log.Fatal("no keys to sign with, pass --key or --remote-signer")
+	}
+
+	if err := tx.Sign(delegates...); err != nil {
+		log.Fatal(err)
+	}
+
+	output := txOutput
+	if output == "" {
+		output = args[0]
+	}
+	if err := tx.Save(output); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Transaction: %v \n", output)
+	printOfflineSigners(tx)
+}
+
+func txMerge(ccmd *cobra.Command, args []string) {
+	transactions := make([]*executor.OfflineTransaction, len(args))
+	for i, path := range args {
+		tx, err := executor.ReadOfflineTransaction(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		transactions[i] = tx
+	}
+
+	merged, err := executor.MergeOfflineTransactions(transactions...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := merged.Save(txOutput); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Transaction: %v \n", txOutput)
+	printOfflineSigners(merged)
+}
+
+func txSend(ccmd *cobra.Command, args []string) {
+	tx, err := executor.ReadOfflineTransaction(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), txTimeout)
+	defer cancel()
+
+	response, err := tx.Send(ctx, resolveRPCEndpoint(rpcURL), solclient.Commitment(txCommitment))
+	if response != nil {
+		fmt.Printf("txHash: %v \n", response.TxSignature)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Slot: %v \n", response.Slot)
+	fmt.Printf("Status: %v \n", response.ConfirmationStatus)
+}
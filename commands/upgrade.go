@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+var (
+	upgradeProgramID    string
+	newUpgradeAuthority string
+	finalUpgrade        bool
+	bufferAddress       string
+	bufferRecipient     string
+
+	upgradeCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "upgrade",
+		Short: "Upgrade program deployed with BPF Upgradeable Loader",
+		Long:  ``,
+		Run:   upgrade,
+	}
+	setUpgradeAuthorityCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "set-upgrade-authority",
+		Short: "Change or revoke upgrade authority of the program",
+		Long:  ``,
+		Run:   setUpgradeAuthority,
+	}
+	closeBufferCmd = &cobra.Command{
+		Hidden: false,
+
+		Use:   "close-buffer",
+		Short: "Close program buffer and reclaim its lamports",
+		Long:  ``,
+		Run:   closeBuffer,
+	}
+)
+
+func init() {
+	upgradeCmd.Flags().StringVarP(&filename, "program-file", "p", "program.so", "Path to file for deploy (i.e. program.so)")
+	viper.BindPFlag("program-file", upgradeCmd.Flags().Lookup("program-file"))
+	upgradeCmd.MarkFlagRequired("program-file")
+
+	upgradeCmd.Flags().StringVar(&upgradeProgramID, "program-id", "", "Program ID")
+	viper.BindPFlag("program-id", upgradeCmd.Flags().Lookup("program-id"))
+	upgradeCmd.MarkFlagRequired("program-id")
+
+	upgradeCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "upgrade authority private key in base58 encoding or keystore:<path>, pays fees as well")
+	viper.BindPFlag("private-key", upgradeCmd.Flags().Lookup("private-key"))
+	upgradeCmd.MarkFlagRequired("private-key")
+
+	upgradeCmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	viper.BindPFlag("url", upgradeCmd.Flags().Lookup("url"))
+
+	upgradeCmd.Flags().StringVar(&bufferAddress, "buffer", "", "Buffer of the interrupted upgrade to resume")
+	viper.BindPFlag("buffer", upgradeCmd.Flags().Lookup("buffer"))
+
+	setUpgradeAuthorityCmd.Flags().StringVar(&upgradeProgramID, "program-id", "", "Program ID")
+	viper.BindPFlag("program-id", setUpgradeAuthorityCmd.Flags().Lookup("program-id"))
+	setUpgradeAuthorityCmd.MarkFlagRequired("program-id")
+
+	setUpgradeAuthorityCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "current upgrade authority private key in base58 encoding or keystore:<path>")
+	viper.BindPFlag("private-key", setUpgradeAuthorityCmd.Flags().Lookup("private-key"))
+	setUpgradeAuthorityCmd.MarkFlagRequired("private-key")
+
+	setUpgradeAuthorityCmd.Flags().StringVar(&newUpgradeAuthority, "new-upgrade-authority", "", "New upgrade authority address")
+	viper.BindPFlag("new-upgrade-authority", setUpgradeAuthorityCmd.Flags().Lookup("new-upgrade-authority"))
+
+	setUpgradeAuthorityCmd.Flags().BoolVar(&finalUpgrade, "final", false, "Make the program immutable")
+	viper.BindPFlag("final", setUpgradeAuthorityCmd.Flags().Lookup("final"))
+
+	setUpgradeAuthorityCmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	viper.BindPFlag("url", setUpgradeAuthorityCmd.Flags().Lookup("url"))
+
+	closeBufferCmd.Flags().StringVar(&bufferAddress, "buffer", "", "Buffer address")
+	viper.BindPFlag("buffer", closeBufferCmd.Flags().Lookup("buffer"))
+	closeBufferCmd.MarkFlagRequired("buffer")
+
+	closeBufferCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "buffer authority private key in base58 encoding or keystore:<path>")
+	viper.BindPFlag("private-key", closeBufferCmd.Flags().Lookup("private-key"))
+	closeBufferCmd.MarkFlagRequired("private-key")
+
+	closeBufferCmd.Flags().StringVar(&bufferRecipient, "recipient", "", "Lamports recipient, buffer authority by default")
+	viper.BindPFlag("recipient", closeBufferCmd.Flags().Lookup("recipient"))
+
+	closeBufferCmd.Flags().StringVarP(&rpcURL, "url", "u", "", "RPC endpoint, solana CLI config is used if omitted")
+	viper.BindPFlag("url", closeBufferCmd.Flags().Lookup("url"))
+
+	SolanoidCmd.AddCommand(upgradeCmd)
+	SolanoidCmd.AddCommand(setUpgradeAuthorityCmd)
+	SolanoidCmd.AddCommand(closeBufferCmd)
+}
+
+func upgradeableDeployerFromFlags() (*UpgradeableDeployer, types.Account) {
+	pk, err := DecodePrivateKey(privateKey)
+	if err != nil {
+		zap.L().Fatal(err.Error())
+	}
+	account := types.AccountFromPrivateKeyBytes(pk)
+
+	deployer, err := NewUpgradeableDeployer(account, resolveRPCEndpoint(rpcURL))
+	if err != nil {
+		zap.L().Fatal(err.Error())
+	}
+
+	return deployer, account
+}
+
+func upgrade(ccmd *cobra.Command, args []string) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		zap.L().Fatal(err.Error())
+	}
+
+	deployer, account := upgradeableDeployerFromFlags()
+
+	result, err := deployer.ResumeUpgradeProgram(context.Background(), common.PublicKeyFromString(upgradeProgramID), account, data, resumeBuffer())
+	if err != nil {
+		if result != nil {
+			fmt.Printf("Resume with: --buffer %s\n", result.Buffer.ToBase58())
+		}
+		log.Fatalf("Error on 'UpgradeProgram': %v\n", err)
+	}
+
+	fmt.Printf("Program PubKey(Id): %s\n", result.ProgramID.ToBase58())
+	fmt.Printf("Signature: %s\n", result.Signature)
+}
+
+func setUpgradeAuthority(ccmd *cobra.Command, args []string) {
+	if (newUpgradeAuthority == "") == !finalUpgrade {
+		log.Fatalf("either --new-upgrade-authority or --final must be provided\n")
+	}
+
+	deployer, account := upgradeableDeployerFromFlags()
+
+	var newAuthority *common.PublicKey
+	if !finalUpgrade {
+		authority := common.PublicKeyFromString(newUpgradeAuthority)
+		newAuthority = &authority
+	}
+
+	txSig, err := deployer.SetUpgradeAuthority(context.Background(), common.PublicKeyFromString(upgradeProgramID), account, newAuthority)
+	if err != nil {
+		log.Fatalf("Error on 'SetUpgradeAuthority': %v\n", err)
+	}
+
+	fmt.Printf("Signature: %s\n", txSig)
+}
+
+func closeBuffer(ccmd *cobra.Command, args []string) {
+	deployer, account := upgradeableDeployerFromFlags()
+
+	recipient := account.PublicKey
+	if bufferRecipient != "" {
+		recipient = common.PublicKeyFromString(bufferRecipient)
+	}
+
+	txSig, err := deployer.CloseBuffer(context.Background(), common.PublicKeyFromString(bufferAddress), account, recipient)
+	if err != nil {
+		log.Fatalf("Error on 'CloseBuffer': %v\n", err)
+	}
+
+	fmt.Printf("Signature: %s\n", txSig)
+}
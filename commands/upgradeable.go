@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Gravity-Tech/solanoid/instructions"
+
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/sysprog"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// UpgradeableWriteChunkSize keeps Write transaction with distinct payer and buffer authority under the packet limit
+const UpgradeableWriteChunkSize = 900
+
+type ProgramDeployResult struct {
+	ProgramID   common.PublicKey
+	ProgramData common.PublicKey
+	Buffer      common.PublicKey
+	Signature   string
+}
+
+// UpgradeableDeployer drives BPFLoaderUpgradeab1e on behalf of the fee payer
+type UpgradeableDeployer struct {
+	payer    types.Account
+	endpoint string
+	client   *solclient.Client
+
+	Uploader   *ChunkUploader
+	Commitment solclient.Commitment
+}
+
+func NewUpgradeableDeployer(payer types.Account, endpoint string) (*UpgradeableDeployer, error) {
+	return &UpgradeableDeployer{
+		payer:      payer,
+		endpoint:   endpoint,
+		client:     solclient.NewClient(endpoint),
+		Uploader:   NewChunkUploader(),
+		Commitment: solclient.CommitmentConfirmed,
+	}, nil
+}
+
+func (d *UpgradeableDeployer) send(ctx context.Context, ixs []types.Instruction, signers ...types.Account) (string, error) {
+	return sendSigned(ctx, d.endpoint, d.payer, ixs, d.Commitment, signers...)
+}
+
+func (d *UpgradeableDeployer) createAccountIx(ctx context.Context, account common.PublicKey, space uint64) (types.Instruction, error) {
+	rentBalance, err := d.client.GetMinimumBalanceForRentExemption(ctx, space)
+	if err != nil {
+		fmt.Printf("get min balance for rent exemption, err: %v\n", err)
+		return types.Instruction{}, err
+	}
+
+	return sysprog.CreateAccount(d.payer.PublicKey, account, instructions.BPFLoaderUpgradeableProgramID, rentBalance, space), nil
+}
+
+// CreateBuffer allocates and initializes the buffer account sized for the program binary
+func (d *UpgradeableDeployer) CreateBuffer(ctx context.Context, authority common.PublicKey, programLen int) (types.Account, error) {
+	buffer := types.NewAccount()
+
+	createIx, err := d.createAccountIx(ctx, buffer.PublicKey, instructions.UpgradeableBufferSize(programLen))
+	if err != nil {
+		return buffer, err
+	}
+
+	txSig, err := d.send(ctx, []types.Instruction{
+		createIx,
+		instructions.UpgradeableInitializeBufferInstruction(buffer.PublicKey, authority),
+	}, buffer)
+	if err != nil {
+		return buffer, err
+	}
+
+	log.Printf("buffer %v created: %v", buffer.PublicKey.ToBase58(), txSig)
+	return buffer, nil
+}
+
+func (d *UpgradeableDeployer) readBuffer(ctx context.Context, buffer, authority common.PublicKey, programLen int) ([]byte, error) {
+	data, owner, err := ReadAccountData(ctx, d.endpoint, buffer)
+	if err != nil {
+		return nil, err
+	}
+	if owner != instructions.BPFLoaderUpgradeableProgramID.ToBase58() {
+		return nil, fmt.Errorf("buffer %v is not owned by upgradeable loader", buffer.ToBase58())
+	}
+	if uint64(len(data)) != instructions.UpgradeableBufferSize(programLen) {
+		return nil, fmt.Errorf("buffer %v size %v does not fit program of %v bytes", buffer.ToBase58(), len(data), programLen)
+	}
+	// UpgradeableLoaderState::Buffer { authority_address: Some(authority) }
+	if data[0] != 1 || data[4] != 1 || !bytes.Equal(data[5:37], authority.Bytes()) {
+		return nil, fmt.Errorf("buffer %v is not writable by %v", buffer.ToBase58(), authority.ToBase58())
+	}
+
+	return data[instructions.UpgradeableBufferMetadataSize:], nil
+}
+
+// WriteBuffer uploads only the chunks which differ from the buffer contents, so it is safe to call again after a partial failure
+func (d *UpgradeableDeployer) WriteBuffer(ctx context.Context, buffer common.PublicKey, authority types.Account, data []byte) (*UploadReport, error) {
+	remote, err := d.readBuffer(ctx, buffer, authority.PublicKey, len(data))
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := DiffChunks(data, remote, UpgradeableWriteChunkSize)
+	total := len(splitArray(data, UpgradeableWriteChunkSize))
+
+	log.Printf("buffer %v: %d of %d chunks to upload", buffer.ToBase58(), len(chunks), total)
+
+	return d.Uploader.Upload(ctx, total, chunks, func(ctx context.Context, chunk UploadChunk) (string, error) {
+		return d.send(ctx, []types.Instruction{
+			instructions.UpgradeableWriteInstruction(buffer, authority.PublicKey, uint32(chunk.Offset), chunk.Data),
+		}, authority)
+	})
+}
+
+// UploadBuffer writes the binary to the existing buffer, a new one is created when buffer is nil.
+// Buffer address is returned on failure as well, pass it back to resume the upload.
+func (d *UpgradeableDeployer) UploadBuffer(ctx context.Context, authority types.Account, data []byte, buffer *common.PublicKey) (common.PublicKey, error) {
+	if buffer == nil {
+		created, err := d.CreateBuffer(ctx, authority.PublicKey, len(data))
+		if err != nil {
+			return created.PublicKey, err
+		}
+		buffer = &created.PublicKey
+	}
+
+	_, err := d.WriteBuffer(ctx, *buffer, authority, data)
+	if err != nil {
+		return *buffer, fmt.Errorf("upload to buffer %v: %v", buffer.ToBase58(), err)
+	}
+
+	return *buffer, nil
+}
+
+// DeployProgram writes the binary to a new buffer and deploys it to the program address.
+// Zero maxDataLen reserves twice the binary size for future upgrades, same as solana CLI.
+func (d *UpgradeableDeployer) DeployProgram(ctx context.Context, program types.Account, authority types.Account, data []byte, maxDataLen uint64) (*ProgramDeployResult, error) {
+	return d.ResumeDeployProgram(ctx, program, authority, data, maxDataLen, nil)
+}
+
+// ResumeDeployProgram is DeployProgram reusing the buffer left by an interrupted deploy
+func (d *UpgradeableDeployer) ResumeDeployProgram(ctx context.Context, program types.Account, authority types.Account, data []byte, maxDataLen uint64, buffer *common.PublicKey) (*ProgramDeployResult, error) {
+	if maxDataLen == 0 {
+		maxDataLen = uint64(len(data)) * 2
+	}
+	if maxDataLen < uint64(len(data)) {
+		return nil, fmt.Errorf("max data len %v is less than program size %v", maxDataLen, len(data))
+	}
+
+	bufferAddress, err := d.UploadBuffer(ctx, authority, data, buffer)
+	if err != nil {
+		return &ProgramDeployResult{ProgramID: program.PublicKey, Buffer: bufferAddress}, err
+	}
+
+	createIx, err := d.createAccountIx(ctx, program.PublicKey, instructions.UpgradeableProgramSize)
+	if err != nil {
+		return nil, err
+	}
+
+	deployIx, err := instructions.UpgradeableDeployWithMaxDataLenInstruction(d.payer.PublicKey, program.PublicKey, bufferAddress, authority.PublicKey, maxDataLen)
+	if err != nil {
+		return nil, err
+	}
+
+	txSig, err := d.send(ctx, []types.Instruction{createIx, deployIx}, program, authority)
+	if err != nil {
+		return &ProgramDeployResult{ProgramID: program.PublicKey, Buffer: bufferAddress}, err
+	}
+
+	programData, _ := instructions.UpgradeableProgramDataAddress(program.PublicKey)
+
+	return &ProgramDeployResult{
+		ProgramID:   program.PublicKey,
+		ProgramData: programData,
+		Buffer:      bufferAddress,
+		Signature:   txSig,
+	}, nil
+}
+
+// UpgradeProgram writes the binary to a new buffer and replaces the program with it. Buffer lamports are returned to the payer.
+func (d *UpgradeableDeployer) UpgradeProgram(ctx context.Context, programID common.PublicKey, authority types.Account, data []byte) (*ProgramDeployResult, error) {
+	return d.ResumeUpgradeProgram(ctx, programID, authority, data, nil)
+}
+
+// ResumeUpgradeProgram is UpgradeProgram reusing the buffer left by an interrupted upgrade
+func (d *UpgradeableDeployer) ResumeUpgradeProgram(ctx context.Context, programID common.PublicKey, authority types.Account, data []byte, buffer *common.PublicKey) (*ProgramDeployResult, error) {
+	bufferAddress, err := d.UploadBuffer(ctx, authority, data, buffer)
+	if err != nil {
+		return &ProgramDeployResult{ProgramID: programID, Buffer: bufferAddress}, err
+	}
+
+	upgradeIx, err := instructions.UpgradeableUpgradeInstruction(programID, bufferAddress, d.payer.PublicKey, authority.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	txSig, err := d.send(ctx, []types.Instruction{upgradeIx}, authority)
+	if err != nil {
+		return &ProgramDeployResult{ProgramID: programID, Buffer: bufferAddress}, err
+	}
+
+	programData, _ := instructions.UpgradeableProgramDataAddress(programID)
+
+	return &ProgramDeployResult{
+		ProgramID:   programID,
+		ProgramData: programData,
+		Buffer:      bufferAddress,
+		Signature:   txSig,
+	}, nil
+}
+
+// SetUpgradeAuthority with nil newAuthority makes the program immutable
+func (d *UpgradeableDeployer) SetUpgradeAuthority(ctx context.Context, programID common.PublicKey, currentAuthority types.Account, newAuthority *common.PublicKey) (string, error) {
+	programData, err := instructions.UpgradeableProgramDataAddress(programID)
+	if err != nil {
+		return "", err
+	}
+
+	return d.send(ctx, []types.Instruction{
+		instructions.UpgradeableSetAuthorityInstruction(programData, currentAuthority.PublicKey, newAuthority),
+	}, currentAuthority)
+}
+
+// CloseBuffer reclaims the lamports of the buffer left by an interrupted deploy
+func (d *UpgradeableDeployer) CloseBuffer(ctx context.Context, buffer common.PublicKey, authority types.Account, recipient common.PublicKey) (string, error) {
+	return d.send(ctx, []types.Instruction{
+		instructions.UpgradeableCloseInstruction(buffer, recipient, authority.PublicKey, nil),
+	}, authority)
+}
+
+// IsUpgradeableProgramDeployed reports whether the program account is already owned by the upgradeable loader
+func (d *UpgradeableDeployer) IsUpgradeableProgramDeployed(ctx context.Context, programID common.PublicKey) (bool, error) {
+	data, owner, err := ReadAccountData(ctx, d.endpoint, programID)
+	if err != nil {
+		return false, err
+	}
+	if owner == "" {
+		return false, nil
+	}
+	if owner != instructions.BPFLoaderUpgradeableProgramID.ToBase58() {
+		return false, fmt.Errorf("program account %v is owned by %v", programID.ToBase58(), owner)
+	}
+
+	// UpgradeableLoaderState::Program tag
+	return len(data) == instructions.UpgradeableProgramSize && data[0] == 2, nil
+}
@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/Gravity-Tech/solanoid/commands/executor"
+
+	"github.com/mr-tron/base58"
+	solclient "github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+const (
+	DefaultUploadWorkers = 8
+	DefaultUploadRetries = 3
+)
+
+type UploadChunk struct {
+	Offset int
+	Data   []byte
+}
+
+// ChunkSender submits a single chunk and blocks until it is confirmed
+type ChunkSender func(ctx context.Context, chunk UploadChunk) (string, error)
+
+type UploadReport struct {
+	Total   int
+	Skipped int
+	Sent    int
+	// Signatures by chunk offset
+	Signatures map[int]string
+	Failed     []UploadChunk
+}
+
+type ChunkUploader struct {
+	Workers int
+	Retries int
+}
+
+func NewChunkUploader() *ChunkUploader {
+	return &ChunkUploader{
+		Workers: DefaultUploadWorkers,
+		Retries: DefaultUploadRetries,
+	}
+}
+
+// DiffChunks splits data into chunks and keeps only those differing from the remote copy.
+// Fresh accounts are zero filled, so passing them as remote skips zero ranges of the binary too.
+func DiffChunks(data, remote []byte, chunkSize int) []UploadChunk {
+	var chunks []UploadChunk
+
+	for i, chunk := range splitArray(data, chunkSize) {
+		offset := i * chunkSize
+
+		end := offset + len(chunk)
+		if end <= len(remote) && bytes.Equal(chunk, remote[offset:end]) {
+			continue
+		}
+
+		chunks = append(chunks, UploadChunk{Offset: offset, Data: chunk})
+	}
+
+	return chunks
+}
+
+func (u *ChunkUploader) sendWithRetries(ctx context.Context, chunk UploadChunk, send ChunkSender) (string, error) {
+	var err error
+	var txSig string
+
+	for attempt := 0; attempt <= u.Retries; attempt++ {
+		txSig, err = send(ctx, chunk)
+		if err == nil {
+			return txSig, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		log.Printf("upload chunk at offset %d failed, attempt %d/%d: %v", chunk.Offset, attempt+1, u.Retries+1, err)
+	}
+
+	return "", err
+}
+
+// Upload sends chunks concurrently with at most Workers transactions in flight.
+// Failed chunks are collected in the report instead of aborting the rest, so the upload can be resumed.
+func (u *ChunkUploader) Upload(ctx context.Context, total int, chunks []UploadChunk, send ChunkSender) (*UploadReport, error) {
+	workers := u.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	report := &UploadReport{
+		Total:      total,
+		Skipped:    total - len(chunks),
+		Signatures: make(map[int]string, len(chunks)),
+	}
+
+	queue := make(chan UploadChunk)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for chunk := range queue {
+				txSig, err := u.sendWithRetries(ctx, chunk, send)
+
+				mu.Lock()
+				if err != nil {
+					report.Failed = append(report.Failed, chunk)
+				} else {
+					report.Sent++
+					report.Signatures[chunk.Offset] = txSig
+					log.Printf("upload program txHash [offset %d, %d/%d]: %s", chunk.Offset, report.Sent, len(chunks), txSig)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, chunk := range chunks {
+		select {
+		case queue <- chunk:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(report.Failed) > 0 {
+		sort.Slice(report.Failed, func(i, j int) bool {
+			return report.Failed[i].Offset < report.Failed[j].Offset
+		})
+		return report, fmt.Errorf("%d of %d chunks failed to upload, first failed offset: %d", len(report.Failed), len(chunks), report.Failed[0].Offset)
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// ReadAccountData returns nil data for missing accounts
+func ReadAccountData(ctx context.Context, endpoint string, address common.PublicKey) ([]byte, string, error) {
+	info, err := solclient.NewClient(endpoint).GetAccountInfo(ctx, address.ToBase58(), solclient.GetAccountInfoConfig{
+		Encoding: solclient.GetAccountInfoConfigEncodingBase64,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if info.Owner == "" {
+		return nil, "", nil
+	}
+
+	encoded, ok := info.Data.([]interface{})
+	if !ok || len(encoded) == 0 {
+		return nil, info.Owner, fmt.Errorf("unexpected account data encoding: %v", info.Data)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded[0].(string))
+	if err != nil {
+		return nil, info.Owner, err
+	}
+
+	return data, info.Owner, nil
+}
+
+// sendSigned uses a dedicated executor, so it is safe to call from concurrent workers
+func sendSigned(ctx context.Context, endpoint string, payer types.Account, ixs []types.Instruction, commitment solclient.Commitment, signers ...types.Account) (string, error) {
+	ge, err := executor.NewEmptyExecutor(base58.Encode(payer.PrivateKey), endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	additionalSigners := make([]executor.GravityBftSigner, 0, len(signers))
+	for _, signer := range signers {
+		if signer.PublicKey == payer.PublicKey {
+			continue
+		}
+		additionalSigners = append(additionalSigners, *executor.NewGravityBftSignerFromAccount(signer))
+	}
+	ge.SetAdditionalSigners(additionalSigners)
+
+	response, err := ge.SendAndConfirm(ctx, ixs, commitment)
+	if err != nil {
+		return "", err
+	}
+
+	return response.TxSignature, nil
+}
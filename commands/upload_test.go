@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/commands/solanatest"
+
+	"github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestDiffChunks(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 0, 0, 7, 8, 9}
+	remote := []byte{1, 2, 3, 0, 0, 0, 7, 8, 9}
+
+	chunks := DiffChunks(data, remote, 3)
+	if len(chunks) != 1 || chunks[0].Offset != 3 {
+		t.Fatalf("expected only the chunk at offset 3, got %+v", chunks)
+	}
+
+	// fresh zero filled account skips zero ranges only
+	chunks = DiffChunks(data, make([]byte, len(data)), 2)
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks to upload, got %+v", chunks)
+	}
+
+	chunks = DiffChunks(data, nil, 4)
+	if len(chunks) != 3 {
+		t.Fatalf("expected all chunks for missing remote, got %+v", chunks)
+	}
+}
+
+func TestChunkUploaderRetriesAndReportsFailures(t *testing.T) {
+	chunks := DiffChunks(make([]byte, 100), nil, 10)
+
+	var mu sync.Mutex
+	attempts := make(map[int]int)
+
+	uploader := &ChunkUploader{Workers: 4, Retries: 2}
+	report, err := uploader.Upload(context.Background(), len(chunks), chunks, func(ctx context.Context, chunk UploadChunk) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts[chunk.Offset]++
+		// offset 20 recovers on retry, offset 50 never succeeds
+		if chunk.Offset == 50 || (chunk.Offset == 20 && attempts[chunk.Offset] == 1) {
+			return "", fmt.Errorf("send failed")
+		}
+		return fmt.Sprintf("sig-%d", chunk.Offset), nil
+	})
+	ValidateErrorExistence(t, err)
+
+	if report.Sent != 9 || len(report.Failed) != 1 || report.Failed[0].Offset != 50 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if attempts[50] != 3 || attempts[20] != 2 {
+		t.Fatalf("unexpected attempts: %v", attempts)
+	}
+	if report.Signatures[20] != "sig-20" {
+		t.Fatalf("missing signature for retried chunk: %v", report.Signatures)
+	}
+}
+
+func TestCreateNewAccountForProgram(t *testing.T) {
+	server := solanatest.NewServer()
+	defer server.Close()
+
+	payer, program := types.NewAccount(), types.NewAccount()
+	server.Airdrop(payer.PublicKey, 1_000_000_000)
+
+	// the account is there once the call returns, no waiting before the upload
+	createNewAccountForProgram(client.NewClient(server.URL), server.URL, payer, program, 100)
+
+	account, ok := server.GetAccount(program.PublicKey)
+	if !ok || account.Owner != BPFLoader2ProgramID || len(account.Data) != 100 || account.Lamports != solanatest.MinimumBalanceForRentExemption(100) {
+		t.Fatalf("unexpected program account: %+v", account)
+	}
+}
@@ -0,0 +1,511 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	DefaultReconnectDelay    = time.Second
+	DefaultMaxReconnectDelay = time.Second * 30
+	// NotificationBuffer is the capacity of subscription channels, the connection
+	// stops reading when a subscriber falls behind by that many notifications
+	NotificationBuffer = 64
+)
+
+var (
+	ErrClientClosed       = errors.New("ws: client closed")
+	ErrConnectionLost     = errors.New("ws: connection lost")
+	ErrSubscriptionClosed = errors.New("ws: subscription closed")
+)
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcResponse struct {
+	Result json.RawMessage
+	Err    error
+}
+
+// pendingCall is the request awaiting its response, sub is set for the subscribe requests
+type pendingCall struct {
+	response chan rpcResponse
+	sub      *Subscription
+}
+
+// incomingMessage covers both responses and notifications
+type incomingMessage struct {
+	ID     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+	Method string          `json:"method"`
+	Params struct {
+		Result       json.RawMessage `json:"result"`
+		Subscription int             `json:"subscription"`
+	} `json:"params"`
+}
+
+// Client multiplexes Solana PubSub subscriptions over a single connection.
+// Dropped connection is redialed and every active subscription is registered again.
+type Client struct {
+	endpoint string
+	dialer   *websocket.Dialer
+
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	nextID   int
+	pending  map[int]pendingCall
+	subs     map[int]*Subscription
+	byServer map[int]*Subscription
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial connects to the PubSub endpoint, i.e. ws://127.0.0.1:8900
+func Dial(ctx context.Context, endpoint string) (*Client, error) {
+	c := &Client{
+		endpoint:          endpoint,
+		dialer:            websocket.DefaultDialer,
+		ReconnectDelay:    DefaultReconnectDelay,
+		MaxReconnectDelay: DefaultMaxReconnectDelay,
+		pending:           make(map[int]pendingCall),
+		subs:              make(map[int]*Subscription),
+		byServer:          make(map[int]*Subscription),
+		closed:            make(chan struct{}),
+	}
+
+	conn, _, err := c.dialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+
+	go c.run(conn)
+
+	return c, nil
+}
+
+// Close drops the connection and closes every subscription channel
+func (c *Client) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.closed)
+
+		c.mu.Lock()
+		conn := c.conn
+		c.conn = nil
+		subs := make([]*Subscription, 0, len(c.subs))
+		for _, sub := range c.subs {
+			subs = append(subs, sub)
+		}
+		c.mu.Unlock()
+
+		if conn != nil {
+			err = conn.Close()
+		}
+		for _, sub := range subs {
+			sub.finish(ErrClientClosed)
+		}
+	})
+
+	return err
+}
+
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) run(conn *websocket.Conn) {
+	for {
+		err := c.readLoop(conn)
+		if c.isClosed() {
+			return
+		}
+		log.Printf("ws: connection to %s lost: %v", c.endpoint, err)
+
+		c.dropConnection()
+
+		conn = c.reconnect()
+		if conn == nil {
+			return
+		}
+
+		go c.resubscribeAll()
+	}
+}
+
+// dropConnection fails requests in flight, their responses are not coming anymore
+func (c *Client) dropConnection() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+	for id, pending := range c.pending {
+		pending.response <- rpcResponse{Err: ErrConnectionLost}
+		delete(c.pending, id)
+	}
+	c.byServer = make(map[int]*Subscription)
+}
+
+func (c *Client) reconnect() *websocket.Conn {
+	delay := c.ReconnectDelay
+
+	for {
+		select {
+		case <-c.closed:
+			return nil
+		case <-time.After(delay):
+		}
+
+		conn, _, err := c.dialer.Dial(c.endpoint, nil)
+		if err == nil {
+			c.mu.Lock()
+			if c.isClosed() {
+				c.mu.Unlock()
+				conn.Close()
+				return nil
+			}
+			c.conn = conn
+			c.mu.Unlock()
+
+			log.Printf("ws: reconnected to %s", c.endpoint)
+			return conn
+		}
+
+		log.Printf("ws: reconnect to %s failed: %v", c.endpoint, err)
+
+		delay *= 2
+		if delay > c.MaxReconnectDelay {
+			delay = c.MaxReconnectDelay
+		}
+	}
+}
+
+func (c *Client) resubscribeAll() {
+	c.mu.Lock()
+	subs := make([]*Subscription, 0, len(c.subs))
+	for _, sub := range c.subs {
+		subs = append(subs, sub)
+	}
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		ctx, cancel := context.WithTimeout(context.Background(), c.MaxReconnectDelay)
+		err := c.register(ctx, sub)
+		cancel()
+
+		if err != nil && !errors.Is(err, ErrConnectionLost) {
+			log.Printf("ws: %s resubscribe failed: %v", sub.method, err)
+			c.forget(sub)
+			sub.finish(err)
+		}
+	}
+}
+
+func (c *Client) readLoop(conn *websocket.Conn) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var incoming incomingMessage
+		if err := json.Unmarshal(message, &incoming); err != nil {
+			log.Printf("ws: malformed message: %s", message)
+			continue
+		}
+
+		if incoming.ID != nil {
+			c.resolve(*incoming.ID, &incoming)
+			continue
+		}
+		if incoming.Method != "" {
+			c.notify(&incoming)
+		}
+	}
+}
+
+// resolve hands the response to the caller. The subscription is bound to the server id right here,
+// so the notifications read next are not dropped while the caller is yet to be scheduled.
+func (c *Client) resolve(id int, incoming *incomingMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.pending[id]
+	delete(c.pending, id)
+
+	if !ok {
+		return
+	}
+
+	response := rpcResponse{Result: incoming.Result}
+	if incoming.Error != nil {
+		response.Err = incoming.Error
+	} else if pending.sub != nil {
+		response.Err = c.bind(pending.sub, incoming.Result)
+	}
+	pending.response <- response
+}
+
+// bind registers the server id of the subscription, c.mu is held
+func (c *Client) bind(sub *Subscription, result json.RawMessage) error {
+	var serverID int
+	if err := json.Unmarshal(result, &serverID); err != nil {
+		return fmt.Errorf("ws: unexpected %s result: %s", sub.method, result)
+	}
+
+	if _, active := c.subs[sub.id]; !active {
+		// unsubscribed while the request was in flight
+		return ErrSubscriptionClosed
+	}
+	sub.serverID = serverID
+	c.byServer[serverID] = sub
+
+	return nil
+}
+
+func (c *Client) notify(incoming *incomingMessage) {
+	c.mu.Lock()
+	sub, ok := c.byServer[incoming.Params.Subscription]
+	c.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	sub.deliver(incoming.Params.Result)
+
+	if sub.oneShot {
+		// server drops one shot subscriptions itself after the notification
+		c.forget(sub)
+		sub.finish(nil)
+	}
+}
+
+// call sends the request and waits for its response
+func (c *Client) call(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+	return c.callFor(ctx, nil, method, params)
+}
+
+// callFor sends the request on behalf of the subscription, nil sub stands for the plain call
+func (c *Client) callFor(ctx context.Context, sub *Subscription, method string, params []interface{}) (json.RawMessage, error) {
+	c.mu.Lock()
+	conn := c.conn
+	if conn == nil {
+		c.mu.Unlock()
+		if c.isClosed() {
+			return nil, ErrClientClosed
+		}
+		return nil, ErrConnectionLost
+	}
+	c.nextID++
+	id := c.nextID
+	response := make(chan rpcResponse, 1)
+	c.pending[id] = pendingCall{response: response, sub: sub}
+	c.mu.Unlock()
+
+	request, err := json.Marshal(RequestBody{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		c.cancelPending(id)
+		return nil, err
+	}
+
+	c.writeMu.Lock()
+	err = conn.WriteMessage(websocket.TextMessage, request)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.cancelPending(id)
+		return nil, err
+	}
+
+	select {
+	case result := <-response:
+		return result.Result, result.Err
+	case <-ctx.Done():
+		c.cancelPending(id)
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, ErrClientClosed
+	}
+}
+
+func (c *Client) cancelPending(id int) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// register subscribes on the current connection, the read loop binds the server id to the subscription
+func (c *Client) register(ctx context.Context, sub *Subscription) error {
+	_, err := c.callFor(ctx, sub, sub.method, sub.params)
+	return err
+}
+
+func (c *Client) forget(sub *Subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.subs, sub.id)
+	if current, ok := c.byServer[sub.serverID]; ok && current == sub {
+		delete(c.byServer, sub.serverID)
+	}
+}
+
+func (c *Client) subscribe(ctx context.Context, sub *Subscription) error {
+	if c.isClosed() {
+		return ErrClientClosed
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	sub.id = c.nextID
+	sub.client = c
+	c.subs[sub.id] = sub
+	c.mu.Unlock()
+
+	if err := c.register(ctx, sub); err != nil {
+		c.forget(sub)
+		sub.finish(err)
+		return err
+	}
+
+	return nil
+}
+
+// Subscription is the untyped part of every subscription, notifications are read from the typed channel
+type Subscription struct {
+	client *Client
+
+	id       int
+	serverID int
+
+	method            string
+	unsubscribeMethod string
+	params            []interface{}
+	oneShot           bool
+
+	// deliver decodes notification into the typed channel
+	deliver func(json.RawMessage)
+	// closeChannel closes the typed channel
+	closeChannel func()
+
+	mu     sync.Mutex
+	once   sync.Once
+	done   chan struct{}
+	err    error
+	closed bool
+}
+
+func newSubscription(method, unsubscribeMethod string, params []interface{}) *Subscription {
+	return &Subscription{
+		method:            method,
+		unsubscribeMethod: unsubscribeMethod,
+		params:            params,
+		done:              make(chan struct{}),
+	}
+}
+
+// Done is closed once the subscription channel is closed
+func (sub *Subscription) Done() <-chan struct{} {
+	return sub.done
+}
+
+// Err explains why the subscription channel was closed, nil for Unsubscribe and one shot subscriptions
+func (sub *Subscription) Err() error {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	return sub.err
+}
+
+func (sub *Subscription) finish(err error) {
+	sub.once.Do(func() {
+		close(sub.done)
+
+		sub.mu.Lock()
+		sub.err = err
+		sub.closed = true
+		if sub.closeChannel != nil {
+			sub.closeChannel()
+		}
+		sub.mu.Unlock()
+	})
+}
+
+// send runs push under the lock the typed channel is closed with, push must give up on Done
+func (sub *Subscription) send(push func()) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+	push()
+}
+
+// Unsubscribe closes the channel and tells the server to stop sending notifications
+func (sub *Subscription) Unsubscribe(ctx context.Context) error {
+	c := sub.client
+
+	c.mu.Lock()
+	_, active := c.subs[sub.id]
+	serverID := sub.serverID
+	_, registered := c.byServer[serverID]
+	c.mu.Unlock()
+
+	c.forget(sub)
+	sub.finish(nil)
+
+	if !active || !registered {
+		return nil
+	}
+
+	result, err := c.call(ctx, sub.unsubscribeMethod, []interface{}{serverID})
+	if err != nil {
+		if errors.Is(err, ErrConnectionLost) || errors.Is(err, ErrClientClosed) {
+			// server forgets subscriptions of the dropped connection
+			return nil
+		}
+		return err
+	}
+
+	var ok bool
+	if err := json.Unmarshal(result, &ok); err != nil || !ok {
+		return fmt.Errorf("ws: %s rejected: %s", sub.unsubscribeMethod, result)
+	}
+
+	return nil
+}
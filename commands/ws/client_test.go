@@ -0,0 +1,349 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakePubSub mimics the validator PubSub endpoint: it numbers subscriptions and pushes notifications on demand
+type fakePubSub struct {
+	t *testing.T
+
+	mu          sync.Mutex
+	conns       []*websocket.Conn
+	nextSubID   int
+	subscribed  map[int]string
+	subscribeCh chan string
+
+	// immediate is the notification pushed right after the subscribe result, by the subscribe method
+	immediate map[string]fakeNotification
+}
+
+type fakeNotification struct {
+	method, result string
+}
+
+func newFakePubSub(t *testing.T) (*fakePubSub, *httptest.Server) {
+	fake := &fakePubSub{
+		t:           t,
+		subscribed:  make(map[int]string),
+		subscribeCh: make(chan string, 16),
+		immediate:   make(map[string]fakeNotification),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func (f *fakePubSub) serve(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.conns = append(f.conns, conn)
+	f.mu.Unlock()
+
+	for {
+		var request RequestBody
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		var (
+			result    interface{}
+			immediate *fakeNotification
+		)
+		if strings.HasSuffix(request.Method, "Unsubscribe") {
+			f.mu.Lock()
+			id := int(request.Params[0].(float64))
+			_, ok := f.subscribed[id]
+			delete(f.subscribed, id)
+			f.mu.Unlock()
+			result = ok
+		} else {
+			f.mu.Lock()
+			f.nextSubID++
+			id := f.nextSubID
+			f.subscribed[id] = request.Method
+			if notification, ok := f.immediate[request.Method]; ok {
+				immediate = &notification
+			}
+			f.mu.Unlock()
+			result = id
+			select {
+			case f.subscribeCh <- request.Method:
+			default:
+			}
+		}
+
+		f.write(conn, map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+		if immediate != nil {
+			f.notifyConn(conn, immediate.method, result.(int), immediate.result)
+		}
+	}
+}
+
+func (f *fakePubSub) write(conn *websocket.Conn, message interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := conn.WriteJSON(message); err != nil {
+		f.t.Logf("fake write: %v", err)
+	}
+}
+
+func (f *fakePubSub) current() *websocket.Conn {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.conns[len(f.conns)-1]
+}
+
+// subID returns the latest server id for the method
+func (f *fakePubSub) subID(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	latest := 0
+	for id, m := range f.subscribed {
+		if m == method && id > latest {
+			latest = id
+		}
+	}
+	return latest
+}
+
+func (f *fakePubSub) notify(method string, subID int, result string) {
+	f.notifyConn(f.current(), method, subID, result)
+}
+
+func (f *fakePubSub) notifyConn(conn *websocket.Conn, method string, subID int, result string) {
+	f.write(conn, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params": map[string]interface{}{
+			"result":       json.RawMessage(result),
+			"subscription": subID,
+		},
+	})
+}
+
+func (f *fakePubSub) awaitSubscribe(t *testing.T, method string) {
+	select {
+	case got := <-f.subscribeCh:
+		if got != method {
+			t.Fatalf("expected %s, got %s", method, got)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("%s was not sent", method)
+	}
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestClientMultiplexesSubscriptions(t *testing.T) {
+	fake, server := newFakePubSub(t)
+	ctx := testContext(t)
+
+	client, err := Dial(ctx, wsURL(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	slots, err := client.SlotSubscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.awaitSubscribe(t, "slotSubscribe")
+
+	account, err := client.AccountSubscribe(ctx, "acc", CommitmentFinalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.awaitSubscribe(t, "accountSubscribe")
+
+	signature, err := client.SignatureSubscribe(ctx, "sig", CommitmentFinalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.awaitSubscribe(t, "signatureSubscribe")
+
+	fake.notify("accountNotification", fake.subID("accountSubscribe"), `{"context":{"slot":7},"value":{"data":["AQI=","base64"],"lamports":42,"owner":"prog"}}`)
+	fake.notify("slotNotification", fake.subID("slotSubscribe"), `{"parent":9,"root":1,"slot":10}`)
+	fake.notify("signatureNotification", fake.subID("signatureSubscribe"), `{"context":{"slot":11},"value":{"err":null}}`)
+
+	accountResult := <-account.C
+	if accountResult.Value.Lamports != 42 || accountResult.Context.Slot != 7 || accountResult.Value.Data[0] != "AQI=" {
+		t.Fatalf("unexpected account notification: %+v", accountResult)
+	}
+
+	slotResult := <-slots.C
+	if slotResult.Slot != 10 || slotResult.Parent != 9 {
+		t.Fatalf("unexpected slot notification: %+v", slotResult)
+	}
+
+	signatureResult, err := signature.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signatureResult.Context.Slot != 11 || signatureResult.Value.Err != nil {
+		t.Fatalf("unexpected signature notification: %+v", signatureResult)
+	}
+
+	// one shot subscription is closed after its notification
+	select {
+	case <-signature.Done():
+	case <-time.After(time.Second):
+		t.Fatal("signature subscription is not closed")
+	}
+}
+
+func TestClientUnsubscribe(t *testing.T) {
+	fake, server := newFakePubSub(t)
+	ctx := testContext(t)
+
+	client, err := Dial(ctx, wsURL(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	logs, err := client.LogsSubscribe(ctx, "acc", CommitmentFinalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.awaitSubscribe(t, "logsSubscribe")
+	subID := fake.subID("logsSubscribe")
+
+	if err := logs.Unsubscribe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fake.subID("logsSubscribe") != 0 {
+		t.Fatal("server still has the subscription")
+	}
+
+	// late notification is dropped, the channel is closed without error
+	fake.notify("logsNotification", subID, `{"context":{"slot":1},"value":{"signature":"sig","err":null,"logs":[]}}`)
+
+	if _, ok := <-logs.C; ok {
+		t.Fatal("notification delivered after unsubscribe")
+	}
+	if logs.Err() != nil {
+		t.Fatalf("unexpected error: %v", logs.Err())
+	}
+
+	// repeated unsubscribe is a no-op
+	if err := logs.Unsubscribe(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientResubscribesAfterReconnect(t *testing.T) {
+	fake, server := newFakePubSub(t)
+	ctx := testContext(t)
+
+	client, err := Dial(ctx, wsURL(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ReconnectDelay = time.Millisecond * 10
+	defer client.Close()
+
+	program, err := client.ProgramSubscribe(ctx, "prog", CommitmentConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.awaitSubscribe(t, "programSubscribe")
+	firstID := fake.subID("programSubscribe")
+
+	fake.current().Close()
+
+	fake.awaitSubscribe(t, "programSubscribe")
+	secondID := fake.subID("programSubscribe")
+	if secondID == firstID {
+		t.Fatal("subscription is not renewed")
+	}
+
+	// give the client a moment to bind the new server id
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		client.mu.Lock()
+		_, bound := client.byServer[secondID]
+		client.mu.Unlock()
+		if bound {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("new subscription id is not bound")
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	fake.notify("programNotification", secondID, `{"context":{"slot":3},"value":{"pubkey":"acc","account":{"data":["","base64"],"lamports":1,"owner":"prog"}}}`)
+
+	select {
+	case result := <-program.C:
+		if result.Value.Pubkey != "acc" || result.Value.Account.Owner != "prog" {
+			t.Fatalf("unexpected program notification: %+v", result)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("notification after reconnect is not delivered")
+	}
+
+	client.Close()
+
+	if _, ok := <-program.C; ok {
+		t.Fatal("channel is not closed")
+	}
+	if program.Err() != ErrClientClosed {
+		t.Fatalf("unexpected error: %v", program.Err())
+	}
+}
+
+func TestClientDeliversNotificationFollowingSubscribeResult(t *testing.T) {
+	fake, server := newFakePubSub(t)
+	fake.immediate["signatureSubscribe"] = fakeNotification{"signatureNotification", `{"context":{"slot":5},"value":{"err":null}}`}
+	ctx := testContext(t)
+
+	client, err := Dial(ctx, wsURL(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 200; i++ {
+		signature, err := client.SignatureSubscribe(ctx, "sig", CommitmentFinalized)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := signature.Wait(ctx)
+		if err != nil {
+			t.Fatalf("#%v notification is lost: %v", i, err)
+		}
+		if result.Context.Slot != 5 {
+			t.Fatalf("unexpected signature notification: %+v", result)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+)
+
+const (
+	CommitmentProcessed = "processed"
+	CommitmentConfirmed = "confirmed"
+	CommitmentFinalized = "finalized"
+)
+
+type NotificationContext struct {
+	Slot uint64 `json:"slot"`
+}
+
+// AccountValue is an account in base64 encoding, Data holds [payload, "base64"]
+type AccountValue struct {
+	Data       []string `json:"data"`
+	Executable bool     `json:"executable"`
+	Lamports   uint64   `json:"lamports"`
+	Owner      string   `json:"owner"`
+	RentEpoch  uint64   `json:"rentEpoch"`
+}
+
+type AccountNotificationResult struct {
+	Context NotificationContext `json:"context"`
+	Value   AccountValue        `json:"value"`
+}
+
+type LogsNotificationResult struct {
+	Context NotificationContext `json:"context"`
+	Value   struct {
+		Signature string      `json:"signature"`
+		Err       interface{} `json:"err"`
+		Logs      []string    `json:"logs"`
+	} `json:"value"`
+}
+
+type SignatureNotificationResult struct {
+	Context NotificationContext `json:"context"`
+	Value   struct {
+		Err interface{} `json:"err"`
+	} `json:"value"`
+}
+
+type ProgramNotificationResult struct {
+	Context NotificationContext `json:"context"`
+	Value   struct {
+		Pubkey  string       `json:"pubkey"`
+		Account AccountValue `json:"account"`
+	} `json:"value"`
+}
+
+type SlotNotificationResult struct {
+	Parent uint64 `json:"parent"`
+	Root   uint64 `json:"root"`
+	Slot   uint64 `json:"slot"`
+}
+
+func commitmentConfig(commitment string) map[string]interface{} {
+	config := map[string]interface{}{}
+	if commitment != "" {
+		config["commitment"] = commitment
+	}
+	return config
+}
+
+func accountConfig(commitment string) map[string]interface{} {
+	config := commitmentConfig(commitment)
+	config["encoding"] = "base64"
+	return config
+}
+
+func decodeNotification(sub *Subscription, raw json.RawMessage, v interface{}) bool {
+	if err := json.Unmarshal(raw, v); err != nil {
+		log.Printf("ws: malformed %s notification: %s", sub.method, raw)
+		return false
+	}
+	return true
+}
+
+// subscribeChannel registers the subscription which decodes the notifications into the elements of ch,
+// a chan of the notification result type. The channel is closed along with the subscription.
+func (c *Client) subscribeChannel(ctx context.Context, sub *Subscription, ch interface{}) error {
+	channel := reflect.ValueOf(ch)
+	resultType := channel.Type().Elem()
+
+	sub.closeChannel = func() { channel.Close() }
+	sub.deliver = func(raw json.RawMessage) {
+		result := reflect.New(resultType)
+		if !decodeNotification(sub, raw, result.Interface()) {
+			return
+		}
+		sub.send(func() {
+			reflect.Select([]reflect.SelectCase{
+				{Dir: reflect.SelectSend, Chan: channel, Send: result.Elem()},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.done)},
+			})
+		})
+	}
+
+	return c.subscribe(ctx, sub)
+}
+
+type AccountSubscription struct {
+	*Subscription
+	C <-chan AccountNotificationResult
+}
+
+// AccountSubscribe notifies on every change of account lamports or data
+func (c *Client) AccountSubscribe(ctx context.Context, account string, commitment string) (*AccountSubscription, error) {
+	ch := make(chan AccountNotificationResult, NotificationBuffer)
+	sub := newSubscription("accountSubscribe", "accountUnsubscribe", []interface{}{account, accountConfig(commitment)})
+	if err := c.subscribeChannel(ctx, sub, ch); err != nil {
+		return nil, err
+	}
+
+	return &AccountSubscription{Subscription: sub, C: ch}, nil
+}
+
+type LogsSubscription struct {
+	*Subscription
+	C <-chan LogsNotificationResult
+}
+
+// LogsSubscribe notifies on transactions mentioning the address, empty mention subscribes to all transactions
+func (c *Client) LogsSubscribe(ctx context.Context, mention string, commitment string) (*LogsSubscription, error) {
+	var filter interface{} = "all"
+	if mention != "" {
+		filter = LogsSubscribeParam{Mentions: []string{mention}}
+	}
+
+	ch := make(chan LogsNotificationResult, NotificationBuffer)
+	sub := newSubscription("logsSubscribe", "logsUnsubscribe", []interface{}{filter, commitmentConfig(commitment)})
+	if err := c.subscribeChannel(ctx, sub, ch); err != nil {
+		return nil, err
+	}
+
+	return &LogsSubscription{Subscription: sub, C: ch}, nil
+}
+
+type SignatureSubscription struct {
+	*Subscription
+	C <-chan SignatureNotificationResult
+}
+
+// SignatureSubscribe delivers a single notification once the transaction reaches the commitment, then the channel is closed
+func (c *Client) SignatureSubscribe(ctx context.Context, signature string, commitment string) (*SignatureSubscription, error) {
+	ch := make(chan SignatureNotificationResult, 1)
+	sub := newSubscription("signatureSubscribe", "signatureUnsubscribe", []interface{}{signature, commitmentConfig(commitment)})
+	sub.oneShot = true
+	if err := c.subscribeChannel(ctx, sub, ch); err != nil {
+		return nil, err
+	}
+
+	return &SignatureSubscription{Subscription: sub, C: ch}, nil
+}
+
+// Wait blocks until the signature notification, it returns the transaction error if any
+func (sub *SignatureSubscription) Wait(ctx context.Context) (*SignatureNotificationResult, error) {
+	select {
+	case result, ok := <-sub.C:
+		if !ok {
+			if err := sub.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrSubscriptionClosed
+		}
+		return &result, nil
+	case <-ctx.Done():
+		sub.Unsubscribe(context.Background())
+		return nil, ctx.Err()
+	}
+}
+
+type ProgramSubscription struct {
+	*Subscription
+	C <-chan ProgramNotificationResult
+}
+
+// ProgramSubscribe notifies on changes of any account owned by the program
+func (c *Client) ProgramSubscribe(ctx context.Context, programID string, commitment string) (*ProgramSubscription, error) {
+	ch := make(chan ProgramNotificationResult, NotificationBuffer)
+	sub := newSubscription("programSubscribe", "programUnsubscribe", []interface{}{programID, accountConfig(commitment)})
+	if err := c.subscribeChannel(ctx, sub, ch); err != nil {
+		return nil, err
+	}
+
+	return &ProgramSubscription{Subscription: sub, C: ch}, nil
+}
+
+type SlotSubscription struct {
+	*Subscription
+	C <-chan SlotNotificationResult
+}
+
+// SlotSubscribe notifies on every slot processed by the validator
+func (c *Client) SlotSubscribe(ctx context.Context) (*SlotSubscription, error) {
+	ch := make(chan SlotNotificationResult, NotificationBuffer)
+	sub := newSubscription("slotSubscribe", "slotUnsubscribe", []interface{}{})
+	if err := c.subscribeChannel(ctx, sub, ch); err != nil {
+		return nil, err
+	}
+
+	return &SlotSubscription{Subscription: sub, C: ch}, nil
+}
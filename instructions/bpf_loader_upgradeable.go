@@ -0,0 +1,201 @@
+package instructions
+
+import (
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+var BPFLoaderUpgradeableProgramID = common.PublicKeyFromString("BPFLoaderUpgradeab1e11111111111111111111111")
+
+// UpgradeableLoaderState is bincode serialized, so enum tags and usize are u32 and u64 respectively
+const (
+	// Buffer { authority_address: Option<Pubkey> }
+	UpgradeableBufferMetadataSize = 4 + 1 + 32
+	// Program { programdata_address: Pubkey }
+	UpgradeableProgramSize = 4 + 32
+	// ProgramData { slot: u64, upgrade_authority_address: Option<Pubkey> }
+	UpgradeableProgramDataMetadataSize = 4 + 8 + 1 + 32
+)
+
+type UpgradeableLoaderInstruction uint32
+
+const (
+	UpgradeableInitializeBuffer UpgradeableLoaderInstruction = iota
+	UpgradeableWrite
+	UpgradeableDeployWithMaxDataLen
+	UpgradeableUpgrade
+	UpgradeableSetAuthority
+	UpgradeableClose
+)
+
+func UpgradeableBufferSize(programLen int) uint64 {
+	return uint64(UpgradeableBufferMetadataSize + programLen)
+}
+
+func UpgradeableProgramDataSize(maxDataLen uint64) uint64 {
+	return UpgradeableProgramDataMetadataSize + maxDataLen
+}
+
+func UpgradeableProgramDataAddress(programID common.PublicKey) (common.PublicKey, error) {
+	address, _, err := common.FindProgramAddress([][]byte{programID.Bytes()}, BPFLoaderUpgradeableProgramID)
+	return address, err
+}
+
+func serializeUpgradeable(data interface{}) []byte {
+	serialized, err := common.SerializeData(data)
+	if err != nil {
+		panic(err)
+	}
+	return serialized
+}
+
+// 0. [writable] source account to initialize.
+// 1. [] Buffer authority, optional, if omitted then the buffer will be immutable.
+func UpgradeableInitializeBufferInstruction(buffer, authority common.PublicKey) types.Instruction {
+	return types.Instruction{
+		ProgramID: BPFLoaderUpgradeableProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: buffer, IsSigner: false, IsWritable: true},
+			{PubKey: authority, IsSigner: false, IsWritable: false},
+		},
+		Data: serializeUpgradeable(UpgradeableInitializeBuffer),
+	}
+}
+
+//	Write {
+//	    offset: u32,
+//	    bytes: Vec<u8>,
+//	},
+//
+// 0. [writable] Buffer account to write program data to.
+// 1. [signer] Buffer authority
+func UpgradeableWriteInstruction(buffer, authority common.PublicKey, offset uint32, bytes []byte) types.Instruction {
+	return types.Instruction{
+		ProgramID: BPFLoaderUpgradeableProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: buffer, IsSigner: false, IsWritable: true},
+			{PubKey: authority, IsSigner: true, IsWritable: false},
+		},
+		Data: serializeUpgradeable(struct {
+			Instruction UpgradeableLoaderInstruction
+			Offset      uint32
+			Length      uint64
+			Bytes       []byte
+		}{
+			Instruction: UpgradeableWrite,
+			Offset:      offset,
+			Length:      uint64(len(bytes)),
+			Bytes:       bytes,
+		}),
+	}
+}
+
+//	DeployWithMaxDataLen {
+//	    max_data_len: usize,
+//	},
+//
+// 0. [writable, signer] The payer account that will pay to create the ProgramData account.
+// 1. [writable] The uninitialized ProgramData account.
+// 2. [writable] The uninitialized Program account.
+// 3. [writable] The Buffer account where the program data has been written.
+// 4. [] Rent sysvar.
+// 5. [] Clock sysvar.
+// 6. [] System program (`solana_sdk::system_program::id()`).
+// 7. [signer] The program's authority
+func UpgradeableDeployWithMaxDataLenInstruction(payer, program, buffer, authority common.PublicKey, maxDataLen uint64) (types.Instruction, error) {
+	programData, err := UpgradeableProgramDataAddress(program)
+	if err != nil {
+		return types.Instruction{}, err
+	}
+
+	return types.Instruction{
+		ProgramID: BPFLoaderUpgradeableProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: payer, IsSigner: true, IsWritable: true},
+			{PubKey: programData, IsSigner: false, IsWritable: true},
+			{PubKey: program, IsSigner: false, IsWritable: true},
+			{PubKey: buffer, IsSigner: false, IsWritable: true},
+			{PubKey: common.SysVarRentPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: common.SysVarClockPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: authority, IsSigner: true, IsWritable: false},
+		},
+		Data: serializeUpgradeable(struct {
+			Instruction UpgradeableLoaderInstruction
+			MaxDataLen  uint64
+		}{
+			Instruction: UpgradeableDeployWithMaxDataLen,
+			MaxDataLen:  maxDataLen,
+		}),
+	}, nil
+}
+
+// 0. [writable] The ProgramData account.
+// 1. [writable] The Program account.
+// 2. [writable] The Buffer account where the program data has been written.
+// 3. [writable] The spill account.
+// 4. [] Rent sysvar.
+// 5. [] Clock sysvar.
+// 6. [signer] The program's authority.
+func UpgradeableUpgradeInstruction(program, buffer, spill, authority common.PublicKey) (types.Instruction, error) {
+	programData, err := UpgradeableProgramDataAddress(program)
+	if err != nil {
+		return types.Instruction{}, err
+	}
+
+	return types.Instruction{
+		ProgramID: BPFLoaderUpgradeableProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: programData, IsSigner: false, IsWritable: true},
+			{PubKey: program, IsSigner: false, IsWritable: true},
+			{PubKey: buffer, IsSigner: false, IsWritable: true},
+			{PubKey: spill, IsSigner: false, IsWritable: true},
+			{PubKey: common.SysVarRentPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: common.SysVarClockPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: authority, IsSigner: true, IsWritable: false},
+		},
+		Data: serializeUpgradeable(UpgradeableUpgrade),
+	}, nil
+}
+
+//  0. [writable] The Buffer or ProgramData account to change the authority of.
+//  1. [signer] The current authority.
+//  2. [] The new authority, optional, if omitted then the program will not be upgradeable.
+//
+// Pass the ProgramData address as account to change the program upgrade authority.
+func UpgradeableSetAuthorityInstruction(account, currentAuthority common.PublicKey, newAuthority *common.PublicKey) types.Instruction {
+	accounts := []types.AccountMeta{
+		{PubKey: account, IsSigner: false, IsWritable: true},
+		{PubKey: currentAuthority, IsSigner: true, IsWritable: false},
+	}
+	if newAuthority != nil {
+		accounts = append(accounts, types.AccountMeta{PubKey: *newAuthority, IsSigner: false, IsWritable: false})
+	}
+
+	return types.Instruction{
+		ProgramID: BPFLoaderUpgradeableProgramID,
+		Accounts:  accounts,
+		Data:      serializeUpgradeable(UpgradeableSetAuthority),
+	}
+}
+
+// 0. [writable] The account to close, if closing a program must be the ProgramData account.
+// 1. [writable] The account to deposit the closed account's lamports.
+// 2. [signer] The account's authority, Optional, required for initialized accounts.
+// 3. [writable] The associated Program account if the account to close is a ProgramData account.
+func UpgradeableCloseInstruction(account, recipient, authority common.PublicKey, program *common.PublicKey) types.Instruction {
+	accounts := []types.AccountMeta{
+		{PubKey: account, IsSigner: false, IsWritable: true},
+		{PubKey: recipient, IsSigner: false, IsWritable: true},
+		{PubKey: authority, IsSigner: true, IsWritable: false},
+	}
+	if program != nil {
+		accounts = append(accounts, types.AccountMeta{PubKey: *program, IsSigner: false, IsWritable: true})
+	}
+
+	return types.Instruction{
+		ProgramID: BPFLoaderUpgradeableProgramID,
+		Accounts:  accounts,
+		Data:      serializeUpgradeable(UpgradeableClose),
+	}
+}
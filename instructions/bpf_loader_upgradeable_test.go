@@ -0,0 +1,51 @@
+package instructions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+func TestUpgradeableWriteInstruction(t *testing.T) {
+	buffer := common.PublicKeyFromString("SRMuApVNdxXokk5GT7XD5cUUgXMBCoAz2LHeuAoKWRt")
+	authority := common.PublicKeyFromString("4k3Dyjzvzp8eMZWUXbBCjEvwSkkk59S5iCNLY3QrkX6R")
+
+	ix := UpgradeableWriteInstruction(buffer, authority, 900, []byte{0xde, 0xad})
+
+	expected := []byte{
+		1, 0, 0, 0, // Write
+		0x84, 3, 0, 0, // offset
+		2, 0, 0, 0, 0, 0, 0, 0, // vec len
+		0xde, 0xad,
+	}
+	if !bytes.Equal(ix.Data, expected) {
+		t.Fatalf("unexpected data: %v", ix.Data)
+	}
+	if !ix.Accounts[1].IsSigner || ix.Accounts[0].IsSigner || !ix.Accounts[0].IsWritable {
+		t.Fatalf("unexpected account metas: %+v", ix.Accounts)
+	}
+}
+
+func TestUpgradeableDeployWithMaxDataLenInstruction(t *testing.T) {
+	payer := common.PublicKeyFromString("SRMuApVNdxXokk5GT7XD5cUUgXMBCoAz2LHeuAoKWRt")
+	program := common.PublicKeyFromString("4k3Dyjzvzp8eMZWUXbBCjEvwSkkk59S5iCNLY3QrkX6R")
+
+	ix, err := UpgradeableDeployWithMaxDataLenInstruction(payer, program, payer, payer, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{2, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0}
+	if !bytes.Equal(ix.Data, expected) {
+		t.Fatalf("unexpected data: %v", ix.Data)
+	}
+
+	programData, err := UpgradeableProgramDataAddress(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ix.Accounts[1].PubKey != programData || len(ix.Accounts) != 8 {
+		t.Fatalf("unexpected account metas: %+v", ix.Accounts)
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// MaxBorshCollectionLen guards against garbage lengths, no Gravity account holds more entries than that
+const MaxBorshCollectionLen = 1 << 16
+
+// UUID is the 16 byte id of swaps, requests and subscriptions
+type UUID [16]byte
+
+func (id UUID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id UUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+// BorshReader decodes borsh serialized program state. The first failure is kept in Err
+// and turns every following read into a no-op, so decoders check it once at the end.
+type BorshReader struct {
+	data []byte
+	pos  int
+	Err  error
+}
+
+func NewBorshReader(data []byte) *BorshReader {
+	return &BorshReader{data: data}
+}
+
+func (r *BorshReader) Offset() int {
+	return r.pos
+}
+
+func (r *BorshReader) next(n int) []byte {
+	if r.Err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.Err = fmt.Errorf("borsh: unexpected end of data at offset %d, %d bytes requested of %d", r.pos, n, len(r.data))
+		return nil
+	}
+
+	chunk := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return chunk
+}
+
+func (r *BorshReader) U8() uint8 {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *BorshReader) Bool() bool {
+	return r.U8() != 0
+}
+
+func (r *BorshReader) U32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *BorshReader) U64() uint64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (r *BorshReader) U128() *big.Int {
+	b := r.next(16)
+	if b == nil {
+		return new(big.Int)
+	}
+
+	// little endian to big endian
+	be := make([]byte, 16)
+	for i := range b {
+		be[15-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func (r *BorshReader) PublicKey() common.PublicKey {
+	return common.PublicKeyFromBytes(r.next(common.PublicKeyLength))
+}
+
+func (r *BorshReader) UUID() UUID {
+	var id UUID
+	copy(id[:], r.next(len(id)))
+	return id
+}
+
+func (r *BorshReader) Fixed(n int) []byte {
+	return append([]byte(nil), r.next(n)...)
+}
+
+// Len reads u32 length prefix of Vec and HashMap
+func (r *BorshReader) Len() int {
+	n := r.U32()
+	if n > MaxBorshCollectionLen {
+		if r.Err == nil {
+			r.Err = fmt.Errorf("borsh: collection length %d at offset %d exceeds limit", n, r.pos-4)
+		}
+		return 0
+	}
+	return int(n)
+}
+
+// Bytes reads Vec<u8>
+func (r *BorshReader) Bytes() []byte {
+	return r.Fixed(r.Len())
+}
+
+// PublicKeys reads Vec<Pubkey>
+func (r *BorshReader) PublicKeys() []common.PublicKey {
+	n := r.Len()
+	keys := make([]common.PublicKey, 0, n)
+	for i := 0; i < n && r.Err == nil; i++ {
+		keys = append(keys, r.PublicKey())
+	}
+	return keys
+}
+
+// UUIDs reads Vec<[u8; 16]>
+func (r *BorshReader) UUIDs() []UUID {
+	n := r.Len()
+	ids := make([]UUID, 0, n)
+	for i := 0; i < n && r.Err == nil; i++ {
+		ids = append(ids, r.UUID())
+	}
+	return ids
+}
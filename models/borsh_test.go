@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestBorshReader(t *testing.T) {
+	data := []byte{
+		1,
+		0x2a, 0, 0, 0,
+		1, 0, 0, 0, 0, 0, 0, 1,
+		// u128 300
+		0x2c, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		// Vec<u8> [7, 8]
+		2, 0, 0, 0, 7, 8,
+	}
+
+	r := NewBorshReader(data)
+	if !r.Bool() || r.U32() != 42 || r.U64() != 1<<56+1 || r.U128().Int64() != 300 {
+		t.Fatalf("unexpected scalars, err: %v", r.Err)
+	}
+	if bytes := r.Bytes(); len(bytes) != 2 || bytes[1] != 8 {
+		t.Fatalf("unexpected bytes: %v", bytes)
+	}
+	if r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	r.U8()
+	if r.Err == nil {
+		t.Fatal("read past the end must fail")
+	}
+
+	r = NewBorshReader([]byte{0xff, 0xff, 0xff, 0xff})
+	if r.PublicKeys(); r.Err == nil {
+		t.Fatal("garbage collection length must fail")
+	}
+}
+
+func TestDecodeMultisig(t *testing.T) {
+	signers := []types.Account{types.NewAccount(), types.NewAccount()}
+
+	data := make([]byte, MultisigSize)
+	data[0], data[1], data[2] = 1, 2, 1
+	copy(data[3:], signers[0].PublicKey.Bytes())
+	copy(data[35:], signers[1].PublicKey.Bytes())
+
+	multisig, err := DecodeMultisig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if multisig.M != 1 || multisig.N != 2 || !multisig.IsInitialized || len(multisig.Signers) != 2 {
+		t.Fatalf("unexpected multisig: %+v", multisig)
+	}
+	if multisig.Signers[1] != signers[1].PublicKey {
+		t.Fatalf("signer mismatch: %v", multisig.Signers[1].ToBase58())
+	}
+
+	if _, err := DecodeMultisig(data[:100]); err == nil {
+		t.Fatal("short multisig must fail")
+	}
+}
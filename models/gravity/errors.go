@@ -0,0 +1,35 @@
+package gravity
+
+import "github.com/Gravity-Tech/solanoid/models"
+
+const Program = "Gravity"
+
+// Errors follow the error enum of the Gravity program, the code is the variant index.
+// The program source is github.com/Gravity-Tech/solana-adapter, src/gravity-core-adapter/gravity,
+// binaries/gravity.so is bound from its build by bind-symlink.sh. The revision is the one the binary
+// is built from, it is not pinned here, so TestGravityContract checks the table against the deployed binary.
+var (
+	ErrInvalidInstruction     = &models.ProgramError{Program: Program, Code: 0, Name: "InvalidInstruction", Message: "instruction data cannot be decoded"}
+	ErrNotRentExempt          = &models.ProgramError{Program: Program, Code: 1, Name: "NotRentExempt", Message: "data account is not rent exempt"}
+	ErrExpectedAmountMismatch = &models.ProgramError{Program: Program, Code: 2, Name: "ExpectedAmountMismatch", Message: "expected amount mismatch"}
+	ErrAmountOverflow         = &models.ProgramError{Program: Program, Code: 3, Name: "AmountOverflow", Message: "amount overflow"}
+	ErrInvalidBFT             = &models.ProgramError{Program: Program, Code: 4, Name: "InvalidBFT", Message: "bft exceeds the amount of consuls"}
+	ErrInvalidRound           = &models.ProgramError{Program: Program, Code: 5, Name: "InvalidRound", Message: "round must be greater than the last one"}
+	ErrAlreadyInitialized     = &models.ProgramError{Program: Program, Code: 6, Name: "AlreadyInitialized", Message: "data account is already initialized"}
+	ErrNotInitialized         = &models.ProgramError{Program: Program, Code: 7, Name: "NotInitialized", Message: "data account is not initialized"}
+	ErrInsufficientSignatures = &models.ProgramError{Program: Program, Code: 8, Name: "InsufficientSignatures", Message: "consul signatures do not reach bft"}
+	ErrAccessDenied           = &models.ProgramError{Program: Program, Code: 9, Name: "AccessDenied", Message: "signer is not allowed to call the instruction"}
+)
+
+var Errors = models.NewProgramErrors(Program,
+	ErrInvalidInstruction,
+	ErrNotRentExempt,
+	ErrExpectedAmountMismatch,
+	ErrAmountOverflow,
+	ErrInvalidBFT,
+	ErrInvalidRound,
+	ErrAlreadyInitialized,
+	ErrNotInitialized,
+	ErrInsufficientSignatures,
+	ErrAccessDenied,
+)
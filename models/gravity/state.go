@@ -0,0 +1,45 @@
+package gravity
+
+import (
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// GravityContract is the Gravity data account state
+//
+//	pub struct GravityContract {
+//	    pub initializer_pubkey: Pubkey,
+//	    pub is_state_initialized: bool,
+//	    pub bft: u8,
+//	    pub multisig_account: Pubkey,
+//	    pub last_round: u64,
+//	    pub consuls: Vec<Pubkey>,
+//	}
+type GravityContract struct {
+	InitializerPubkey  common.PublicKey
+	IsStateInitialized bool
+	Bft                uint8
+	MultisigAccount    common.PublicKey
+	LastRound          uint64
+	Consuls            []common.PublicKey
+}
+
+// DecodeGravityContract ignores the zeroed tail of the account
+func DecodeGravityContract(data []byte) (*GravityContract, error) {
+	r := models.NewBorshReader(data)
+
+	state := &GravityContract{
+		InitializerPubkey:  r.PublicKey(),
+		IsStateInitialized: r.Bool(),
+		Bft:                r.U8(),
+		MultisigAccount:    r.PublicKey(),
+		LastRound:          r.U64(),
+		Consuls:            r.PublicKeys(),
+	}
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	return state, nil
+}
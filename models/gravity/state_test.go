@@ -0,0 +1,44 @@
+package gravity
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestDecodeGravityContract(t *testing.T) {
+	initializer, multisig := types.NewAccount(), types.NewAccount()
+	consuls := []types.Account{types.NewAccount(), types.NewAccount(), types.NewAccount()}
+
+	// account is allocated with zeroed tail
+	data := make([]byte, 299)
+	copy(data[0:32], initializer.PublicKey.Bytes())
+	data[32] = 1
+	data[33] = 2
+	copy(data[34:66], multisig.PublicKey.Bytes())
+	binary.LittleEndian.PutUint64(data[66:74], 7)
+	binary.LittleEndian.PutUint32(data[74:78], uint32(len(consuls)))
+	for i, consul := range consuls {
+		copy(data[78+i*32:], consul.PublicKey.Bytes())
+	}
+
+	state, err := DecodeGravityContract(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.IsStateInitialized || state.Bft != 2 || state.LastRound != 7 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if state.InitializerPubkey != initializer.PublicKey || state.MultisigAccount != multisig.PublicKey {
+		t.Fatalf("unexpected keys: %+v", state)
+	}
+	if len(state.Consuls) != 3 || state.Consuls[2] != consuls[2].PublicKey {
+		t.Fatalf("unexpected consuls: %v", state.Consuls)
+	}
+
+	if _, err := DecodeGravityContract(data[:80]); err == nil {
+		t.Fatal("truncated consuls must fail")
+	}
+}
@@ -9,4 +9,16 @@ type CommandResponse struct {
 	TxSignature       string
 	Account           *types.Account
 	Message           *types.Message
+
+	Slot               uint64
+	ConfirmationStatus string
+	TxError            interface{}
+
+	Simulation *SimulationResult
+}
+
+type SimulationResult struct {
+	Logs          []string
+	UnitsConsumed uint64
+	Err           *TransactionError
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+const (
+	MultisigMaxSigners = 11
+	MultisigSize       = 3 + MultisigMaxSigners*common.PublicKeyLength
+)
+
+// Multisig is the multisig account of Gravity and Nebula, laid out as spl_token::state::Multisig
+//
+//	pub struct Multisig {
+//	    pub m: u8,
+//	    pub n: u8,
+//	    pub is_initialized: bool,
+//	    pub signers: [Pubkey; MAX_SIGNERS],
+//	}
+type Multisig struct {
+	M             uint8
+	N             uint8
+	IsInitialized bool
+	// Signers holds the first N signers only
+	Signers []common.PublicKey
+}
+
+func DecodeMultisig(data []byte) (*Multisig, error) {
+	if len(data) != MultisigSize {
+		return nil, fmt.Errorf("invalid multisig account size: %d", len(data))
+	}
+
+	r := NewBorshReader(data)
+	multisig := &Multisig{
+		M:             r.U8(),
+		N:             r.U8(),
+		IsInitialized: r.Bool(),
+	}
+	if multisig.N > MultisigMaxSigners {
+		return nil, fmt.Errorf("invalid multisig signers count: %d", multisig.N)
+	}
+
+	for i := 0; i < int(multisig.N); i++ {
+		multisig.Signers = append(multisig.Signers, r.PublicKey())
+	}
+
+	return multisig, r.Err
+}
@@ -0,0 +1,39 @@
+package nebula
+
+import "github.com/Gravity-Tech/solanoid/models"
+
+const Program = "Nebula"
+
+// Errors follow the error enum of the Nebula program, the code is the variant index.
+// The program source is github.com/Gravity-Tech/solana-adapter, src/gravity-core-adapter/nebula,
+// binaries/nebula.so is bound from its build by bind-symlink.sh. The revision is the one the binary
+// is built from, it is not pinned here, so TestNebulaDeployment checks the table against the deployed binary.
+var (
+	ErrInvalidInstruction     = &models.ProgramError{Program: Program, Code: 0, Name: "InvalidInstruction", Message: "instruction data cannot be decoded"}
+	ErrAlreadyInitialized     = &models.ProgramError{Program: Program, Code: 1, Name: "AlreadyInitialized", Message: "data account is already initialized"}
+	ErrNotInitialized         = &models.ProgramError{Program: Program, Code: 2, Name: "NotInitialized", Message: "data account is not initialized"}
+	ErrInvalidBFT             = &models.ProgramError{Program: Program, Code: 3, Name: "InvalidBFT", Message: "bft exceeds the amount of oracles"}
+	ErrAccessDenied           = &models.ProgramError{Program: Program, Code: 4, Name: "AccessDenied", Message: "caller is not an oracle"}
+	ErrInsufficientSignatures = &models.ProgramError{Program: Program, Code: 5, Name: "InsufficientSignatures", Message: "oracle signatures do not reach bft"}
+	ErrInvalidRound           = &models.ProgramError{Program: Program, Code: 6, Name: "InvalidRound", Message: "round must be greater than the last one"}
+	ErrSubscriptionExists     = &models.ProgramError{Program: Program, Code: 7, Name: "SubscriptionExists", Message: "subscription id is already registered"}
+	ErrInvalidDataType        = &models.ProgramError{Program: Program, Code: 8, Name: "InvalidDataType", Message: "value does not match the nebula data type"}
+	ErrPulseNotFound          = &models.ProgramError{Program: Program, Code: 9, Name: "PulseNotFound", Message: "pulse is not found"}
+	ErrDataHashMismatch       = &models.ProgramError{Program: Program, Code: 10, Name: "DataHashMismatch", Message: "value does not match the pulse data hash"}
+	ErrInvalidSubscriber      = &models.ProgramError{Program: Program, Code: 11, Name: "InvalidSubscriber", Message: "subscriber does not match the subscription"}
+)
+
+var Errors = models.NewProgramErrors(Program,
+	ErrInvalidInstruction,
+	ErrAlreadyInitialized,
+	ErrNotInitialized,
+	ErrInvalidBFT,
+	ErrAccessDenied,
+	ErrInsufficientSignatures,
+	ErrInvalidRound,
+	ErrSubscriptionExists,
+	ErrInvalidDataType,
+	ErrPulseNotFound,
+	ErrDataHashMismatch,
+	ErrInvalidSubscriber,
+)
@@ -0,0 +1,129 @@
+package nebula
+
+import (
+	"math/big"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+type Subscription struct {
+	SubscriptionID   models.UUID
+	Sender           common.PublicKey
+	ContractAddress  common.PublicKey
+	MinConfirmations uint8
+	Reward           uint64
+}
+
+type Pulse struct {
+	PulseID  uint64
+	DataHash []byte
+	Height   *big.Int
+	IsSent   bool
+}
+
+// NebulaContract is the Nebula data account state. Maps are flattened to slices sorted by key,
+// the same order borsh serializes them in.
+//
+//	pub struct NebulaContract {
+//	    pub rounds_dict: HashMap<PulseID, u8>,
+//	    subscriptions_queue: Vec<SubscriptionID>,
+//	    pub oracles: Vec<Pubkey>,
+//	    pub bft: u8,
+//	    pub multisig_account: Pubkey,
+//	    pub gravity_contract: Pubkey,
+//	    pub data_type: DataType,
+//	    pub last_round: PulseID,
+//	    pub last_pulse_id: PulseID,
+//	    subscriptions_map: HashMap<SubscriptionID, Subscription>,
+//	    pulses_map: HashMap<PulseID, Pulse>,
+//	    is_pulse_sent: HashMap<PulseID, bool>,
+//	    pub is_state_initialized: bool,
+//	    pub initializer_pubkey: Pubkey,
+//	}
+type NebulaContract struct {
+	Rounds             map[uint64]uint8
+	SubscriptionsQueue []models.UUID
+	Oracles            []common.PublicKey
+	Bft                uint8
+	MultisigAccount    common.PublicKey
+	GravityContract    common.PublicKey
+	DataType           uint8
+	LastRound          uint64
+	LastPulseID        uint64
+	Subscriptions      []Subscription
+	Pulses             []Pulse
+	IsStateInitialized bool
+	InitializerPubkey  common.PublicKey
+}
+
+// Subscription looks the subscription up by id
+func (state *NebulaContract) Subscription(id models.UUID) (*Subscription, bool) {
+	for i := range state.Subscriptions {
+		if state.Subscriptions[i].SubscriptionID == id {
+			return &state.Subscriptions[i], true
+		}
+	}
+	return nil, false
+}
+
+// DecodeNebulaContract ignores the zeroed tail of the account
+func DecodeNebulaContract(data []byte) (*NebulaContract, error) {
+	r := models.NewBorshReader(data)
+	state := &NebulaContract{}
+
+	roundsLen := r.Len()
+	state.Rounds = make(map[uint64]uint8, roundsLen)
+	for i := 0; i < roundsLen && r.Err == nil; i++ {
+		state.Rounds[r.U64()] = r.U8()
+	}
+
+	state.SubscriptionsQueue = r.UUIDs()
+	state.Oracles = r.PublicKeys()
+	state.Bft = r.U8()
+	state.MultisigAccount = r.PublicKey()
+	state.GravityContract = r.PublicKey()
+	state.DataType = r.U8()
+	state.LastRound = r.U64()
+	state.LastPulseID = r.U64()
+
+	subscriptionsLen := r.Len()
+	for i := 0; i < subscriptionsLen && r.Err == nil; i++ {
+		state.Subscriptions = append(state.Subscriptions, Subscription{
+			SubscriptionID:   r.UUID(),
+			Sender:           r.PublicKey(),
+			ContractAddress:  r.PublicKey(),
+			MinConfirmations: r.U8(),
+			Reward:           r.U64(),
+		})
+	}
+
+	pulsesLen := r.Len()
+	for i := 0; i < pulsesLen && r.Err == nil; i++ {
+		state.Pulses = append(state.Pulses, Pulse{
+			PulseID:  r.U64(),
+			DataHash: r.Bytes(),
+			Height:   r.U128(),
+		})
+	}
+
+	sentLen := r.Len()
+	sent := make(map[uint64]bool, sentLen)
+	for i := 0; i < sentLen && r.Err == nil; i++ {
+		sent[r.U64()] = r.Bool()
+	}
+
+	state.IsStateInitialized = r.Bool()
+	state.InitializerPubkey = r.PublicKey()
+
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	for i := range state.Pulses {
+		state.Pulses[i].IsSent = sent[state.Pulses[i].PulseID]
+	}
+
+	return state, nil
+}
@@ -0,0 +1,83 @@
+package nebula
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestDecodeNebulaContract(t *testing.T) {
+	oracle, multisig, gravity, subscriber, initializer := types.NewAccount(), types.NewAccount(), types.NewAccount(), types.NewAccount(), types.NewAccount()
+	subID := models.UUID{1, 2, 3}
+
+	var buf bytes.Buffer
+	le := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	// rounds_dict
+	le(uint32(1))
+	le(uint64(5))
+	le(uint8(1))
+	// subscriptions_queue
+	le(uint32(1))
+	buf.Write(subID[:])
+	// oracles
+	le(uint32(1))
+	buf.Write(oracle.PublicKey.Bytes())
+	le(uint8(1))
+	buf.Write(multisig.PublicKey.Bytes())
+	buf.Write(gravity.PublicKey.Bytes())
+	le(Bytes)
+	le(uint64(5))
+	le(uint64(3))
+	// subscriptions_map
+	le(uint32(1))
+	buf.Write(subID[:])
+	buf.Write(initializer.PublicKey.Bytes())
+	buf.Write(subscriber.PublicKey.Bytes())
+	le(uint8(1))
+	le(uint64(10))
+	// pulses_map
+	le(uint32(1))
+	le(uint64(3))
+	le(uint32(2))
+	buf.Write([]byte{0xaa, 0xbb})
+	le(uint64(77))
+	le(uint64(0))
+	// is_pulse_sent
+	le(uint32(1))
+	le(uint64(3))
+	le(uint8(1))
+	le(uint8(1))
+	buf.Write(initializer.PublicKey.Bytes())
+
+	data := make([]byte, 1500)
+	copy(data, buf.Bytes())
+
+	state, err := DecodeNebulaContract(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Rounds[5] != 1 || len(state.SubscriptionsQueue) != 1 || state.Bft != 1 || state.DataType != Bytes {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if len(state.Oracles) != 1 || state.Oracles[0] != oracle.PublicKey || state.GravityContract != gravity.PublicKey {
+		t.Fatalf("unexpected keys: %+v", state)
+	}
+	if state.LastRound != 5 || state.LastPulseID != 3 || !state.IsStateInitialized || state.InitializerPubkey != initializer.PublicKey {
+		t.Fatalf("unexpected tail: %+v", state)
+	}
+
+	subscription, ok := state.Subscription(subID)
+	if !ok || subscription.ContractAddress != subscriber.PublicKey || subscription.Reward != 10 {
+		t.Fatalf("unexpected subscription: %+v", subscription)
+	}
+
+	if len(state.Pulses) != 1 || state.Pulses[0].Height.Int64() != 77 || !state.Pulses[0].IsSent || !bytes.Equal(state.Pulses[0].DataHash, []byte{0xaa, 0xbb}) {
+		t.Fatalf("unexpected pulses: %+v", state.Pulses)
+	}
+}
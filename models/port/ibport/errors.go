@@ -0,0 +1,34 @@
+package ibport
+
+import "github.com/Gravity-Tech/solanoid/models"
+
+const Program = "IB Port"
+
+// Errors follow the error enum of the IB Port program, the code is the variant index.
+// The program source is github.com/Gravity-Tech/solana-adapter, src/gravity-core-adapter/ibport,
+// binaries/ibport.so is bound from its build by bind-symlink.sh. The revision is the one the binary
+// is built from, it is not pinned here, so TestIBPortAttachValue checks the table against the deployed binary.
+var (
+	ErrInvalidInstruction = &models.ProgramError{Program: Program, Code: 0, Name: "InvalidInstruction", Message: "instruction data cannot be decoded"}
+	// ErrRequestAlreadyProcessed prevents the double spend of the attached swap
+	ErrRequestAlreadyProcessed = &models.ProgramError{Program: Program, Code: 1, Name: "RequestAlreadyProcessed", Message: "swap request is already processed"}
+	ErrAccessDenied            = &models.ProgramError{Program: Program, Code: 2, Name: "AccessDenied", Message: "caller is neither the nebula nor the initializer"}
+	ErrInvalidRequestStatus    = &models.ProgramError{Program: Program, Code: 3, Name: "InvalidRequestStatus", Message: "request status does not allow the transition"}
+	ErrRequestNotFound         = &models.ProgramError{Program: Program, Code: 4, Name: "RequestNotFound", Message: "unwrap request is not found"}
+	ErrInvalidAttachedValue    = &models.ProgramError{Program: Program, Code: 5, Name: "InvalidAttachedValue", Message: "attached value cannot be decoded"}
+	ErrAmountOverflow          = &models.ProgramError{Program: Program, Code: 6, Name: "AmountOverflow", Message: "amount overflow"}
+	ErrAlreadyInitialized      = &models.ProgramError{Program: Program, Code: 7, Name: "AlreadyInitialized", Message: "data account is already initialized"}
+	ErrInvalidTokenAccount     = &models.ProgramError{Program: Program, Code: 8, Name: "InvalidTokenAccount", Message: "token account does not belong to the port token"}
+)
+
+var Errors = models.NewProgramErrors(Program,
+	ErrInvalidInstruction,
+	ErrRequestAlreadyProcessed,
+	ErrAccessDenied,
+	ErrInvalidRequestStatus,
+	ErrRequestNotFound,
+	ErrInvalidAttachedValue,
+	ErrAmountOverflow,
+	ErrAlreadyInitialized,
+	ErrInvalidTokenAccount,
+)
@@ -0,0 +1,142 @@
+package port
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+type RequestStatus uint8
+
+const (
+	RequestStatusNone RequestStatus = iota
+	RequestStatusNew
+	RequestStatusRejected
+	RequestStatusSuccess
+	RequestStatusReturned
+)
+
+func (status RequestStatus) String() string {
+	switch status {
+	case RequestStatusNone:
+		return "None"
+	case RequestStatusNew:
+		return "New"
+	case RequestStatusRejected:
+		return "Rejected"
+	case RequestStatusSuccess:
+		return "Success"
+	case RequestStatusReturned:
+		return "Returned"
+	}
+	return fmt.Sprintf("RequestStatus(%d)", uint8(status))
+}
+
+func (status RequestStatus) MarshalText() ([]byte, error) {
+	return []byte(status.String()), nil
+}
+
+// ForeignAddress is the receiver on the other chain, EVM addresses are left padded with zeros
+type ForeignAddress [32]byte
+
+func (address ForeignAddress) String() string {
+	return "0x" + hex.EncodeToString(address[:])
+}
+
+func (address ForeignAddress) MarshalText() ([]byte, error) {
+	return []byte(address.String()), nil
+}
+
+type SwapStatus struct {
+	SwapID models.UUID
+	Status RequestStatus
+}
+
+// UnwrapRequest is the pending transfer to the foreign chain
+//
+//	pub struct UnwrapRequest {
+//	    pub destination_address: ForeignAddress,
+//	    pub origin_address: Pubkey,
+//	    pub amount: u64,
+//	}
+type UnwrapRequest struct {
+	RequestID          models.UUID
+	DestinationAddress ForeignAddress
+	OriginAddress      common.PublicKey
+	Amount             uint64
+}
+
+// PortContract is the data account state shared by IB Port and LU Port
+//
+//	pub struct IBPortContract {
+//	    pub nebula_address: Pubkey,
+//	    pub token_address: Pubkey,
+//	    pub initializer_pubkey: Pubkey,
+//	    pub oracles: Vec<Pubkey>,
+//	    pub swap_status: HashMap<[u8; 16], RequestStatus>,
+//	    pub requests: HashMap<[u8; 16], UnwrapRequest>,
+//	    pub is_state_initialized: bool,
+//	    pub requests_queue: Vec<[u8; 16]>,
+//	}
+type PortContract struct {
+	NebulaAddress      common.PublicKey
+	TokenAddress       common.PublicKey
+	InitializerPubkey  common.PublicKey
+	Oracles            []common.PublicKey
+	SwapStatuses       []SwapStatus
+	Requests           []UnwrapRequest
+	IsStateInitialized bool
+	RequestsQueue      []models.UUID
+}
+
+// SwapStatus returns RequestStatusNone for unknown swaps, as the program does
+func (state *PortContract) SwapStatus(id models.UUID) RequestStatus {
+	for _, swap := range state.SwapStatuses {
+		if swap.SwapID == id {
+			return swap.Status
+		}
+	}
+	return RequestStatusNone
+}
+
+// DecodePortContract ignores the zeroed tail of the account
+func DecodePortContract(data []byte) (*PortContract, error) {
+	r := models.NewBorshReader(data)
+
+	state := &PortContract{
+		NebulaAddress:     r.PublicKey(),
+		TokenAddress:      r.PublicKey(),
+		InitializerPubkey: r.PublicKey(),
+		Oracles:           r.PublicKeys(),
+	}
+
+	statusesLen := r.Len()
+	for i := 0; i < statusesLen && r.Err == nil; i++ {
+		state.SwapStatuses = append(state.SwapStatuses, SwapStatus{
+			SwapID: r.UUID(),
+			Status: RequestStatus(r.U8()),
+		})
+	}
+
+	requestsLen := r.Len()
+	for i := 0; i < requestsLen && r.Err == nil; i++ {
+		request := UnwrapRequest{RequestID: r.UUID()}
+		copy(request.DestinationAddress[:], r.Fixed(32))
+		request.OriginAddress = r.PublicKey()
+		request.Amount = r.U64()
+
+		state.Requests = append(state.Requests, request)
+	}
+
+	state.IsStateInitialized = r.Bool()
+	state.RequestsQueue = r.UUIDs()
+
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	return state, nil
+}
@@ -0,0 +1,60 @@
+package port
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Gravity-Tech/solanoid/models"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+func TestDecodePortContract(t *testing.T) {
+	nebula, token, initializer, oracle, origin := types.NewAccount(), types.NewAccount(), types.NewAccount(), types.NewAccount(), types.NewAccount()
+	swapID, requestID := models.UUID{9}, models.UUID{7}
+
+	var buf bytes.Buffer
+	le := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	buf.Write(nebula.PublicKey.Bytes())
+	buf.Write(token.PublicKey.Bytes())
+	buf.Write(initializer.PublicKey.Bytes())
+	le(uint32(1))
+	buf.Write(oracle.PublicKey.Bytes())
+	// swap_status
+	le(uint32(1))
+	buf.Write(swapID[:])
+	le(uint8(RequestStatusSuccess))
+	// requests
+	le(uint32(1))
+	buf.Write(requestID[:])
+	buf.Write(bytes.Repeat([]byte{0x11}, 32))
+	buf.Write(origin.PublicKey.Bytes())
+	le(uint64(1000))
+	le(uint8(1))
+	// requests_queue
+	le(uint32(1))
+	buf.Write(requestID[:])
+
+	data := make([]byte, 20000)
+	copy(data, buf.Bytes())
+
+	state, err := DecodePortContract(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.NebulaAddress != nebula.PublicKey || state.TokenAddress != token.PublicKey || !state.IsStateInitialized {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if state.SwapStatus(swapID) != RequestStatusSuccess || state.SwapStatus(requestID) != RequestStatusNone {
+		t.Fatalf("unexpected swap statuses: %+v", state.SwapStatuses)
+	}
+	if len(state.Requests) != 1 || state.Requests[0].OriginAddress != origin.PublicKey || state.Requests[0].Amount != 1000 {
+		t.Fatalf("unexpected requests: %+v", state.Requests)
+	}
+	if len(state.RequestsQueue) != 1 || state.RequestsQueue[0] != requestID {
+		t.Fatalf("unexpected requests queue: %v", state.RequestsQueue)
+	}
+}
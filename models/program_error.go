@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// ProgramError is the variant of the program error enum, the code is its index,
+// so a failed instruction reports it as {"Custom": code}.
+// The declared errors are compared by identity, use errors.Is against them.
+type ProgramError struct {
+	Program string
+	Code    uint32
+	Name    string
+	Message string
+}
+
+func (e *ProgramError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%v: custom program error: %#x", e.Program, e.Code)
+	}
+	return fmt.Sprintf("%v: %v (%#x): %v", e.Program, e.Name, e.Code, e.Message)
+}
+
+// ProgramErrors is the error enum of the program
+type ProgramErrors struct {
+	Program string
+
+	codes map[uint32]*ProgramError
+}
+
+func NewProgramErrors(program string, errs ...*ProgramError) *ProgramErrors {
+	codes := make(map[uint32]*ProgramError, len(errs))
+	for _, err := range errs {
+		codes[err.Code] = err
+	}
+
+	return &ProgramErrors{Program: program, codes: codes}
+}
+
+// Lookup returns the declared error, undeclared codes get an anonymous error of the program
+func (p *ProgramErrors) Lookup(code uint32) *ProgramError {
+	if err, ok := p.codes[code]; ok {
+		return err
+	}
+	return &ProgramError{Program: p.Program, Code: code}
+}
+
+// TransactionFailure is the TransactionError with the failed program resolved.
+// It unwraps to the ProgramError if the program is known and the error is custom,
+// to the TransactionError otherwise.
+type TransactionFailure struct {
+	TxError *TransactionError
+	// ProgramID is the program which raised the error, it is the one the failed instruction invoked
+	// if the logs are not available. Zero unless it is InstructionError.
+	ProgramID common.PublicKey
+	// ProgramError is nil for builtin instruction errors and programs with no errors registered
+	ProgramError *ProgramError
+	// Logs are the "Program log:" messages of the failed instruction
+	Logs []string
+}
+
+func (e *TransactionFailure) Error() string {
+	message := e.TxError.Error()
+	if e.ProgramError != nil {
+		message = fmt.Sprintf("instruction #%d failed: %v", *e.TxError.InstructionIndex, e.ProgramError)
+	}
+	if len(e.Logs) > 0 {
+		message = fmt.Sprintf("%v; logs: %v", message, strings.Join(e.Logs, "; "))
+	}
+	return message
+}
+
+func (e *TransactionFailure) Unwrap() error {
+	if e.ProgramError != nil {
+		return e.ProgramError
+	}
+	return e.TxError
+}
+
+const (
+	programLogPrefix = "Program log: "
+	// top level instructions are logged as invoke [1], CPI go deeper
+	topLevelInvokeSuffix = " invoke [1]"
+	failedInfix          = " failed: "
+)
+
+// InstructionLogs returns the "Program log:" messages logged while the instruction at index was executed.
+// All messages are returned if the logs do not tell the instructions apart.
+func InstructionLogs(logs []string, index int) []string {
+	var messages []string
+	for _, line := range instructionLines(logs, index) {
+		if strings.HasPrefix(line, programLogPrefix) {
+			messages = append(messages, strings.TrimPrefix(line, programLogPrefix))
+		}
+	}
+	return messages
+}
+
+// FailedProgram is the program which raised the error of the instruction at index. The failure propagates
+// up the invocations and every caller logs it after the callee, so the first "failed:" line is the raising one.
+func FailedProgram(logs []string, index int) (common.PublicKey, bool) {
+	for _, line := range instructionLines(logs, index) {
+		if !strings.HasPrefix(line, "Program ") || !strings.Contains(line, failedInfix) {
+			continue
+		}
+
+		address := strings.TrimPrefix(line[:strings.Index(line, failedInfix)], "Program ")
+		decoded, err := base58.Decode(address)
+		if err != nil || len(decoded) != common.PublicKeyLength {
+			continue
+		}
+		return common.PublicKeyFromBytes(decoded), true
+	}
+
+	return common.PublicKey{}, false
+}
+
+// instructionLines are the log lines of the instruction at index, all lines if the logs do not tell the instructions apart
+func instructionLines(logs []string, index int) []string {
+	var instruction []string
+
+	current := -1
+	for _, line := range logs {
+		if strings.HasPrefix(line, "Program ") && strings.HasSuffix(line, topLevelInvokeSuffix) {
+			current++
+			continue
+		}
+		if current == index {
+			instruction = append(instruction, line)
+		}
+	}
+
+	if current < 0 {
+		return logs
+	}
+	return instruction
+}
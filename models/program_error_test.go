@@ -0,0 +1,55 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInstructionLogs(t *testing.T) {
+	logs := []string{
+		"Program 11111111111111111111111111111111 invoke [1]",
+		"Program 11111111111111111111111111111111 success",
+		"Program AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ invoke [1]",
+		"Program log: Instruction: AttachValue",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA invoke [2]",
+		"Program log: Instruction: MintTo",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA success",
+		"Program log: swap is already processed",
+		"Program AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ failed: custom program error: 0x1",
+	}
+
+	expected := []string{"Instruction: AttachValue", "Instruction: MintTo", "swap is already processed"}
+	if actual := InstructionLogs(logs, 1); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected logs of the failed instruction: %v", actual)
+	}
+	if actual := InstructionLogs(logs, 0); len(actual) != 0 {
+		t.Fatalf("unexpected logs of the system instruction: %v", actual)
+	}
+	if actual := InstructionLogs([]string{"Program log: a", "b"}, 3); !reflect.DeepEqual(actual, []string{"a"}) {
+		t.Fatalf("unexpected logs without invocations: %v", actual)
+	}
+
+	if program, ok := FailedProgram(logs, 1); !ok || program.ToBase58() != "AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ" {
+		t.Fatalf("unexpected failed program: %v", program.ToBase58())
+	}
+	if _, ok := FailedProgram(logs, 0); ok {
+		t.Fatal("succeeded instruction has no failed program")
+	}
+
+	// the callee fails first, the caller reports the same error after it
+	nested := []string{
+		"Program AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ invoke [1]",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA invoke [2]",
+		"Program log: Error: insufficient funds",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA failed: custom program error: 0x1",
+		"Program AH3QKaj942UUxDjaRaGh7hvdadsD8yfU9LRTa9KXfJkZ failed: custom program error: 0x1",
+	}
+	if program, ok := FailedProgram(nested, 0); !ok || program.ToBase58() != "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA" {
+		t.Fatalf("unexpected failed program of the nested failure: %v", program.ToBase58())
+	}
+
+	errs := NewProgramErrors("IB Port", &ProgramError{Program: "IB Port", Code: 1, Name: "RequestAlreadyProcessed"})
+	if errs.Lookup(1).Name != "RequestAlreadyProcessed" || errs.Lookup(42).Code != 42 || errs.Lookup(42).Program != "IB Port" {
+		t.Fatal("unexpected program error lookup")
+	}
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TransactionError is the decoded form of the err field returned by
+// simulateTransaction and getSignatureStatuses, e.g.
+//
+//	"BlockhashNotFound"
+//	{"InstructionError": [1, {"Custom": 6}]}
+//	{"InstructionError": [0, "InvalidAccountData"]}
+type TransactionError struct {
+	// Kind is the TransactionError variant, "InstructionError" for program failures
+	Kind string
+	// InstructionIndex is set for InstructionError only
+	InstructionIndex *int
+	// InstructionError is the InstructionError variant, "Custom" for program defined errors
+	InstructionError string
+	// Custom is the program defined error code
+	Custom *uint32
+
+	Raw interface{}
+}
+
+func (e *TransactionError) Error() string {
+	if e.InstructionIndex == nil {
+		return fmt.Sprintf("transaction error: %v", e.Kind)
+	}
+	if e.Custom != nil {
+		return fmt.Sprintf("instruction #%d failed: custom program error: %#x", *e.InstructionIndex, *e.Custom)
+	}
+	return fmt.Sprintf("instruction #%d failed: %v", *e.InstructionIndex, e.InstructionError)
+}
+
+func firstKey(value map[string]interface{}) (string, interface{}) {
+	for k, v := range value {
+		return k, v
+	}
+	return "", nil
+}
+
+func decodeInstructionError(txErr *TransactionError, value interface{}) error {
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return fmt.Errorf("unexpected InstructionError format: %v", value)
+	}
+
+	index, ok := pair[0].(float64)
+	if !ok {
+		return fmt.Errorf("unexpected instruction index: %v", pair[0])
+	}
+	i := int(index)
+	txErr.InstructionIndex = &i
+
+	switch ixErr := pair[1].(type) {
+	case string:
+		txErr.InstructionError = ixErr
+	case map[string]interface{}:
+		kind, inner := firstKey(ixErr)
+		txErr.InstructionError = kind
+
+		if kind == "Custom" {
+			code, ok := inner.(float64)
+			if !ok {
+				return fmt.Errorf("unexpected custom error code: %v", inner)
+			}
+			custom := uint32(code)
+			txErr.Custom = &custom
+		}
+	default:
+		return fmt.Errorf("unexpected InstructionError format: %v", pair[1])
+	}
+
+	return nil
+}
+
+// DecodeTransactionError accepts the err value as unmarshalled by encoding/json.
+// Nil is returned for nil input.
+func DecodeTransactionError(value interface{}) (*TransactionError, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	txErr := &TransactionError{Raw: value}
+
+	switch v := value.(type) {
+	case string:
+		txErr.Kind = v
+	case map[string]interface{}:
+		kind, inner := firstKey(v)
+		txErr.Kind = kind
+
+		if kind == "InstructionError" {
+			if err := decodeInstructionError(txErr, inner); err != nil {
+				return txErr, err
+			}
+		}
+	default:
+		return txErr, fmt.Errorf("unexpected transaction error format: %v", value)
+	}
+
+	return txErr, nil
+}
+
+// DecodeTransactionErrorJSON is DecodeTransactionError for the raw JSON value.
+func DecodeTransactionErrorJSON(raw json.RawMessage) (*TransactionError, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	return DecodeTransactionError(value)
+}
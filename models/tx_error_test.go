@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeTransactionError(t *testing.T) {
+	txErr, err := DecodeTransactionErrorJSON(json.RawMessage(`{"InstructionError":[1,{"Custom":6}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txErr.Kind != "InstructionError" || txErr.InstructionError != "Custom" {
+		t.Fatalf("unexpected decode result: %+v", txErr)
+	}
+	if *txErr.InstructionIndex != 1 || *txErr.Custom != 6 {
+		t.Fatalf("unexpected index or code: %v %v", *txErr.InstructionIndex, *txErr.Custom)
+	}
+
+	txErr, err = DecodeTransactionErrorJSON(json.RawMessage(`{"InstructionError":[0,"InvalidAccountData"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txErr.InstructionError != "InvalidAccountData" || txErr.Custom != nil {
+		t.Fatalf("unexpected decode result: %+v", txErr)
+	}
+
+	txErr, err = DecodeTransactionErrorJSON(json.RawMessage(`"BlockhashNotFound"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txErr.Kind != "BlockhashNotFound" || txErr.InstructionIndex != nil {
+		t.Fatalf("unexpected decode result: %+v", txErr)
+	}
+
+	txErr, err = DecodeTransactionErrorJSON(json.RawMessage(`null`))
+	if err != nil || txErr != nil {
+		t.Fatalf("expected nil for null error, got %+v, %v", txErr, err)
+	}
+}